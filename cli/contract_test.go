@@ -502,6 +502,138 @@ func TestComlileAndInvokeFunction(t *testing.T) {
 		require.Len(t, res.Stack, 1)
 		require.Equal(t, []byte("on update|sub update"), res.Stack[0].Value())
 	})
+
+	t.Run("Update via command", func(t *testing.T) {
+		nefName := path.Join(tmpDir, "updated.nef")
+		manifestName := path.Join(tmpDir, "updated.manifest.json")
+		e.Run(t, "neo-go", "contract", "compile",
+			"--config", "testdata/deploy/neo-go.yml",
+			"--in", "testdata/deploy/", // compile all files in dir
+			"--out", nefName, "--manifest", manifestName)
+
+		t.Cleanup(func() {
+			os.Remove(nefName)
+			os.Remove(manifestName)
+		})
+
+		t.Run("missing contract hash", func(t *testing.T) {
+			e.RunWithError(t, "neo-go", "contract", "update",
+				"--rpc-endpoint", "http://"+e.RPC.Addr,
+				"--wallet", validatorWallet, "--address", validatorAddr,
+				"--in", nefName, "--manifest", manifestName)
+		})
+		t.Run("missing manifest", func(t *testing.T) {
+			e.RunWithError(t, "neo-go", "contract", "update",
+				"--rpc-endpoint", "http://"+e.RPC.Addr,
+				"--wallet", validatorWallet, "--address", validatorAddr,
+				"--in", nefName, h.StringLE())
+		})
+		t.Run("too many data parameters", func(t *testing.T) {
+			e.RunWithError(t, "neo-go", "contract", "update",
+				"--rpc-endpoint", "http://"+e.RPC.Addr,
+				"--wallet", validatorWallet, "--address", validatorAddr,
+				"--in", nefName, "--manifest", manifestName,
+				h.StringLE(), "1", "2")
+		})
+
+		e.In.WriteString("one\r")
+		e.Run(t, "neo-go", "contract", "update",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			"--in", nefName, "--manifest", manifestName,
+			h.StringLE())
+		e.checkTxPersisted(t, "Sent invocation transaction ")
+
+		cs := e.Chain.GetContractState(h)
+		require.NotNil(t, cs)
+		require.Equal(t, uint16(2), cs.UpdateCounter)
+	})
+
+	t.Run("Destroy", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "contract", "destroy",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			h.StringLE(), "1")
+
+		e.In.WriteString("one\r")
+		e.Run(t, "neo-go", "contract", "destroy",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			h.StringLE())
+		e.checkTxPersisted(t, "Sent invocation transaction ")
+
+		require.Nil(t, e.Chain.GetContractState(h))
+	})
+}
+
+func TestContractManifestAddGroup(t *testing.T) {
+	e := newExecutor(t, true)
+
+	// For proper nef generation.
+	config.Version = "0.90.0-test"
+
+	tmpDir := path.Join(os.TempDir(), "neogo.test.addgroup")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	nefName := path.Join(tmpDir, "deploy.nef")
+	manifestName := path.Join(tmpDir, "deploy.manifest.json")
+	e.Run(t, "neo-go", "contract", "compile",
+		"--in", "testdata/deploy/main.go",
+		"--config", "testdata/deploy/neo-go.yml",
+		"--out", nefName, "--manifest", manifestName)
+
+	t.Run("missing sender", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "contract", "manifest", "add-group",
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			"--nef", nefName, "--manifest", manifestName)
+	})
+	t.Run("missing NEF", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "contract", "manifest", "add-group",
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			"--sender", validatorAddr, "--manifest", manifestName)
+	})
+	t.Run("missing wallet", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "contract", "manifest", "add-group",
+			"--sender", validatorAddr, "--nef", nefName, "--manifest", manifestName)
+	})
+
+	addGroup := func(t *testing.T) {
+		e.In.WriteString("one\r")
+		e.Run(t, "neo-go", "contract", "manifest", "add-group",
+			"--wallet", validatorWallet, "--address", validatorAddr,
+			"--sender", validatorAddr, "--nef", nefName, "--manifest", manifestName)
+	}
+	addGroup(t)
+	addGroup(t) // Signature is replaced for the same key.
+
+	rawNef, err := ioutil.ReadFile(nefName)
+	require.NoError(t, err)
+	nf, err := nef.FileFromBytes(rawNef)
+	require.NoError(t, err)
+	rawManifest, err := ioutil.ReadFile(manifestName)
+	require.NoError(t, err)
+	m := new(manifest.Manifest)
+	require.NoError(t, json.Unmarshal(rawManifest, m))
+	require.Len(t, m.Groups, 1)
+
+	sender, err := address.StringToUint160(validatorAddr)
+	require.NoError(t, err)
+	h := state.CreateContractHash(sender, nf.Checksum, m.Name)
+	require.NoError(t, manifest.Groups(m.Groups).AreValid(h))
+
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "contract", "deploy",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet, "--address", validatorAddr,
+		"--in", nefName, "--manifest", manifestName)
+	e.checkTxPersisted(t, "Sent invocation transaction ")
+
+	cs := e.Chain.GetContractState(h)
+	require.NotNil(t, cs)
+	require.Equal(t, m.Groups, cs.Manifest.Groups)
 }
 
 func TestContractInspect(t *testing.T) {
@@ -63,3 +63,48 @@ func TestDBRestore(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, d1, d2, "dumps differ")
 }
+
+func TestDBSnapshot(t *testing.T) {
+	tmpDir := path.Join(os.TempDir(), "neogo.snapshottest")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	cfg, err := config.LoadFile("../config/protocol.unit_testnet.yml")
+	require.NoError(t, err, "could not load config")
+	cfg.ApplicationConfiguration.DBConfiguration.Type = "leveldb"
+	cfg.ApplicationConfiguration.DBConfiguration.LevelDBOptions.DataDirectoryPath = path.Join(tmpDir, "neogotestchain")
+
+	out, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "protocol.unit_testnet.yml"), out, os.ModePerm))
+
+	e := newExecutor(t, false)
+	snapshotPath := path.Join(tmpDir, "snapshot")
+	exportArgs := []string{"neo-go", "db", "snapshot", "export", "--unittest",
+		"--config-path", tmpDir, "--out", snapshotPath}
+	importArgs := []string{"neo-go", "db", "snapshot", "import", "--unittest",
+		"--config-path", tmpDir, "--in", snapshotPath}
+
+	// Empty chain.
+	e.RunWithError(t, exportArgs...)
+	// Missing file.
+	e.RunWithError(t, importArgs...)
+	// Not a snapshot.
+	require.NoError(t, ioutil.WriteFile(snapshotPath, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, os.ModePerm))
+	e.RunWithError(t, importArgs...)
+	// Invalid state validator key.
+	e.RunWithError(t, append(importArgs, "--state-validators", "bad")...)
+
+	e.Run(t, "neo-go", "db", "restore", "--unittest",
+		"--config-path", tmpDir, "--in", "./testdata/chain50x2.acc")
+
+	// No validated state roots.
+	e.RunWithError(t, exportArgs...)
+	e.RunWithError(t, append(exportArgs, "--height", "10")...)
+	// Too high.
+	e.RunWithError(t, append(exportArgs, "--height", "1000")...)
+	// Non-empty DB.
+	e.RunWithError(t, importArgs...)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNEP11(t *testing.T) {
+	e := newExecutor(t, true)
+
+	tmpDir := os.TempDir()
+	walletPath := path.Join(tmpDir, "walletForNEP11.json")
+	t.Cleanup(func() {
+		os.Remove(walletPath)
+	})
+
+	nnsHash, err := e.Chain.GetNativeContractScriptHash(nativenames.NameService)
+	require.NoError(t, err)
+
+	// Register `neo.com` domain owned by the validator.
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "contract", "invokefunction",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet, "--address", validatorAddr,
+		nnsHash.StringLE(), "addRoot", "string:com", "--", validatorAddr)
+	e.checkTxPersisted(t, "Sent invocation transaction ")
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "contract", "invokefunction",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet, "--address", validatorAddr,
+		nnsHash.StringLE(), "register", "string:neo.com", "hash160:"+validatorAddr, "--", validatorAddr)
+	e.checkTxPersisted(t, "Sent invocation transaction ")
+
+	tokenID := hex.EncodeToString([]byte("neo.com"))
+	e.Run(t, "neo-go", "wallet", "init", "--wallet", walletPath)
+
+	t.Run("import", func(t *testing.T) {
+		// missing token hash
+		e.RunWithError(t, "neo-go", "wallet", "nep11", "import",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", walletPath)
+
+		// not a NEP11 token
+		e.RunWithError(t, "neo-go", "wallet", "nep11", "import",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", walletPath,
+			"--token", e.Chain.UtilityTokenHash().StringLE())
+
+		e.Run(t, "neo-go", "wallet", "nep11", "import",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", walletPath,
+			"--token", nnsHash.StringLE())
+
+		checkNNSInfo := func(t *testing.T) {
+			e.checkNextLine(t, "^Name:\\s*NameService")
+			e.checkNextLine(t, "^Symbol:\\s*NNS")
+			e.checkNextLine(t, "^Hash:\\s*"+nnsHash.StringLE())
+			e.checkNextLine(t, "^Decimals:\\s*0")
+			e.checkNextLine(t, "^Address:\\s*"+address.Uint160ToString(nnsHash))
+			e.checkNextLine(t, "^Standard:\\s*NEP-11")
+		}
+		checkNNSInfo(t)
+
+		// already imported
+		e.RunWithError(t, "neo-go", "wallet", "nep11", "import",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--wallet", walletPath,
+			"--token", nnsHash.StringLE())
+
+		e.Run(t, "neo-go", "wallet", "nep11", "info",
+			"--wallet", walletPath, "--token", "NNS")
+		checkNNSInfo(t)
+		e.checkEOF(t)
+
+		// NEP11 tokens are not shown by NEP17 commands.
+		e.Run(t, "neo-go", "wallet", "nep17", "info",
+			"--wallet", walletPath)
+		e.checkEOF(t)
+		e.RunWithError(t, "neo-go", "wallet", "nep17", "info",
+			"--wallet", walletPath, "--token", "NNS")
+	})
+
+	t.Run("balance", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "nep11", "balance",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet,
+			"--address", validatorAddr,
+		}
+		checkBalance := func(t *testing.T) {
+			e.checkNextLine(t, "^\\s*Account\\s+"+validatorAddr)
+			e.checkNextLine(t, "^\\s*NNS:\\s+NameService \\("+nnsHash.StringLE()+"\\)")
+			e.checkNextLine(t, "^\\s*Token:\\s*"+tokenID+"$")
+			e.checkNextLine(t, "^\\s*Amount\\s*:\\s*1$")
+			e.checkNextLine(t, "^\\s*Updated\\s*:\\s*[0-9]+$")
+			e.checkEOF(t)
+		}
+		e.Run(t, cmd...)
+		checkBalance(t)
+		e.Run(t, append(cmd, "--token", "NNS", "--id", tokenID)...)
+		checkBalance(t)
+
+		e.Run(t, append(cmd, "--id", "abcdef")...)
+		e.checkNextLine(t, "^\\s*Account\\s+"+validatorAddr)
+		e.checkEOF(t)
+		e.RunWithError(t, append(cmd, "--id", "notahex")...)
+	})
+
+	t.Run("ownerOf", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "nep11", "ownerOf",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--token", nnsHash.StringLE(),
+		}
+		e.RunWithError(t, cmd...) // missing token ID
+		e.Run(t, append(cmd, "--id", tokenID)...)
+		e.checkNextLine(t, "^"+validatorAddr+"$")
+		e.checkEOF(t)
+	})
+
+	t.Run("tokensOf", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "nep11", "tokensOf",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--token", nnsHash.StringLE(),
+		}
+		e.RunWithError(t, cmd...) // missing owner
+		e.Run(t, append(cmd, "--address", validatorAddr)...)
+		e.checkNextLine(t, "^"+tokenID+"$")
+		e.checkEOF(t)
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		e.Run(t, "neo-go", "wallet", "nep11", "properties",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--token", nnsHash.StringLE(),
+			"--id", tokenID)
+		e.checkNextLine(t, `"name":"neo.com"`)
+		e.checkEOF(t)
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		const recipient = "NTh9TnZTstvAePEYWDGLLxidBikJE24uTo"
+		cmd := []string{"neo-go", "wallet", "nep11", "transfer",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet,
+			"--from", validatorAddr,
+			"--to", recipient,
+			"--token", "NNS",
+		}
+		e.RunWithError(t, cmd...) // missing token ID
+		e.In.WriteString("one\r")
+		e.RunWithError(t, append(cmd, "--id", tokenID, "--amount", "1")...) // non-divisible
+
+		e.In.WriteString("one\r")
+		e.Run(t, append(cmd, "--id", tokenID)...)
+		e.checkTxPersisted(t)
+
+		recipientHash, err := address.StringToUint160(recipient)
+		require.NoError(t, err)
+		e.Run(t, "neo-go", "wallet", "nep11", "ownerOf",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--token", nnsHash.StringLE(),
+			"--id", tokenID)
+		e.checkNextLine(t, "^"+address.Uint160ToString(recipientHash)+"$")
+
+		e.Run(t, "neo-go", "wallet", "nep11", "tokensOf",
+			"--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--token", nnsHash.StringLE(),
+			"--address", recipient)
+		e.checkNextLine(t, "^"+tokenID+"$")
+		e.checkEOF(t)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		e.In.WriteString("y\r")
+		e.Run(t, "neo-go", "wallet", "nep11", "remove",
+			"--wallet", walletPath, "--token", nnsHash.StringLE())
+		e.Run(t, "neo-go", "wallet", "nep11", "info",
+			"--wallet", walletPath)
+		_, err := e.Out.ReadString('\n')
+		require.Equal(t, err, io.EOF)
+	})
+}
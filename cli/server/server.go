@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 
 	"github.com/nspcc-dev/neo-go/cli/options"
 	"github.com/nspcc-dev/neo-go/pkg/config"
@@ -12,6 +13,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/chaindump"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/network"
 	"github.com/nspcc-dev/neo-go/pkg/network/metrics"
@@ -64,12 +66,46 @@ func NewCommands() []cli.Command {
 			Usage: "directory for storing JSON dumps",
 		},
 	)
+	var cfgSnapshotOutFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgSnapshotOutFlags, cfgFlags)
+	cfgSnapshotOutFlags = append(cfgSnapshotOutFlags,
+		cli.UintFlag{
+			Name:  "height",
+			Usage: "height of the state to export (default: the latest validated one)",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "Output file (stdout if not given)",
+		},
+	)
+	var stateValidatorsFlag = cli.StringFlag{
+		Name:  "state-validators",
+		Usage: "comma-separated hex-encoded public keys of trusted state validators to check snapshot state root (if StateRootInHeader is disabled)",
+	}
+	var cfgSnapshotInFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgSnapshotInFlags, cfgFlags)
+	cfgSnapshotInFlags = append(cfgSnapshotInFlags,
+		cli.StringFlag{
+			Name:  "in, i",
+			Usage: "Input file (stdin if not given)",
+		},
+		stateValidatorsFlag,
+	)
+	var cfgNodeFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgNodeFlags, cfgFlags)
+	cfgNodeFlags = append(cfgNodeFlags,
+		cli.StringFlag{
+			Name:  "snapshot",
+			Usage: "state snapshot file to start from if the DB is empty",
+		},
+		stateValidatorsFlag,
+	)
 	return []cli.Command{
 		{
 			Name:   "node",
 			Usage:  "start a NEO node",
 			Action: startServer,
-			Flags:  cfgFlags,
+			Flags:  cfgNodeFlags,
 		},
 		{
 			Name:  "db",
@@ -87,6 +123,24 @@ func NewCommands() []cli.Command {
 					Action: restoreDB,
 					Flags:  cfgCountInFlags,
 				},
+				{
+					Name:  "snapshot",
+					Usage: "state snapshot manipulations",
+					Subcommands: []cli.Command{
+						{
+							Name:   "export",
+							Usage:  "export state snapshot at the given height to the file",
+							Action: exportSnapshot,
+							Flags:  cfgSnapshotOutFlags,
+						},
+						{
+							Name:   "import",
+							Usage:  "import state snapshot from the file into an empty DB",
+							Action: importSnapshot,
+							Flags:  cfgSnapshotInFlags,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -295,6 +349,115 @@ func restoreDB(ctx *cli.Context) error {
 	return nil
 }
 
+func exportSnapshot(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var outStream = os.Stdout
+	if out := ctx.String("out"); out != "" {
+		outStream, err = os.Create(out)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	defer outStream.Close()
+	writer := io.NewBinWriterFromIO(outStream)
+
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+	defer prometheus.ShutDown()
+	defer pprof.ShutDown()
+
+	height := uint32(ctx.Uint("height"))
+	if !ctx.IsSet("height") {
+		if cfg.ProtocolConfiguration.StateRootInHeader {
+			// State root for the height is proven by the next header.
+			height = chain.BlockHeight()
+			if hdrHeight := chain.HeaderHeight(); height >= hdrHeight && hdrHeight > 0 {
+				height = hdrHeight - 1
+			}
+		} else {
+			height = chain.GetStateModule().CurrentValidatedHeight()
+			if height == 0 {
+				return cli.NewExitError("no validated state roots available", 1)
+			}
+		}
+	}
+	if err := chain.ExportSnapshot(writer, height); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't export snapshot: %w", err), 1)
+	}
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	validators, err := getStateValidators(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var inStream = os.Stdin
+	if in := ctx.String("in"); in != "" {
+		inStream, err = os.Open(in)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	defer inStream.Close()
+
+	chain, err := initBlockChain(cfg, log)
+	if err != nil {
+		return err
+	}
+	go chain.Run()
+	defer chain.Close()
+	return importSnapshotIntoChain(chain, io.NewBinReaderFromIO(inStream), validators)
+}
+
+// getStateValidators returns trusted state validators keys specified via
+// `--state-validators` flag.
+func getStateValidators(ctx *cli.Context) (keys.PublicKeys, error) {
+	s := ctx.String("state-validators")
+	if s == "" {
+		return nil, nil
+	}
+	var pubs keys.PublicKeys
+	for _, k := range strings.Split(s, ",") {
+		pub, err := keys.NewPublicKeyFromString(strings.TrimSpace(k))
+		if err != nil {
+			return nil, fmt.Errorf("invalid state validator key %q: %w", k, err)
+		}
+		pubs = append(pubs, pub)
+	}
+	return pubs, nil
+}
+
+// importSnapshotIntoChain imports state snapshot from r into the chain. The
+// chain needs to be closed and reopened afterwards to be used.
+func importSnapshotIntoChain(chain *core.Blockchain, r *io.BinReader, validators keys.PublicKeys) error {
+	if err := chain.ImportSnapshot(r, validators); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't import snapshot: %w", err), 1)
+	}
+	return nil
+}
+
 func startServer(ctx *cli.Context) error {
 	cfg, err := getConfigFromContext(ctx)
 	if err != nil {
@@ -308,6 +471,16 @@ func startServer(ctx *cli.Context) error {
 	grace, cancel := context.WithCancel(newGraceContext())
 	defer cancel()
 
+	if snapshot := ctx.String("snapshot"); snapshot != "" {
+		validators, err := getStateValidators(ctx)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		if err := startFromSnapshot(cfg, log, snapshot, validators); err != nil {
+			return err
+		}
+	}
+
 	serverConfig := network.NewServerConfig(cfg)
 
 	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
@@ -356,6 +529,28 @@ Main:
 	return nil
 }
 
+// startFromSnapshot imports state snapshot from the given file if the DB
+// doesn't have any blocks yet and does nothing otherwise.
+func startFromSnapshot(cfg config.Config, log *zap.Logger, snapshot string, validators keys.PublicKeys) error {
+	chain, err := initBlockChain(cfg, log)
+	if err != nil {
+		return err
+	}
+	go chain.Run()
+	defer chain.Close()
+	if height := chain.BlockHeight(); height != 0 {
+		log.Info("DB is not empty, snapshot is ignored", zap.Uint32("height", height))
+		return nil
+	}
+
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer f.Close()
+	return importSnapshotIntoChain(chain, io.NewBinReaderFromIO(f), validators)
+}
+
 // configureAddresses sets up addresses for RPC, Prometheus and Pprof depending from the provided config.
 // In case RPC or Prometheus or Pprof Address provided each of them will use it.
 // In case global Address (of the node) provided and RPC/Prometheus/Pprof don't have configured addresses they will
@@ -0,0 +1,69 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/urfave/cli"
+)
+
+func manifestAddGroup(ctx *cli.Context) error {
+	sender := ctx.Generic("sender").(*flags.Address)
+	if !sender.IsSet {
+		return cli.NewExitError("invalid sender", 1)
+	}
+
+	nefPath := ctx.String("nef")
+	if len(nefPath) == 0 {
+		return cli.NewExitError(errors.New("no .nef file was provided, specify it with the '--nef' flag"), 1)
+	}
+	manifestPath := ctx.String("manifest")
+	if len(manifestPath) == 0 {
+		return cli.NewExitError(errNoManifestFile, 1)
+	}
+	nf, m, _, err := readContractFiles(nefPath, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	h := state.CreateContractHash(sender.Uint160(), nf.Checksum, m.Name)
+
+	acc, _, err := getAccFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	priv := acc.PrivateKey()
+	gr := manifest.Group{
+		PublicKey: priv.PublicKey(),
+		Signature: priv.Sign(h.BytesBE()),
+	}
+
+	var found bool
+	for i := range m.Groups {
+		if m.Groups[i].PublicKey.Equal(gr.PublicKey) {
+			m.Groups[i].Signature = gr.Signature
+			found = true
+			break
+		}
+	}
+	if !found {
+		m.Groups = append(m.Groups, gr)
+	}
+
+	rawM, err := json.Marshal(m)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't marshal manifest: %w", err), 1)
+	}
+
+	err = ioutil.WriteFile(manifestPath, rawM, os.ModePerm)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't write manifest file: %w", err), 1)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package smartcontract
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// permission is a manifest permission in the contract configuration file
+// format:
+//
+//   - hash: <LE hex contract hash>  # or `group: <hex public key>`, any contract if omitted
+//     methods: '*'                  # or the list of method names
+type permission manifest.Permission
+
+// trusts is a set of trusted contracts in the contract configuration file
+// format, either '*' or the list of LE hex contract hashes.
+type trusts manifest.WildUint160s
+
+// group is a manifest group in the contract configuration file format.
+type group struct {
+	PubKey    string `yaml:"pubkey"`
+	Signature string `yaml:"signature"`
+}
+
+const (
+	permHashKey   = "hash"
+	permGroupKey  = "group"
+	permMethodKey = "methods"
+	wildcard      = "*"
+)
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (p permission) MarshalYAML() (interface{}, error) {
+	m := make(map[string]interface{})
+	switch p.Contract.Type {
+	case manifest.PermissionHash:
+		m[permHashKey] = p.Contract.Hash().StringLE()
+	case manifest.PermissionGroup:
+		m[permGroupKey] = hex.EncodeToString(p.Contract.Group().Bytes())
+	}
+	if p.Methods.IsWildcard() {
+		m[permMethodKey] = wildcard
+	} else {
+		m[permMethodKey] = p.Methods.Value
+	}
+	return m, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler interface.
+func (p *permission) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]interface{}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	for k := range m {
+		if k != permHashKey && k != permGroupKey && k != permMethodKey {
+			return fmt.Errorf("unknown permission parameter: '%s'", k)
+		}
+	}
+	_, hasHash := m[permHashKey]
+	_, hasGroup := m[permGroupKey]
+	switch {
+	case hasHash && hasGroup:
+		return errors.New("permission can't have both hash and group")
+	case hasHash:
+		s, ok := m[permHashKey].(string)
+		if !ok {
+			return errors.New("hash must be a string")
+		}
+		h, err := util.Uint160DecodeStringLE(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid contract hash: %w", err)
+		}
+		p.Contract = manifest.NewPermission(manifest.PermissionHash, h).Contract
+	case hasGroup:
+		s, ok := m[permGroupKey].(string)
+		if !ok {
+			return errors.New("group must be a string")
+		}
+		pub, err := keys.NewPublicKeyFromString(s)
+		if err != nil {
+			return fmt.Errorf("invalid group public key: %w", err)
+		}
+		p.Contract = manifest.NewPermission(manifest.PermissionGroup, pub).Contract
+	default:
+		p.Contract = manifest.NewPermission(manifest.PermissionWildcard).Contract
+	}
+	methods, ok := m[permMethodKey]
+	if !ok {
+		return errors.New("methods are not specified")
+	}
+	names, err := parseWildcard(methods, permMethodKey)
+	if err != nil {
+		return err
+	}
+	p.Methods.Value = names
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (t trusts) MarshalYAML() (interface{}, error) {
+	if t.Value == nil {
+		return wildcard, nil
+	}
+	hashes := make([]string, len(t.Value))
+	for i := range t.Value {
+		hashes[i] = t.Value[i].StringLE()
+	}
+	return hashes, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler interface.
+func (t *trusts) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	hashes, err := parseWildcard(v, "trusts")
+	if err != nil || hashes == nil {
+		t.Value = nil
+		return err
+	}
+	t.Value = make([]util.Uint160, len(hashes))
+	for i := range hashes {
+		t.Value[i], err = util.Uint160DecodeStringLE(strings.TrimPrefix(hashes[i], "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid trusted contract hash: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseWildcard parses either a wildcard string (returning nil) or a list of
+// strings.
+func parseWildcard(v interface{}, name string) ([]string, error) {
+	switch val := v.(type) {
+	case string:
+		if val == wildcard {
+			return nil, nil
+		}
+	case []interface{}:
+		res := make([]string, len(val))
+		for i := range val {
+			s, ok := val[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", name)
+			}
+			res[i] = s
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("%s must be either '%s' or a list", name, wildcard)
+}
+
+// toManifest converts group to manifest.Group.
+func (g group) toManifest() (manifest.Group, error) {
+	pub, err := keys.NewPublicKeyFromString(g.PubKey)
+	if err != nil {
+		return manifest.Group{}, fmt.Errorf("invalid group public key: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(g.Signature)
+	if err != nil {
+		return manifest.Group{}, fmt.Errorf("invalid group signature: %w", err)
+	}
+	return manifest.Group{PublicKey: pub, Signature: sig}, nil
+}
@@ -0,0 +1,113 @@
+package smartcontract
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestPermissionMarshal(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	h := util.Uint160{1, 2, 3}
+
+	testMarshal := func(t *testing.T, p *manifest.Permission) {
+		data, err := yaml.Marshal((*permission)(p))
+		require.NoError(t, err)
+
+		actual := new(permission)
+		require.NoError(t, yaml.Unmarshal(data, actual))
+		require.Equal(t, *p, manifest.Permission(*actual))
+	}
+	t.Run("wildcard", func(t *testing.T) {
+		testMarshal(t, manifest.NewPermission(manifest.PermissionWildcard))
+	})
+	t.Run("hash", func(t *testing.T) {
+		p := manifest.NewPermission(manifest.PermissionHash, h)
+		p.Methods.Add("a")
+		p.Methods.Add("b")
+		testMarshal(t, p)
+	})
+	t.Run("group", func(t *testing.T) {
+		p := manifest.NewPermission(manifest.PermissionGroup, priv.PublicKey())
+		p.Methods.Restrict()
+		testMarshal(t, p)
+	})
+	t.Run("hash with 0x", func(t *testing.T) {
+		var p permission
+		require.NoError(t, yaml.Unmarshal([]byte("hash: '0x"+h.StringLE()+"'\nmethods: '*'"), &p))
+		require.Equal(t, *manifest.NewPermission(manifest.PermissionHash, h), manifest.Permission(p))
+	})
+}
+
+func TestPermissionUnmarshalErrors(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub := hex.EncodeToString(priv.PublicKey().Bytes())
+	h := util.Uint160{1, 2, 3}.StringLE()
+
+	for name, data := range map[string]string{
+		"unknown key":     "hash: " + h + "\nmethods: '*'\nextra: 1",
+		"hash and group":  "hash: " + h + "\ngroup: " + pub + "\nmethods: '*'",
+		"invalid hash":    "hash: 123\nmethods: '*'",
+		"hash type":       "hash: [1]\nmethods: '*'",
+		"invalid group":   "group: 0102\nmethods: '*'",
+		"group type":      "group: [1]\nmethods: '*'",
+		"no methods":      "hash: " + h,
+		"invalid methods": "methods: all",
+		"methods list":    "methods: [a, [b]]",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var p permission
+			require.Error(t, yaml.Unmarshal([]byte(data), &p))
+		})
+	}
+}
+
+func TestTrustsMarshal(t *testing.T) {
+	testMarshal := func(t *testing.T, expected manifest.WildUint160s) {
+		data, err := yaml.Marshal(trusts(expected))
+		require.NoError(t, err)
+
+		var actual trusts
+		require.NoError(t, yaml.Unmarshal(data, &actual))
+		require.Equal(t, expected, manifest.WildUint160s(actual))
+	}
+	t.Run("wildcard", func(t *testing.T) {
+		testMarshal(t, manifest.WildUint160s{})
+	})
+	t.Run("empty", func(t *testing.T) {
+		testMarshal(t, manifest.WildUint160s{Value: []util.Uint160{}})
+	})
+	t.Run("hashes", func(t *testing.T) {
+		testMarshal(t, manifest.WildUint160s{Value: []util.Uint160{{1, 2, 3}, {4, 5, 6}}})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var tr trusts
+		require.Error(t, yaml.Unmarshal([]byte("[123]"), &tr))
+		require.Error(t, yaml.Unmarshal([]byte("all"), &tr))
+	})
+}
+
+func TestGroupToManifest(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub := hex.EncodeToString(priv.PublicKey().Bytes())
+	sig := []byte{1, 2, 3}
+
+	g := group{PubKey: pub, Signature: base64.StdEncoding.EncodeToString(sig)}
+	actual, err := g.toManifest()
+	require.NoError(t, err)
+	require.Equal(t, manifest.Group{PublicKey: priv.PublicKey(), Signature: sig}, actual)
+
+	_, err = group{PubKey: "0102", Signature: g.Signature}.toManifest()
+	require.Error(t, err)
+	_, err = group{PubKey: pub, Signature: "not base64!"}.toManifest()
+	require.Error(t, err)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,6 +19,7 @@ import (
 	"github.com/nspcc-dev/neo-go/cli/options"
 	"github.com/nspcc-dev/neo-go/cli/paramcontext"
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
@@ -29,6 +32,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/dap"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/urfave/cli"
 	"gopkg.in/yaml.v2"
@@ -86,8 +90,16 @@ func RuntimeNotify(args []interface{}) {
 }`
 )
 
-// NewCommands returns 'contract' command.
-func NewCommands() []cli.Command {
+// ChainFunc creates a chain for contracts to be executed against using the
+// configuration specified by the command flags (see `contract debug`), the
+// node's database is used if useDB is set. The chain is never run and its
+// changes are never persisted. The closer returned releases the chain.
+type ChainFunc func(ctx *cli.Context, useDB bool) (blockchainer.Blockchainer, io.Closer, error)
+
+// NewCommands returns 'contract' command. Chain for `contract debug` is
+// created with newChain, this package can't do it itself because core
+// package tests depend on it.
+func NewCommands(newChain ChainFunc) []cli.Command {
 	testInvokeScriptFlags := []cli.Flag{
 		cli.StringFlag{
 			Name:  "in, i",
@@ -113,6 +125,10 @@ func NewCommands() []cli.Command {
 			Usage: "Manifest input file (*.manifest.json)",
 		},
 	}...)
+	// Flag slices can't be shared between commands, because cli appends
+	// help flag to them.
+	updateFlags := append([]cli.Flag{}, deployFlags...)
+	destroyFlags := append([]cli.Flag{}, invokeFunctionFlags...)
 	return []cli.Command{{
 		Name:  "contract",
 		Usage: "compile - debug - deploy smart contracts",
@@ -154,6 +170,14 @@ func NewCommands() []cli.Command {
 						Name:  "no-events",
 						Usage: "do not check emitted events with the manifest",
 					},
+					cli.BoolFlag{
+						Name:  "no-permissions",
+						Usage: "do not check whether contract calls are allowed by permissions",
+					},
+					cli.BoolFlag{
+						Name:  "infer-permissions",
+						Usage: "add permissions needed for contract calls to the manifest",
+					},
 				},
 			},
 			{
@@ -167,6 +191,35 @@ func NewCommands() []cli.Command {
 				Action: contractDeploy,
 				Flags:  deployFlags,
 			},
+			{
+				Name:      "update",
+				Usage:     "update deployed smart contract (.nef with description)",
+				UsageText: "neo-go contract update -r endpoint -w wallet [-a address] [-g gas] --in contract.nef --manifest contract.manifest.json [--out file] [--force] scripthash [data] [--] [signers...]",
+				Description: `Updates contract with the given script hash by invoking its 'update' method
+   with the new NEF, manifest and an optional data parameter (that will be
+   passed to '_deploy' method). Contracts can't be updated directly via
+   ContractManagement, so the contract must have an 'update' method accepting
+   these parameters and calling ContractManagement's 'update' from it. Signers
+   can be specified the same way as for invokefunction command if contract
+   checks witnesses in its 'update' method. Unlike invokefunction, the sender
+   is the first signer of the test invocation too (with None scope unless
+   specified otherwise), just like in the transaction sent.
+`,
+				Action: contractUpdate,
+				Flags:  updateFlags,
+			},
+			{
+				Name:      "destroy",
+				Usage:     "destroy deployed smart contract",
+				UsageText: "neo-go contract destroy -r endpoint -w wallet [-a address] [-g gas] [--out file] [--force] scripthash [--] [signers...]",
+				Description: `Destroys contract with the given script hash by invoking its 'destroy'
+   method (that should call ContractManagement's 'destroy'). Signers can be
+   specified the same way as for invokefunction command, the sender is added
+   to the test invocation signers the same way as for update command.
+`,
+				Action: contractDestroy,
+				Flags:  destroyFlags,
+			},
 			{
 				Name:      "invokefunction",
 				Usage:     "invoke deployed contract on the blockchain",
@@ -333,6 +386,118 @@ func NewCommands() []cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "debug",
+				Usage:     "debug a contract invocation via Debug Adapter Protocol",
+				UsageText: "neo-go contract debug -i contract.nef [-m manifest.json] [-d debug.json] [-s sender] [--chain] [--config-path path] [--listen address] [--stop-on-entry] [--script script] [method] [arguments...]",
+				Description: `Starts Debug Adapter Protocol server for the given contract, so that
+   DAP-capable editors can be used to set source breakpoints, step through the
+   contract code and inspect its variables and stacks. Server communicates via
+   stdin/stdout unless TCP address to listen on is given, in this case a single
+   client connection is accepted.
+
+   The contract invocation to debug is either built from the given method and
+   arguments (see testinvokefunction documentation for the details about
+   arguments) or specified as a hex-encoded script calling the contract. Any of
+   these parameters can be overridden by the client in the launch request
+   arguments ('program', 'manifest', 'debugInfo', 'sender', 'method', 'args',
+   'script' and 'stopOnEntry').
+
+   Manifest and debug information file names are derived from the NEF file name
+   if not specified. The contract is deployed by the sender (zero account by
+   default) and invoked in a test VM of a new in-memory chain created with the
+   node's configuration from the --config-path directory for the network
+   chosen (privnet by default). The chain from the node's database can be used
+   instead with --chain. Deployment and invocation changes are never
+   persisted, so every launch starts from the same chain state. The contract
+   can call native and deployed contracts, runtime logs and notifications are
+   reported as the program output.
+`,
+				Action: func(ctx *cli.Context) error {
+					return contractDebug(ctx, newChain)
+				},
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in, i",
+						Usage: "path to NEF file",
+					},
+					cli.StringFlag{
+						Name:  "manifest, m",
+						Usage: "path to manifest file",
+					},
+					cli.StringFlag{
+						Name:  "debug, d",
+						Usage: "path to debug information file",
+					},
+					flags.AddressFlag{
+						Name:  "sender, s",
+						Usage: "account deploying the contract and signing the invocation (zero account by default)",
+					},
+					cli.StringFlag{
+						Name:  "script",
+						Usage: "hex-encoded invocation script",
+					},
+					cli.StringFlag{
+						Name:  "listen, l",
+						Usage: "TCP address to listen on instead of using stdin/stdout",
+					},
+					cli.BoolFlag{
+						Name:  "stop-on-entry",
+						Usage: "stop at the first statement executed",
+					},
+					cli.BoolFlag{
+						Name:  "chain",
+						Usage: "use the chain from the node's database instead of a new in-memory one (writes are discarded)",
+					},
+					cli.StringFlag{
+						Name:  "config-path",
+						Usage: "path to the node's configuration",
+					},
+					// Network flags without short names conflicting
+					// with the other ones (see options.Network).
+					cli.BoolFlag{Name: "privnet"},
+					cli.BoolFlag{Name: "mainnet"},
+					cli.BoolFlag{Name: "testnet"},
+					cli.BoolFlag{Name: "unittest", Hidden: true},
+				},
+			},
+			{
+				Name:  "manifest",
+				Usage: "manifest-related commands",
+				Subcommands: []cli.Command{
+					{
+						Name:      "add-group",
+						Usage:     "adds group to the manifest",
+						UsageText: "neo-go contract manifest add-group -w wallet [-a address] -s address --nef contract.nef --manifest contract.manifest.json",
+						Description: `Signs hash of the contract to be deployed by the given sender (it depends
+   on the sender, NEF checksum and contract name) with the key of the given
+   wallet account and adds the resulting group to the manifest (replacing
+   the signature if the group with this key is already present there). The
+   manifest file is updated in place.
+`,
+						Action: manifestAddGroup,
+						Flags: []cli.Flag{
+							walletFlag,
+							flags.AddressFlag{
+								Name:  "sender, s",
+								Usage: "deploy transaction sender",
+							},
+							flags.AddressFlag{
+								Name:  "address, a",
+								Usage: "account to sign group with",
+							},
+							cli.StringFlag{
+								Name:  "nef, n",
+								Usage: "path to the NEF file",
+							},
+							cli.StringFlag{
+								Name:  "manifest, m",
+								Usage: "path to the manifest",
+							},
+						},
+					},
+				},
+			},
 			{
 				Name:   "calc-hash",
 				Usage:  "calculates hash of a contract after deployment",
@@ -392,6 +557,7 @@ func initSmartContract(ctx *cli.Context) error {
 				},
 			},
 		},
+		Permissions: []permission{permission(*manifest.NewPermission(manifest.PermissionWildcard))},
 	}
 	b, err := yaml.Marshal(m)
 	if err != nil {
@@ -429,8 +595,10 @@ func contractCompile(ctx *cli.Context) error {
 		DebugInfo:    debugFile,
 		ManifestFile: manifestFile,
 
-		NoStandardCheck: ctx.Bool("no-standards"),
-		NoEventsCheck:   ctx.Bool("no-events"),
+		NoStandardCheck:    ctx.Bool("no-standards"),
+		NoEventsCheck:      ctx.Bool("no-events"),
+		NoPermissionsCheck: ctx.Bool("no-permissions"),
+		InferPermissions:   ctx.Bool("infer-permissions"),
 	}
 
 	if len(confFile) != 0 {
@@ -442,6 +610,12 @@ func contractCompile(ctx *cli.Context) error {
 		o.ContractEvents = conf.Events
 		o.ContractSupportedStandards = conf.SupportedStandards
 		o.SafeMethods = conf.SafeMethods
+		o.Permissions = conf.GetPermissions()
+		o.Trusts = conf.GetTrusts()
+		o.Groups, err = conf.GetGroups()
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("bad config: %w", err), 1)
+		}
 	}
 
 	result, err := compiler.CompileAndSave(src, o)
@@ -541,6 +715,13 @@ func invokeInternal(ctx *cli.Context, signAndPush bool) error {
 }
 
 func invokeWithArgs(ctx *cli.Context, signAndPush bool, script util.Uint160, operation string, params []smartcontract.Parameter, cosigners []transaction.Signer) (util.Uint160, error) {
+	return invokeContract(ctx, signAndPush, false, script, operation, params, cosigners)
+}
+
+// invokeContract is similar to invokeWithArgs, but if addSender is set, test
+// invocation is performed with the transaction sender being the first signer
+// (see withSender).
+func invokeContract(ctx *cli.Context, signAndPush bool, addSender bool, script util.Uint160, operation string, params []smartcontract.Parameter, cosigners []transaction.Signer) (util.Uint160, error) {
 	var (
 		err               error
 		gas               fixedn.Fixed8
@@ -564,6 +745,9 @@ func invokeWithArgs(ctx *cli.Context, signAndPush bool, script util.Uint160, ope
 		if err != nil {
 			return sender, cli.NewExitError(fmt.Errorf("failed to calculate network fee: %w", err), 1)
 		}
+		if addSender {
+			cosigners = withSender(sender, cosigners)
+		}
 	}
 	gctx, cancel := options.GetTimeoutContext(ctx)
 	defer cancel()
@@ -616,6 +800,21 @@ func invokeWithArgs(ctx *cli.Context, signAndPush bool, script util.Uint160, ope
 	return sender, nil
 }
 
+// withSender returns the list of signers with sender being the first one (with
+// None scope unless specified otherwise), the same way transaction signers are
+// formed, so that test invocation is performed in the same environment.
+func withSender(sender util.Uint160, cosigners []transaction.Signer) []transaction.Signer {
+	res := []transaction.Signer{{Account: sender, Scopes: transaction.None}}
+	for _, c := range cosigners {
+		if c.Account == sender {
+			res[0].Scopes = c.Scopes
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
 func testInvokeScript(ctx *cli.Context) error {
 	src := ctx.String("in")
 	if len(src) == 0 {
@@ -665,6 +864,43 @@ type ProjectConfig struct {
 	SafeMethods        []string
 	SupportedStandards []string
 	Events             []manifest.Event
+	Permissions        []permission `yaml:",omitempty"`
+	Trusts             *trusts      `yaml:",omitempty"`
+	Groups             []group      `yaml:",omitempty"`
+}
+
+// GetPermissions returns manifest permissions specified in the configuration.
+func (c ProjectConfig) GetPermissions() []manifest.Permission {
+	if c.Permissions == nil {
+		return nil
+	}
+	ps := make([]manifest.Permission, len(c.Permissions))
+	for i := range c.Permissions {
+		ps[i] = manifest.Permission(c.Permissions[i])
+	}
+	return ps
+}
+
+// GetTrusts returns trusted contracts specified in the configuration, nil
+// means no contracts are trusted.
+func (c ProjectConfig) GetTrusts() *manifest.WildUint160s {
+	return (*manifest.WildUint160s)(c.Trusts)
+}
+
+// GetGroups returns manifest groups specified in the configuration.
+func (c ProjectConfig) GetGroups() ([]manifest.Group, error) {
+	if c.Groups == nil {
+		return nil, nil
+	}
+	gs := make([]manifest.Group, len(c.Groups))
+	for i := range c.Groups {
+		var err error
+		gs[i], err = c.Groups[i].toManifest()
+		if err != nil {
+			return nil, fmt.Errorf("group #%d: %w", i, err)
+		}
+	}
+	return gs, nil
 }
 
 func inspect(ctx *cli.Context) error {
@@ -700,6 +936,57 @@ func inspect(ctx *cli.Context) error {
 	return nil
 }
 
+func contractDebug(ctx *cli.Context, newChain ChainFunc) error {
+	args := dap.LaunchArguments{
+		Program:     ctx.String("in"),
+		Manifest:    ctx.String("manifest"),
+		DebugInfo:   ctx.String("debug"),
+		Script:      ctx.String("script"),
+		StopOnEntry: ctx.Bool("stop-on-entry"),
+	}
+	if sender := ctx.Generic("sender").(*flags.Address); sender.IsSet {
+		args.Sender = &sender.Value
+	}
+	if cliArgs := ctx.Args(); cliArgs.Present() {
+		_, params, err := cmdargs.ParseParams(cliArgs.Tail(), true)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		args.Method, args.Args = cliArgs.First(), params
+	}
+
+	chain, closer, err := newChain(ctx, ctx.Bool("chain"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer closer.Close()
+
+	var conn io.ReadWriter
+	if addr := ctx.String("listen"); addr != "" {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Fprintf(ctx.App.ErrWriter, "Listening on %s\n", l.Addr())
+		c, err := l.Accept()
+		l.Close()
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		defer c.Close()
+		conn = c
+	} else {
+		conn = struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, ctx.App.Writer}
+	}
+	if err := dap.NewServer(conn, chain, args).Serve(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
 func getAccFromContext(ctx *cli.Context) (*wallet.Account, *wallet.Wallet, error) {
 	var addr util.Uint160
 
@@ -746,36 +1033,9 @@ func contractDeploy(ctx *cli.Context) error {
 	if len(manifestFile) == 0 {
 		return cli.NewExitError(errNoManifestFile, 1)
 	}
-
-	f, err := ioutil.ReadFile(in)
-	if err != nil {
-		return cli.NewExitError(err, 1)
-	}
-	// Check the file.
-	nefFile, err := nef.FileFromBytes(f)
-	if err != nil {
-		return cli.NewExitError(fmt.Errorf("failed to read .nef file: %w", err), 1)
-	}
-
-	manifestBytes, err := ioutil.ReadFile(manifestFile)
-	if err != nil {
-		return cli.NewExitError(fmt.Errorf("failed to read manifest file: %w", err), 1)
-	}
-	m := &manifest.Manifest{}
-	err = json.Unmarshal(manifestBytes, m)
+	nefFile, m, appCallParams, err := readContractFiles(in, manifestFile)
 	if err != nil {
-		return cli.NewExitError(fmt.Errorf("failed to restore manifest file: %w", err), 1)
-	}
-
-	appCallParams := []smartcontract.Parameter{
-		{
-			Type:  smartcontract.ByteArrayType,
-			Value: f,
-		},
-		{
-			Type:  smartcontract.ByteArrayType,
-			Value: manifestBytes,
-		},
+		return err
 	}
 	_, data, err := cmdargs.ParseParams(ctx.Args(), true)
 	if err != nil {
@@ -800,7 +1060,9 @@ func contractDeploy(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("failed to get management contract's hash: %w", err), 1)
 	}
-	sender, extErr := invokeWithArgs(ctx, true, mgmtHash, "deploy", appCallParams, nil)
+	// Contract hash depends on the sender, so it must be the same for the test
+	// invocation (it matters for manifest groups check).
+	sender, extErr := invokeContract(ctx, true, true, mgmtHash, "deploy", appCallParams, nil)
 	if extErr != nil {
 		return extErr
 	}
@@ -810,6 +1072,105 @@ func contractDeploy(ctx *cli.Context) error {
 	return nil
 }
 
+// contractUpdate updates deployed contract via its 'update' method.
+func contractUpdate(ctx *cli.Context) error {
+	args := ctx.Args()
+	if !args.Present() {
+		return cli.NewExitError(errNoScriptHash, 1)
+	}
+	script, err := flags.ParseAddress(args[0])
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("incorrect script hash: %w", err), 1)
+	}
+	in := ctx.String("in")
+	if len(in) == 0 {
+		return cli.NewExitError(errNoInput, 1)
+	}
+	manifestFile := ctx.String("manifest")
+	if len(manifestFile) == 0 {
+		return cli.NewExitError(errNoManifestFile, 1)
+	}
+	_, _, params, err := readContractFiles(in, manifestFile)
+	if err != nil {
+		return err
+	}
+	cosignersOffset, data, err := cmdargs.ParseParams(args[1:], true)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("unable to parse 'data' parameter: %w", err), 1)
+	}
+	if len(data) > 1 {
+		return cli.NewExitError("'data' should be represented as a single parameter", 1)
+	}
+	params = append(params, data...)
+	cosigners, exitErr := cmdargs.GetSignersFromContext(ctx, 1+cosignersOffset)
+	if exitErr != nil {
+		return exitErr
+	}
+	_, err = invokeContract(ctx, true, true, script, "update", params, cosigners)
+	return err
+}
+
+// contractDestroy destroys deployed contract via its 'destroy' method.
+func contractDestroy(ctx *cli.Context) error {
+	args := ctx.Args()
+	if !args.Present() {
+		return cli.NewExitError(errNoScriptHash, 1)
+	}
+	script, err := flags.ParseAddress(args[0])
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("incorrect script hash: %w", err), 1)
+	}
+	cosignersOffset, params, err := cmdargs.ParseParams(args[1:], true)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if len(params) != 0 {
+		return cli.NewExitError("'destroy' doesn't accept parameters", 1)
+	}
+	cosigners, exitErr := cmdargs.GetSignersFromContext(ctx, 1+cosignersOffset)
+	if exitErr != nil {
+		return exitErr
+	}
+	_, err = invokeContract(ctx, true, true, script, "destroy", params, cosigners)
+	return err
+}
+
+// readContractFiles reads and checks NEF and manifest files, it returns them
+// along with the parameters for ContractManagement's deploy/update methods.
+func readContractFiles(nefPath, manifestPath string) (*nef.File, *manifest.Manifest, []smartcontract.Parameter, error) {
+	f, err := ioutil.ReadFile(nefPath)
+	if err != nil {
+		return nil, nil, nil, cli.NewExitError(err, 1)
+	}
+	// Check the file.
+	nefFile, err := nef.FileFromBytes(f)
+	if err != nil {
+		return nil, nil, nil, cli.NewExitError(fmt.Errorf("failed to read .nef file: %w", err), 1)
+	}
+
+	manifestBytes, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, nil, nil, cli.NewExitError(fmt.Errorf("failed to read manifest file: %w", err), 1)
+	}
+	m := &manifest.Manifest{}
+	err = json.Unmarshal(manifestBytes, m)
+	if err != nil {
+		return nil, nil, nil, cli.NewExitError(fmt.Errorf("failed to restore manifest file: %w", err), 1)
+	}
+
+	params := []smartcontract.Parameter{
+		{
+			Type:  smartcontract.ByteArrayType,
+			Value: f,
+		},
+		{
+			Type:  smartcontract.ByteArrayType,
+			Value: manifestBytes,
+		},
+	}
+	return &nefFile, m, params, nil
+}
+
 // ParseContractConfig reads contract configuration file (.yaml) and returns unmarshalled ProjectConfig.
 func ParseContractConfig(confFile string) (ProjectConfig, error) {
 	conf := ProjectConfig{}
@@ -62,5 +62,7 @@ events:
   parameters:
   - name: args
     type: Array
+permissions:
+- methods: '*'
 `, string(manifest))
 }
@@ -47,6 +47,13 @@ func Update(script, manifest []byte) {
 	contract.Call(mgmt, "update", contract.All, script, manifest)
 }
 
+// Destroy destroys the contract.
+func Destroy() {
+	ctx := storage.GetReadOnlyContext()
+	mgmt := storage.Get(ctx, mgmtKey).(interop.Hash160)
+	contract.Call(mgmt, "destroy", contract.All)
+}
+
 // GetValue returns stored value.
 func GetValue() string {
 	ctx := storage.GetReadOnlyContext()
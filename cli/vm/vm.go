@@ -1,29 +1,124 @@
 package vm
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/abiosoft/readline"
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	vmcli "github.com/nspcc-dev/neo-go/pkg/vm/cli"
 	"github.com/urfave/cli"
+	"go.uber.org/zap"
 )
 
 // NewCommands returns 'vm' command.
 func NewCommands() []cli.Command {
+	flags := []cli.Flag{
+		cli.BoolFlag{Name: "debug, d"},
+		cli.BoolFlag{
+			Name:  "chain",
+			Usage: "execute scripts against the chain from the node's database (writes are discarded)",
+		},
+		cli.BoolFlag{
+			Name:  "in-memory",
+			Usage: "execute scripts against a new in-memory chain",
+		},
+		cli.StringFlag{
+			Name:  "config-path",
+			Usage: "path to the node's configuration used with --chain or --in-memory",
+		},
+	}
+	flags = append(flags, options.Network...)
 	return []cli.Command{{
 		Name:   "vm",
 		Usage:  "start the virtual machine",
 		Action: startVMPrompt,
-		Flags: []cli.Flag{
-			cli.BoolFlag{Name: "debug, d"},
-		},
+		Flags:  flags,
 	}}
 }
 
 func startVMPrompt(ctx *cli.Context) error {
-	p := vmcli.NewWithConfig(true, os.Exit, &readline.Config{
+	chain, store, err := newChain(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	onExit := func(code int) {
+		if store != nil {
+			_ = store.Close()
+		}
+		os.Exit(code)
+	}
+	rlCfg := &readline.Config{
 		Stdout: ctx.App.Writer,
 		Stderr: ctx.App.ErrWriter,
-	})
+	}
+	var p *vmcli.VMCLI
+	if chain != nil {
+		p = vmcli.NewWithChain(chain, true, onExit, rlCfg)
+	} else {
+		p = vmcli.NewWithConfig(true, onExit, rlCfg)
+	}
 	return p.Run()
 }
+
+// newChain creates a chain for the VM to execute scripts against if it's
+// requested.
+func newChain(ctx *cli.Context) (*core.Blockchain, storage.Store, error) {
+	useDB, inMemory := ctx.Bool("chain"), ctx.Bool("in-memory")
+	if !useDB && !inMemory {
+		return nil, nil, nil
+	}
+	if useDB && inMemory {
+		return nil, nil, errors.New("--chain and --in-memory can't be used together")
+	}
+	return initChain(ctx, useDB)
+}
+
+// NewChain creates a chain for scripts to be executed against using the node's
+// configuration from the path given by the "config-path" flag ("./config" by
+// default) for the network chosen by options.Network flags. The chain is
+// created in memory unless useDB is set, then the node's database is used.
+// The closer returned releases the chain's store.
+func NewChain(ctx *cli.Context, useDB bool) (blockchainer.Blockchainer, io.Closer, error) {
+	chain, store, err := initChain(ctx, useDB)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain, store, nil
+}
+
+// initChain creates a chain (see NewChain). Chain is never run and its store
+// is wrapped into an in-memory layer that is never persisted, so the database
+// isn't changed.
+func initChain(ctx *cli.Context, useDB bool) (*core.Blockchain, storage.Store, error) {
+	configPath := "./config"
+	if argCp := ctx.String("config-path"); argCp != "" {
+		configPath = argCp
+	}
+	cfg, err := config.Load(configPath, options.GetNetwork(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	var store storage.Store
+	if useDB {
+		store, err = storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not initialize storage: %w", err)
+		}
+	} else {
+		store = storage.NewMemoryStore()
+	}
+	store = storage.NewMemCachedStore(store)
+	chain, err := core.NewBlockchain(store, cfg.ProtocolConfiguration, zap.NewNop())
+	if err != nil {
+		_ = store.Close()
+		return nil, nil, fmt.Errorf("could not initialize blockchain: %w", err)
+	}
+	return chain, store, nil
+}
@@ -0,0 +1,486 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/cli/cmdargs"
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/cli/paramcontext"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest/standard"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+var (
+	tokenIDFlag = cli.StringFlag{
+		Name:  "id",
+		Usage: "Hex-encoded token ID",
+	}
+	tokenHashFlag = flags.AddressFlag{
+		Name:  "token",
+		Usage: "Token contract address or hash in LE",
+	}
+)
+
+func newNEP11Commands() []cli.Command {
+	balanceFlags := []cli.Flag{
+		walletPathFlag,
+		tokenFlag,
+		flags.AddressFlag{
+			Name:  "address, a",
+			Usage: "Address to use",
+		},
+		tokenIDFlag,
+	}
+	balanceFlags = append(balanceFlags, options.RPC...)
+	importFlags := []cli.Flag{
+		walletPathFlag,
+		tokenHashFlag,
+	}
+	importFlags = append(importFlags, options.RPC...)
+	transferFlags := []cli.Flag{
+		walletPathFlag,
+		outFlag,
+		fromAddrFlag,
+		toAddrFlag,
+		tokenFlag,
+		tokenIDFlag,
+		gasFlag,
+		cli.StringFlag{
+			Name:  "amount",
+			Usage: "Amount of divisible token to send",
+		},
+	}
+	transferFlags = append(transferFlags, options.RPC...)
+	ownerOfFlags := []cli.Flag{
+		tokenHashFlag,
+		tokenIDFlag,
+	}
+	ownerOfFlags = append(ownerOfFlags, options.RPC...)
+	tokensOfFlags := []cli.Flag{
+		tokenHashFlag,
+		flags.AddressFlag{
+			Name:  "address",
+			Usage: "NFT owner address or hash in LE",
+		},
+	}
+	tokensOfFlags = append(tokensOfFlags, options.RPC...)
+	return []cli.Command{
+		{
+			Name:      "balance",
+			Usage:     "get address balance",
+			UsageText: "balance --wallet <path> --rpc-endpoint <node> [--timeout <time>] [--address <address>] [--token <hash-or-name>] [--id <token-id>]",
+			Action:    getNEP11Balance,
+			Flags:     balanceFlags,
+		},
+		{
+			Name:      "import",
+			Usage:     "import NEP11 token to a wallet",
+			UsageText: "import --wallet <path> --rpc-endpoint <node> --timeout <time> --token <hash>",
+			Action:    importNEP11Token,
+			Flags:     importFlags,
+		},
+		{
+			Name:      "info",
+			Usage:     "print imported NEP11 token info",
+			UsageText: "print --wallet <path> [--token <hash-or-name>]",
+			Action:    printNEP11Info,
+			Flags: []cli.Flag{
+				walletPathFlag,
+				cli.StringFlag{
+					Name:  "token",
+					Usage: "Token name or hash",
+				},
+			},
+		},
+		{
+			Name:      "remove",
+			Usage:     "remove NEP11 token from the wallet",
+			UsageText: "remove --wallet <path> --token <hash-or-name>",
+			Action:    removeNEP11Token,
+			Flags: []cli.Flag{
+				walletPathFlag,
+				cli.StringFlag{
+					Name:  "token",
+					Usage: "Token name or hash",
+				},
+				forceFlag,
+			},
+		},
+		{
+			Name:      "transfer",
+			Usage:     "transfer NEP11 tokens",
+			UsageText: "transfer --wallet <path> --rpc-endpoint <node> --timeout <time> --from <addr> --to <addr> --token <hash-or-name> --id <token-id> [--amount string] [-- <cosigner1:Scope> [<cosigner2> [...]]]",
+			Action:    transferNEP11,
+			Flags:     transferFlags,
+			Description: `Transfers specified NEP11 token with optional cosigners list attached to
+   the transfer. Amount should be specified for divisible NEP11 tokens and
+   omitted for non-divisible NEP11 tokens. See 'contract testinvokefunction'
+   documentation for the details about cosigners syntax. If no cosigners are
+   given then the sender with CalledByEntry scope will be used as the only
+   signer.
+`,
+		},
+		{
+			Name:      "ownerOf",
+			Usage:     "print owner(s) of the specified NEP11 token",
+			UsageText: "ownerOf --rpc-endpoint <node> --timeout <time> --token <hash> --id <token-id>",
+			Action:    printNEP11Owner,
+			Flags:     ownerOfFlags,
+		},
+		{
+			Name:      "tokensOf",
+			Usage:     "print list of tokens IDs for the specified NFT owner",
+			UsageText: "tokensOf --rpc-endpoint <node> --timeout <time> --token <hash> --address <addr>",
+			Action:    printNEP11TokensOf,
+			Flags:     tokensOfFlags,
+		},
+		{
+			Name:      "properties",
+			Usage:     "print properties of the specified NEP11 token",
+			UsageText: "properties --rpc-endpoint <node> --timeout <time> --token <hash> --id <token-id>",
+			Action:    printNEP11Properties,
+			Flags:     ownerOfFlags,
+		},
+	}
+}
+
+func importNEP11Token(ctx *cli.Context) error {
+	return importNEPToken(ctx, manifest.NEP11StandardName)
+}
+
+// getNEP11TokenInfo returns NEP11 token info after checking that the contract
+// complies with NEP11 standard.
+func getNEP11TokenInfo(c *client.Client, tokenHash util.Uint160) (*wallet.Token, error) {
+	cs, err := c.GetContractStateByHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := standard.CheckABI(&cs.Manifest, manifest.NEP11StandardName); err != nil {
+		return nil, err
+	}
+	return c.NEP11TokenInfo(tokenHash)
+}
+
+func printNEP11Info(ctx *cli.Context) error {
+	return printNEPInfo(ctx, manifest.NEP11StandardName)
+}
+
+func removeNEP11Token(ctx *cli.Context) error {
+	return removeNEPToken(ctx, manifest.NEP11StandardName)
+}
+
+func getNEP11Balance(ctx *cli.Context) error {
+	var accounts []*wallet.Account
+
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("bad wallet: %w", err), 1)
+	}
+	defer wall.Close()
+
+	addrFlag := ctx.Generic("address").(*flags.Address)
+	if addrFlag.IsSet {
+		addrHash := addrFlag.Uint160()
+		acc := wall.GetAccount(addrHash)
+		if acc == nil {
+			return cli.NewExitError(fmt.Errorf("can't find account for the address: %s", address.Uint160ToString(addrHash)), 1)
+		}
+		accounts = append(accounts, acc)
+	} else {
+		if len(wall.Accounts) == 0 {
+			return cli.NewExitError(errors.New("no accounts in the wallet"), 1)
+		}
+		accounts = wall.Accounts
+	}
+
+	var tokenID []byte
+	if id := ctx.String("id"); id != "" {
+		tokenID, err = hex.DecodeString(id)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid token ID: %w", err), 1)
+		}
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	name := ctx.String("token")
+
+	for k, acc := range accounts {
+		addrHash, err := address.StringToUint160(acc.Address)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid account address: %w", err), 1)
+		}
+		balances, err := c.GetNEP11Balances(addrHash)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		if k != 0 {
+			fmt.Fprintln(ctx.App.Writer)
+		}
+		fmt.Fprintf(ctx.App.Writer, "Account %s\n", acc.Address)
+
+		for i := range balances.Balances {
+			var tokenName, tokenSymbol string
+			tokenDecimals := 0
+			asset := balances.Balances[i].Asset
+			token, err := getMatchingToken(ctx, wall, asset.StringLE(), manifest.NEP11StandardName)
+			if err != nil {
+				token, err = c.NEP11TokenInfo(asset)
+			}
+			if err == nil {
+				if name != "" && !(token.Name == name || token.Symbol == name || token.Address() == name || token.Hash.StringLE() == name) {
+					continue
+				}
+				tokenName = token.Name
+				tokenSymbol = token.Symbol
+				tokenDecimals = int(token.Decimals)
+			} else {
+				if name != "" {
+					continue
+				}
+				tokenSymbol = "UNKNOWN"
+			}
+			var printed bool
+			for _, tb := range balances.Balances[i].Tokens {
+				if tokenID != nil && tb.ID != hex.EncodeToString(tokenID) {
+					continue
+				}
+				if !printed {
+					fmt.Fprintf(ctx.App.Writer, "%s: %s (%s)\n", tokenSymbol, tokenName, asset.StringLE())
+					printed = true
+				}
+				amount := tb.Amount
+				if tokenDecimals != 0 {
+					b, ok := new(big.Int).SetString(amount, 10)
+					if ok {
+						amount = fixedn.ToString(b, tokenDecimals)
+					}
+				}
+				fmt.Fprintf(ctx.App.Writer, "\tToken: %s\n", tb.ID)
+				fmt.Fprintf(ctx.App.Writer, "\t\tAmount : %s\n", amount)
+				fmt.Fprintf(ctx.App.Writer, "\t\tUpdated: %d\n", tb.LastUpdated)
+			}
+		}
+	}
+	return nil
+}
+
+func transferNEP11(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	fromFlag := ctx.Generic("from").(*flags.Address)
+	from, err := getDefaultAddress(fromFlag, wall)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	acc, err := getDecryptedAccount(ctx, wall, from)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	tokenID, err := getTokenIDFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	toFlag := ctx.Generic("to").(*flags.Address)
+	to := toFlag.Uint160()
+	token, err := getMatchingToken(ctx, wall, ctx.String("token"), manifest.NEP11StandardName)
+	if err != nil {
+		fmt.Fprintln(ctx.App.ErrWriter, "Can't find matching token in the wallet. Querying RPC-node for balances.")
+		token, err = getMatchingTokenRPC(ctx, c, from, ctx.String("token"), manifest.NEP11StandardName)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to get matching token: %w", err), 1)
+		}
+	}
+
+	var args []interface{}
+	amountArg := ctx.String("amount")
+	if token.Decimals == 0 {
+		if amountArg != "" {
+			return cli.NewExitError("amount can't be specified for non-divisible token", 1)
+		}
+		args = []interface{}{to, tokenID}
+	} else {
+		if amountArg == "" {
+			return cli.NewExitError("amount should be specified for divisible token", 1)
+		}
+		amount, err := fixedn.FromString(amountArg, int(token.Decimals))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid amount: %w", err), 1)
+		}
+		args = []interface{}{from, to, amount, tokenID}
+	}
+
+	cosigners, extErr := cmdargs.GetSignersFromContext(ctx, 0)
+	if extErr != nil {
+		return extErr
+	}
+	cosignersAccounts, err := cmdargs.GetSignersAccounts(wall, cosigners)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to create NEP11 transfer transaction: %w", err), 1)
+	}
+
+	gas := flags.Fixed8FromContext(ctx, "gas")
+	tx, err := c.CreateNEP11TransferTx(acc, token.Hash, int64(gas), cosignersAccounts, args...)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if outFile := ctx.String("out"); outFile != "" {
+		if err := paramcontext.InitAndSave(c.GetNetwork(), tx, acc, outFile); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	} else {
+		_, err := c.SignAndPushTx(tx, acc, cosignersAccounts)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	fmt.Fprintln(ctx.App.Writer, tx.Hash().StringLE())
+	return nil
+}
+
+func printNEP11Owner(ctx *cli.Context) error {
+	tokenHash := ctx.Generic("token").(*flags.Address)
+	if !tokenHash.IsSet {
+		return cli.NewExitError("token contract hash was not set", 1)
+	}
+	tokenID, err := getTokenIDFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	decimals, err := c.NEP11Decimals(tokenHash.Uint160())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to get token decimals: %w", err), 1)
+	}
+	if decimals == 0 {
+		owner, err := c.NEP11NDOwnerOf(tokenHash.Uint160(), string(tokenID))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to call NEP11 `ownerOf` method: %w", err), 1)
+		}
+		fmt.Fprintln(ctx.App.Writer, address.Uint160ToString(owner))
+		return nil
+	}
+	owners, err := c.NEP11DOwnerOf(tokenHash.Uint160(), string(tokenID))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to call NEP11 `ownerOf` method: %w", err), 1)
+	}
+	for _, owner := range owners {
+		fmt.Fprintln(ctx.App.Writer, address.Uint160ToString(owner))
+	}
+	return nil
+}
+
+func printNEP11TokensOf(ctx *cli.Context) error {
+	tokenHash := ctx.Generic("token").(*flags.Address)
+	if !tokenHash.IsSet {
+		return cli.NewExitError("token contract hash was not set", 1)
+	}
+	acc := ctx.Generic("address").(*flags.Address)
+	if !acc.IsSet {
+		return cli.NewExitError("owner address flag was not set", 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+
+	ids, err := c.NEP11TokensOf(tokenHash.Uint160(), acc.Uint160())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to call NEP11 `tokensOf` method: %w", err), 1)
+	}
+	for _, id := range ids {
+		fmt.Fprintln(ctx.App.Writer, hex.EncodeToString(id))
+	}
+	return nil
+}
+
+func printNEP11Properties(ctx *cli.Context) error {
+	tokenHash := ctx.Generic("token").(*flags.Address)
+	if !tokenHash.IsSet {
+		return cli.NewExitError("token contract hash was not set", 1)
+	}
+	tokenID, err := getTokenIDFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	props, err := c.NEP11Properties(tokenHash.Uint160(), string(tokenID))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to get NEP11 token properties: %w", err), 1)
+	}
+	bs, err := json.Marshal(result.NewNEP11Properties(props.Value().([]stackitem.MapElement)))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to marshal properties: %w", err), 1)
+	}
+	fmt.Fprintln(ctx.App.Writer, string(bs))
+	return nil
+}
+
+// getTokenIDFromContext returns decoded token ID specified via `--id` flag.
+func getTokenIDFromContext(ctx *cli.Context) ([]byte, error) {
+	id := ctx.String("id")
+	if id == "" {
+		return nil, errors.New("token ID should be specified")
+	}
+	tokenID, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token ID: %w", err)
+	}
+	return tokenID, nil
+}
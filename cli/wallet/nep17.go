@@ -13,6 +13,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/urfave/cli"
@@ -186,7 +187,7 @@ func getNEP17Balance(ctx *cli.Context) error {
 			var tokenName, tokenSymbol string
 			tokenDecimals := 0
 			asset := balances.Balances[i].Asset
-			token, err := getMatchingToken(ctx, wall, asset.StringLE())
+			token, err := getMatchingToken(ctx, wall, asset.StringLE(), manifest.NEP17StandardName)
 			if err != nil {
 				token, err = c.NEP17TokenInfo(asset)
 			}
@@ -218,22 +219,46 @@ func getNEP17Balance(ctx *cli.Context) error {
 	return nil
 }
 
-func getMatchingToken(ctx *cli.Context, w *wallet.Wallet, name string) (*wallet.Token, error) {
+func getMatchingToken(ctx *cli.Context, w *wallet.Wallet, name string, standard string) (*wallet.Token, error) {
 	return getMatchingTokenAux(ctx, func(i int) *wallet.Token {
+		if !isTokenOfStandard(w.Extra.Tokens[i], standard) {
+			return nil
+		}
 		return w.Extra.Tokens[i]
 	}, len(w.Extra.Tokens), name)
 }
 
-func getMatchingTokenRPC(ctx *cli.Context, c *client.Client, addr util.Uint160, name string) (*wallet.Token, error) {
-	bs, err := c.GetNEP17Balances(addr)
-	if err != nil {
-		return nil, err
-	}
-	get := func(i int) *wallet.Token {
-		t, _ := c.NEP17TokenInfo(bs.Balances[i].Asset)
-		return t
+// isTokenOfStandard checks whether the token implements the given standard.
+// Tokens imported before standards were stored in the wallet are NEP-17 ones.
+func isTokenOfStandard(t *wallet.Token, standard string) bool {
+	return t.Standard == standard || (t.Standard == "" && standard == manifest.NEP17StandardName)
+}
+
+func getMatchingTokenRPC(ctx *cli.Context, c *client.Client, addr util.Uint160, name string, standard string) (*wallet.Token, error) {
+	switch standard {
+	case manifest.NEP17StandardName:
+		bs, err := c.GetNEP17Balances(addr)
+		if err != nil {
+			return nil, err
+		}
+		get := func(i int) *wallet.Token {
+			t, _ := c.NEP17TokenInfo(bs.Balances[i].Asset)
+			return t
+		}
+		return getMatchingTokenAux(ctx, get, len(bs.Balances), name)
+	case manifest.NEP11StandardName:
+		bs, err := c.GetNEP11Balances(addr)
+		if err != nil {
+			return nil, err
+		}
+		get := func(i int) *wallet.Token {
+			t, _ := c.NEP11TokenInfo(bs.Balances[i].Asset)
+			return t
+		}
+		return getMatchingTokenAux(ctx, get, len(bs.Balances), name)
+	default:
+		return nil, fmt.Errorf("unsupported %s token", standard)
 	}
-	return getMatchingTokenAux(ctx, get, len(bs.Balances), name)
 }
 
 func getMatchingTokenAux(ctx *cli.Context, get func(i int) *wallet.Token, n int, name string) (*wallet.Token, error) {
@@ -258,6 +283,10 @@ func getMatchingTokenAux(ctx *cli.Context, get func(i int) *wallet.Token, n int,
 }
 
 func importNEP17Token(ctx *cli.Context) error {
+	return importNEPToken(ctx, manifest.NEP17StandardName)
+}
+
+func importNEPToken(ctx *cli.Context, standard string) error {
 	wall, err := openWallet(ctx.String("wallet"))
 	if err != nil {
 		return cli.NewExitError(err, 1)
@@ -285,7 +314,13 @@ func importNEP17Token(ctx *cli.Context) error {
 		return cli.NewExitError(err, 1)
 	}
 
-	tok, err := c.NEP17TokenInfo(tokenHash)
+	var tok *wallet.Token
+	switch standard {
+	case manifest.NEP17StandardName:
+		tok, err = c.NEP17TokenInfo(tokenHash)
+	case manifest.NEP11StandardName:
+		tok, err = getNEP11TokenInfo(c, tokenHash)
+	}
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("can't receive token info: %w", err), 1)
 	}
@@ -305,9 +340,18 @@ func printTokenInfo(ctx *cli.Context, tok *wallet.Token) {
 	fmt.Fprintf(w, "Hash:\t%s\n", tok.Hash.StringLE())
 	fmt.Fprintf(w, "Decimals: %d\n", tok.Decimals)
 	fmt.Fprintf(w, "Address: %s\n", tok.Address())
+	standard := tok.Standard
+	if standard == "" {
+		standard = manifest.NEP17StandardName
+	}
+	fmt.Fprintf(w, "Standard:\t%s\n", standard)
 }
 
 func printNEP17Info(ctx *cli.Context) error {
+	return printNEPInfo(ctx, manifest.NEP17StandardName)
+}
+
+func printNEPInfo(ctx *cli.Context, standard string) error {
 	wall, err := openWallet(ctx.String("wallet"))
 	if err != nil {
 		return cli.NewExitError(err, 1)
@@ -315,7 +359,7 @@ func printNEP17Info(ctx *cli.Context) error {
 	defer wall.Close()
 
 	if name := ctx.String("token"); name != "" {
-		token, err := getMatchingToken(ctx, wall, name)
+		token, err := getMatchingToken(ctx, wall, name, standard)
 		if err != nil {
 			return cli.NewExitError(err, 1)
 		}
@@ -323,23 +367,32 @@ func printNEP17Info(ctx *cli.Context) error {
 		return nil
 	}
 
-	for i, t := range wall.Extra.Tokens {
-		if i > 0 {
+	var count int
+	for _, t := range wall.Extra.Tokens {
+		if !isTokenOfStandard(t, standard) {
+			continue
+		}
+		if count > 0 {
 			fmt.Fprintln(ctx.App.Writer)
 		}
 		printTokenInfo(ctx, t)
+		count++
 	}
 	return nil
 }
 
 func removeNEP17Token(ctx *cli.Context) error {
+	return removeNEPToken(ctx, manifest.NEP17StandardName)
+}
+
+func removeNEPToken(ctx *cli.Context, standard string) error {
 	wall, err := openWallet(ctx.String("wallet"))
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
 	defer wall.Close()
 
-	token, err := getMatchingToken(ctx, wall, ctx.String("token"))
+	token, err := getMatchingToken(ctx, wall, ctx.String("token"), standard)
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -401,10 +454,10 @@ func multiTransferNEP17(ctx *cli.Context) error {
 		}
 		token, ok := cache[ss[0]]
 		if !ok {
-			token, err = getMatchingToken(ctx, wall, ss[0])
+			token, err = getMatchingToken(ctx, wall, ss[0], manifest.NEP17StandardName)
 			if err != nil {
 				fmt.Fprintln(ctx.App.ErrWriter, "Can't find matching token in the wallet. Querying RPC-node for balances.")
-				token, err = getMatchingTokenRPC(ctx, c, from, ss[0])
+				token, err = getMatchingTokenRPC(ctx, c, from, ss[0], manifest.NEP17StandardName)
 				if err != nil {
 					return cli.NewExitError(err, 1)
 				}
@@ -466,10 +519,10 @@ func transferNEP17(ctx *cli.Context) error {
 
 	toFlag := ctx.Generic("to").(*flags.Address)
 	to := toFlag.Uint160()
-	token, err := getMatchingToken(ctx, wall, ctx.String("token"))
+	token, err := getMatchingToken(ctx, wall, ctx.String("token"), manifest.NEP17StandardName)
 	if err != nil {
 		fmt.Fprintln(ctx.App.ErrWriter, "Can't find matching token in the wallet. Querying RPC-node for balances.")
-		token, err = getMatchingTokenRPC(ctx, c, from, ctx.String("token"))
+		token, err = getMatchingTokenRPC(ctx, c, from, ctx.String("token"), manifest.NEP17StandardName)
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("failed to get matching token: %w", err), 1)
 		}
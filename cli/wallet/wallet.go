@@ -234,6 +234,11 @@ func NewCommands() []cli.Command {
 				Usage:       "work with NEP17 contracts",
 				Subcommands: newNEP17Commands(),
 			},
+			{
+				Name:        "nep11",
+				Usage:       "work with NEP11 contracts",
+				Subcommands: newNEP11Commands(),
+			},
 			{
 				Name:        "candidate",
 				Usage:       "work with candidates",
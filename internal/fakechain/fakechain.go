@@ -11,6 +11,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer/services"
+	"github.com/nspcc-dev/neo-go/pkg/core/dao"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
 	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
@@ -40,6 +41,23 @@ type FakeChain struct {
 	NotaryDepositExpiration  uint32
 	PostBlock                []func(blockchainer.Blockchainer, *mempool.Pool, *block.Block)
 	UtilityTokenBalance      *big.Int
+	StateSync                *FakeStateSync
+}
+
+// FakeStateSync implements StateSync interface.
+type FakeStateSync struct {
+	IsActiveFlag     bool
+	NeedHeadersFlag  bool
+	NeedMPTNodesFlag bool
+	NeedBlocksFlag   bool
+	Height           uint32
+	Headers          []*block.Header
+	Blocks           []*block.Block
+	MPTNodes         map[util.Uint256][]byte
+	AddedMPTNodes    [][]byte
+	StateRoots       []*state.MPTRoot
+	SyncPointCalls   int
+	AddMPTNodesFunc  func(nodes [][]byte) error
 }
 
 // NewFakeChain returns new FakeChain structure.
@@ -52,6 +70,7 @@ func NewFakeChain() *FakeChain {
 		hdrHashes:             make(map[uint32]util.Uint256),
 		txs:                   make(map[util.Uint256]*transaction.Transaction),
 		ProtocolConfiguration: config.ProtocolConfiguration{Magic: netmode.UnitTestNet, P2PNotaryRequestPayloadPoolSize: 10},
+		StateSync:             &FakeStateSync{MPTNodes: make(map[util.Uint256][]byte)},
 	}
 }
 
@@ -254,11 +273,21 @@ func (chain *FakeChain) GetNextBlockValidators() ([]*keys.PublicKey, error) {
 	panic("TODO")
 }
 
+// ForEachNEP11Transfer implements Blockchainer interface.
+func (chain *FakeChain) ForEachNEP11Transfer(util.Uint160, func(*state.NEP11Transfer) (bool, error)) error {
+	panic("TODO")
+}
+
 // ForEachNEP17Transfer implements Blockchainer interface.
 func (chain *FakeChain) ForEachNEP17Transfer(util.Uint160, func(*state.NEP17Transfer) (bool, error)) error {
 	panic("TODO")
 }
 
+// GetNEP11Balances implements Blockchainer interface.
+func (chain *FakeChain) GetNEP11Balances(util.Uint160) *state.NEP11Balances {
+	panic("TODO")
+}
+
 // GetNEP17Balances implements Blockchainer interface.
 func (chain *FakeChain) GetNEP17Balances(util.Uint160) *state.NEP17Balances {
 	panic("TODO")
@@ -289,13 +318,33 @@ func (chain *FakeChain) GetStateModule() blockchainer.StateRoot {
 	return nil
 }
 
+// GetStateSyncModule implements Blockchainer interface.
+func (chain *FakeChain) GetStateSyncModule() blockchainer.StateSync {
+	return chain.StateSync
+}
+
 // GetStorageItem implements Blockchainer interface.
 func (chain *FakeChain) GetStorageItem(id int32, key []byte) state.StorageItem {
 	panic("TODO")
 }
 
+// GetStorageChanges implements Blockchainer interface.
+func (chain *FakeChain) GetStorageChanges(hash util.Uint256) ([]state.StorageChange, error) {
+	panic("TODO")
+}
+
 // GetTestVM implements Blockchainer interface.
-func (chain *FakeChain) GetTestVM(t trigger.Type, tx *transaction.Transaction, b *block.Block) *vm.VM {
+func (chain *FakeChain) GetTestVM(t trigger.Type, tx *transaction.Transaction, b *block.Block) (*vm.VM, dao.DAO) {
+	panic("TODO")
+}
+
+// GetTestHistoricVM implements Blockchainer interface.
+func (chain *FakeChain) GetTestHistoricVM(t trigger.Type, tx *transaction.Transaction, b *block.Block, root util.Uint256) (*vm.VM, dao.DAO, error) {
+	panic("TODO")
+}
+
+// GetReplayVM implements Blockchainer interface.
+func (chain *FakeChain) GetReplayVM(h util.Uint256) (*vm.VM, *transaction.Transaction, error) {
 	panic("TODO")
 }
 
@@ -431,3 +480,81 @@ func (chain *FakeChain) UnsubscribeFromNotifications(ch chan<- *state.Notificati
 func (chain *FakeChain) UnsubscribeFromTransactions(ch chan<- *transaction.Transaction) {
 	panic("TODO")
 }
+
+// AddBlock implements StateSync interface.
+func (s *FakeStateSync) AddBlock(b *block.Block) error {
+	s.Blocks = append(s.Blocks, b)
+	return nil
+}
+
+// AddHeaders implements StateSync interface.
+func (s *FakeStateSync) AddHeaders(hdrs ...*block.Header) error {
+	s.Headers = append(s.Headers, hdrs...)
+	return nil
+}
+
+// AddMPTNodes implements StateSync interface.
+func (s *FakeStateSync) AddMPTNodes(nodes [][]byte) error {
+	if s.AddMPTNodesFunc != nil {
+		return s.AddMPTNodesFunc(nodes)
+	}
+	s.AddedMPTNodes = append(s.AddedMPTNodes, nodes...)
+	return nil
+}
+
+// AddStateRoot implements StateSync interface.
+func (s *FakeStateSync) AddStateRoot(_ util.Uint160, sr *state.MPTRoot) error {
+	s.StateRoots = append(s.StateRoots, sr)
+	return nil
+}
+
+// BlockHeight implements StateSync interface.
+func (s *FakeStateSync) BlockHeight() uint32 {
+	return s.Height
+}
+
+// GetMPTNode implements StateSync interface.
+func (s *FakeStateSync) GetMPTNode(h util.Uint256) ([]byte, error) {
+	if n, ok := s.MPTNodes[h]; ok {
+		return n, nil
+	}
+	return nil, errors.New("not found")
+}
+
+// GetUnknownMPTNodesBatch implements StateSync interface.
+func (s *FakeStateSync) GetUnknownMPTNodesBatch(limit int) []util.Uint256 {
+	res := make([]util.Uint256, 0, limit)
+	for h := range s.MPTNodes {
+		if len(res) == limit {
+			break
+		}
+		res = append(res, h)
+	}
+	return res
+}
+
+// IsActive implements StateSync interface.
+func (s *FakeStateSync) IsActive() bool {
+	return s.IsActiveFlag
+}
+
+// NeedBlocks implements StateSync interface.
+func (s *FakeStateSync) NeedBlocks() bool {
+	return s.NeedBlocksFlag
+}
+
+// NeedHeaders implements StateSync interface.
+func (s *FakeStateSync) NeedHeaders() bool {
+	return s.NeedHeadersFlag
+}
+
+// NeedMPTNodes implements StateSync interface.
+func (s *FakeStateSync) NeedMPTNodes() bool {
+	return s.NeedMPTNodesFlag
+}
+
+// SelectSyncPoint implements StateSync interface.
+func (s *FakeStateSync) SelectSyncPoint() error {
+	s.SyncPointCalls++
+	return nil
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
-	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
@@ -55,12 +54,7 @@ func NewDeployTx(bc blockchainer.Blockchainer, name string, sender util.Uint160,
 	// nef.NewFile() cares about version a lot.
 	config.Version = "0.90.0-test"
 
-	avm, di, err := compiler.CompileWithDebugInfo(name, r)
-	if err != nil {
-		return nil, util.Uint160{}, nil, err
-	}
-
-	ne, err := nef.NewFile(avm)
+	ne, di, err := compiler.CompileWithDebugInfo(name, r)
 	if err != nil {
 		return nil, util.Uint160{}, nil, err
 	}
@@ -79,6 +73,12 @@ func NewDeployTx(bc blockchainer.Blockchainer, name string, sender util.Uint160,
 		o.ContractEvents = conf.Events
 		o.ContractSupportedStandards = conf.SupportedStandards
 		o.SafeMethods = conf.SafeMethods
+		o.Permissions = conf.GetPermissions()
+		o.Trusts = conf.GetTrusts()
+		o.Groups, err = conf.GetGroups()
+		if err != nil {
+			return nil, util.Uint160{}, nil, fmt.Errorf("failed to parse configuration: %w", err)
+		}
 
 	}
 	m, err := compiler.CreateManifest(di, o)
@@ -104,7 +104,7 @@ func NewDeployTx(bc blockchainer.Blockchainer, name string, sender util.Uint160,
 	tx.Signers = []transaction.Signer{{Account: sender}}
 	h := state.CreateContractHash(tx.Sender(), ne.Checksum, name)
 
-	return tx, h, avm, nil
+	return tx, h, ne.Script, nil
 }
 
 // SignTx signs provided transactions with validator keys.
@@ -22,10 +22,12 @@ var (
 	}
 )
 
-// newGlobal creates new global variable.
-func (c *codegen) newGlobal(pkg string, name string) {
+// newGlobal creates new global variable and returns its index.
+func (c *codegen) newGlobal(pkg string, name string) int {
 	name = c.getIdentName(pkg, name)
-	c.globals[name] = len(c.globals)
+	index := len(c.globals)
+	c.globals[name] = index
+	return index
 }
 
 // getIdentName returns fully-qualified name for a variable.
@@ -293,7 +295,19 @@ func isSyscall(fun *funcScope) bool {
 		return false
 	}
 	return fun.pkg.Name() == "neogointernal" && (strings.HasPrefix(fun.name, "Syscall") ||
-		strings.HasPrefix(fun.name, "Opcode"))
+		strings.HasPrefix(fun.name, "Opcode") || strings.HasPrefix(fun.name, "CallWithToken"))
+}
+
+// isMultiReturnMethod checks whether decl is a contract method returning
+// multiple values.
+func isMultiReturnMethod(decl *ast.FuncDecl, isMain bool) bool {
+	return isMain && decl.Recv == nil && decl.Name.IsExported() &&
+		decl.Type.Results.NumFields() > 1
+}
+
+// isContractCall returns true if fun is contract.Call function.
+func isContractCall(fun *funcScope) bool {
+	return fun.pkg != nil && fun.pkg.Path() == interopPrefix+"/contract" && fun.name == "Call"
 }
 
 const interopPrefix = "github.com/nspcc-dev/neo-go/pkg/interop"
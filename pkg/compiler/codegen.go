@@ -15,6 +15,9 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
@@ -93,9 +96,18 @@ type codegen struct {
 	// docIndex maps file path to an index in documents array.
 	docIndex map[string]int
 
+	// staticVariables contains global variables debug information.
+	staticVariables []string
+
 	// emittedEvents contains all events emitted by contract.
 	emittedEvents map[string][][]string
 
+	// callTokens contains method tokens referenced by CALLT instructions.
+	callTokens []nef.MethodToken
+
+	// invokedContracts contains contract methods called by the contract.
+	invokedContracts []InvokedContract
+
 	// Label table for recording jump destinations.
 	l []int
 }
@@ -388,6 +400,7 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 	isDeploy := isDeployFunc(decl)
 	if isInit || isDeploy {
 		f = c.newFuncScope(decl, c.newLabel())
+		f.rng.Start = uint16(c.prog.Len())
 	} else {
 		f, ok = c.funcs[c.getFuncNameFromDecl("", decl)]
 		if ok {
@@ -395,16 +408,19 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 			if isSyscall(f) || isCustomBuiltin(f) {
 				return
 			}
+			f.rng.Start = uint16(c.prog.Len())
+			c.emitMultiReturnWrapper(f, pkg)
 			c.setLabel(f.label)
 		} else if f, ok = c.lambda[c.getIdentName("", decl.Name.Name)]; ok {
 			isLambda = ok
+			f.rng.Start = uint16(c.prog.Len())
 			c.setLabel(f.label)
 		} else {
 			f = c.newFunc(decl)
+			f.rng.Start = uint16(c.prog.Len())
 		}
 	}
 
-	f.rng.Start = uint16(c.prog.Len())
 	c.scope = f
 	ast.Inspect(decl, c.scope.analyzeVoidCalls) // @OPTIMIZE
 
@@ -468,6 +484,20 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 	}
 }
 
+// emitMultiReturnWrapper emits an entry point for exported contract method
+// returning multiple values. Internally such functions leave all results on
+// the stack, but only one value can be returned from contract method, so
+// wrapper calls the function and packs results into an array (with the first
+// result being the first element of it).
+func (c *codegen) emitMultiReturnWrapper(f *funcScope, pkg *types.Package) {
+	if !isMultiReturnMethod(f.decl, pkg == c.mainPkg.Pkg) {
+		return
+	}
+	emit.Call(c.prog.BinWriter, opcode.CALLL, f.label)
+	emit.Int(c.prog.BinWriter, int64(f.decl.Type.Results.NumFields()))
+	emit.Opcodes(c.prog.BinWriter, opcode.PACK, opcode.RET)
+}
+
 func (c *codegen) Visit(node ast.Node) ast.Visitor {
 	if c.prog.Err != nil {
 		return nil
@@ -479,7 +509,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 	//     x = 2
 	// )
 	case *ast.GenDecl:
-		if n.Tok == token.VAR || n.Tok == token.CONST {
+		if n.Tok == token.VAR {
 			c.saveSequencePoint(n)
 		}
 		if n.Tok == token.CONST {
@@ -501,13 +531,14 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			case *ast.ValueSpec:
 				for _, id := range t.Names {
 					if id.Name != "_" {
+						var index int
 						if c.scope == nil {
 							// it is a global declaration
-							c.newGlobal("", id.Name)
+							index = c.newGlobal("", id.Name)
 						} else {
-							c.scope.newLocal(id.Name)
+							index = c.scope.newLocal(id.Name)
 						}
-						c.registerDebugVariable(id.Name, t.Type)
+						c.registerDebugVariable(id.Name, t.Type, index)
 					}
 				}
 				for i := range t.Names {
@@ -536,12 +567,10 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		for i := 0; i < len(n.Lhs); i++ {
 			switch t := n.Lhs[i].(type) {
 			case *ast.Ident:
-				if n.Tok == token.DEFINE {
+				if n.Tok == token.DEFINE && t.Name != "_" {
+					index := c.scope.newLocal(t.Name)
 					if !multiRet {
-						c.registerDebugVariable(t.Name, n.Rhs[i])
-					}
-					if t.Name != "_" {
-						c.scope.newLocal(t.Name)
+						c.registerDebugVariable(t.Name, n.Rhs[i], index)
 					}
 				}
 				if !isAssignOp && (i == 0 || !multiRet) {
@@ -550,28 +579,30 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				c.emitStoreVar("", t.Name)
 
 			case *ast.SelectorExpr:
-				if !isAssignOp {
+				if !isAssignOp && (i == 0 || !multiRet) {
 					ast.Walk(c, n.Rhs[i])
 				}
 				typ := c.typeOf(t.X)
 				if typ == nil {
 					// Store to other package global variable.
 					c.emitStoreVar(t.X.(*ast.Ident).Name, t.Sel.Name)
-					return nil
+					continue
 				}
-				strct, ok := c.getStruct(typ)
+				path, ok := c.getFieldPath(t, typ)
 				if !ok {
-					c.prog.Err = fmt.Errorf("nested selector assigns not supported yet")
+					c.prog.Err = fmt.Errorf("selectors are supported only on structs")
 					return nil
 				}
-				ast.Walk(c, t.X)                      // load the struct
-				i := indexOfStruct(strct, t.Sel.Name) // get the index of the field
-				c.emitStoreStructField(i)             // store the field
+				ast.Walk(c, t.X) // load the struct
+				for _, j := range path[:len(path)-1] {
+					c.emitLoadField(j) // load embedded struct
+				}
+				c.emitStoreStructField(path[len(path)-1]) // store the field
 
 			// Assignments to index expressions.
 			// slice[0] = 10
 			case *ast.IndexExpr:
-				if !isAssignOp {
+				if !isAssignOp && (i == 0 || !multiRet) {
 					ast.Walk(c, n.Rhs[i])
 				}
 				ast.Walk(c, t.X)
@@ -582,12 +613,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		return nil
 
 	case *ast.SliceExpr:
-		if isCompoundSlice(c.typeOf(n.X.(*ast.Ident)).Underlying()) {
-			c.prog.Err = errors.New("subslices are supported only for []byte")
-			return nil
-		}
-		name := n.X.(*ast.Ident).Name
-		c.emitLoadVar("", name)
+		ast.Walk(c, n.X)
 
 		if n.Low != nil {
 			ast.Walk(c, n.Low)
@@ -601,7 +627,11 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			emit.Opcodes(c.prog.BinWriter, opcode.OVER, opcode.SIZE)
 		}
 
-		emit.Opcodes(c.prog.BinWriter, opcode.OVER, opcode.SUB, opcode.SUBSTR)
+		if isCompoundSlice(c.typeOf(n.X)) {
+			c.emitSubslice()
+		} else {
+			emit.Opcodes(c.prog.BinWriter, opcode.OVER, opcode.SUB, opcode.SUBSTR)
+		}
 
 		return nil
 
@@ -834,6 +864,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				isFunc = true
 			}
 			if ok && canInline(f.pkg.Path()) {
+				c.saveSequencePoint(n)
 				c.inlineCall(f, n)
 				return nil
 			}
@@ -852,7 +883,15 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			if ok {
 				f.selector = fun.X.(*ast.Ident)
 				isBuiltin = isCustomBuiltin(f)
+				if isContractCall(f) {
+					var method string
+					if tv := c.typeAndValueOf(n.Args[1]); tv.Value != nil {
+						method = constant.StringVal(tv.Value)
+					}
+					c.addInvokedContract(c.constantHash(n.Args[0]), method)
+				}
 				if canInline(f.pkg.Path()) {
+					c.saveSequencePoint(n)
 					c.inlineCall(f, n)
 					return nil
 				}
@@ -987,14 +1026,15 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			}
 			return nil
 		}
-		strct, ok := c.getStruct(typ)
+		path, ok := c.getFieldPath(n, typ)
 		if !ok {
 			c.prog.Err = fmt.Errorf("selectors are supported only on structs")
 			return nil
 		}
 		ast.Walk(c, n.X) // load the struct
-		i := indexOfStruct(strct, n.Sel.Name)
-		c.emitLoadField(i) // load the field
+		for _, i := range path {
+			c.emitLoadField(i) // load the field
+		}
 		return nil
 
 	case *ast.UnaryExpr:
@@ -1261,7 +1301,8 @@ func (c *codegen) isCallExprSyscall(e ast.Expr) bool {
 	}
 	name, _ := c.getFuncNameFromSelector(sel)
 	f, ok := c.funcs[name]
-	return ok && isSyscall(f)
+	// Contract call results are converted just like they were for System.Contract.Call.
+	return ok && isSyscall(f) && !strings.HasPrefix(f.name, "CallWithToken")
 }
 
 // processDefers emits code for `defer` statements.
@@ -1549,6 +1590,10 @@ func (c *codegen) getByteArray(expr ast.Expr) []byte {
 }
 
 func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
+	if strings.HasPrefix(f.name, "CallWithToken") {
+		c.convertCallWithToken(f, expr)
+		return
+	}
 	for _, arg := range expr.Args[1:] {
 		ast.Walk(c, arg)
 	}
@@ -1571,12 +1616,128 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 	}
 }
 
-// emitSliceHelper emits 3 items on stack: slice, its first index, and its size.
-func (c *codegen) emitSliceHelper(e ast.Expr) {
-	if !isByteSlice(c.typeOf(e)) {
-		c.prog.Err = fmt.Errorf("copy is supported only for byte-slices")
+// convertCallWithToken emits CALLT instruction referring to the method token
+// described by the constant call parameters.
+func (c *codegen) convertCallWithToken(f *funcScope, expr *ast.CallExpr) {
+	if expr.Ellipsis.IsValid() {
+		c.prog.Err = errors.New("CALLT arguments can't be passed as a slice")
+		return
+	}
+	var params [3]constant.Value
+	for i := range params {
+		params[i] = c.typeAndValueOf(expr.Args[i]).Value
+		if params[i] == nil {
+			c.prog.Err = fmt.Errorf("CALLT parameter #%d must be a constant", i+1)
+			return
+		}
+	}
+	u, err := util.Uint160DecodeBytesBE([]byte(constant.StringVal(params[0])))
+	if err != nil {
+		c.prog.Err = fmt.Errorf("invalid contract hash for CALLT: %w", err)
+		return
+	}
+	flags, ok := constant.Int64Val(params[2])
+	if !ok || flags < 0 || callflag.CallFlag(flags)&^callflag.All != 0 {
+		c.prog.Err = fmt.Errorf("invalid call flags for CALLT: %s", params[2])
+		return
+	}
+	method := constant.StringVal(params[1])
+	if strings.HasPrefix(method, "_") {
+		c.prog.Err = fmt.Errorf("invalid method name for CALLT: %s", method)
+		return
+	}
+	args := expr.Args[3:]
+	id, err := c.addCallToken(nef.MethodToken{
+		Hash:       u,
+		Method:     method,
+		ParamCount: uint16(len(args)),
+		HasReturn:  f.name == "CallWithToken",
+		CallFlag:   callflag.CallFlag(flags),
+	})
+	if err != nil {
+		c.prog.Err = err
 		return
 	}
+	c.addInvokedContract(&u, method)
+	for _, arg := range args {
+		ast.Walk(c, arg)
+	}
+	c.emitReverse(len(args))
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, id)
+	emit.Instruction(c.prog.BinWriter, opcode.CALLT, buf)
+}
+
+// addCallToken returns index of the method token in the contract token table
+// adding it there if needed.
+func (c *codegen) addCallToken(t nef.MethodToken) (uint16, error) {
+	for i := range c.callTokens {
+		if c.callTokens[i] == t {
+			return uint16(i), nil
+		}
+	}
+	if len(c.callTokens) > math.MaxUint16 {
+		return 0, errors.New("too many method tokens")
+	}
+	c.callTokens = append(c.callTokens, t)
+	return uint16(len(c.callTokens) - 1), nil
+}
+
+// addInvokedContract records contract method called by the code, nil hash or
+// empty method mean they're not known at compile time.
+func (c *codegen) addInvokedContract(h *util.Uint160, method string) {
+	for _, ic := range c.invokedContracts {
+		if ic.Method == method && (ic.Hash == nil && h == nil ||
+			ic.Hash != nil && h != nil && ic.Hash.Equals(*h)) {
+			return
+		}
+	}
+	c.invokedContracts = append(c.invokedContracts, InvokedContract{Hash: h, Method: method})
+}
+
+// constantHash returns contract hash (in BE form) specified by e if it's
+// known at compile time and nil otherwise.
+func (c *codegen) constantHash(e ast.Expr) *util.Uint160 {
+	var b []byte
+	switch t := e.(type) {
+	case *ast.CallExpr:
+		// Type conversion like interop.Hash160("...") or []byte("...").
+		if len(t.Args) == 1 && c.typeAndValueOf(t.Fun).IsType() {
+			return c.constantHash(t.Args[0])
+		}
+		return nil
+	case *ast.CompositeLit:
+		if !isByteSlice(c.typeOf(t)) {
+			return nil
+		}
+		b = make([]byte, len(t.Elts))
+		for i := range t.Elts {
+			tv := c.typeAndValueOf(t.Elts[i])
+			if tv.Value == nil {
+				return nil
+			}
+			v, ok := constant.Int64Val(tv.Value)
+			if !ok {
+				return nil
+			}
+			b[i] = byte(v)
+		}
+	default:
+		tv := c.typeAndValueOf(e)
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return nil
+		}
+		b = []byte(constant.StringVal(tv.Value))
+	}
+	u, err := util.Uint160DecodeBytesBE(b)
+	if err != nil {
+		return nil
+	}
+	return &u
+}
+
+// emitSliceHelper emits 3 items on stack: slice, its first index, and its size.
+func (c *codegen) emitSliceHelper(e ast.Expr) {
 	var hasLowIndex bool
 	switch src := e.(type) {
 	case *ast.SliceExpr:
@@ -1604,6 +1765,71 @@ func (c *codegen) emitSliceHelper(e ast.Expr) {
 	}
 }
 
+// emitSubslice creates a new array from the `x low high` items on stack,
+// containing x[low:high] elements.
+func (c *codegen) emitSubslice() {
+	start := c.newLabel()
+	end := c.newLabel()
+	emit.Opcodes(c.prog.BinWriter, opcode.NEWARRAY0) // x low high res
+	c.setLabel(start)
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH2, opcode.PICK, // x low high res low
+		opcode.PUSH2, opcode.PICK) // x low high res low high
+	emit.Jmp(c.prog.BinWriter, opcode.JMPGEL, end) // x low high res
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP,     // x low high res res
+		opcode.PUSH4, opcode.PICK, opcode.PUSH4, opcode.PICK, // x low high res res x low
+		opcode.PICKITEM, opcode.APPEND, // x low high res
+		opcode.ROT, opcode.INC, // x high res low+1
+		opcode.ROT, opcode.ROT) // x low+1 high res
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, start)
+	c.setLabel(end)
+	emit.Opcodes(c.prog.BinWriter, opcode.NIP, opcode.NIP, opcode.NIP)
+}
+
+// emitCopyItems copies `n` items from `src` array to `dst` one (like MEMCPY
+// does for buffers) using `dst di src si n` items on stack. Overlapping arrays
+// are handled properly.
+func (c *codegen) emitCopyItems() {
+	fwd := c.newLabel()
+	fwdEnd := c.newLabel()
+	bwd := c.newLabel()
+	end := c.newLabel()
+
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH3, opcode.PICK, opcode.PUSH2, opcode.PICK) // ... di si
+	emit.Jmp(c.prog.BinWriter, opcode.JMPGTL, bwd)
+
+	// for k := 0; k < n; k++ { dst[di+k] = src[si+k] }
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH0) // dst di src si n k
+	c.setLabel(fwd)
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP, opcode.PUSH2, opcode.PICK) // ... n k k n
+	emit.Jmp(c.prog.BinWriter, opcode.JMPGEL, fwdEnd)
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH5, opcode.PICK, // ... n k dst
+		opcode.PUSH5, opcode.PICK, opcode.PUSH2, opcode.PICK, opcode.ADD, // ... n k dst di+k
+		opcode.PUSH5, opcode.PICK, // ... n k dst di+k src
+		opcode.PUSH5, opcode.PICK, opcode.PUSH4, opcode.PICK, opcode.ADD, // ... n k dst di+k src si+k
+		opcode.PICKITEM, opcode.SETITEM, opcode.INC) // dst di src si n k+1
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, fwd)
+	c.setLabel(fwdEnd)
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, end)
+
+	// for n--; n >= 0; n-- { dst[di+n] = src[si+n] }
+	c.setLabel(bwd)
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP) // dst di src si n n
+	emit.Jmp(c.prog.BinWriter, opcode.JMPIFNOTL, end)
+	emit.Opcodes(c.prog.BinWriter, opcode.DEC, // dst di src si n-1
+		opcode.PUSH4, opcode.PICK, // ... n-1 dst
+		opcode.PUSH4, opcode.PICK, opcode.PUSH2, opcode.PICK, opcode.ADD, // ... n-1 dst di+n-1
+		opcode.PUSH4, opcode.PICK, // ... n-1 dst di+n-1 src
+		opcode.PUSH4, opcode.PICK, opcode.PUSH4, opcode.PICK, opcode.ADD, // ... n-1 dst di+n-1 src si+n-1
+		opcode.PICKITEM, opcode.SETITEM) // dst di src si n-1
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, bwd)
+
+	c.setLabel(end)
+	for i := 0; i < 5; i++ {
+		emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+	}
+}
+
 func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 	var name string
 	switch t := expr.Fun.(type) {
@@ -1628,22 +1854,28 @@ func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 			emit.Int(c.prog.BinWriter, 5)
 			emit.Opcodes(c.prog.BinWriter, opcode.REVERSEN)
 		}
-		emit.Opcodes(c.prog.BinWriter, opcode.MEMCPY)
+		if isCompoundSlice(c.typeOf(expr.Args[0])) {
+			c.emitCopyItems()
+		} else {
+			emit.Opcodes(c.prog.BinWriter, opcode.MEMCPY)
+		}
 	case "make":
 		typ := c.typeOf(expr.Args[0])
 		switch {
 		case isMap(typ):
 			emit.Opcodes(c.prog.BinWriter, opcode.NEWMAP)
 		default:
-			if len(expr.Args) == 3 {
-				c.prog.Err = fmt.Errorf("`make()` with a capacity argument is not supported")
-				return
-			}
 			ast.Walk(c, expr.Args[1])
+			// There is no capacity in VM, but the argument still needs
+			// to be evaluated if it's not a constant.
+			if len(expr.Args) == 3 && c.typeAndValueOf(expr.Args[2]).Value == nil {
+				ast.Walk(c, expr.Args[2])
+				emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+			}
 			if isByteSlice(typ) {
 				emit.Opcodes(c.prog.BinWriter, opcode.NEWBUFFER)
 			} else {
-				neoT := toNeoType(typ.(*types.Slice).Elem())
+				neoT := toNeoType(typ.Underlying().(*types.Slice).Elem())
 				emit.Instruction(c.prog.BinWriter, opcode.NEWARRAYT, []byte{byte(neoT)})
 			}
 		}
@@ -1797,6 +2029,24 @@ func (c *codegen) convertMap(lit *ast.CompositeLit) {
 	}
 }
 
+// getFieldPath returns indices of the fields to be loaded to get the field
+// selected by sel from the struct of type typ. There can be more than one
+// index for promoted fields of embedded structs.
+func (c *codegen) getFieldPath(sel *ast.SelectorExpr, typ types.Type) ([]int, bool) {
+	if s := c.typeInfo.Selections[sel]; s != nil && s.Kind() == types.FieldVal {
+		return s.Index(), true
+	}
+	strct, ok := c.getStruct(typ)
+	if !ok {
+		return nil, false
+	}
+	i := indexOfStruct(strct, sel.Sel.Name)
+	if i < 0 {
+		return nil, false
+	}
+	return []int{i}, true
+}
+
 func (c *codegen) getStruct(typ types.Type) (*types.Struct, bool) {
 	switch t := typ.Underlying().(type) {
 	case *types.Struct:
@@ -2041,8 +2291,9 @@ func newCodegen(info *buildInfo, pkg *loader.PackageInfo) *codegen {
 	}
 }
 
-// CodeGen compiles the program to bytecode.
-func CodeGen(info *buildInfo) ([]byte, *DebugInfo, error) {
+// CodeGen compiles the program to NEF file containing bytecode and method
+// tokens it refers to.
+func CodeGen(info *buildInfo) (*nef.File, *DebugInfo, error) {
 	pkg := info.program.Package(info.initialPackage)
 	c := newCodegen(info, pkg)
 
@@ -2054,7 +2305,15 @@ func CodeGen(info *buildInfo) ([]byte, *DebugInfo, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	return buf, c.emitDebugInfo(buf), nil
+	f, err := nef.NewFile(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while trying to create .nef file: %w", err)
+	}
+	if len(c.callTokens) != 0 {
+		f.Tokens = c.callTokens
+		f.Checksum = f.CalculateChecksum()
+	}
+	return f, c.emitDebugInfo(buf), nil
 }
 
 func (c *codegen) resolveFuncDecls(f *ast.File, pkg *types.Package) {
@@ -2118,9 +2377,27 @@ func (c *codegen) writeJumps(b []byte) ([]byte, error) {
 	for _, f := range c.funcs {
 		f.rng.Start, f.rng.End = correctRange(f.rng.Start, f.rng.End, offsets)
 	}
+	for _, sps := range c.sequencePoints {
+		for i := range sps {
+			sps[i].Opcode = correctOffset(sps[i].Opcode, offsets)
+		}
+	}
 	return shortenJumps(b, offsets), nil
 }
 
+// correctOffset returns the offset of instruction at ip after the jumps at
+// the specified offsets are shortened.
+func correctOffset(ip int, offsets []int) int {
+	res := ip
+	for _, ind := range offsets {
+		if ind >= ip {
+			break
+		}
+		res -= longToShortRemoveCount
+	}
+	return res
+}
+
 func correctRange(start, end uint16, offsets []int) (uint16, uint16) {
 	newStart, newEnd := start, end
 loop:
@@ -43,6 +43,14 @@ type Options struct {
 	// This setting has effect only if manifest is emitted.
 	NoStandardCheck bool
 
+	// NoPermissionsCheck specifies if contract calls made by contract need to be
+	// allowed by permissions. This setting has effect only if manifest is emitted.
+	NoPermissionsCheck bool
+
+	// InferPermissions specifies if permissions needed for contract calls made
+	// by contract are to be added to the ones specified in Permissions.
+	InferPermissions bool
+
 	// Name is contract's name to be written to manifest.
 	Name string
 
@@ -54,6 +62,16 @@ type Options struct {
 
 	// SafeMethods contains list of methods which will be marked as safe in manifest.
 	SafeMethods []string
+
+	// Permissions is a list of permissions for contract calls, everything is
+	// allowed if it's empty (and permissions are not inferred).
+	Permissions []manifest.Permission
+
+	// Trusts is a set of contracts trusted by the contract, none if it's nil.
+	Trusts *manifest.WildUint160s
+
+	// Groups is a list of groups the contract belongs to.
+	Groups []manifest.Group
 }
 
 type buildInfo struct {
@@ -142,17 +160,22 @@ func getBuildInfo(name string, src interface{}) (*buildInfo, error) {
 // Compile compiles a Go program into bytecode that can run on the NEO virtual machine.
 // If `r != nil`, `name` is interpreted as a filename, and `r` as file contents.
 // Otherwise `name` is either file name or name of the directory containing source files.
+// Method tokens used by the program are not returned, so the script can't be
+// run if it refers to them, use CompileWithDebugInfo to get them.
 func Compile(name string, r io.Reader) ([]byte, error) {
-	buf, _, err := CompileWithDebugInfo(name, r)
+	f, _, err := CompileWithDebugInfo(name, r)
 	if err != nil {
 		return nil, err
 	}
 
-	return buf, nil
+	return f.Script, nil
 }
 
-// CompileWithDebugInfo compiles a Go program into bytecode and emits debug info.
-func CompileWithDebugInfo(name string, r io.Reader) ([]byte, *DebugInfo, error) {
+// CompileWithDebugInfo compiles a Go program into NEF file (containing bytecode
+// along with method tokens it uses) and emits debug info. Note that it used
+// to return the bytecode only before method tokens support, it's available as
+// the Script field of the NEF file now.
+func CompileWithDebugInfo(name string, r io.Reader) (*nef.File, *DebugInfo, error) {
 	ctx, err := getBuildInfo(name, r)
 	if err != nil {
 		return nil, nil, err
@@ -173,14 +196,11 @@ func CompileAndSave(src string, o *Options) ([]byte, error) {
 	if len(o.Ext) == 0 {
 		o.Ext = fileExt
 	}
-	b, di, err := CompileWithDebugInfo(src, nil)
+	f, di, err := CompileWithDebugInfo(src, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error while trying to compile smart contract file: %w", err)
 	}
-	f, err := nef.NewFile(b)
-	if err != nil {
-		return nil, fmt.Errorf("error while trying to create .nef file: %w", err)
-	}
+	b := f.Script
 	bytes, err := f.Bytes()
 	if err != nil {
 		return nil, fmt.Errorf("error while serializing .nef file: %w", err)
@@ -257,6 +277,16 @@ func CreateManifest(di *DebugInfo, o *Options) (*manifest.Manifest, error) {
 			}
 		}
 	}
+	if err := manifest.Permissions(m.Permissions).AreValid(); err != nil {
+		return nil, fmt.Errorf("invalid permissions: %w", err)
+	}
+	if !o.NoPermissionsCheck {
+		for _, ic := range di.InvokedContracts {
+			if !isCallAllowed(m.Permissions, ic) {
+				return nil, fmt.Errorf("%s is not allowed by permissions", ic)
+			}
+		}
+	}
 	if !o.NoEventsCheck {
 		for name := range di.EmittedEvents {
 			ev := m.ABI.GetEvent(name)
@@ -9,6 +9,10 @@ import (
 
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
 	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/gas"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -130,3 +134,136 @@ func TestOnPayableChecks(t *testing.T) {
 		require.Error(t, compileAndCheck(t, src))
 	})
 }
+
+func TestManifestPermissions(t *testing.T) {
+	src := `package foo
+	import "github.com/nspcc-dev/neo-go/pkg/interop"
+	import "github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	import "github.com/nspcc-dev/neo-go/pkg/interop/native/gas"
+	func Main(h interop.Hash160, method string) {
+		gas.Transfer(h, h, 1, nil)
+		contract.Call(interop.Hash160("\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f\x10\x11\x12\x13\x14"), "m1", contract.All)
+		contract.Call(h, "m2", contract.All)
+		contract.Call([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, method, contract.All)
+		contract.Call(h, "m2", contract.ReadOnly)
+	}`
+	_, di, err := compiler.CompileWithDebugInfo("foo", strings.NewReader(src))
+	require.NoError(t, err)
+
+	gasHash, err := util.Uint160DecodeBytesBE([]byte(gas.Hash))
+	require.NoError(t, err)
+	h, err := util.Uint160DecodeBytesBE([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20})
+	require.NoError(t, err)
+	require.Equal(t, []compiler.InvokedContract{
+		{Hash: &gasHash, Method: "transfer"},
+		{Hash: &h, Method: "m1"},
+		{Method: "m2"},
+		{Hash: &h},
+	}, di.InvokedContracts)
+
+	newPermission := func(typ manifest.PermissionType, methods []string, args ...interface{}) manifest.Permission {
+		p := manifest.NewPermission(typ, args...)
+		p.Methods.Value = methods
+		return *p
+	}
+	t.Run("wildcard by default", func(t *testing.T) {
+		m, err := compiler.CreateManifest(di, &compiler.Options{Name: "foo"})
+		require.NoError(t, err)
+		require.Equal(t, []manifest.Permission{
+			newPermission(manifest.PermissionWildcard, nil),
+		}, m.Permissions)
+	})
+	t.Run("infer", func(t *testing.T) {
+		m, err := compiler.CreateManifest(di, &compiler.Options{Name: "foo", InferPermissions: true})
+		require.NoError(t, err)
+		require.Equal(t, []manifest.Permission{
+			newPermission(manifest.PermissionHash, []string{"transfer"}, gasHash),
+			newPermission(manifest.PermissionHash, nil, h),
+			newPermission(manifest.PermissionWildcard, []string{"m2"}),
+		}, m.Permissions)
+	})
+	t.Run("infer, extend declared", func(t *testing.T) {
+		declared := []manifest.Permission{
+			newPermission(manifest.PermissionHash, []string{"balanceOf"}, gasHash),
+			newPermission(manifest.PermissionWildcard, []string{"m1"}),
+		}
+		m, err := compiler.CreateManifest(di, &compiler.Options{
+			Name:             "foo",
+			Permissions:      declared,
+			InferPermissions: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []manifest.Permission{
+			newPermission(manifest.PermissionHash, []string{"balanceOf", "transfer"}, gasHash),
+			newPermission(manifest.PermissionWildcard, []string{"m1", "m2"}),
+			newPermission(manifest.PermissionHash, nil, h),
+		}, m.Permissions)
+		require.Equal(t, []string{"balanceOf"}, declared[0].Methods.Value, "declared permissions are not changed")
+	})
+	t.Run("check", func(t *testing.T) {
+		check := func(t *testing.T, ok bool, ps ...manifest.Permission) {
+			_, err := compiler.CreateManifest(di, &compiler.Options{Name: "foo", Permissions: ps})
+			if ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		}
+		check(t, true,
+			newPermission(manifest.PermissionHash, []string{"transfer"}, gasHash),
+			newPermission(manifest.PermissionHash, nil, h))
+		check(t, false,
+			newPermission(manifest.PermissionHash, []string{"balanceOf"}, gasHash),
+			newPermission(manifest.PermissionHash, nil, h))
+		check(t, false,
+			newPermission(manifest.PermissionHash, []string{"transfer"}, gasHash),
+			newPermission(manifest.PermissionHash, []string{"m1"}, h))
+		check(t, true,
+			newPermission(manifest.PermissionWildcard, []string{"transfer"}),
+			newPermission(manifest.PermissionHash, nil, h))
+
+		priv, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+		check(t, true, newPermission(manifest.PermissionGroup, nil, priv.PublicKey()))
+		check(t, false, newPermission(manifest.PermissionGroup, []string{"transfer"}, priv.PublicKey()))
+
+		_, err = compiler.CreateManifest(di, &compiler.Options{
+			Name:               "foo",
+			Permissions:        []manifest.Permission{newPermission(manifest.PermissionHash, []string{"m1"}, h)},
+			NoPermissionsCheck: true,
+		})
+		require.NoError(t, err)
+	})
+	t.Run("duplicate permissions", func(t *testing.T) {
+		m, err := compiler.CreateManifest(di, &compiler.Options{
+			Name: "foo",
+			Permissions: []manifest.Permission{
+				newPermission(manifest.PermissionWildcard, nil),
+				newPermission(manifest.PermissionWildcard, []string{"m1"}),
+			},
+		})
+		require.Error(t, err)
+		require.Nil(t, m)
+	})
+}
+
+func TestManifestTrustsGroups(t *testing.T) {
+	_, di, err := compiler.CompileWithDebugInfo("foo", strings.NewReader(`package foo
+	func Main() {}`))
+	require.NoError(t, err)
+
+	m, err := compiler.CreateManifest(di, &compiler.Options{Name: "foo"})
+	require.NoError(t, err)
+	require.False(t, m.Trusts.IsWildcard())
+	require.Equal(t, 0, len(m.Trusts.Value))
+	require.Equal(t, 0, len(m.Groups))
+
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	groups := []manifest.Group{{PublicKey: priv.PublicKey(), Signature: make([]byte, 64)}}
+	trusts := &manifest.WildUint160s{Value: []util.Uint160{{1, 2, 3}}}
+	m, err = compiler.CreateManifest(di, &compiler.Options{Name: "foo", Trusts: trusts, Groups: groups})
+	require.NoError(t, err)
+	require.Equal(t, *trusts, m.Trusts)
+	require.Equal(t, groups, m.Groups)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 )
 
@@ -22,8 +23,20 @@ type DebugInfo struct {
 	Documents []string          `json:"documents"`
 	Methods   []MethodDebugInfo `json:"methods"`
 	Events    []EventDebugInfo  `json:"events"`
+	// StaticVariables contains a list of global variables in a
+	// "{name},{type},{slot}" format.
+	StaticVariables []string `json:"static-variables"`
 	// EmittedEvents contains events occurring in code.
 	EmittedEvents map[string][][]string `json:"-"`
+	// InvokedContracts contains contract methods called by the code.
+	InvokedContracts []InvokedContract `json:"-"`
+}
+
+// InvokedContract is a contract method called by the code. Hash is nil and
+// Method is empty if they're not known at compile time.
+type InvokedContract struct {
+	Hash   *util.Uint160
+	Method string
 }
 
 // MethodDebugInfo represents smart-contract's method debug information.
@@ -46,7 +59,9 @@ type MethodDebugInfo struct {
 	ReturnType string `json:"return"`
 	// ReturnTypeSC is return type to use in manifest.
 	ReturnTypeSC smartcontract.ParamType `json:"-"`
-	Variables    []string                `json:"variables"`
+	// Variables is a list of method's local variables in a
+	// "{name},{type},{slot}" format.
+	Variables []string `json:"variables"`
 	// SeqPoints is a map between source lines and byte-code instruction offsets.
 	SeqPoints []DebugSeqPoint `json:"sequence-points"`
 }
@@ -107,17 +122,18 @@ func (c *codegen) saveSequencePoint(n ast.Node) {
 		Opcode:    c.prog.Len(),
 		Document:  c.docIndex[start.Filename],
 		StartLine: start.Line,
-		StartCol:  start.Offset,
+		StartCol:  start.Column,
 		EndLine:   end.Line,
-		EndCol:    end.Offset,
+		EndCol:    end.Column,
 	})
 }
 
 func (c *codegen) emitDebugInfo(contract []byte) *DebugInfo {
 	d := &DebugInfo{
-		MainPkg:   c.mainPkg.Pkg.Name(),
-		Events:    []EventDebugInfo{},
-		Documents: c.documents,
+		MainPkg:         c.mainPkg.Pkg.Name(),
+		Events:          []EventDebugInfo{},
+		Documents:       c.documents,
+		StaticVariables: c.staticVariables,
 	}
 	if c.initEndOffset > 0 {
 		d.Methods = append(d.Methods, MethodDebugInfo{
@@ -175,16 +191,21 @@ func (c *codegen) emitDebugInfo(contract []byte) *DebugInfo {
 		d.Methods = append(d.Methods, *m)
 	}
 	d.EmittedEvents = c.emittedEvents
+	d.InvokedContracts = c.invokedContracts
 	return d
 }
 
-func (c *codegen) registerDebugVariable(name string, expr ast.Expr) {
+func (c *codegen) registerDebugVariable(name string, expr ast.Expr, index int) {
+	_, vt := c.scAndVMTypeFromExpr(expr)
+	v := name + "," + vt.String() + "," + strconv.Itoa(index)
 	if c.scope == nil {
-		// do not save globals for now
+		if c.currPkg != c.mainPkg.Pkg {
+			v = c.currPkg.Name() + "." + v
+		}
+		c.staticVariables = append(c.staticVariables, v)
 		return
 	}
-	_, vt := c.scAndVMTypeFromExpr(expr)
-	c.scope.variables = append(c.scope.variables, name+","+vt.String())
+	c.scope.variables = append(c.scope.variables, v)
 }
 
 func (c *codegen) methodInfoFromScope(name string, scope *funcScope) *MethodDebugInfo {
@@ -230,7 +251,11 @@ func (c *codegen) scAndVMReturnTypeFromScope(scope *funcScope) (smartcontract.Pa
 		st, vt := c.scAndVMTypeFromExpr(results.List[0].Type)
 		return st, vt.String()
 	default:
-		// multiple return values are not supported in debugger
+		// multiple return values are packed into an array for exported
+		// methods and are left on stack for internal calls
+		if isMultiReturnMethod(scope.decl, scope.pkg == c.mainPkg.Pkg) {
+			return smartcontract.ArrayType, "Array"
+		}
 		return smartcontract.AnyType, "Any"
 	}
 }
@@ -436,13 +461,36 @@ func (di *DebugInfo) ConvertToManifest(o *Options) (*manifest.Manifest, error) {
 	if result.ABI.Events == nil {
 		result.ABI.Events = make([]manifest.Event, 0)
 	}
-	result.Permissions = []manifest.Permission{
-		{
-			Contract: manifest.PermissionDesc{
-				Type: manifest.PermissionWildcard,
+	if len(o.Groups) != 0 {
+		result.Groups = o.Groups
+	}
+	if o.Trusts != nil {
+		result.Trusts = *o.Trusts
+	}
+	switch {
+	case len(o.Permissions) != 0:
+		result.Permissions = make([]manifest.Permission, len(o.Permissions))
+		for i := range o.Permissions {
+			result.Permissions[i] = o.Permissions[i]
+			if !o.Permissions[i].Methods.IsWildcard() {
+				// Methods can be added to it if permissions are inferred.
+				result.Permissions[i].Methods.Value = append([]string{}, o.Permissions[i].Methods.Value...)
+			}
+		}
+	case !o.InferPermissions:
+		result.Permissions = []manifest.Permission{
+			{
+				Contract: manifest.PermissionDesc{
+					Type: manifest.PermissionWildcard,
+				},
+				Methods: manifest.WildStrings{},
 			},
-			Methods: manifest.WildStrings{},
-		},
+		}
+	}
+	if o.InferPermissions {
+		for _, ic := range di.InvokedContracts {
+			result.Permissions = addPermission(result.Permissions, ic)
+		}
 	}
 	return result, nil
 }
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/nspcc-dev/neo-go/internal/testserdes"
@@ -89,7 +90,7 @@ func _deploy(data interface{}, isUpdate bool) {}
 
 	t.Run("variables", func(t *testing.T) {
 		vars := map[string][]string{
-			"Main": {"s,ByteString", "res,Integer"},
+			"Main": {"s,ByteString,0", "res,Integer,1"},
 		}
 		for i := range d.Methods {
 			v, ok := vars[d.Methods[i].ID]
@@ -304,6 +305,136 @@ func TestSequencePoints(t *testing.T) {
 	require.Equal(t, 2, len(ps))
 	require.Equal(t, 4, ps[0].StartLine)
 	require.Equal(t, 6, ps[1].StartLine)
+
+	// Columns are counted from the beginning of the line (not the file).
+	require.Equal(t, 4, ps[0].StartCol)
+	require.Equal(t, 15, ps[0].EndCol)
+	require.Equal(t, 3, ps[1].StartCol)
+	require.Equal(t, 15, ps[1].EndCol)
+}
+
+func TestSequencePointsShortJumps(t *testing.T) {
+	src := `package foo
+	func Main(a int) int {
+		b := sum(a, 1)
+		return b
+	}
+	func sum(x, y int) int {
+		return x + y
+	}`
+
+	info, err := getBuildInfo("foo.go", src)
+	require.NoError(t, err)
+
+	pkg := info.program.Package(info.initialPackage)
+	c := newCodegen(info, pkg)
+	require.NoError(t, c.compile(info, pkg))
+
+	buf, err := c.writeJumps(c.prog.Bytes())
+	require.NoError(t, err)
+	d := c.emitDebugInfo(buf)
+
+	expected := map[int]opcode.Opcode{
+		4: opcode.RET, // return b
+		7: opcode.RET, // return x + y
+	}
+	for _, m := range d.Methods {
+		for _, sp := range m.SeqPoints {
+			require.True(t, int(m.Range.Start) <= sp.Opcode && sp.Opcode <= int(m.Range.End))
+			if op, ok := expected[sp.StartLine]; ok {
+				require.Equal(t, op, opcode.Opcode(buf[sp.Opcode]), "line %d", sp.StartLine)
+			}
+		}
+	}
+}
+
+func TestSequencePointsInline(t *testing.T) {
+	src := `package foo
+	import "github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	const c = 1
+	var g = 2
+	func Main() int {
+		runtime.Log("log")
+		return c + g
+	}`
+
+	_, d, err := CompileWithDebugInfo("foo.go", strings.NewReader(src))
+	require.NoError(t, err)
+
+	lines := make(map[string][]int)
+	for _, m := range d.Methods {
+		for _, sp := range m.SeqPoints {
+			if d.Documents[sp.Document] == "foo.go" {
+				lines[m.ID] = append(lines[m.ID], sp.StartLine)
+			}
+		}
+	}
+	// Constants produce no code, inlined call has a sequence point at the
+	// call site.
+	require.Equal(t, []int{4}, lines["_initialize"])
+	require.Equal(t, []int{6, 7}, lines["Main"])
+}
+
+func TestDebugInfo_VariableSlots(t *testing.T) {
+	src := `package foo
+	var a, b = 1, "str"
+	func Main(x int) int {
+		c := x + a
+		var d []byte
+		e, _ := 2, 3
+		for i := range d {
+			c += i
+		}
+		f, g := pair()
+		return c + e + f + g + len(b)
+	}
+	func pair() (int, int) { return 1, 2 }`
+
+	info, err := getBuildInfo("foo.go", src)
+	require.NoError(t, err)
+
+	pkg := info.program.Package(info.initialPackage)
+	c := newCodegen(info, pkg)
+	require.NoError(t, c.compile(info, pkg))
+
+	d := c.emitDebugInfo(c.prog.Bytes())
+	require.Equal(t, []string{"a,Any,0", "b,Any,1"}, d.StaticVariables)
+	for i := range d.Methods {
+		if d.Methods[i].ID == "Main" {
+			require.Equal(t, []string{"c,Integer,0", "d,ByteString,1", "e,Integer,2"}, d.Methods[i].Variables)
+			return
+		}
+	}
+	t.Fatal("Main method not found")
+}
+
+func TestDebugInfo_MultipleReturn(t *testing.T) {
+	src := `package foo
+	func Main() int {
+		a, _ := Pair()
+		b, _ := pair()
+		return a + b
+	}
+	func Pair() (int, bool) { return 1, true }
+	func pair() (int, bool) { return 2, false }`
+
+	info, err := getBuildInfo("foo.go", src)
+	require.NoError(t, err)
+
+	pkg := info.program.Package(info.initialPackage)
+	c := newCodegen(info, pkg)
+	require.NoError(t, c.compile(info, pkg))
+
+	d := c.emitDebugInfo(c.prog.Bytes())
+	// Only exported methods are wrapped to return an array.
+	returnTypes := map[string]string{
+		"Main": "Integer",
+		"Pair": "Array",
+		"pair": "Any",
+	}
+	for i := range d.Methods {
+		require.Equal(t, returnTypes[d.Methods[i].ID], d.Methods[i].ReturnType, d.Methods[i].ID)
+	}
 }
 
 func TestDebugInfo_MarshalJSON(t *testing.T) {
@@ -306,7 +306,7 @@ func TestJumpOptimize(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 6, len(di.Methods))
 	for _, mi := range di.Methods {
-		require.Equal(t, b[mi.Range.Start], byte(opcode.INITSLOT))
-		require.Equal(t, b[mi.Range.End], byte(opcode.RET))
+		require.Equal(t, b.Script[mi.Range.Start], byte(opcode.INITSLOT))
+		require.Equal(t, b.Script[mi.Range.End], byte(opcode.RET))
 	}
 }
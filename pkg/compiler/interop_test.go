@@ -1,6 +1,7 @@
 package compiler_test
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
@@ -12,6 +13,9 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/dao"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
+	contractint "github.com/nspcc-dev/neo-go/pkg/core/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
@@ -119,8 +123,11 @@ func spawnVM(t *testing.T, ic *interop.Context, src string) *vm.VM {
 	b, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
 	require.NoError(t, err)
 	v := core.SpawnVM(ic)
-	invokeMethod(t, testMainIdent, b, v, di)
-	v.LoadScriptWithFlags(b, callflag.All)
+	v.LoadToken = contractint.LoadToken(ic)
+	invokeMethod(t, testMainIdent, b.Script, v, di)
+	v.Context().NEF = b
+	v.LoadScriptWithFlags(b.Script, callflag.All)
+	v.Context().NEF = b
 	return v
 }
 
@@ -134,7 +141,7 @@ func TestAppCall(t *testing.T) {
 	mBar, err := di.ConvertToManifest(&compiler.Options{Name: "Bar"})
 	require.NoError(t, err)
 
-	barH := hash.Hash160(barCtr)
+	barH := hash.Hash160(barCtr.Script)
 
 	srcInner := `package foo
 	import "github.com/nspcc-dev/neo-go/pkg/interop/contract"
@@ -160,25 +167,21 @@ func TestAppCall(t *testing.T) {
 	m, err := di.ConvertToManifest(&compiler.Options{Name: "Foo"})
 	require.NoError(t, err)
 
-	ih := hash.Hash160(inner)
+	ih := hash.Hash160(inner.Script)
 	var contractGetter = func(_ dao.DAO, h util.Uint160) (*state.Contract, error) {
 		if h.Equals(ih) {
-			innerNef, err := nef.NewFile(inner)
-			require.NoError(t, err)
 			return &state.Contract{
 				ContractBase: state.ContractBase{
 					Hash:     ih,
-					NEF:      *innerNef,
+					NEF:      *inner,
 					Manifest: *m,
 				},
 			}, nil
 		} else if h.Equals(barH) {
-			barNef, err := nef.NewFile(barCtr)
-			require.NoError(t, err)
 			return &state.Contract{
 				ContractBase: state.ContractBase{
 					Hash:     barH,
-					NEF:      *barNef,
+					NEF:      *barCtr,
 					Manifest: *mBar,
 				},
 			}, nil
@@ -284,6 +287,133 @@ func TestAppCall(t *testing.T) {
 		require.NoError(t, v.Run())
 		assertResult(t, v, big.NewInt(42))
 	})
+
+	t.Run("CALLT", func(t *testing.T) {
+		src := `package foo
+		import "github.com/nspcc-dev/neo-go/pkg/interop/contract"
+		import "github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"
+		const scriptHash = ` + fmt.Sprintf("%#v", string(ih.BytesBE())) + `
+		func Main() []byte {
+			x := []byte{1, 2}
+			y := []byte{3, 4}
+			return neogointernal.CallWithToken(scriptHash, "append", int(contract.All), x, y).([]byte)
+		}`
+		nf, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(nf.Tokens))
+
+		v := core.SpawnVM(ic)
+		v.LoadToken = contractint.LoadToken(ic)
+		invokeMethod(t, testMainIdent, nf.Script, v, di)
+		v.Context().NEF = nf
+		require.NoError(t, v.Run())
+		assertResult(t, v, []byte{1, 2, 3, 4})
+	})
+}
+
+func TestCallWithToken(t *testing.T) {
+	t.Run("tokens", func(t *testing.T) {
+		cs := native.NewContracts(true, map[string][]uint32{})
+		src := `package foo
+		import "github.com/nspcc-dev/neo-go/pkg/interop"
+		import "github.com/nspcc-dev/neo-go/pkg/interop/native/gas"
+		import "github.com/nspcc-dev/neo-go/pkg/interop/native/neo"
+		import "github.com/nspcc-dev/neo-go/pkg/interop/native/policy"
+		func Main(addr interop.Hash160) int {
+			policy.SetFeePerByte(gas.BalanceOf(addr))
+			return gas.BalanceOf(addr) + neo.BalanceOf(addr) + gas.BalanceOf(addr)
+		}`
+		nf, _, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+		require.NoError(t, err)
+		require.Equal(t, []nef.MethodToken{
+			{
+				Hash:       cs.GAS.Hash,
+				Method:     "balanceOf",
+				ParamCount: 1,
+				HasReturn:  true,
+				CallFlag:   callflag.ReadStates,
+			},
+			{
+				Hash:       cs.Policy.Hash,
+				Method:     "setFeePerByte",
+				ParamCount: 1,
+				CallFlag:   callflag.States,
+			},
+			{
+				Hash:       cs.NEO.Hash,
+				Method:     "balanceOf",
+				ParamCount: 1,
+				HasReturn:  true,
+				CallFlag:   callflag.ReadStates,
+			},
+		}, nf.Tokens)
+		require.Equal(t, nf.CalculateChecksum(), nf.Checksum)
+
+		var callt int
+		ctx := vm.NewContext(nf.Script)
+		for op, param, err := ctx.Next(); err == nil && ctx.IP() < len(nf.Script); op, param, err = ctx.Next() {
+			switch op {
+			case opcode.CALLT:
+				require.True(t, int(binary.LittleEndian.Uint16(param)) < len(nf.Tokens))
+				callt++
+			case opcode.SYSCALL:
+				require.NotEqual(t, interopnames.ToID([]byte(interopnames.SystemContractCall)), binary.LittleEndian.Uint32(param))
+			}
+		}
+		require.Equal(t, 5, callt)
+	})
+	t.Run("contract.Call", func(t *testing.T) {
+		// Method called may be void, so System.Contract.Call is used even
+		// for constant hashes.
+		src := `package foo
+		import "github.com/nspcc-dev/neo-go/pkg/interop"
+		import "github.com/nspcc-dev/neo-go/pkg/interop/contract"
+		const h = "aaaaaaaaaaaaaaaaaaaa"
+		func Main() int {
+			contract.Call(interop.Hash160(h), "drop", contract.All)
+			return contract.Call(interop.Hash160(h), "get", contract.ReadOnly, 1, 2).(int)
+		}`
+		nf, _, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(nf.Tokens))
+
+		var syscall int
+		ctx := vm.NewContext(nf.Script)
+		for op, param, err := ctx.Next(); err == nil && ctx.IP() < len(nf.Script); op, param, err = ctx.Next() {
+			require.NotEqual(t, opcode.CALLT, op)
+			if op == opcode.SYSCALL {
+				require.Equal(t, interopnames.ToID([]byte(interopnames.SystemContractCall)), binary.LittleEndian.Uint32(param))
+				syscall++
+			}
+		}
+		require.Equal(t, 2, syscall)
+	})
+	t.Run("no tokens", func(t *testing.T) {
+		src := `package foo
+		func Main() int { return 42 }`
+		nf, _, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(nf.Tokens))
+	})
+
+	errCases := map[string]string{
+		"variable hash":   `h := "aaaaaaaaaaaaaaaaaaaa"; neogointernal.CallWithTokenNoRet(h, "m", 0)`,
+		"variable method": `m := "method"; neogointernal.CallWithTokenNoRet("aaaaaaaaaaaaaaaaaaaa", m, 0)`,
+		"variable flags":  `f := 1; neogointernal.CallWithTokenNoRet("aaaaaaaaaaaaaaaaaaaa", "m", f)`,
+		"invalid hash":    `neogointernal.CallWithTokenNoRet("aaa", "m", 0)`,
+		"invalid flags":   `neogointernal.CallWithTokenNoRet("aaaaaaaaaaaaaaaaaaaa", "m", 16)`,
+		"invalid method":  `neogointernal.CallWithTokenNoRet("aaaaaaaaaaaaaaaaaaaa", "_m", 0)`,
+		"slice arguments": `args := []interface{}{1}; neogointernal.CallWithTokenNoRet("aaaaaaaaaaaaaaaaaaaa", "m", 0, args...)`,
+	}
+	for name, body := range errCases {
+		t.Run(name, func(t *testing.T) {
+			src := `package foo
+			import "github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"
+			func Main() { ` + body + ` }`
+			_, err := compiler.Compile("foo.go", strings.NewReader(src))
+			require.Error(t, err)
+		})
+	}
 }
 
 func getAppCallScript(h string) string {
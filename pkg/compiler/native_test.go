@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
-	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/nnsrecords"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
@@ -24,7 +23,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/roles"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
-	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
@@ -258,9 +257,8 @@ func runNativeTestCase(t *testing.T, ctr interop.ContractMD, name, method string
 	src := fmt.Sprintf(srcTmpl, name, name, methodUpper, strings.Join(params, ","))
 
 	v, s := vmAndCompileInterop(t, src)
-	id := interopnames.ToID([]byte(interopnames.SystemContractCall))
 	result := getTestStackItem(md.MD.ReturnType)
-	s.interops[id] = testContractCall(t, ctr.Hash, md, result)
+	s.callToken = testCallToken(t, ctr.Hash, md, result)
 	require.NoError(t, v.Run())
 	if isVoid {
 		require.Equal(t, 0, v.Estack().Len())
@@ -295,21 +293,21 @@ func getTestStackItem(typ smartcontract.ParamType) stackitem.Item {
 	}
 }
 
-func testContractCall(t *testing.T, hash util.Uint160, md interop.MethodAndPrice, result stackitem.Item) func(*vm.VM) error {
-	return func(v *vm.VM) error {
-		h := v.Estack().Pop().Bytes()
-		require.Equal(t, hash.BytesBE(), h)
+func testCallToken(t *testing.T, hash util.Uint160, md interop.MethodAndPrice, result stackitem.Item) func(*vm.VM, nef.MethodToken) error {
+	return func(v *vm.VM, tok nef.MethodToken) error {
+		require.Equal(t, hash, tok.Hash)
+		require.Equal(t, md.MD.Name, tok.Method)
+		require.Equal(t, md.RequiredFlags, tok.CallFlag)
+		require.Equal(t, len(md.MD.Parameters), int(tok.ParamCount))
+		require.Equal(t, md.MD.ReturnType != smartcontract.VoidType, tok.HasReturn)
+		require.True(t, v.Estack().Len() >= int(tok.ParamCount))
 
-		method := v.Estack().Pop().String()
-		require.Equal(t, md.MD.Name, method)
-
-		fs := callflag.CallFlag(int32(v.Estack().Pop().BigInt().Int64()))
-		require.Equal(t, fs, md.RequiredFlags)
-
-		args := v.Estack().Pop().Array()
-		require.Equal(t, len(md.MD.Parameters), len(args))
-
-		v.Estack().PushVal(result)
+		for i := 0; i < int(tok.ParamCount); i++ {
+			v.Estack().Pop()
+		}
+		if tok.HasReturn {
+			v.Estack().PushVal(result)
+		}
 		return nil
 	}
 }
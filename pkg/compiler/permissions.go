@@ -0,0 +1,88 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+)
+
+// String implements fmt.Stringer interface.
+func (ic InvokedContract) String() string {
+	var h, m = "unknown contract", "unknown method"
+	if ic.Hash != nil {
+		h = "contract " + ic.Hash.StringLE()
+	}
+	if ic.Method != "" {
+		m = fmt.Sprintf("method '%s'", ic.Method)
+	}
+	return fmt.Sprintf("call to %s of %s", m, h)
+}
+
+// isAllowedBy checks whether contract call is allowed by the permission. Group
+// permissions can't be checked without the manifest of the contract being
+// called, so they're assumed to match any known contract.
+func (ic InvokedContract) isAllowedBy(p *manifest.Permission) bool {
+	switch p.Contract.Type {
+	case manifest.PermissionHash:
+		if ic.Hash == nil || !p.Contract.Hash().Equals(*ic.Hash) {
+			return false
+		}
+	case manifest.PermissionGroup:
+		if ic.Hash == nil {
+			return false
+		}
+	}
+	if p.Methods.IsWildcard() {
+		return true
+	}
+	return ic.Method != "" && p.Methods.Contains(ic.Method)
+}
+
+// isCallAllowed checks whether contract call is allowed by any of the given
+// permissions. Calls to unknown contracts can't be checked, so they're always
+// allowed.
+func isCallAllowed(ps []manifest.Permission, ic InvokedContract) bool {
+	if ic.Hash == nil {
+		return true
+	}
+	for i := range ps {
+		if ic.isAllowedBy(&ps[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// addPermission adds permission for the given contract call if it's not
+// allowed by existing ones. Calls to unknown contracts need a wildcard contract
+// permission, calls to unknown methods need a wildcard methods permission.
+func addPermission(ps []manifest.Permission, ic InvokedContract) []manifest.Permission {
+	for i := range ps {
+		if ic.isAllowedBy(&ps[i]) {
+			return ps
+		}
+	}
+	var p *manifest.Permission
+	for i := range ps {
+		if ic.Hash == nil && ps[i].Contract.Type == manifest.PermissionWildcard ||
+			ic.Hash != nil && ps[i].Contract.Type == manifest.PermissionHash && ps[i].Contract.Hash().Equals(*ic.Hash) {
+			p = &ps[i]
+			break
+		}
+	}
+	if p == nil {
+		if ic.Hash == nil {
+			ps = append(ps, *manifest.NewPermission(manifest.PermissionWildcard))
+		} else {
+			ps = append(ps, *manifest.NewPermission(manifest.PermissionHash, *ic.Hash))
+		}
+		p = &ps[len(ps)-1]
+		p.Methods.Restrict()
+	}
+	if ic.Method == "" {
+		p.Methods.Value = nil
+	} else {
+		p.Methods.Add(ic.Method)
+	}
+	return ps
+}
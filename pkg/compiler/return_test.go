@@ -3,8 +3,13 @@ package compiler_test
 import (
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -112,9 +117,12 @@ func TestNamedReturn(t *testing.T) {
 			src := fmt.Sprintf(src, ret)
 			v := vmAndCompile(t, src)
 			require.NoError(t, v.Run())
-			require.Equal(t, len(result), v.Estack().Len())
+			// Exported method results are packed into an array.
+			require.Equal(t, 1, v.Estack().Len())
+			arr := v.Estack().Pop().Array()
+			require.Equal(t, len(result), len(arr))
 			for i := range result {
-				assert.EqualValues(t, result[i], v.Estack().Pop().Value())
+				assert.EqualValues(t, result[i], arr[i].Value())
 			}
 		}
 	}
@@ -138,3 +146,86 @@ func TestTypeAssertReturn(t *testing.T) {
 	`
 	eval(t, src, big.NewInt(5))
 }
+
+func TestMultipleReturnMethod(t *testing.T) {
+	src := `package foo
+	func Main() int {
+		v, ok := Get("a")
+		if !ok {
+			return -1
+		}
+		_, ok = Get("b")
+		if ok {
+			return -2
+		}
+		return len(v)
+	}
+	func Get(k string) (value []byte, ok bool) {
+		if k == "a" {
+			return []byte{1, 2, 3}, true
+		}
+		return nil, false
+	}
+	var recovered bool
+	func Recovered(fail bool) (int, string) {
+		defer func() {
+			if r := recover(); r != nil {
+				recovered = true
+			}
+		}()
+		if fail {
+			panic("oops")
+		}
+		return 2, "ok"
+	}`
+
+	t.Run("internal call", func(t *testing.T) {
+		eval(t, src, big.NewInt(3))
+	})
+
+	b, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+	require.NoError(t, err)
+	invoke := func(t *testing.T, method string, args ...interface{}) []stackitem.Item {
+		v := vm.New()
+		invokeMethod(t, method, b.Script, v, di)
+		for i := len(args) - 1; i >= 0; i-- {
+			v.Estack().PushVal(args[i])
+		}
+		require.NoError(t, v.Run())
+		require.Equal(t, 1, v.Estack().Len())
+		return v.Estack().Pop().Array()
+	}
+	t.Run("packed", func(t *testing.T) {
+		require.Equal(t, []stackitem.Item{
+			stackitem.NewBuffer([]byte{1, 2, 3}),
+			stackitem.NewBool(true),
+		}, invoke(t, "Get", "a"))
+		require.Equal(t, []stackitem.Item{
+			stackitem.Null{},
+			stackitem.NewBool(false),
+		}, invoke(t, "Get", "b"))
+	})
+	t.Run("recover", func(t *testing.T) {
+		require.Equal(t, []stackitem.Item{
+			stackitem.Make(2),
+			stackitem.Make("ok"),
+		}, invoke(t, "Recovered", false))
+		// Default values are returned after recovery.
+		require.Equal(t, []stackitem.Item{
+			stackitem.Make(0),
+			stackitem.Make(""),
+		}, invoke(t, "Recovered", true))
+	})
+	t.Run("manifest", func(t *testing.T) {
+		m, err := compiler.CreateManifest(di, &compiler.Options{Name: "foo"})
+		require.NoError(t, err)
+		md := m.ABI.GetMethod("get", 1)
+		require.NotNil(t, md)
+		require.Equal(t, smartcontract.ArrayType, md.ReturnType)
+		for i := range di.Methods {
+			if di.Methods[i].ID == "Get" {
+				require.Equal(t, "Array", di.Methods[i].ReturnType)
+			}
+		}
+	})
+}
@@ -422,14 +422,59 @@ func TestByteSlices(t *testing.T) {
 }
 
 func TestSubsliceCompound(t *testing.T) {
-	src := `package foo
-	func Main() []int {
-		a := []int{0, 1, 2, 3}
-		b := a[1:3]
-		return b
-	}`
-	_, err := compiler.Compile("", strings.NewReader(src))
-	require.Error(t, err)
+	t.Run("Simple", func(t *testing.T) {
+		src := `package foo
+		func Main() []int {
+			a := []int{0, 1, 2, 3}
+			b := a[1:3]
+			return b
+		}`
+		eval(t, src, []stackitem.Item{
+			stackitem.NewBigInteger(big.NewInt(1)),
+			stackitem.NewBigInteger(big.NewInt(2)),
+		})
+	})
+	t.Run("OmittedIndices", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := []int{0, 1, 2, 3}
+			b := a[:2]
+			c := a[2:]
+			d := a[:]
+			return len(b)*100 + len(c)*10 + len(d) + b[1]*1000 + c[0]*10000
+		}`
+		eval(t, src, big.NewInt(2*10000+1*1000+2*100+2*10+4))
+	})
+	t.Run("Empty", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := []int{0, 1, 2, 3}
+			return len(a[2:2])
+		}`
+		eval(t, src, big.NewInt(0))
+	})
+	t.Run("OfStructField", func(t *testing.T) {
+		src := `package foo
+		type pair struct { a, b string }
+		type holder struct { ps []pair }
+		func Main() string {
+			h := holder{ps: []pair{{"a", "b"}, {"c", "d"}, {"e", "f"}}}
+			ps := h.ps[1:][1:]
+			return ps[0].b
+		}`
+		eval(t, src, []byte("f"))
+	})
+	t.Run("Array", func(t *testing.T) {
+		src := `package foo
+		func Main() []int {
+			a := [3]int{1, 2, 3}
+			return a[1:]
+		}`
+		eval(t, src, []stackitem.Item{
+			stackitem.NewBigInteger(big.NewInt(2)),
+			stackitem.NewBigInteger(big.NewInt(3)),
+		})
+	})
 }
 
 func TestRemove(t *testing.T) {
@@ -494,28 +539,92 @@ func TestMake(t *testing.T) {
 		}`
 		eval(t, src, big.NewInt(10))
 	})
-	t.Run("CapacityError", func(t *testing.T) {
+	t.Run("Capacity", func(t *testing.T) {
 		src := `package foo
 		func Main() int {
 			a := make([]int, 1, 2)
-			return a[0]
+			a = append(a, 3)
+			return len(a) + a[1]
 		}`
-		_, err := compiler.Compile("foo.go", strings.NewReader(src))
-		require.Error(t, err)
+		eval(t, src, big.NewInt(5))
+	})
+	t.Run("NonConstantCapacity", func(t *testing.T) {
+		src := `package foo
+		var called bool
+		func getCap() int {
+			called = true
+			return 10
+		}
+		func Main() int {
+			a := make([]byte, 2, getCap())
+			if !called {
+				return -1
+			}
+			return len(a)
+		}`
+		eval(t, src, big.NewInt(2))
 	})
 }
 
 func TestCopy(t *testing.T) {
-	t.Run("Invalid", func(t *testing.T) {
-		src := `package foo
-		func Main() []int {
-			src := []int{3, 2, 1}
-			dst := make([]int, 2)
-			copy(dst, src)
-			return dst
-		}`
-		_, err := compiler.Compile("foo.go", strings.NewReader(src))
-		require.Error(t, err)
+	t.Run("Compound", func(t *testing.T) {
+		newInts := func(ns ...int64) []stackitem.Item {
+			res := make([]stackitem.Item, len(ns))
+			for i := range ns {
+				res[i] = stackitem.NewBigInteger(big.NewInt(ns[i]))
+			}
+			return res
+		}
+		t.Run("Simple", func(t *testing.T) {
+			src := `package foo
+			func Main() []int {
+				src := []int{3, 2, 1}
+				dst := make([]int, 2)
+				copy(dst, src)
+				return dst
+			}`
+			eval(t, src, newInts(3, 2))
+		})
+		t.Run("BothIndices", func(t *testing.T) {
+			src := `package foo
+			func Main() []int {
+				src := []int{4, 3, 2, 1}
+				dst := make([]int, 4)
+				n := copy(dst[1:], src[1:3])
+				dst[0] = n
+				return dst
+			}`
+			eval(t, src, newInts(2, 3, 2, 0))
+		})
+		t.Run("OverlapForward", func(t *testing.T) {
+			src := `package foo
+			func Main() []int {
+				a := []int{1, 2, 3, 4}
+				copy(a, a[1:])
+				return a
+			}`
+			eval(t, src, newInts(2, 3, 4, 4))
+		})
+		t.Run("OverlapBackward", func(t *testing.T) {
+			src := `package foo
+			func Main() []int {
+				a := []int{1, 2, 3, 4}
+				copy(a[1:], a)
+				return a
+			}`
+			eval(t, src, newInts(1, 1, 2, 3))
+		})
+		t.Run("Structs", func(t *testing.T) {
+			src := `package foo
+			type pair struct { a, b int }
+			func Main() int {
+				src := []pair{{1, 2}, {3, 4}}
+				dst := make([]pair, 3)
+				n := copy(dst[1:], src)
+				return n*100 + dst[1].b*10 + dst[2].a
+			}`
+			eval(t, src, big.NewInt(223))
+		})
 	})
 	t.Run("Simple", func(t *testing.T) {
 		src := `package foo
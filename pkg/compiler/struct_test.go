@@ -407,6 +407,77 @@ var structTestCases = []testCase{
 		}`,
 		big.NewInt(42),
 	},
+	{
+		"nested selectors (pointers)",
+		`package foo
+		type S1 struct { x *S2 }
+		type S2 struct { y *S3 }
+		type S3 struct { a int }
+		func Main() int {
+			s1 := &S1{x: &S2{y: &S3{}}}
+			s3 := s1.x.y
+			s1.x.y.a = 11
+			s1.x.y.a += 31
+			return s3.a
+		}`,
+		big.NewInt(42),
+	},
+	{
+		"nested selectors (index expression)",
+		`package foo
+		type S1 struct { x []S2 }
+		type S2 struct { a int }
+		func Main() int {
+			s1 := S1{x: []S2{{1}, {2}}}
+			s1.x[1].a = 11
+			return s1.x[0].a + s1.x[1].a
+		}`,
+		big.NewInt(12),
+	},
+	{
+		"nested selectors (multiple return values)",
+		`package foo
+		type S1 struct { x S2 }
+		type S2 struct { a, b int }
+		func pair() (int, int) { return 11, 31 }
+		func Main() int {
+			var s1 S1
+			arr := []int{0, 0}
+			s1.x.a, arr[1] = pair()
+			s1.x.b, s1.x.a = pair()
+			return s1.x.a*100 + s1.x.b + arr[1]
+		}`,
+		big.NewInt(3100 + 11 + 31),
+	},
+	{
+		"embedded struct fields",
+		`package foo
+		type S3 struct { c int }
+		type S2 struct { b int; S3 }
+		type S1 struct { a int; S2 }
+		func Main() int {
+			var s1 S1
+			s1.a = 1
+			s1.b = 2
+			s1.c = 3
+			s1.S2.S3.c += 10
+			return s1.a*100 + s1.S2.b*10 + s1.S3.c
+		}`,
+		big.NewInt(133),
+	},
+	{
+		"embedded struct pointer",
+		`package foo
+		type S2 struct { b int }
+		type S1 struct { a int; *S2 }
+		func Main() int {
+			s1 := S1{a: 1, S2: &S2{}}
+			s2 := s1.S2
+			s1.b = 2
+			return s1.a*10 + s2.b
+		}`,
+		big.NewInt(12),
+	},
 	{
 		"omit field names",
 		`package foo
@@ -55,8 +55,13 @@ func isString(typ types.Type) bool {
 }
 
 func isCompoundSlice(typ types.Type) bool {
-	t, ok := typ.Underlying().(*types.Slice)
-	return ok && !isByte(t.Elem())
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		return !isByte(t.Elem())
+	case *types.Array:
+		return !isByte(t.Elem())
+	}
+	return false
 }
 
 func isByteSlice(typ types.Type) bool {
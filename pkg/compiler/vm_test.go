@@ -11,6 +11,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/stretchr/testify/assert"
@@ -69,7 +70,14 @@ func vmAndCompileInterop(t *testing.T, src string) (*vm.VM, *storagePlugin) {
 	b, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
 	require.NoError(t, err)
 
-	invokeMethod(t, testMainIdent, b, vm, di)
+	vm.LoadToken = func(id int32) error {
+		if storePlugin.callToken == nil {
+			return errors.New("unexpected CALLT")
+		}
+		return storePlugin.callToken(vm, b.Tokens[id])
+	}
+
+	invokeMethod(t, testMainIdent, b.Script, vm, di)
 	return vm, storePlugin
 }
 
@@ -96,6 +104,8 @@ type storagePlugin struct {
 	mem      map[string][]byte
 	interops map[uint32]func(v *vm.VM) error
 	events   []state.NotificationEvent
+	// callToken handles CALLT instruction for the given method token.
+	callToken func(v *vm.VM, t nef.MethodToken) error
 }
 
 func newStoragePlugin() *storagePlugin {
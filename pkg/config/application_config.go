@@ -11,7 +11,10 @@ import (
 // ApplicationConfiguration config specific to the node.
 type ApplicationConfiguration struct {
 	Address           string                  `yaml:"Address"`
+	AddressBookPath   string                  `yaml:"AddressBookPath"`
 	AttemptConnPeers  int                     `yaml:"AttemptConnPeers"`
+	BanDuration       time.Duration           `yaml:"BanDuration"`
+	CompactBlocks     bool                    `yaml:"CompactBlocks"`
 	DBConfiguration   storage.DBConfiguration `yaml:"DBConfiguration"`
 	DialTimeout       time.Duration           `yaml:"DialTimeout"`
 	LogPath           string                  `yaml:"LogPath"`
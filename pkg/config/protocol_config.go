@@ -30,16 +30,27 @@ type (
 		NativeUpdateHistories map[string][]uint32 `yaml:"NativeActivations"`
 		// P2PSigExtensions enables additional signature-related logic.
 		P2PSigExtensions bool `yaml:"P2PSigExtensions"`
+		// P2PStateExchangeExtensions enables additional P2P MPT state data
+		// exchange logic (getmptdata and mptdata messages) and state
+		// synchronization for fresh nodes.
+		P2PStateExchangeExtensions bool `yaml:"P2PStateExchangeExtensions"`
 		// ReservedAttributes allows to have reserved attributes range for experimental or private purposes.
 		ReservedAttributes bool `yaml:"ReservedAttributes"`
 		// SaveStorageBatch enables storage batch saving before every persist.
-		SaveStorageBatch bool     `yaml:"SaveStorageBatch"`
-		SecondsPerBlock  int      `yaml:"SecondsPerBlock"`
-		SeedList         []string `yaml:"SeedList"`
-		StandbyCommittee []string `yaml:"StandbyCommittee"`
+		SaveStorageBatch bool `yaml:"SaveStorageBatch"`
+		// SaveStorageChanges enables saving of contract storage changes made
+		// by every successfully executed transaction (for getapplicationlog).
+		SaveStorageChanges bool     `yaml:"SaveStorageChanges"`
+		SecondsPerBlock    int      `yaml:"SecondsPerBlock"`
+		SeedList           []string `yaml:"SeedList"`
+		StandbyCommittee   []string `yaml:"StandbyCommittee"`
 		// StateRooInHeader enables storing state root in block header.
 		StateRootInHeader bool `yaml:"StateRootInHeader"`
-		ValidatorsCount   int  `yaml:"ValidatorsCount"`
+		// StateSyncValidators is the list of state validators keys trusted
+		// to sign state roots used for P2P state synchronization (it's only
+		// needed if StateRootInHeader is disabled).
+		StateSyncValidators []string `yaml:"StateSyncValidators"`
+		ValidatorsCount     int      `yaml:"ValidatorsCount"`
 		// Whether to verify received blocks.
 		VerifyBlocks bool `yaml:"VerifyBlocks"`
 		// Whether to verify transactions in received blocks.
@@ -19,6 +19,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/contract"
 	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
@@ -140,8 +141,13 @@ type Blockchain struct {
 	// where n = ValidatorsCount.
 	defaultBlockWitness atomic.Value
 
+	// execHook stores vm.OnExecHook set for all VMs spawned by the chain.
+	execHook atomic.Value
+
 	stateRoot *stateroot.Module
 
+	stateSync *stateSync
+
 	// Notification subsystem.
 	events  chan bcEvent
 	subCh   chan interface{}
@@ -219,6 +225,9 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 	if err := bc.init(); err != nil {
 		return nil, err
 	}
+	if bc.stateSync, err = newStateSync(bc); err != nil {
+		return nil, fmt.Errorf("can't init state synchronization: %w", err)
+	}
 
 	return bc, nil
 }
@@ -331,6 +340,12 @@ func (bc *Blockchain) init() error {
 		}
 	}
 
+	// Contract storage is incomplete while P2P state synchronization is in
+	// progress, native caches are initialized when it's finished.
+	if _, err := bc.dao.Store.Get(storage.SYSStateSyncPoint.Bytes()); err == nil {
+		return nil
+	}
+
 	err = bc.contracts.NEO.InitializeCache(bc, bc.dao)
 	if err != nil {
 		return fmt.Errorf("can't init cache for NEO native contract: %w", err)
@@ -507,6 +522,18 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 		if err != nil {
 			return err
 		}
+	} else {
+		// The header is already known (maybe received long before the
+		// block), so the block must match it and the state it follows.
+		if h := bc.GetHeaderHash(int(block.Index)); !h.Equals(block.Hash()) {
+			return fmt.Errorf("%w: block %d doesn't match stored header", ErrHdrHashMismatch, block.Index)
+		}
+		if bc.config.StateRootInHeader {
+			if sr := bc.stateRoot.CurrentLocalStateRoot(); block.PrevStateRoot != sr {
+				return fmt.Errorf("%w: %s != %s",
+					ErrHdrInvalidStateRoot, block.PrevStateRoot.StringLE(), sr.StringLE())
+			}
+		}
 	}
 	if bc.config.VerifyBlocks {
 		merkle := block.ComputeMerkleRoot()
@@ -673,6 +700,15 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 		err := v.Run()
 		var faultException string
 		if !v.HasFailed() {
+			if bc.config.SaveStorageChanges {
+				changes := systemInterop.DAO.GetStorageChanges()
+				if len(changes) != 0 {
+					if err := cache.PutStorageChangeLog(tx.Hash(), changes, writeBuf); err != nil {
+						return fmt.Errorf("failed to store storage changes: %w", err)
+					}
+					writeBuf.Reset()
+				}
+			}
 			_, err := systemInterop.DAO.Persist()
 			if err != nil {
 				return fmt.Errorf("failed to persist invocation results: %w", err)
@@ -885,7 +921,7 @@ func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.C
 		return
 	}
 	arr, ok := note.Item.Value().([]stackitem.Item)
-	if !ok || len(arr) != 3 {
+	if !ok || !(len(arr) == 3 || len(arr) == 4) {
 		return
 	}
 	var from []byte
@@ -917,7 +953,45 @@ func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.C
 		}
 		amount = bigint.FromBytes(bs)
 	}
-	bc.processNEP17Transfer(d, h, b, note.ScriptHash, from, to, amount)
+	if len(arr) == 3 {
+		bc.processNEP17Transfer(d, h, b, note.ScriptHash, from, to, amount)
+		return
+	}
+	if !bc.isNEP11(d, note.ScriptHash) {
+		return
+	}
+	id, err := arr[3].TryBytes()
+	if err != nil {
+		return
+	}
+	if len(id) > state.MaxNEP11TokenIDLen {
+		bc.log.Warn("NEP-11 transfer is not tracked, token ID is too long",
+			zap.Stringer("contract", note.ScriptHash),
+			zap.Stringer("tx", h),
+			zap.Int("length", len(id)))
+		return
+	}
+	bc.processNEP11Transfer(d, h, b, note.ScriptHash, from, to, amount, id)
+}
+
+// isNEP11 checks whether the contract with the given hash declares NEP-11
+// standard support.
+func (bc *Blockchain) isNEP11(cache *dao.Cached, sc util.Uint160) bool {
+	// Native NameService is NEP-11 compliant, but its manifest doesn't list
+	// standards.
+	if sc.Equals(bc.contracts.NameService.Hash) {
+		return true
+	}
+	cs, err := bc.contracts.Management.GetContract(cache, sc)
+	if err != nil {
+		return false
+	}
+	for _, st := range cs.Manifest.SupportedStandards {
+		if st == manifest.NEP11StandardName {
+			return true
+		}
+	}
+	return false
 }
 
 func parseUint160(addr []byte) util.Uint160 {
@@ -927,19 +1001,25 @@ func parseUint160(addr []byte) util.Uint160 {
 	return util.Uint160{}
 }
 
+// getAssetID returns the ID of the token contract with the given hash.
+func (bc *Blockchain) getAssetID(cache *dao.Cached, sc util.Uint160) (int32, bool) {
+	nativeContract := bc.contracts.ByHash(sc)
+	if nativeContract != nil {
+		return nativeContract.Metadata().ID, true
+	}
+	assetContract, err := bc.contracts.Management.GetContract(cache, sc)
+	if err != nil {
+		return 0, false
+	}
+	return assetContract.ID, true
+}
+
 func (bc *Blockchain) processNEP17Transfer(cache *dao.Cached, h util.Uint256, b *block.Block, sc util.Uint160, from, to []byte, amount *big.Int) {
 	toAddr := parseUint160(to)
 	fromAddr := parseUint160(from)
-	var id int32
-	nativeContract := bc.contracts.ByHash(sc)
-	if nativeContract != nil {
-		id = nativeContract.Metadata().ID
-	} else {
-		assetContract, err := bc.contracts.Management.GetContract(cache, sc)
-		if err != nil {
-			return
-		}
-		id = assetContract.ID
+	id, ok := bc.getAssetID(cache, sc)
+	if !ok {
+		return
 	}
 	transfer := &state.NEP17Transfer{
 		Asset:     id,
@@ -996,6 +1076,95 @@ func (bc *Blockchain) processNEP17Transfer(cache *dao.Cached, h util.Uint256, b
 	}
 }
 
+func (bc *Blockchain) processNEP11Transfer(cache *dao.Cached, h util.Uint256, b *block.Block, sc util.Uint160, from, to []byte, amount *big.Int, tokenID []byte) {
+	toAddr := parseUint160(to)
+	fromAddr := parseUint160(from)
+	id, ok := bc.getAssetID(cache, sc)
+	if !ok {
+		return
+	}
+	transfer := &state.NEP11Transfer{
+		NEP17Transfer: state.NEP17Transfer{
+			Asset:     id,
+			From:      fromAddr,
+			To:        toAddr,
+			Block:     b.Index,
+			Timestamp: b.Timestamp,
+			Tx:        h,
+		},
+		ID: tokenID,
+	}
+	if !fromAddr.Equals(util.Uint160{}) {
+		balances, err := cache.GetNEP11Balances(fromAddr)
+		if err != nil {
+			return
+		}
+		balances.AddBalance(id, tokenID, new(big.Int).Neg(amount), b.Index)
+		transfer.Amount = *new(big.Int).Neg(amount)
+		balances.NewBatch, err = cache.AppendNEP11Transfer(fromAddr,
+			balances.NextTransferBatch, balances.NewBatch, transfer)
+		if err != nil {
+			return
+		}
+		if balances.NewBatch {
+			balances.NextTransferBatch++
+		}
+		if err := cache.PutNEP11Balances(fromAddr, balances); err != nil {
+			return
+		}
+	}
+	if !toAddr.Equals(util.Uint160{}) {
+		balances, err := cache.GetNEP11Balances(toAddr)
+		if err != nil {
+			return
+		}
+		balances.AddBalance(id, tokenID, amount, b.Index)
+		transfer.Amount = *amount
+		balances.NewBatch, err = cache.AppendNEP11Transfer(toAddr,
+			balances.NextTransferBatch, balances.NewBatch, transfer)
+		if err != nil {
+			return
+		}
+		if balances.NewBatch {
+			balances.NextTransferBatch++
+		}
+		if err := cache.PutNEP11Balances(toAddr, balances); err != nil {
+			return
+		}
+	}
+}
+
+// ForEachNEP11Transfer executes f for each nep11 transfer in log.
+func (bc *Blockchain) ForEachNEP11Transfer(acc util.Uint160, f func(*state.NEP11Transfer) (bool, error)) error {
+	balances, err := bc.dao.GetNEP11Balances(acc)
+	if err != nil {
+		return nil
+	}
+	for i := int(balances.NextTransferBatch); i >= 0; i-- {
+		lg, err := bc.dao.GetNEP11TransferLog(acc, uint32(i))
+		if err != nil {
+			return nil
+		}
+		cont, err := lg.ForEach(f)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			break
+		}
+	}
+	return nil
+}
+
+// GetNEP11Balances returns NEP11 balances for the acc.
+func (bc *Blockchain) GetNEP11Balances(acc util.Uint160) *state.NEP11Balances {
+	bs, err := bc.dao.GetNEP11Balances(acc)
+	if err != nil {
+		return nil
+	}
+	return bs
+}
+
 // ForEachNEP17Transfer executes f for each nep17 transfer in log.
 func (bc *Blockchain) ForEachNEP17Transfer(acc util.Uint160, f func(*state.NEP17Transfer) (bool, error)) error {
 	balances, err := bc.dao.GetNEP17Balances(acc)
@@ -1128,9 +1297,16 @@ func (bc *Blockchain) GetStorageItem(id int32, key []byte) state.StorageItem {
 	return bc.dao.GetStorageItem(id, key)
 }
 
-// GetStorageItems returns all storage items for a given contract id.
+// GetStorageItems returns all storage items for a given contract id. They're
+// taken from a consistent storage snapshot if the store supports them, so the
+// result is not affected by blocks being persisted concurrently.
 func (bc *Blockchain) GetStorageItems(id int32) (map[string]state.StorageItem, error) {
-	return bc.dao.GetStorageItems(id)
+	snap, err := storage.NewSnapshot(bc.dao.Store)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+	return dao.NewSimple(snap, bc.config.StateRootInHeader).GetStorageItems(id)
 }
 
 // GetBlock returns a Block by the given hash.
@@ -1398,12 +1574,20 @@ var (
 )
 
 func (bc *Blockchain) verifyHeader(currHeader, prevHeader *block.Header) error {
-	if bc.config.StateRootInHeader {
+	// State root can only be checked for the header following the current
+	// state, headers ahead of it are checked when their blocks are processed.
+	if bc.config.StateRootInHeader && bc.stateRoot.CurrentLocalHeight() == prevHeader.Index {
 		if sr := bc.stateRoot.CurrentLocalStateRoot(); currHeader.PrevStateRoot != sr {
 			return fmt.Errorf("%w: %s != %s",
 				ErrHdrInvalidStateRoot, currHeader.PrevStateRoot.StringLE(), sr.StringLE())
 		}
 	}
+	return bc.verifyHeaderLink(currHeader, prevHeader)
+}
+
+// verifyHeaderLink checks that currHeader properly follows prevHeader and is
+// signed by the consensus nodes specified there, state root is not checked.
+func (bc *Blockchain) verifyHeaderLink(currHeader, prevHeader *block.Header) error {
 	if prevHeader.Hash() != currHeader.PrevHash {
 		return ErrHdrHashMismatch
 	}
@@ -1689,14 +1873,96 @@ func (bc *Blockchain) GetEnrollments() ([]state.Validator, error) {
 	return bc.contracts.NEO.GetCandidates(bc.dao)
 }
 
-// GetTestVM returns a VM and a Store setup for a test run of some sort of code.
-func (bc *Blockchain) GetTestVM(t trigger.Type, tx *transaction.Transaction, b *block.Block) *vm.VM {
-	d := bc.dao.GetWrapped().(*dao.Simple)
-	systemInterop := bc.newInteropContext(t, d, b, tx)
+// GetTestVM returns a VM and a DAO setup for a test run of some sort of code.
+// The DAO is the one used by the VM, it allows to inspect storage changes made
+// by the code. These changes are isolated from the chain state unless the DAO
+// is persisted, which flushes them into the chain's in-memory cache (so it must
+// never be done for a running node).
+func (bc *Blockchain) GetTestVM(t trigger.Type, tx *transaction.Transaction, b *block.Block) (*vm.VM, dao.DAO) {
+	systemInterop := bc.newInteropContext(t, bc.dao, b, tx)
+	vm := systemInterop.SpawnVM()
+	vm.SetPriceGetter(systemInterop.GetPrice)
+	vm.LoadToken = contract.LoadToken(systemInterop)
+	return vm, systemInterop.DAO
+}
+
+// GetTestHistoricVM returns a VM set up for a test run of some script using
+// contract storage state from the MPT with the given root, so that scripts can
+// be executed as if they were run at some point in the past. Contract states
+// are taken from this storage too, but other native contract caches (like
+// committee or policy settings) always reflect the latest chain state. All
+// storage changes made by the script are discarded, but they can be inspected
+// via the DAO returned along with the VM (see GetTestVM).
+func (bc *Blockchain) GetTestHistoricVM(t trigger.Type, tx *transaction.Transaction, b *block.Block, root util.Uint256) (*vm.VM, dao.DAO, error) {
+	if bc.config.KeepOnlyLatestState {
+		return nil, nil, errors.New("historic state is not available with KeepOnlyLatestState enabled")
+	}
+	trieStore, err := mpt.NewTrieStore(root, bc.dao.Store)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := dao.NewSimple(trieStore, bc.config.StateRootInHeader)
+	systemInterop := bc.newInteropContextWithGetter(t, d, bc.contracts.Management.GetContractFromDAO, b, tx)
 	vm := systemInterop.SpawnVM()
 	vm.SetPriceGetter(systemInterop.GetPrice)
 	vm.LoadToken = contract.LoadToken(systemInterop)
-	return vm
+	return vm, systemInterop.DAO, nil
+}
+
+// GetStorageChanges returns contract storage changes made by the given
+// transaction if they're saved (see SaveStorageChanges setting).
+func (bc *Blockchain) GetStorageChanges(hash util.Uint256) ([]state.StorageChange, error) {
+	return bc.dao.GetStorageChangeLog(hash)
+}
+
+// GetReplayVM returns a VM set up to re-execute the given persisted
+// transaction in the context of its block using contract storage state from
+// the MPT at the previous height (see GetTestHistoricVM for limitations).
+// Transactions preceding it in the block are executed first, but OnPersist
+// effects (like fee burning) are not applied. Transaction script is loaded
+// into the VM with the transaction system fee as a GAS limit. All storage
+// changes are discarded.
+func (bc *Blockchain) GetReplayVM(h util.Uint256) (*vm.VM, *transaction.Transaction, error) {
+	tx, height, err := bc.dao.GetTransaction(h)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := bc.GetBlock(bc.GetHeaderHash(int(height)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get block %d: %w", height, err)
+	}
+	sr, err := bc.stateRoot.GetStateRoot(height - 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get state root for height %d: %w", height-1, err)
+	}
+	trieStore, err := mpt.NewTrieStore(sr.Root, bc.dao.Store)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := dao.NewSimple(trieStore, bc.config.StateRootInHeader)
+	for _, prev := range b.Transactions {
+		if prev.Hash().Equals(h) {
+			break
+		}
+		ic := bc.newInteropContextWithGetter(trigger.Application, d.GetWrapped(), bc.contracts.Management.GetContractFromDAO, b, prev)
+		v := ic.SpawnVM()
+		v.LoadScriptWithFlags(prev.Script, callflag.All)
+		v.SetPriceGetter(ic.GetPrice)
+		v.LoadToken = contract.LoadToken(ic)
+		v.GasLimit = prev.SystemFee
+		if v.Run() == nil {
+			if _, err := ic.DAO.Persist(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	ic := bc.newInteropContextWithGetter(trigger.Application, d, bc.contracts.Management.GetContractFromDAO, b, tx)
+	v := ic.SpawnVM()
+	v.LoadScriptWithFlags(tx.Script, callflag.All)
+	v.SetPriceGetter(ic.GetPrice)
+	v.LoadToken = contract.LoadToken(ic)
+	v.GasLimit = tx.SystemFee
+	return v, tx, nil
 }
 
 // Various witness verification errors.
@@ -1859,8 +2125,18 @@ func hashAndIndexToBytes(h util.Uint256, index uint32) []byte {
 }
 
 func (bc *Blockchain) newInteropContext(trigger trigger.Type, d dao.DAO, block *block.Block, tx *transaction.Transaction) *interop.Context {
-	ic := interop.NewContext(trigger, bc, d, bc.contracts.Management.GetContract, bc.contracts.Contracts, block, tx, bc.log)
+	return bc.newInteropContextWithGetter(trigger, d, bc.contracts.Management.GetContract, block, tx)
+}
+
+// newInteropContextWithGetter is similar to newInteropContext, but allows to
+// specify contract state getter.
+func (bc *Blockchain) newInteropContextWithGetter(trigger trigger.Type, d dao.DAO,
+	getContract func(dao.DAO, util.Uint160) (*state.Contract, error), block *block.Block, tx *transaction.Transaction) *interop.Context {
+	ic := interop.NewContext(trigger, bc, d, getContract, bc.contracts.Contracts, block, tx, bc.log)
 	ic.Functions = [][]interop.Function{systemInterops, neoInterops}
+	if f, ok := bc.execHook.Load().(vm.OnExecHook); ok {
+		ic.OnExecHook = f
+	}
 	switch {
 	case tx != nil:
 		ic.Container = tx
@@ -1870,6 +2146,14 @@ func (bc *Blockchain) newInteropContext(trigger trigger.Type, d dao.DAO, block *
 	return ic
 }
 
+// SetOnExecHook sets the hook called after every instruction executed by any
+// VM the chain creates (for block and transaction processing, verification
+// and test invocations), nil removes it. It's intended to be used for testing
+// and debugging purposes like contract code coverage collection.
+func (bc *Blockchain) SetOnExecHook(f vm.OnExecHook) {
+	bc.execHook.Store(f)
+}
+
 // P2PSigExtensionsEnabled defines whether P2P signature extensions are enabled.
 func (bc *Blockchain) P2PSigExtensionsEnabled() bool {
 	return bc.config.P2PSigExtensions
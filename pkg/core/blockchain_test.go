@@ -1555,6 +1555,7 @@ func TestRemoveUntraceable(t *testing.T) {
 	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
 		c.ProtocolConfiguration.MaxTraceableBlocks = 2
 		c.ProtocolConfiguration.RemoveUntraceableBlocks = true
+		c.ProtocolConfiguration.SaveStorageChanges = true
 	})
 
 	tx1, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
@@ -1570,6 +1571,8 @@ func TestRemoveUntraceable(t *testing.T) {
 	_, h1, err := bc.GetTransaction(tx1.Hash())
 	require.NoError(t, err)
 	require.Equal(t, tx1Height, h1)
+	_, err = bc.GetStorageChanges(tx1.Hash())
+	require.NoError(t, err)
 
 	require.NoError(t, bc.AddBlock(bc.newBlock()))
 
@@ -1577,12 +1580,55 @@ func TestRemoveUntraceable(t *testing.T) {
 	require.Error(t, err)
 	_, err = bc.GetAppExecResults(tx1.Hash(), trigger.Application)
 	require.Error(t, err)
+	_, err = bc.GetStorageChanges(tx1.Hash())
+	require.Error(t, err)
 	_, err = bc.GetBlock(b1.Hash())
 	require.Error(t, err)
 	_, err = bc.GetHeader(b1.Hash())
 	require.NoError(t, err)
 }
 
+func TestSaveStorageChanges(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ProtocolConfiguration.SaveStorageChanges = true
+	})
+	acc := util.Uint160{1, 2, 3}
+	tx := transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.NEO.Hash, 10)
+
+	changes, err := bc.GetStorageChanges(tx.Hash())
+	require.NoError(t, err)
+	require.NotEmpty(t, changes)
+	var created bool
+	for _, ch := range changes {
+		// GAS balances are also changed by PostPersist.
+		if ch.ID != bc.contracts.NEO.ID {
+			continue
+		}
+		require.Equal(t, state.StorageItem(ch.NewValue), bc.dao.GetStorageItem(ch.ID, ch.Key))
+		if ch.OldValue == nil {
+			created = true
+		}
+	}
+	require.True(t, created) // Recipient balance.
+
+	t.Run("failed transaction", func(t *testing.T) {
+		tx := newNEP17Transfer(bc.contracts.NEO.Hash, acc, util.Uint160{}, 1)
+		tx.SystemFee = 100000000
+		tx.ValidUntilBlock = bc.BlockHeight() + 1
+		addSigners(neoOwner, tx)
+		require.NoError(t, testchain.SignTx(bc, tx))
+		require.NoError(t, bc.AddBlock(bc.newBlock(tx)))
+		_, err := bc.GetStorageChanges(tx.Hash())
+		require.Error(t, err)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		bc := newTestChain(t)
+		tx := transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.NEO.Hash, 10)
+		_, err := bc.GetStorageChanges(tx.Hash())
+		require.Error(t, err)
+	})
+}
+
 func TestInvalidNotification(t *testing.T) {
 	bc := newTestChain(t)
 
@@ -0,0 +1,23 @@
+package blockchainer
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// StateSync represents state synchronization module.
+type StateSync interface {
+	AddBlock(b *block.Block) error
+	AddHeaders(...*block.Header) error
+	AddMPTNodes([][]byte) error
+	AddStateRoot(sender util.Uint160, sr *state.MPTRoot) error
+	BlockHeight() uint32
+	GetMPTNode(h util.Uint256) ([]byte, error)
+	GetUnknownMPTNodesBatch(limit int) []util.Uint256
+	IsActive() bool
+	NeedBlocks() bool
+	NeedHeaders() bool
+	NeedMPTNodes() bool
+	SelectSyncPoint() error
+}
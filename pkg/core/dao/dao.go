@@ -29,6 +29,7 @@ var (
 // DAO is a data access object.
 type DAO interface {
 	AppendAppExecResult(aer *state.AppExecResult, buf *io.BufBinWriter) error
+	AppendNEP11Transfer(acc util.Uint160, index uint32, isNew bool, tr *state.NEP11Transfer) (bool, error)
 	AppendNEP17Transfer(acc util.Uint160, index uint32, isNew bool, tr *state.NEP17Transfer) (bool, error)
 	DeleteBlock(h util.Uint256, buf *io.BufBinWriter) error
 	DeleteContractID(id int32) error
@@ -41,8 +42,12 @@ type DAO interface {
 	GetCurrentBlockHeight() (uint32, error)
 	GetCurrentHeaderHeight() (i uint32, h util.Uint256, err error)
 	GetHeaderHashes() ([]util.Uint256, error)
+	GetNEP11Balances(acc util.Uint160) (*state.NEP11Balances, error)
+	GetNEP11TransferLog(acc util.Uint160, index uint32) (*state.NEP11TransferLog, error)
 	GetNEP17Balances(acc util.Uint160) (*state.NEP17Balances, error)
 	GetNEP17TransferLog(acc util.Uint160, index uint32) (*state.NEP17TransferLog, error)
+	GetStorageChangeLog(hash util.Uint256) ([]state.StorageChange, error)
+	GetStorageChanges() []state.StorageChange
 	GetStorageItem(id int32, key []byte) state.StorageItem
 	GetStorageItems(id int32) (map[string]state.StorageItem, error)
 	GetStorageItemsWithPrefix(id int32, prefix []byte) (map[string]state.StorageItem, error)
@@ -54,8 +59,11 @@ type DAO interface {
 	PutAppExecResult(aer *state.AppExecResult, buf *io.BufBinWriter) error
 	PutContractID(id int32, hash util.Uint160) error
 	PutCurrentHeader(hashAndIndex []byte) error
+	PutNEP11Balances(acc util.Uint160, bs *state.NEP11Balances) error
+	PutNEP11TransferLog(acc util.Uint160, index uint32, lg *state.NEP11TransferLog) error
 	PutNEP17Balances(acc util.Uint160, bs *state.NEP17Balances) error
 	PutNEP17TransferLog(acc util.Uint160, index uint32, lg *state.NEP17TransferLog) error
+	PutStorageChangeLog(hash util.Uint256, changes []state.StorageChange, buf *io.BufBinWriter) error
 	PutStorageItem(id int32, key []byte, si state.StorageItem) error
 	PutVersion(v string) error
 	Seek(id int32, prefix []byte, f func(k, v []byte))
@@ -216,6 +224,77 @@ func (dao *Simple) AppendNEP17Transfer(acc util.Uint160, index uint32, isNew boo
 
 // -- end transfer log.
 
+// -- start nep11 balances.
+
+// GetNEP11Balances retrieves nep11 balances from the cache.
+func (dao *Simple) GetNEP11Balances(acc util.Uint160) (*state.NEP11Balances, error) {
+	key := storage.AppendPrefix(storage.STNEP11Balances, acc.BytesBE())
+	bs := state.NewNEP11Balances()
+	err := dao.GetAndDecode(bs, key)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// PutNEP11Balances saves nep11 balances from the cache.
+func (dao *Simple) PutNEP11Balances(acc util.Uint160, bs *state.NEP11Balances) error {
+	key := storage.AppendPrefix(storage.STNEP11Balances, acc.BytesBE())
+	return dao.Put(bs, key)
+}
+
+// -- end nep11 balances.
+
+// -- start nep11 transfer log.
+
+func getNEP11TransferLogKey(acc util.Uint160, index uint32) []byte {
+	key := make([]byte, 1+util.Uint160Size+4)
+	key[0] = byte(storage.STNEP11Transfers)
+	copy(key[1:], acc.BytesBE())
+	binary.LittleEndian.PutUint32(key[1+util.Uint160Size:], index)
+	return key
+}
+
+// GetNEP11TransferLog retrieves nep11 transfer log from the cache.
+func (dao *Simple) GetNEP11TransferLog(acc util.Uint160, index uint32) (*state.NEP11TransferLog, error) {
+	key := getNEP11TransferLogKey(acc, index)
+	value, err := dao.Store.Get(key)
+	if err != nil {
+		if err == storage.ErrKeyNotFound {
+			return new(state.NEP11TransferLog), nil
+		}
+		return nil, err
+	}
+	return &state.NEP11TransferLog{Raw: value}, nil
+}
+
+// PutNEP11TransferLog saves given nep11 transfer log in the cache.
+func (dao *Simple) PutNEP11TransferLog(acc util.Uint160, index uint32, lg *state.NEP11TransferLog) error {
+	key := getNEP11TransferLogKey(acc, index)
+	return dao.Store.Put(key, lg.Raw)
+}
+
+// AppendNEP11Transfer appends a single NEP11 transfer to a log.
+// First return value signalizes that log size has exceeded batch size.
+func (dao *Simple) AppendNEP11Transfer(acc util.Uint160, index uint32, isNew bool, tr *state.NEP11Transfer) (bool, error) {
+	var lg *state.NEP11TransferLog
+	if isNew {
+		lg = new(state.NEP11TransferLog)
+	} else {
+		var err error
+		lg, err = dao.GetNEP11TransferLog(acc, index)
+		if err != nil {
+			return false, err
+		}
+	}
+	if err := lg.Append(tr); err != nil {
+		return false, err
+	}
+	return lg.Size() >= state.NEP11TransferBatchSize, dao.PutNEP11TransferLog(acc, index, lg)
+}
+
+// -- end nep11 transfer log.
+
 // -- start notification event.
 
 // GetAppExecResults gets application execution results with the specified trigger from the
@@ -339,6 +418,28 @@ func (dao *Simple) Seek(id int32, prefix []byte, f func(k, v []byte)) {
 	})
 }
 
+// GetStorageChanges returns contract storage changes accumulated by this DAO
+// (relative to the lower one) sorted by contract ID and key.
+func (dao *Simple) GetStorageChanges() []state.StorageChange {
+	kvs := dao.Store.GetChanges([]byte{byte(storage.STStorage)})
+	changes := make([]state.StorageChange, 0, len(kvs))
+	for _, kv := range kvs {
+		changes = append(changes, state.StorageChange{
+			ID:       int32(binary.LittleEndian.Uint32(kv.Key[1:])),
+			Key:      kv.Key[5:],
+			OldValue: kv.OldValue,
+			NewValue: kv.NewValue,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ID != changes[j].ID {
+			return changes[i].ID < changes[j].ID
+		}
+		return bytes.Compare(changes[i].Key, changes[j].Key) < 0
+	})
+	return changes
+}
+
 // makeStorageItemKey returns a key used to store StorageItem in the DB.
 func makeStorageItemKey(id int32, key []byte) []byte {
 	// 1 for prefix + 4 for Uint32 + len(key) for key
@@ -351,6 +452,42 @@ func makeStorageItemKey(id int32, key []byte) []byte {
 
 // -- end storage item.
 
+// -- start storage change log.
+
+// GetStorageChangeLog returns contract storage changes saved for the given
+// transaction.
+func (dao *Simple) GetStorageChangeLog(hash util.Uint256) ([]state.StorageChange, error) {
+	key := storage.AppendPrefix(storage.STStorageChanges, hash.BytesBE())
+	data, err := dao.Store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var changes []state.StorageChange
+	r := io.NewBinReaderFromBuf(data)
+	r.ReadArray(&changes)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return changes, nil
+}
+
+// PutStorageChangeLog saves contract storage changes made by the given
+// transaction. It can reuse given buffer for the purpose of value
+// serialization.
+func (dao *Simple) PutStorageChangeLog(hash util.Uint256, changes []state.StorageChange, buf *io.BufBinWriter) error {
+	key := storage.AppendPrefix(storage.STStorageChanges, hash.BytesBE())
+	if buf == nil {
+		buf = io.NewBufBinWriter()
+	}
+	buf.WriteArray(changes)
+	if buf.Err != nil {
+		return buf.Err
+	}
+	return dao.Store.Put(key, buf.Bytes())
+}
+
+// -- end storage change log.
+
 // -- other.
 
 // GetBlock returns Block by the given hash if it exists in the store.
@@ -543,12 +680,14 @@ func (dao *Simple) DeleteBlock(h util.Uint256, w *io.BufBinWriter) error {
 	}
 	batch.Put(key, w.Bytes())
 
-	key[0] = byte(storage.DataTransaction)
 	for _, tx := range b.Transactions {
 		copy(key[1:], tx.Hash().BytesBE())
+		key[0] = byte(storage.DataTransaction)
 		batch.Delete(key)
 		key[0] = byte(storage.STNotification)
 		batch.Delete(key)
+		key[0] = byte(storage.STStorageChanges)
+		batch.Delete(key)
 	}
 
 	key[0] = byte(storage.STNotification)
@@ -11,6 +11,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/stretchr/testify/require"
@@ -83,6 +84,45 @@ func TestDeleteStorageItem(t *testing.T) {
 	require.Nil(t, gotStorageItem)
 }
 
+func TestGetStorageChanges(t *testing.T) {
+	dao := NewSimple(storage.NewMemoryStore(), false)
+	require.NoError(t, dao.PutStorageItem(1, []byte{1}, state.StorageItem{1}))
+	require.NoError(t, dao.PutStorageItem(1, []byte{2}, state.StorageItem{2}))
+	require.NoError(t, dao.PutStorageItem(-1, []byte{3}, state.StorageItem{3}))
+	require.NoError(t, dao.PutStorageItem(2, []byte{4}, state.StorageItem{4}))
+	_, err := dao.Persist()
+	require.NoError(t, err)
+
+	d := dao.GetWrapped()
+	require.NoError(t, d.PutStorageItem(2, []byte{4}, state.StorageItem{4}))  // Same value.
+	require.NoError(t, d.DeleteStorageItem(2, []byte{5}))                     // Missing item.
+	require.NoError(t, d.PutStorageItem(1, []byte{2}, state.StorageItem{}))   // Modified.
+	require.NoError(t, d.PutStorageItem(1, []byte{0}, state.StorageItem{42})) // Created.
+	require.NoError(t, d.DeleteStorageItem(-1, []byte{3}))                    // Deleted.
+	require.NoError(t, d.PutContractID(1, util.Uint160{1, 2, 3}))             // Not a storage item.
+	require.Equal(t, []state.StorageChange{
+		{ID: -1, Key: []byte{3}, OldValue: []byte{3}},
+		{ID: 1, Key: []byte{0}, NewValue: []byte{42}},
+		{ID: 1, Key: []byte{2}, OldValue: []byte{2}, NewValue: []byte{}},
+	}, d.GetStorageChanges())
+}
+
+func TestPutGetStorageChangeLog(t *testing.T) {
+	dao := NewSimple(storage.NewMemoryStore(), false)
+	hash := random.Uint256()
+	_, err := dao.GetStorageChangeLog(hash)
+	require.Error(t, err)
+
+	changes := []state.StorageChange{
+		{ID: -1, Key: []byte{3}, OldValue: []byte{3}},
+		{ID: 1, Key: []byte{0}, NewValue: []byte{}},
+	}
+	require.NoError(t, dao.PutStorageChangeLog(hash, changes, nil))
+	actual, err := dao.GetStorageChangeLog(hash)
+	require.NoError(t, err)
+	require.Equal(t, changes, actual)
+}
+
 func TestGetBlock_NotExists(t *testing.T) {
 	dao := NewSimple(storage.NewMemoryStore(), false)
 	hash := random.Uint256()
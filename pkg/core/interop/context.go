@@ -45,7 +45,9 @@ type Context struct {
 	Log           *zap.Logger
 	VM            *vm.VM
 	Functions     [][]Function
-	getContract   func(dao.DAO, util.Uint160) (*state.Contract, error)
+	// OnExecHook is set for every VM spawned by this context (if not nil).
+	OnExecHook  vm.OnExecHook
+	getContract func(dao.DAO, util.Uint160) (*state.Contract, error)
 }
 
 // NewContext returns new interop context.
@@ -269,6 +271,9 @@ func (ic *Context) SpawnVM() *vm.VM {
 	v := vm.NewWithTrigger(ic.Trigger)
 	v.GasLimit = -1
 	v.SyscallHandler = ic.SyscallHandler
+	if ic.OnExecHook != nil {
+		v.SetOnExecHook(ic.OnExecHook)
+	}
 	ic.VM = v
 	return v
 }
@@ -0,0 +1,213 @@
+package mpt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// ErrNodeNotMissing is returned on attempt to restore a node that is not
+// known to be missing from the Billet (it was either restored already or
+// doesn't belong to the trie at all).
+var ErrNodeNotMissing = errors.New("node is not missing")
+
+// Billet is a part of MPT trie with missing hash nodes that need to be restored.
+// It's used to restore the trie from the nodes received by their hashes (like
+// during state synchronization). Billet only adds nodes to the storage, each
+// node is stored with the reference counter set to the number of paths it's
+// located at if refcounting is enabled (the same way Trie does it), so the
+// result is indistinguishable from the trie built by sequential Put calls.
+type Billet struct {
+	Store *storage.MemCachedStore
+
+	root            util.Uint256
+	refcountEnabled bool
+	// missing maps hashes of the nodes to be restored to the paths (in
+	// nibbles) they're located at.
+	missing map[util.Uint256][][]byte
+}
+
+// NewBillet returns new billet for the MPT trie restoring with the given root
+// hash. Nodes already present in the storage are traversed to find the
+// missing ones, so an interrupted restore can be continued with the new
+// Billet over the same storage.
+func NewBillet(root util.Uint256, enableRefCount bool, store *storage.MemCachedStore) (*Billet, error) {
+	b := &Billet{
+		Store:           store,
+		root:            root,
+		refcountEnabled: enableRefCount,
+		missing:         make(map[util.Uint256][][]byte),
+	}
+	if root.Equals(util.Uint256{}) {
+		return b, nil
+	}
+	if err := b.traverse(root, []byte{}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Root returns the root hash of the trie being restored.
+func (b *Billet) Root() util.Uint256 {
+	return b.root
+}
+
+// IsComplete returns true when there are no missing nodes left.
+func (b *Billet) IsComplete() bool {
+	return len(b.missing) == 0
+}
+
+// GetMissing returns at most limit hashes of the nodes that are still missing.
+func (b *Billet) GetMissing(limit int) []util.Uint256 {
+	if limit > len(b.missing) {
+		limit = len(b.missing)
+	}
+	res := make([]util.Uint256, 0, limit)
+	for h := range b.missing {
+		if len(res) == limit {
+			break
+		}
+		res = append(res, h)
+	}
+	return res
+}
+
+// RestoreNode adds the node given in its serialized form (with type) to the
+// storage. The node must be one of the missing ones. f is called for every
+// key-value pair that becomes known as a result of this (that is for leaves
+// restored or found in the storage), keys are given in their original
+// (not nibbled) form.
+func (b *Billet) RestoreNode(data []byte, f func(key, value []byte)) error {
+	h := hash.DoubleSha256(data)
+	paths, ok := b.missing[h]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNodeNotMissing, h.StringBE())
+	}
+	n, err := decodeNode(data)
+	if err != nil {
+		return err
+	}
+	switch n.(type) {
+	case *BranchNode, *ExtensionNode, *LeafNode:
+	default:
+		return fmt.Errorf("unexpected node type %d", n.Type())
+	}
+	delete(b.missing, h)
+	b.putNode(h, data, uint32(len(paths)))
+	for _, path := range paths {
+		if err := b.expand(n, path, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expand processes node n located at the given path: leaf value is passed to
+// f and every child is either marked as missing or (if it's already stored)
+// expanded recursively with its reference counter incremented.
+func (b *Billet) expand(n Node, path []byte, f func(key, value []byte)) error {
+	if l, ok := n.(*LeafNode); ok {
+		if len(path)%2 != 0 {
+			return fmt.Errorf("leaf at odd path %x", path)
+		}
+		f(fromNibbles(path), l.value)
+		return nil
+	}
+	return forEachChild(n, path, func(h util.Uint256, path []byte) error {
+		if paths, ok := b.missing[h]; ok {
+			b.missing[h] = append(paths, path)
+			return nil
+		}
+		data, err := b.Store.Get(makeStorageKey(h.BytesBE()))
+		if err != nil {
+			b.missing[h] = [][]byte{path}
+			return nil
+		}
+		child, err := decodeNode(data)
+		if err != nil {
+			return err
+		}
+		if b.refcountEnabled {
+			cnt := binary.LittleEndian.Uint32(data[len(data)-4:])
+			b.putNode(h, data[:len(data)-4], cnt+1)
+		}
+		return b.expand(child, path, f)
+	})
+}
+
+// traverse walks stored nodes starting from the one with the given hash and
+// marks absent ones as missing.
+func (b *Billet) traverse(h util.Uint256, path []byte) error {
+	if paths, ok := b.missing[h]; ok {
+		b.missing[h] = append(paths, path)
+		return nil
+	}
+	data, err := b.Store.Get(makeStorageKey(h.BytesBE()))
+	if err != nil {
+		b.missing[h] = [][]byte{path}
+		return nil
+	}
+	n, err := decodeNode(data)
+	if err != nil {
+		return err
+	}
+	return forEachChild(n, path, b.traverse)
+}
+
+func (b *Billet) putNode(h util.Uint256, data []byte, refcount uint32) {
+	var val = data
+	if b.refcountEnabled {
+		val = make([]byte, len(data)+4)
+		copy(val, data)
+		binary.LittleEndian.PutUint32(val[len(data):], refcount)
+	}
+	_ = b.Store.Put(makeStorageKey(h.BytesBE()), val)
+}
+
+// forEachChild calls f for every non-empty child of the given branch or
+// extension node passing child hash and its path.
+func forEachChild(n Node, path []byte, f func(util.Uint256, []byte) error) error {
+	switch n := n.(type) {
+	case *BranchNode:
+		for i, c := range n.Children {
+			hn, ok := c.(*HashNode)
+			if !ok {
+				return errors.New("branch child is not a hash node")
+			}
+			if hn.IsEmpty() {
+				continue
+			}
+			p := path
+			if i != lastChild {
+				p = append(copySlice(path), byte(i))
+			}
+			if err := f(hn.Hash(), p); err != nil {
+				return err
+			}
+		}
+	case *ExtensionNode:
+		hn, ok := n.next.(*HashNode)
+		if !ok || hn.IsEmpty() {
+			return errors.New("extension child is not a hash node")
+		}
+		return f(hn.Hash(), append(copySlice(path), n.key...))
+	}
+	return nil
+}
+
+// decodeNode decodes node with type ignoring any trailing data (like
+// reference counter).
+func decodeNode(data []byte) (Node, error) {
+	var n NodeObject
+	r := io.NewBinReaderFromBuf(data)
+	n.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return n.Node, nil
+}
@@ -0,0 +1,115 @@
+package mpt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newBilletTestTrie(t *testing.T, enableRefCount bool) *Trie {
+	tr := NewTrie(nil, enableRefCount, newTestStore())
+	pairs := [][]byte{
+		{0x01}, {0xAB},
+		{0x01, 0x02}, {0xCD},
+		{0x01, 0x03}, {0xCD}, // the same leaf at different paths
+		{0x10, 0x20}, {0xEF},
+		{0x10, 0x21}, {0xCD},
+		{0xF0}, {0xAB},
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		require.NoError(t, tr.Put(pairs[i], pairs[i+1]))
+	}
+	tr.Flush()
+	return tr
+}
+
+// restoreTrie restores trie from src using the given number of nodes per step,
+// interrupting and recreating billet every time.
+func restoreTrie(t *testing.T, src *Trie, enableRefCount bool, dst *storage.MemCachedStore, restart bool) map[string][]byte {
+	pairs := make(map[string][]byte)
+	root := src.StateRoot()
+	b, err := NewBillet(root, enableRefCount, dst)
+	require.NoError(t, err)
+	for !b.IsComplete() {
+		missing := b.GetMissing(2)
+		require.NotEmpty(t, missing)
+		for _, h := range missing {
+			data, err := src.Store.Get(makeStorageKey(h.BytesBE()))
+			require.NoError(t, err)
+			if enableRefCount {
+				data = data[:len(data)-4]
+			}
+			require.NoError(t, b.RestoreNode(data, func(k, v []byte) {
+				pairs[string(k)] = v
+			}))
+			require.True(t, errors.Is(b.RestoreNode(data, func(k, v []byte) {}), ErrNodeNotMissing))
+		}
+		if restart {
+			b, err = NewBillet(root, enableRefCount, dst)
+			require.NoError(t, err)
+		}
+	}
+	return pairs
+}
+
+func testBilletRestore(t *testing.T, enableRefCount bool, restart bool) {
+	src := newBilletTestTrie(t, enableRefCount)
+	dst := newTestStore()
+	pairs := restoreTrie(t, src, enableRefCount, dst, restart)
+
+	expected := make(map[string][]byte)
+	require.NoError(t, src.Seek(nil, func(k, v []byte) {
+		expected[string(k)] = v
+	}))
+	if !restart {
+		require.Equal(t, expected, pairs)
+	}
+
+	// Stored nodes must be exactly the same as in the source trie including
+	// reference counters.
+	srcNodes := make(map[string][]byte)
+	src.Store.Seek([]byte{byte(storage.DataMPT)}, func(k, v []byte) {
+		srcNodes[string(k)] = append([]byte{}, v...)
+	})
+	dstNodes := make(map[string][]byte)
+	dst.Seek([]byte{byte(storage.DataMPT)}, func(k, v []byte) {
+		dstNodes[string(k)] = append([]byte{}, v...)
+	})
+	require.Equal(t, srcNodes, dstNodes)
+
+	tr := NewTrie(NewHashNode(src.StateRoot()), enableRefCount, dst)
+	for k, v := range expected {
+		tr.testHas(t, []byte(k), v)
+	}
+	if enableRefCount {
+		// Refcounts must allow to delete everything.
+		for k := range expected {
+			require.NoError(t, tr.Delete([]byte(k)))
+		}
+		tr.Flush()
+		var count int
+		dst.Seek([]byte{byte(storage.DataMPT)}, func(k, v []byte) { count++ })
+		require.Equal(t, 0, count)
+	}
+}
+
+func TestBillet_RestoreNode(t *testing.T) {
+	t.Run("no refcount", func(t *testing.T) { testBilletRestore(t, false, false) })
+	t.Run("refcount", func(t *testing.T) { testBilletRestore(t, true, false) })
+	t.Run("restart", func(t *testing.T) { testBilletRestore(t, true, true) })
+	t.Run("empty root", func(t *testing.T) {
+		b, err := NewBillet(util.Uint256{}, true, newTestStore())
+		require.NoError(t, err)
+		require.True(t, b.IsComplete())
+	})
+	t.Run("invalid node", func(t *testing.T) {
+		src := newBilletTestTrie(t, false)
+		b, err := NewBillet(src.StateRoot(), false, newTestStore())
+		require.NoError(t, err)
+		require.True(t, errors.Is(b.RestoreNode([]byte{byte(LeafT), 1, 2}, func(k, v []byte) {}), ErrNodeNotMissing))
+		require.Equal(t, []util.Uint256{src.StateRoot()}, b.GetMissing(10))
+	})
+}
@@ -0,0 +1,77 @@
+package mpt
+
+import (
+	"bytes"
+)
+
+// Seek calls f for every key-value pair from t which key starts with the given
+// prefix. Pairs are passed to f in ascending key order, both key and value are
+// copies that can be retained by the caller. Nodes loaded from the store during
+// traversal are not cached in t, so it can be used on big tries.
+func (t *Trie) Seek(prefix []byte, f func(k, v []byte)) error {
+	return t.seek(t.root, []byte{}, toNibbles(prefix), f)
+}
+
+// seek traverses subtrie rooting in curr. from is a path to curr and prefix is
+// the remaining part of the requested prefix.
+func (t *Trie) seek(curr Node, from []byte, prefix []byte, f func(k, v []byte)) error {
+	switch n := curr.(type) {
+	case *LeafNode:
+		if len(prefix) == 0 {
+			f(fromNibbles(from), copySlice(n.value))
+		}
+	case *BranchNode:
+		if len(prefix) != 0 {
+			i, rest := splitPath(prefix)
+			return t.seek(n.Children[i], appendPath(from, i), rest, f)
+		}
+		// Value stored in the branch itself goes first as it has the shortest key.
+		if err := t.seek(n.Children[lastChild], from, nil, f); err != nil {
+			return err
+		}
+		for i := byte(0); i < lastChild; i++ {
+			if err := t.seek(n.Children[i], appendPath(from, i), nil, f); err != nil {
+				return err
+			}
+		}
+	case *ExtensionNode:
+		if len(prefix) <= len(n.key) {
+			if bytes.HasPrefix(n.key, prefix) {
+				return t.seek(n.next, appendPath(from, n.key...), nil, f)
+			}
+		} else if bytes.HasPrefix(prefix, n.key) {
+			return t.seek(n.next, appendPath(from, n.key...), prefix[len(n.key):], f)
+		}
+	case *HashNode:
+		if n.IsEmpty() {
+			return nil
+		}
+		r, err := t.getFromStore(n.hash)
+		if err != nil {
+			return err
+		}
+		return t.seek(r, from, prefix, f)
+	default:
+		panic("invalid MPT node type")
+	}
+	return nil
+}
+
+// appendPath returns a new path consisting of path followed by nibbles. Paths
+// are shared between different branches of traversal, so it always allocates.
+func appendPath(path []byte, nibbles ...byte) []byte {
+	res := make([]byte, len(path)+len(nibbles))
+	copy(res, path)
+	copy(res[len(path):], nibbles)
+	return res
+}
+
+// fromNibbles performs operation opposite to toNibbles and expects path to be
+// of even length.
+func fromNibbles(path []byte) []byte {
+	result := make([]byte, len(path)/2)
+	for i := range result {
+		result[i] = path[2*i]<<4 | path[2*i+1]
+	}
+	return result
+}
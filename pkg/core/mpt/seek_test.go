@@ -0,0 +1,60 @@
+package mpt
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrie_Seek(t *testing.T) {
+	kv := map[string][]byte{
+		"\x01":         {1},
+		"\x01\x02":     {2},
+		"\x01\x02\x03": {3},
+		"\x01\x03":     {4},
+		"\x01\x23\x45": {5},
+		"\x02":         {6},
+		"\x12\x34":     {7},
+		"\xab\xcd\xef": {8},
+	}
+	tr := NewTrie(nil, false, newTestStore())
+	for k, v := range kv {
+		require.NoError(t, tr.Put([]byte(k), v))
+	}
+	tr.Flush()
+
+	check := func(t *testing.T, tr *Trie, prefix []byte) {
+		var expected []string
+		for k := range kv {
+			if bytes.HasPrefix([]byte(k), prefix) {
+				expected = append(expected, k)
+			}
+		}
+		sort.Strings(expected)
+
+		var actual []string
+		require.NoError(t, tr.Seek(prefix, func(k, v []byte) {
+			require.Equal(t, kv[string(k)], v)
+			actual = append(actual, string(k))
+		}))
+		require.Equal(t, expected, actual)
+	}
+	prefixes := [][]byte{nil, {0x01}, {0x01, 0x02}, {0x01, 0x23}, {0x02}, {0x12, 0x34, 0x56}, {0xab}, {0xff}}
+
+	t.Run("in memory", func(t *testing.T) {
+		for _, p := range prefixes {
+			check(t, tr, p)
+		}
+	})
+	t.Run("from store", func(t *testing.T) {
+		for _, p := range prefixes {
+			check(t, NewTrie(NewHashNode(tr.StateRoot()), false, tr.Store), p)
+		}
+	})
+	t.Run("missing node", func(t *testing.T) {
+		tr := NewTrie(NewHashNode(tr.StateRoot()), false, newTestStore())
+		require.Error(t, tr.Seek(nil, func(k, v []byte) {}))
+	})
+}
@@ -0,0 +1,92 @@
+package mpt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// TrieStore is a read-only storage.Store implementation that serves contract
+// storage items (storage.STStorage prefix) from the MPT with the given root,
+// so that contracts can be executed against some historical state. All other
+// keys are read from the backing store.
+type TrieStore struct {
+	trie  *Trie
+	lower storage.Store
+}
+
+// ErrReadOnly is returned for any write operation on TrieStore.
+var ErrReadOnly = errors.New("TrieStore is read-only")
+
+var _ storage.Store = (*TrieStore)(nil)
+
+// NewTrieStore returns new TrieStore for the MPT with the given root. MPT nodes
+// and all non-storage data are read from the backend. It returns an error if
+// the root node can't be found in the backend (which is the case when old
+// states are not kept).
+func NewTrieStore(root util.Uint256, backend *storage.MemCachedStore) (*TrieStore, error) {
+	tr := NewTrie(NewHashNode(root), false, storage.NewMemCachedStore(backend))
+	if !root.Equals(util.Uint256{}) {
+		if _, err := tr.getFromStore(root); err != nil {
+			return nil, fmt.Errorf("can't find state root node %s: %w", root.StringLE(), err)
+		}
+	}
+	return &TrieStore{
+		trie:  tr,
+		lower: backend,
+	}, nil
+}
+
+// Get implements storage.Store interface.
+func (s *TrieStore) Get(key []byte) ([]byte, error) {
+	if len(key) == 0 || key[0] != byte(storage.STStorage) {
+		return s.lower.Get(key)
+	}
+	res, err := s.trie.Get(key[1:])
+	if err != nil && errors.Is(err, ErrNotFound) {
+		// Mimic the real storage behaviour.
+		return nil, storage.ErrKeyNotFound
+	}
+	return res, err
+}
+
+// Seek implements storage.Store interface. Storage items are taken from the
+// MPT if prefix starts with storage.STStorage.
+func (s *TrieStore) Seek(prefix []byte, f func(k, v []byte)) {
+	if len(prefix) == 0 || prefix[0] != byte(storage.STStorage) {
+		s.lower.Seek(prefix, f)
+		return
+	}
+	// Error here means missing nodes, so there is nothing more to return.
+	_ = s.trie.Seek(prefix[1:], func(k, v []byte) {
+		f(append([]byte{byte(storage.STStorage)}, k...), v)
+	})
+}
+
+// Batch implements storage.Store interface.
+func (s *TrieStore) Batch() storage.Batch {
+	return s.lower.Batch()
+}
+
+// Put implements storage.Store interface, it always returns ErrReadOnly.
+func (s *TrieStore) Put(k, v []byte) error {
+	return ErrReadOnly
+}
+
+// Delete implements storage.Store interface, it always returns ErrReadOnly.
+func (s *TrieStore) Delete(k []byte) error {
+	return ErrReadOnly
+}
+
+// PutBatch implements storage.Store interface, it always returns ErrReadOnly.
+func (s *TrieStore) PutBatch(storage.Batch) error {
+	return ErrReadOnly
+}
+
+// Close implements storage.Store interface, it doesn't close the backing
+// store.
+func (s *TrieStore) Close() error {
+	return nil
+}
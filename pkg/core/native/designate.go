@@ -154,6 +154,13 @@ func (s *Designate) getDesignatedByRole(ic *interop.Context, args []stackitem.It
 	return pubsToArray(pubs)
 }
 
+// InvalidateCache marks cached role data as outdated, so that it's reread
+// from the storage on the next request. It's needed when contract
+// storage is replaced bypassing the contract (like on state snapshot import).
+func (s *Designate) InvalidateCache() {
+	s.rolesChangedFlag.Store(true)
+}
+
 func (s *Designate) rolesChanged() bool {
 	rc := s.rolesChangedFlag.Load()
 	return rc == nil || rc.(bool)
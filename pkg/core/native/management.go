@@ -59,6 +59,12 @@ func makeContractKey(h util.Uint160) []byte {
 	return makeUint160Key(prefixContract, h)
 }
 
+// ContractStateKey returns Management contract ID and the key the state of the
+// contract with the given hash is stored by in its storage.
+func ContractStateKey(h util.Uint160) (int32, []byte) {
+	return managementContractID, makeContractKey(h)
+}
+
 // newManagement creates new Management native contract.
 func newManagement() *Management {
 	var m = &Management{
@@ -129,7 +135,7 @@ func (m *Management) getContract(ic *interop.Context, args []stackitem.Item) sta
 	if err != nil {
 		panic(err)
 	}
-	ctr, err := m.GetContract(ic.DAO, hash)
+	ctr, err := ic.GetContract(hash)
 	if err != nil {
 		if err == storage.ErrKeyNotFound {
 			return stackitem.Null{}
@@ -149,10 +155,13 @@ func (m *Management) GetContract(d dao.DAO, hash util.Uint160) (*state.Contract,
 	} else if cs != nil {
 		return cs, nil
 	}
-	return m.getContractFromDAO(d, hash)
+	return m.GetContractFromDAO(d, hash)
 }
 
-func (m *Management) getContractFromDAO(d dao.DAO, hash util.Uint160) (*state.Contract, error) {
+// GetContractFromDAO returns contract with given hash from given DAO ignoring
+// cached contract states, so it can be used with DAOs that don't represent
+// current chain state.
+func (m *Management) GetContractFromDAO(d dao.DAO, hash util.Uint160) (*state.Contract, error) {
 	contract := new(state.Contract)
 	key := makeContractKey(hash)
 	err := getSerializableFromDAO(m.ID, d, key, contract)
@@ -502,7 +511,7 @@ func (m *Management) PostPersist(ic *interop.Context) error {
 		if cs != nil {
 			continue
 		}
-		newCs, err := m.getContractFromDAO(ic.DAO, h)
+		newCs, err := m.GetContractFromDAO(ic.DAO, h)
 		if err != nil {
 			// Contract was destroyed.
 			delete(m.contracts, h)
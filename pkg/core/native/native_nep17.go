@@ -235,6 +235,30 @@ func (c *nep17TokenNative) balanceOf(ic *interop.Context, args []stackitem.Item)
 	return stackitem.NewBigInteger(balance)
 }
 
+// ForEachBalance calls f for every account stored in the contract with its
+// balance, it stops and returns an error if some balance can't be decoded.
+func (c *nep17TokenNative) ForEachBalance(d dao.DAO, f func(acc util.Uint160, balance *big.Int)) error {
+	var err error
+	d.Seek(c.ID, []byte{prefixAccount}, func(k, v []byte) {
+		if err != nil {
+			return
+		}
+		acc, e := util.Uint160DecodeBytesBE(k)
+		if e != nil {
+			err = e
+			return
+		}
+		si := state.StorageItem(v)
+		balance, e := c.balFromBytes(&si)
+		if e != nil {
+			err = fmt.Errorf("can not deserialize balance state for %s: %w", acc.StringLE(), e)
+			return
+		}
+		f(acc, balance)
+	})
+	return err
+}
+
 func (c *nep17TokenNative) mint(ic *interop.Context, h util.Uint160, amount *big.Int, callOnPayment bool) {
 	if amount.Sign() == 0 {
 		return
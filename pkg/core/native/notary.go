@@ -177,6 +177,15 @@ func (n *Notary) PostPersist(ic *interop.Context) error {
 	return nil
 }
 
+// InvalidateCache marks cached Notary values as outdated, so that they're
+// reread from the storage. It's needed when contract storage is replaced
+// bypassing the contract (like on state synchronization).
+func (n *Notary) InvalidateCache() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.isValid = false
+}
+
 // onPayment records deposited amount as belonging to "from" address with a lock
 // till the specified chain's height.
 func (n *Notary) onPayment(ic *interop.Context, args []stackitem.Item) stackitem.Item {
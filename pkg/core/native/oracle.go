@@ -449,6 +449,13 @@ func (o *Oracle) getPrice(ic *interop.Context, _ []stackitem.Item) stackitem.Ite
 	return stackitem.NewBigInteger(big.NewInt(o.getPriceInternal(ic.DAO)))
 }
 
+// InvalidateCache marks cached Oracle request price as outdated, so that it's
+// reread from the storage. It's needed when contract storage is replaced
+// bypassing the contract (like on state synchronization).
+func (o *Oracle) InvalidateCache() {
+	o.requestPriceChanged.Store(true)
+}
+
 func (o *Oracle) getPriceInternal(d dao.DAO) int64 {
 	if !o.requestPriceChanged.Load().(bool) {
 		return o.requestPrice.Load().(int64)
@@ -181,6 +181,15 @@ func (p *Policy) PostPersist(ic *interop.Context) error {
 	return nil
 }
 
+// InvalidateCache marks cached Policy values as outdated, so that they're
+// reread from the storage. It's needed when contract storage is replaced
+// bypassing the contract (like on state synchronization).
+func (p *Policy) InvalidateCache() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.isValid = false
+}
+
 // getFeePerByte is Policy contract method and returns required transaction's fee
 // per byte.
 func (p *Policy) getFeePerByte(ic *interop.Context, _ []stackitem.Item) stackitem.Item {
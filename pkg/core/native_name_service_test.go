@@ -1,13 +1,17 @@
 package core
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/internal/random"
 	"github.com/nspcc-dev/neo-go/internal/testchain"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/dao"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/nnsrecords"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
@@ -403,3 +407,74 @@ func newAccountWithGAS(t *testing.T, bc *Blockchain) *wallet.Account {
 	transferTokenFromMultisigAccount(t, bc, acc.PrivateKey().GetScriptHash(), bc.contracts.GAS.Hash, 1000_00000000)
 	return acc
 }
+
+func TestNEP11Tracking(t *testing.T) {
+	bc := newTestChain(t)
+
+	transferFundsToCommittee(t, bc)
+	from := newAccountWithGAS(t, bc)
+	to := newAccountWithGAS(t, bc)
+	fromHash, toHash := from.Contract.ScriptHash(), to.Contract.ScriptHash()
+	nnsID := bc.contracts.NameService.ID
+
+	testNameServiceInvoke(t, bc, "addRoot", stackitem.Null{}, "com")
+	testNameServiceInvokeAux(t, bc, defaultRegisterSysfee, from, "register",
+		true, "neo.com", fromHash)
+	registered := bc.BlockHeight()
+
+	bs := bc.GetNEP11Balances(fromHash)
+	require.Equal(t, 1, len(bs.Trackers))
+	require.Equal(t, state.NEP11TokenBalance{Balance: *big.NewInt(1), LastUpdatedBlock: registered},
+		bs.Trackers[nnsID].Tokens["neo.com"])
+
+	testNameServiceInvokeAux(t, bc, defaultRegisterSysfee, from, "transfer",
+		true, toHash.BytesBE(), []byte("neo.com"))
+	transferred := bc.BlockHeight()
+
+	require.Equal(t, 0, len(bc.GetNEP11Balances(fromHash).Trackers))
+	bs = bc.GetNEP11Balances(toHash)
+	require.Equal(t, state.NEP11TokenBalance{Balance: *big.NewInt(1), LastUpdatedBlock: transferred},
+		bs.Trackers[nnsID].Tokens["neo.com"])
+
+	var transfers []state.NEP11Transfer
+	require.NoError(t, bc.ForEachNEP11Transfer(fromHash, func(tr *state.NEP11Transfer) (bool, error) {
+		transfers = append(transfers, *tr)
+		return true, nil
+	}))
+	require.Equal(t, 2, len(transfers))
+	// Newest transfers go first.
+	require.Equal(t, transferred, transfers[0].Block)
+	require.Equal(t, toHash, transfers[0].To)
+	require.Equal(t, big.NewInt(-1), &transfers[0].Amount)
+	require.Equal(t, []byte("neo.com"), transfers[0].ID)
+	require.Equal(t, registered, transfers[1].Block)
+	require.Equal(t, util.Uint160{}, transfers[1].From)
+	require.Equal(t, big.NewInt(1), &transfers[1].Amount)
+	require.Equal(t, nnsID, transfers[1].Asset)
+
+	t.Run("not tracked", func(t *testing.T) {
+		acc := random.Uint160()
+		d := dao.NewCached(bc.dao)
+		b := bc.topBlock.Load().(*block.Block)
+		transfer := func(sc util.Uint160, id []byte) {
+			bc.handleNotification(&state.NotificationEvent{
+				ScriptHash: sc,
+				Name:       "Transfer",
+				Item: stackitem.NewArray([]stackitem.Item{stackitem.Null{},
+					stackitem.NewByteArray(acc.BytesBE()), stackitem.Make(1), stackitem.NewByteArray(id)}),
+			}, d, b, random.Uint256())
+		}
+		// GAS doesn't declare NEP-11 support.
+		transfer(bc.contracts.GAS.Hash, []byte("token"))
+		// Token ID is too long.
+		transfer(bc.contracts.NameService.Hash, make([]byte, state.MaxNEP11TokenIDLen+1))
+		bs, err := d.GetNEP11Balances(acc)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(bs.Trackers))
+
+		transfer(bc.contracts.NameService.Hash, []byte("token"))
+		bs, err = d.GetNEP11Balances(acc)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(bs.Trackers))
+	})
+}
@@ -0,0 +1,408 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+const (
+	// snapshotMagic is the first 4 bytes of any state snapshot ("NGSS").
+	snapshotMagic uint32 = 0x5353474e
+	// snapshotVersion is the current state snapshot format version.
+	snapshotVersion byte = 0
+	// snapshotBatchSize is the number of storage items after which MPT nodes
+	// and items are flushed to the persistent store during snapshot import.
+	snapshotBatchSize = 10000
+)
+
+// Various errors returned on snapshot import.
+var (
+	ErrSnapshotFormat    = errors.New("invalid snapshot format")
+	ErrSnapshotNotEmpty  = errors.New("snapshot can only be imported into an empty chain")
+	ErrSnapshotStateRoot = errors.New("snapshot state doesn't match its state root")
+)
+
+// snapshotBlocksStart returns the index of the first full block included into
+// the snapshot for the given height. Transactions from the last
+// MaxValidUntilBlockIncrement blocks are needed to check new ones for
+// duplicates, older blocks are represented by headers only.
+func snapshotBlocksStart(height uint32) uint32 {
+	if height < transaction.MaxValidUntilBlockIncrement {
+		return 1
+	}
+	return height - transaction.MaxValidUntilBlockIncrement + 1
+}
+
+// ExportSnapshot writes the state snapshot for the given height to w. The
+// snapshot contains state root for this height, all block headers up to it
+// (and the next one if state roots are included into headers), the last
+// MaxValidUntilBlockIncrement blocks and all contract storage items from the
+// MPT. If state roots are not included into headers, state root for the given
+// height must be validated (signed by state validators). The height must be
+// available in the MPT, which is not guaranteed for old heights with
+// KeepOnlyLatestState setting enabled.
+func (bc *Blockchain) ExportSnapshot(w *io.BinWriter, height uint32) error {
+	if height == 0 || height > bc.BlockHeight() {
+		return fmt.Errorf("invalid snapshot height %d (current height is %d)", height, bc.BlockHeight())
+	}
+	sr, err := bc.stateRoot.GetStateRoot(height)
+	if err != nil {
+		return fmt.Errorf("can't get state root for %d: %w", height, err)
+	}
+	top := height
+	if bc.config.StateRootInHeader {
+		top++
+		if top > bc.HeaderHeight() {
+			return fmt.Errorf("no header for %d to prove state root %d", top, height)
+		}
+	} else if len(sr.Witness) == 0 {
+		return fmt.Errorf("state root for %d is not validated", height)
+	}
+
+	w.WriteU32LE(snapshotMagic)
+	w.WriteB(snapshotVersion)
+	w.WriteU32LE(uint32(bc.config.Magic))
+	sr.EncodeBinary(w)
+
+	w.WriteVarUint(uint64(top) + 1)
+	for i := uint32(0); i <= top; i++ {
+		h, err := bc.GetHeader(bc.GetHeaderHash(int(i)))
+		if err != nil {
+			return fmt.Errorf("can't get header %d: %w", i, err)
+		}
+		h.EncodeBinary(w)
+		if w.Err != nil {
+			return w.Err
+		}
+	}
+
+	start := snapshotBlocksStart(height)
+	w.WriteVarUint(uint64(height - start + 1))
+	for i := start; i <= height; i++ {
+		b, err := bc.GetBlock(bc.GetHeaderHash(int(i)))
+		if err != nil {
+			return fmt.Errorf("can't get block %d: %w", i, err)
+		}
+		b.EncodeBinary(w)
+		if w.Err != nil {
+			return w.Err
+		}
+	}
+
+	// Old MPT nodes can be removed while traversing with KeepOnlyLatestState
+	// enabled, so a frozen view of the storage is used.
+	snap, err := storage.NewSnapshot(bc.dao.Store)
+	if err != nil {
+		return fmt.Errorf("can't create storage snapshot: %w", err)
+	}
+	defer snap.Close()
+	tr := mpt.NewTrie(mpt.NewHashNode(sr.Root), false, storage.NewMemCachedStore(snap))
+	err = tr.Seek(nil, func(k, v []byte) {
+		w.WriteVarBytes(k)
+		w.WriteVarBytes(v)
+	})
+	if err != nil {
+		return fmt.Errorf("can't traverse MPT with root %s: %w", sr.Root.StringLE(), err)
+	}
+	w.WriteVarBytes(nil)
+	return w.Err
+}
+
+// ImportSnapshot reads the state snapshot created with ExportSnapshot from r
+// and stores it into the chain that must not have any blocks except the
+// genesis one. Headers and blocks are verified and the state is checked
+// against the state root which in turn is verified via the next block header
+// (if state roots are included into headers) or state validators signature.
+// In the latter case the signature is checked against the given trusted state
+// validators keys, validators designated in the snapshot state can't be used
+// for that because the snapshot can designate any keys. They're ignored if
+// state roots are included into headers (headers are verified starting from
+// the genesis block).
+// After successful import the chain must be closed and reopened with the same
+// storage to continue from the snapshot height. In case of error storage is
+// left in an inconsistent state and can't be used.
+//
+// The snapshot doesn't contain application logs and blocks older than
+// MaxValidUntilBlockIncrement (only their headers are available). NEP-11 and
+// NEP-17 transfer tracking data is not included either, NEO and GAS balances
+// are restored from their contract storage, but transfer history and balances
+// of other tokens are only tracked starting from the snapshot height.
+func (bc *Blockchain) ImportSnapshot(r *io.BinReader, stateValidators keys.PublicKeys) error {
+	bc.addLock.Lock()
+	defer bc.addLock.Unlock()
+
+	if bc.BlockHeight() != 0 || bc.HeaderHeight() != 0 {
+		return ErrSnapshotNotEmpty
+	}
+	magic := r.ReadU32LE()
+	ver := r.ReadB()
+	network := r.ReadU32LE()
+	if r.Err != nil {
+		return r.Err
+	}
+	if magic != snapshotMagic {
+		return fmt.Errorf("%w: bad magic %x", ErrSnapshotFormat, magic)
+	}
+	if ver != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrSnapshotFormat, ver)
+	}
+	if network != uint32(bc.config.Magic) {
+		return fmt.Errorf("%w: network mismatch (%d vs %d)", ErrSnapshotFormat, network, bc.config.Magic)
+	}
+	sr := new(state.MPTRoot)
+	sr.DecodeBinary(r)
+	if r.Err != nil {
+		return r.Err
+	}
+	if sr.Index == 0 {
+		return fmt.Errorf("%w: zero height", ErrSnapshotFormat)
+	}
+	if !bc.config.StateRootInHeader {
+		if len(sr.Witness) == 0 {
+			return fmt.Errorf("%w: state root is not signed", ErrSnapshotFormat)
+		}
+		if len(stateValidators) == 0 {
+			return errors.New("trusted state validators are required to check state root")
+		}
+		if err := bc.stateRoot.VerifyStateRootWitness(sr, stateValidators); err != nil {
+			return fmt.Errorf("invalid state root witness: %w", err)
+		}
+	}
+
+	top := sr.Index
+	if bc.config.StateRootInHeader {
+		top++
+	}
+	if err := bc.importSnapshotHeaders(r, top); err != nil {
+		return err
+	}
+	lastBlock, err := bc.importSnapshotBlocks(r, sr.Index)
+	if err != nil {
+		return err
+	}
+	root, err := bc.importSnapshotState(r)
+	if err != nil {
+		return err
+	}
+	if !root.Equals(sr.Root) {
+		return fmt.Errorf("%w: %s vs %s", ErrSnapshotStateRoot, root.StringLE(), sr.Root.StringLE())
+	}
+	if bc.config.StateRootInHeader {
+		next, err := bc.GetHeader(bc.GetHeaderHash(int(top)))
+		if err != nil {
+			return err
+		}
+		if !next.PrevStateRoot.Equals(root) {
+			return fmt.Errorf("%w: %s vs %s in header %d", ErrSnapshotStateRoot,
+				root.StringLE(), next.PrevStateRoot.StringLE(), top)
+		}
+	}
+	// Designate contract storage is replaced, so its cache is stale.
+	bc.contracts.Designate.InvalidateCache()
+	if err := bc.restoreNativeBalances(sr.Index); err != nil {
+		return fmt.Errorf("can't restore native token balances: %w", err)
+	}
+	if err := bc.stateRoot.AddSnapshotStateRoot(sr); err != nil {
+		return err
+	}
+	if err := bc.dao.StoreAsCurrentBlock(lastBlock, nil); err != nil {
+		return err
+	}
+	if _, err := bc.dao.Persist(); err != nil {
+		return err
+	}
+	bc.log.Info("state snapshot imported",
+		zap.Uint32("height", sr.Index),
+		zap.String("root", sr.Root.StringLE()))
+	return nil
+}
+
+// importSnapshotHeaders reads headers from 0 to top from r, verifies and
+// stores them.
+func (bc *Blockchain) importSnapshotHeaders(r *io.BinReader, top uint32) error {
+	if n := r.ReadVarUint(); r.Err == nil && n != uint64(top)+1 {
+		return fmt.Errorf("%w: expected %d headers, got %d", ErrSnapshotFormat, top+1, n)
+	}
+	prev := &block.Header{StateRootEnabled: bc.config.StateRootInHeader}
+	prev.DecodeBinary(r)
+	if r.Err != nil {
+		return r.Err
+	}
+	if !prev.Hash().Equals(bc.GetHeaderHash(0)) {
+		return fmt.Errorf("%w: genesis block mismatch", ErrSnapshotFormat)
+	}
+	batch := make([]*block.Header, 0, headerBatchCount)
+	for i := uint32(1); i <= top; i++ {
+		h := &block.Header{StateRootEnabled: bc.config.StateRootInHeader}
+		h.DecodeBinary(r)
+		if r.Err != nil {
+			return r.Err
+		}
+		if err := bc.verifyHeaderLink(h, prev); err != nil {
+			return fmt.Errorf("invalid header %d: %w", i, err)
+		}
+		batch = append(batch, h)
+		if len(batch) == headerBatchCount || i == top {
+			if err := bc.addHeaders(false, batch...); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		prev = h
+	}
+	return nil
+}
+
+// importSnapshotBlocks reads the last blocks up to the given height from r,
+// checks them against already stored headers and stores them with their
+// transactions. It returns the last block read.
+func (bc *Blockchain) importSnapshotBlocks(r *io.BinReader, height uint32) (*block.Block, error) {
+	start := snapshotBlocksStart(height)
+	if n := r.ReadVarUint(); r.Err == nil && n != uint64(height-start+1) {
+		return nil, fmt.Errorf("%w: expected %d blocks, got %d", ErrSnapshotFormat, height-start+1, n)
+	}
+	var (
+		b   *block.Block
+		buf = io.NewBufBinWriter()
+	)
+	for i := start; i <= height; i++ {
+		b = block.New(bc.config.StateRootInHeader)
+		b.DecodeBinary(r)
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		if !b.Hash().Equals(bc.GetHeaderHash(int(i))) {
+			return nil, fmt.Errorf("%w: block %d doesn't match its header", ErrSnapshotFormat, i)
+		}
+		if !b.MerkleRoot.Equals(b.ComputeMerkleRoot()) {
+			return nil, fmt.Errorf("%w: block %d has invalid MerkleRoot", ErrSnapshotFormat, i)
+		}
+		if err := bc.storeBlockData(b, buf); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// storeBlockData stores the block with its transactions (and conflict
+// records for them) without processing it.
+func (bc *Blockchain) storeBlockData(b *block.Block, buf *io.BufBinWriter) error {
+	if err := bc.dao.StoreAsBlock(b, buf); err != nil {
+		return err
+	}
+	buf.Reset()
+	for _, tx := range b.Transactions {
+		if err := bc.dao.StoreAsTransaction(tx, b.Index, buf); err != nil {
+			return err
+		}
+		buf.Reset()
+		if !bc.config.P2PSigExtensions {
+			continue
+		}
+		for _, attr := range tx.GetAttributes(transaction.ConflictsT) {
+			dummyTx := transaction.NewTrimmedTX(attr.Value.(*transaction.Conflicts).Hash)
+			dummyTx.Version = transaction.DummyVersion
+			if err := bc.dao.StoreAsTransaction(dummyTx, b.Index, buf); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	return nil
+}
+
+// importSnapshotState replaces contract storage with the one read from r
+// updating MPT accordingly. It returns the resulting state root.
+func (bc *Blockchain) importSnapshotState(r *io.BinReader) (util.Uint256, error) {
+	var (
+		res      util.Uint256
+		mptStore = storage.NewMemCachedStore(bc.dao.Store)
+		tr       = mpt.NewTrie(nil, bc.config.KeepOnlyLatestState, mptStore)
+	)
+	// Genesis storage is dropped along with the tracking data for it.
+	if err := bc.resetState(); err != nil {
+		return res, err
+	}
+
+	var flush = func() error {
+		tr.Flush()
+		tr.Collapse(10)
+		if _, err := mptStore.Persist(); err != nil {
+			return err
+		}
+		_, err := bc.dao.Persist()
+		return err
+	}
+	for count := 1; ; count++ {
+		k := r.ReadVarBytes(storage.MaxStorageKeyLen + 4)
+		if r.Err != nil {
+			return res, r.Err
+		}
+		if len(k) == 0 {
+			break
+		}
+		v := r.ReadVarBytes(storage.MaxStorageValueLen)
+		if r.Err != nil {
+			return res, r.Err
+		}
+		if err := tr.Put(k, v); err != nil {
+			return res, fmt.Errorf("can't put item into MPT: %w", err)
+		}
+		if err := bc.dao.Store.Put(append([]byte{byte(storage.STStorage)}, k...), v); err != nil {
+			return res, err
+		}
+		if count%snapshotBatchSize == 0 {
+			if err := flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return res, err
+	}
+	return tr.StateRoot(), nil
+}
+
+// restoreNativeBalances recreates NEP-17 balance tracking data for NEO and GAS
+// from their contract storage as it's used for transaction verification.
+func (bc *Blockchain) restoreNativeBalances(index uint32) error {
+	var (
+		balances = make(map[util.Uint160]*state.NEP17Balances)
+		track    = func(id int32) func(util.Uint160, *big.Int) {
+			return func(acc util.Uint160, balance *big.Int) {
+				if balance.Sign() == 0 {
+					return
+				}
+				bs, ok := balances[acc]
+				if !ok {
+					bs = state.NewNEP17Balances()
+					balances[acc] = bs
+				}
+				bs.Trackers[id] = state.NEP17Tracker{Balance: *balance, LastUpdatedBlock: index}
+			}
+		}
+	)
+	if err := bc.contracts.NEO.ForEachBalance(bc.dao, track(bc.contracts.NEO.ID)); err != nil {
+		return err
+	}
+	if err := bc.contracts.GAS.ForEachBalance(bc.dao, track(bc.contracts.GAS.ID)); err != nil {
+		return err
+	}
+	for acc, bs := range balances {
+		if err := bc.dao.PutNEP17Balances(acc, bs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func exportSnapshot(t *testing.T, bc *Blockchain, height uint32) []byte {
+	w := io.NewBufBinWriter()
+	require.NoError(t, bc.ExportSnapshot(w.BinWriter, height))
+	return w.Bytes()
+}
+
+// checkSnapshotImport imports snapshot into the new chain, reopens it and
+// checks that it can continue from the snapshot height. The reopened chain
+// is returned.
+func checkSnapshotImport(t *testing.T, bc *Blockchain, data []byte, height uint32, f func(*config.Config), pubs keys.PublicKeys) *Blockchain {
+	st := storage.NewMemoryStore()
+	bcImp := initTestChain(t, st, f)
+	require.NoError(t, bcImp.ImportSnapshot(io.NewBinReaderFromBuf(data), pubs))
+
+	bcNew := newTestChainWithCustomCfgAndStore(t, st, f)
+	require.Equal(t, height, bcNew.BlockHeight())
+	expected, err := bc.GetStateModule().GetStateRoot(height)
+	require.NoError(t, err)
+	require.Equal(t, expected.Root, bcNew.GetStateModule().CurrentLocalStateRoot())
+	for i := height; i < bc.BlockHeight(); i++ {
+		b, err := bc.GetBlock(bc.GetHeaderHash(int(i + 1)))
+		require.NoError(t, err)
+		require.NoError(t, bcNew.AddBlock(b))
+		require.Equal(t, bc.GetStateModule().CurrentLocalStateRoot(), bcNew.GetStateModule().CurrentLocalStateRoot())
+	}
+	return bcNew
+}
+
+func TestSnapshot_StateRootInHeader(t *testing.T) {
+	f := func(c *config.Config) {
+		c.ProtocolConfiguration.StateRootInHeader = true
+	}
+	bc := newTestChainWithCustomCfg(t, f)
+
+	acc := util.Uint160{1, 2, 3}
+	transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.GAS.Hash, 1_0000_0000)
+	transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.NEO.Hash, 10)
+	height := bc.BlockHeight()
+	transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.GAS.Hash, 2_0000_0000)
+
+	t.Run("invalid height", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		require.Error(t, bc.ExportSnapshot(w.BinWriter, 0))
+		require.Error(t, bc.ExportSnapshot(w.BinWriter, bc.BlockHeight()+1))
+	})
+	t.Run("no next header", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		require.Error(t, bc.ExportSnapshot(w.BinWriter, bc.BlockHeight()))
+	})
+
+	data := exportSnapshot(t, bc, height)
+	t.Run("non-empty chain", func(t *testing.T) {
+		require.True(t, errors.Is(bc.ImportSnapshot(io.NewBinReaderFromBuf(data), nil), ErrSnapshotNotEmpty))
+	})
+	t.Run("bad magic", func(t *testing.T) {
+		bcImp := initTestChain(t, nil, f)
+		raw := append([]byte{}, data...)
+		raw[0]++
+		require.True(t, errors.Is(bcImp.ImportSnapshot(io.NewBinReaderFromBuf(raw), nil), ErrSnapshotFormat))
+	})
+	t.Run("truncated", func(t *testing.T) {
+		bcImp := initTestChain(t, nil, f)
+		require.Error(t, bcImp.ImportSnapshot(io.NewBinReaderFromBuf(data[:len(data)-1]), nil))
+	})
+	t.Run("good", func(t *testing.T) {
+		bcNew := checkSnapshotImport(t, bc, data, height, f, nil)
+		require.Equal(t, bc.GetUtilityTokenBalance(acc), bcNew.GetUtilityTokenBalance(acc))
+		expected, _ := bc.GetGoverningTokenBalance(acc)
+		actual, _ := bcNew.GetGoverningTokenBalance(acc)
+		require.Equal(t, expected, actual)
+	})
+}
+
+func TestSnapshot_ValidatedStateRoot(t *testing.T) {
+	bc := newTestChain(t)
+
+	_, pubs, accs := newMajorityMultisigWithGAS(t, 2)
+	bc.setNodesByRole(t, true, noderoles.StateValidator, pubs)
+	acc := util.Uint160{1, 2, 3}
+	transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.GAS.Hash, 1_0000_0000)
+	height := bc.BlockHeight()
+	transferTokenFromMultisigAccount(t, bc, acc, bc.contracts.GAS.Hash, 2_0000_0000)
+
+	t.Run("not validated", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		require.Error(t, bc.ExportSnapshot(w.BinWriter, height))
+	})
+
+	sr, err := bc.GetStateModule().GetStateRoot(height)
+	require.NoError(t, err)
+	testSignStateRoot(t, sr, pubs, accs...)
+	require.NoError(t, bc.GetStateModule().AddStateRoot(sr))
+
+	data := exportSnapshot(t, bc, height)
+	t.Run("no trusted validators", func(t *testing.T) {
+		bcImp := initTestChain(t, nil, nil)
+		require.Error(t, bcImp.ImportSnapshot(io.NewBinReaderFromBuf(data), nil))
+	})
+	bcNew := checkSnapshotImport(t, bc, data, height, nil, pubs)
+	require.Equal(t, height, bcNew.GetStateModule().CurrentValidatedHeight())
+	require.Equal(t, bc.GetUtilityTokenBalance(acc), bcNew.GetUtilityTokenBalance(acc))
+
+	_, otherPubs, otherAccs := newMajorityMultisigWithGAS(t, 2)
+	t.Run("untrusted validators", func(t *testing.T) {
+		bcImp := initTestChain(t, nil, nil)
+		require.Error(t, bcImp.ImportSnapshot(io.NewBinReaderFromBuf(data), otherPubs))
+	})
+	t.Run("bad signature", func(t *testing.T) {
+		sr, err := bc.GetStateModule().GetStateRoot(height)
+		require.NoError(t, err)
+		testSignStateRoot(t, sr, otherPubs, otherAccs...)
+
+		w := io.NewBufBinWriter()
+		sr.EncodeBinary(w.BinWriter)
+		require.NoError(t, w.Err)
+		signed := w.Bytes()
+		// Magic, version and network precede the state root.
+		raw := append([]byte{}, data[:9]...)
+		raw = append(raw, signed...)
+		raw = append(raw, data[9+len(signed):]...)
+
+		bcImp := initTestChain(t, nil, nil)
+		require.Error(t, bcImp.ImportSnapshot(io.NewBinReaderFromBuf(raw), pubs))
+	})
+}
@@ -0,0 +1,194 @@
+package state
+
+import (
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+// NEP11TransferBatchSize is the maximum number of entries for NEP11TransferLog.
+const NEP11TransferBatchSize = 128
+
+// MaxNEP11TokenIDLen is the maximum length of NEP11 token ID that is tracked.
+const MaxNEP11TokenIDLen = 64
+
+// NEP11TokenBalance contains info about a single token owned by the account.
+type NEP11TokenBalance struct {
+	// Balance is the current balance of the account for this token (it's
+	// always 1 for non-divisible tokens).
+	Balance big.Int
+	// LastUpdatedBlock is a number of block when last `transfer` of this
+	// token to or from the account occurred.
+	LastUpdatedBlock uint32
+}
+
+// NEP11Tracker contains info about a single account in a NEP11 contract.
+type NEP11Tracker struct {
+	// Tokens maps token IDs (converted to string) to balances, only tokens
+	// with non-zero balance are stored.
+	Tokens map[string]NEP11TokenBalance
+}
+
+// NEP11TransferLog is a log of NEP11 token transfers for the specific account.
+type NEP11TransferLog struct {
+	Raw []byte
+}
+
+// NEP11Transfer represents a single NEP11 Transfer event.
+type NEP11Transfer struct {
+	NEP17Transfer
+	// ID is a NEP11 token ID.
+	ID []byte
+}
+
+// NEP11Balances is a map of the NEP11 contract IDs
+// to the corresponding structures.
+type NEP11Balances struct {
+	Trackers map[int32]NEP11Tracker
+	// NextTransferBatch stores an index of the next transfer batch.
+	NextTransferBatch uint32
+	// NewBatch is true if batch with the `NextTransferBatch` index should be created.
+	NewBatch bool
+}
+
+// NewNEP11Balances returns new NEP11Balances.
+func NewNEP11Balances() *NEP11Balances {
+	return &NEP11Balances{
+		Trackers: make(map[int32]NEP11Tracker),
+	}
+}
+
+// AddBalance adds amount to the balance of the token with the given ID of
+// the asset with the given contract ID.
+func (bs *NEP11Balances) AddBalance(asset int32, id []byte, amount *big.Int, index uint32) {
+	tr := bs.Trackers[asset]
+	if tr.Tokens == nil {
+		tr.Tokens = make(map[string]NEP11TokenBalance)
+	}
+	tb := tr.Tokens[string(id)]
+	tb.Balance = *new(big.Int).Add(&tb.Balance, amount)
+	tb.LastUpdatedBlock = index
+	if tb.Balance.Sign() == 0 {
+		delete(tr.Tokens, string(id))
+	} else {
+		tr.Tokens[string(id)] = tb
+	}
+	if len(tr.Tokens) == 0 {
+		delete(bs.Trackers, asset)
+	} else {
+		bs.Trackers[asset] = tr
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (bs *NEP11Balances) DecodeBinary(r *io.BinReader) {
+	bs.NextTransferBatch = r.ReadU32LE()
+	bs.NewBatch = r.ReadBool()
+	lenBalances := r.ReadVarUint()
+	m := make(map[int32]NEP11Tracker, lenBalances)
+	for i := 0; i < int(lenBalances); i++ {
+		key := int32(r.ReadU32LE())
+		var tr NEP11Tracker
+		tr.DecodeBinary(r)
+		m[key] = tr
+	}
+	bs.Trackers = m
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (bs *NEP11Balances) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(bs.NextTransferBatch)
+	w.WriteBool(bs.NewBatch)
+	w.WriteVarUint(uint64(len(bs.Trackers)))
+	for k, v := range bs.Trackers {
+		w.WriteU32LE(uint32(k))
+		v.EncodeBinary(w)
+	}
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (t *NEP11Tracker) EncodeBinary(w *io.BinWriter) {
+	w.WriteVarUint(uint64(len(t.Tokens)))
+	for id, tb := range t.Tokens {
+		w.WriteVarBytes([]byte(id))
+		w.WriteVarBytes(bigint.ToBytes(&tb.Balance))
+		w.WriteU32LE(tb.LastUpdatedBlock)
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *NEP11Tracker) DecodeBinary(r *io.BinReader) {
+	lenTokens := r.ReadVarUint()
+	t.Tokens = make(map[string]NEP11TokenBalance, lenTokens)
+	for i := 0; i < int(lenTokens); i++ {
+		id := r.ReadVarBytes(MaxNEP11TokenIDLen)
+		var tb NEP11TokenBalance
+		tb.Balance = *bigint.FromBytes(r.ReadVarBytes(bigint.MaxBytesLen))
+		tb.LastUpdatedBlock = r.ReadU32LE()
+		t.Tokens[string(id)] = tb
+	}
+}
+
+// Append appends single transfer to a log.
+func (lg *NEP11TransferLog) Append(tr *NEP11Transfer) error {
+	w := io.NewBufBinWriter()
+	// The first entry, set up counter.
+	if len(lg.Raw) == 0 {
+		w.WriteB(1)
+	}
+	tr.EncodeBinary(w.BinWriter)
+	if w.Err != nil {
+		return w.Err
+	}
+	if len(lg.Raw) != 0 {
+		lg.Raw[0]++
+	}
+	lg.Raw = append(lg.Raw, w.Bytes()...)
+	return nil
+}
+
+// ForEach iterates over transfer log returning on first error.
+func (lg *NEP11TransferLog) ForEach(f func(*NEP11Transfer) (bool, error)) (bool, error) {
+	if lg == nil || len(lg.Raw) == 0 {
+		return true, nil
+	}
+	transfers := make([]NEP11Transfer, lg.Size())
+	r := io.NewBinReaderFromBuf(lg.Raw[1:])
+	for i := 0; i < lg.Size(); i++ {
+		transfers[i].DecodeBinary(r)
+	}
+	if r.Err != nil {
+		return false, r.Err
+	}
+	for i := len(transfers) - 1; i >= 0; i-- {
+		cont, err := f(&transfers[i])
+		if err != nil {
+			return false, err
+		}
+		if !cont {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Size returns an amount of transfer written in log.
+func (lg *NEP11TransferLog) Size() int {
+	if len(lg.Raw) == 0 {
+		return 0
+	}
+	return int(lg.Raw[0])
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (t *NEP11Transfer) EncodeBinary(w *io.BinWriter) {
+	t.NEP17Transfer.EncodeBinary(w)
+	w.WriteVarBytes(t.ID)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *NEP11Transfer) DecodeBinary(r *io.BinReader) {
+	t.NEP17Transfer.DecodeBinary(r)
+	t.ID = r.ReadVarBytes(MaxNEP11TokenIDLen)
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNEP11TransferLog_Append(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	expected := []*NEP11Transfer{
+		randomNEP11Transfer(r),
+		randomNEP11Transfer(r),
+		randomNEP11Transfer(r),
+	}
+
+	lg := new(NEP11TransferLog)
+	for _, tr := range expected {
+		require.NoError(t, lg.Append(tr))
+	}
+
+	require.Equal(t, len(expected), lg.Size())
+
+	i := len(expected) - 1
+	cont, err := lg.ForEach(func(tr *NEP11Transfer) (bool, error) {
+		require.Equal(t, expected[i], tr)
+		i--
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, cont)
+}
+
+func TestNEP11Balances_AddBalance(t *testing.T) {
+	bs := NewNEP11Balances()
+	bs.AddBalance(1, []byte{1}, big.NewInt(1), 10)
+	bs.AddBalance(1, []byte{2}, big.NewInt(5), 11)
+	bs.AddBalance(2, []byte{1}, big.NewInt(1), 12)
+	require.Equal(t, 2, len(bs.Trackers))
+	require.Equal(t, NEP11TokenBalance{Balance: *big.NewInt(5), LastUpdatedBlock: 11}, bs.Trackers[1].Tokens["\x02"])
+
+	bs.AddBalance(1, []byte{2}, big.NewInt(-5), 13)
+	require.Equal(t, 1, len(bs.Trackers[1].Tokens))
+	bs.AddBalance(2, []byte{1}, big.NewInt(-1), 14)
+	require.Equal(t, 1, len(bs.Trackers))
+
+	testserdes.EncodeDecodeBinary(t, bs, NewNEP11Balances())
+}
+
+func TestNEP11Transfer_DecodeBinary(t *testing.T) {
+	expected := &NEP11Transfer{
+		NEP17Transfer: NEP17Transfer{
+			Asset:     123,
+			From:      util.Uint160{5, 6, 7},
+			To:        util.Uint160{8, 9, 10},
+			Amount:    *big.NewInt(1),
+			Block:     12345,
+			Timestamp: 54321,
+			Tx:        util.Uint256{8, 5, 3},
+		},
+		ID: []byte{1, 2, 3},
+	}
+
+	testserdes.EncodeDecodeBinary(t, expected, new(NEP11Transfer))
+}
+
+func randomNEP11Transfer(r *rand.Rand) *NEP11Transfer {
+	return &NEP11Transfer{
+		NEP17Transfer: *randomTransfer(r),
+		ID:            random.Bytes(10),
+	}
+}
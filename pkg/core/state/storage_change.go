@@ -0,0 +1,45 @@
+package state
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+// StorageChange describes a change of contract storage item made by some
+// invocation. OldValue is nil for the items created by it and NewValue is nil
+// for the items it deleted.
+type StorageChange struct {
+	ID       int32  `json:"id"`
+	Key      []byte `json:"key"`
+	OldValue []byte `json:"oldvalue"`
+	NewValue []byte `json:"newvalue"`
+}
+
+// EncodeBinary implements the Serializable interface.
+func (c *StorageChange) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(uint32(c.ID))
+	w.WriteVarBytes(c.Key)
+	encodeOptionalBytes(w, c.OldValue)
+	encodeOptionalBytes(w, c.NewValue)
+}
+
+// DecodeBinary implements the Serializable interface.
+func (c *StorageChange) DecodeBinary(r *io.BinReader) {
+	c.ID = int32(r.ReadU32LE())
+	c.Key = r.ReadVarBytes()
+	c.OldValue = decodeOptionalBytes(r)
+	c.NewValue = decodeOptionalBytes(r)
+}
+
+func encodeOptionalBytes(w *io.BinWriter, b []byte) {
+	w.WriteBool(b != nil)
+	if b != nil {
+		w.WriteVarBytes(b)
+	}
+}
+
+func decodeOptionalBytes(r *io.BinReader) []byte {
+	if !r.ReadBool() {
+		return nil
+	}
+	return r.ReadVarBytes()
+}
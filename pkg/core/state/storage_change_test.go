@@ -0,0 +1,22 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+)
+
+func TestEncodeDecodeStorageChange(t *testing.T) {
+	t.Run("modified", func(t *testing.T) {
+		c := &StorageChange{ID: -1, Key: []byte{1, 2}, OldValue: []byte{3}, NewValue: []byte{}}
+		testserdes.EncodeDecodeBinary(t, c, new(StorageChange))
+	})
+	t.Run("created", func(t *testing.T) {
+		c := &StorageChange{ID: 1, Key: []byte{1, 2}, NewValue: []byte{4}}
+		testserdes.EncodeDecodeBinary(t, c, new(StorageChange))
+	})
+	t.Run("deleted", func(t *testing.T) {
+		c := &StorageChange{ID: 1, Key: []byte{}, OldValue: []byte{4}}
+		testserdes.EncodeDecodeBinary(t, c, new(StorageChange))
+	})
+}
@@ -11,7 +11,10 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -25,6 +28,9 @@ type (
 		mpt     *mpt.Trie
 		bc      blockchainer.Blockchainer
 		log     *zap.Logger
+		// refCount is set if MPT nodes are reference counted (and removed
+		// when they're no longer referenced).
+		refCount bool
 
 		currentLocal    atomic.Value
 		localHeight     atomic.Uint32
@@ -56,10 +62,58 @@ func NewModule(bc blockchainer.Blockchainer, log *zap.Logger, s *storage.MemCach
 
 // GetStateProof returns proof of having key in the MPT with the specified root.
 func (s *Module) GetStateProof(root util.Uint256, key []byte) ([][]byte, error) {
-	tr := mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(s.Store))
+	tr, release, err := s.newReadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	return tr.GetProof(key)
 }
 
+// GetState returns value for the key from the MPT with the specified root.
+func (s *Module) GetState(root util.Uint256, key []byte) ([]byte, error) {
+	tr, release, err := s.newReadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return tr.Get(key)
+}
+
+// newReadTrie returns a trie with the specified root for reading, release
+// function must be called after use. MPT nodes are never changed or removed
+// if reference counting is disabled, so Store itself is consistent for any
+// root then. Otherwise nodes of old roots can be removed concurrently and a
+// storage snapshot is used, which is cheap for disk-based stores, but copies
+// all of the data for in-memory ones.
+func (s *Module) newReadTrie(root util.Uint256) (*mpt.Trie, func(), error) {
+	if !s.refCount {
+		return mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(s.Store)), func() {}, nil
+	}
+	snap, err := storage.NewSnapshot(s.Store)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() { _ = snap.Close() }
+	return mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(snap)), release, nil
+}
+
+// GetMPTNode returns serialized MPT node (without reference counter) with
+// the given hash.
+func (s *Module) GetMPTNode(h util.Uint256) ([]byte, error) {
+	data, err := s.Store.Get(append([]byte{byte(storage.DataMPT)}, h.BytesBE()...))
+	if err != nil {
+		return nil, err
+	}
+	var n mpt.NodeObject
+	r := io.NewBinReaderFromBuf(data)
+	n.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return n.Bytes(), nil
+}
+
 // GetStateRoot returns state root for a given height.
 func (s *Module) GetStateRoot(height uint32) (*state.MPTRoot, error) {
 	return s.getStateRoot(makeStateRootKey(height))
@@ -70,6 +124,11 @@ func (s *Module) CurrentLocalStateRoot() util.Uint256 {
 	return s.currentLocal.Load().(util.Uint256)
 }
 
+// CurrentLocalHeight returns height of the local state root.
+func (s *Module) CurrentLocalHeight() uint32 {
+	return s.localHeight.Load()
+}
+
 // CurrentValidatedHeight returns current state root validated height.
 func (s *Module) CurrentValidatedHeight() uint32 {
 	return s.validatedHeight.Load()
@@ -77,6 +136,7 @@ func (s *Module) CurrentValidatedHeight() uint32 {
 
 // Init initializes state root module at the given height.
 func (s *Module) Init(height uint32, enableRefCount bool) error {
+	s.refCount = enableRefCount
 	data, err := s.Store.Get([]byte{byte(storage.DataMPT), prefixValidated})
 	if err == nil {
 		s.validatedHeight.Store(binary.LittleEndian.Uint32(data))
@@ -151,6 +211,23 @@ func (s *Module) VerifyStateRoot(r *state.MPTRoot) error {
 	return s.verifyWitness(r)
 }
 
+// VerifyStateRootWitness checks state root witness against the given list of
+// state validators. Unlike VerifyStateRoot it doesn't require the previous
+// state root to be present and doesn't use validators cached by the module.
+func (s *Module) VerifyStateRootWitness(r *state.MPTRoot, pubs keys.PublicKeys) error {
+	if len(r.Witness) != 1 {
+		return errors.New("no witness")
+	}
+	if len(pubs) == 0 {
+		return errors.New("no state validators")
+	}
+	script, err := smartcontract.CreateDefaultMultiSigRedeemScript(pubs)
+	if err != nil {
+		return err
+	}
+	return s.bc.VerifyWitness(hash.Hash160(script), r, &r.Witness[0], maxVerificationGAS)
+}
+
 const maxVerificationGAS = 1_00000000
 
 // verifyWitness verifies state root witness.
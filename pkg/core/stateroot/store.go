@@ -89,3 +89,18 @@ func (s *Module) AddStateRoot(sr *state.MPTRoot) error {
 	}
 	return nil
 }
+
+// AddSnapshotStateRoot stores state root restored from the state snapshot as
+// the local one (and validated one if it has a witness). It doesn't check the
+// root, so the caller must ensure it matches the state and is properly signed.
+func (s *Module) AddSnapshotStateRoot(sr *state.MPTRoot) error {
+	if err := s.addLocalStateRoot(s.Store, sr); err != nil {
+		return err
+	}
+	if len(sr.Witness) == 0 {
+		return nil
+	}
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, sr.Index)
+	return s.Store.Put([]byte{byte(storage.DataMPT), prefixValidated}, data)
+}
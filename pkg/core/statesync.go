@@ -0,0 +1,433 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+// stateSyncStage is the stage of P2P state synchronization.
+type stateSyncStage byte
+
+const (
+	// stateSyncInactive means that state synchronization is disabled, not
+	// needed or completed already.
+	stateSyncInactive stateSyncStage = iota
+	// stateSyncHeaders means that headers are being fetched.
+	stateSyncHeaders
+	// stateSyncRoot means that headers are fetched and the module waits for
+	// a validated state root to synchronize to. It's only used if state
+	// roots are not included into headers.
+	stateSyncRoot
+	// stateSyncMPT means that MPT nodes are being fetched.
+	stateSyncMPT
+	// stateSyncBlocks means that the last MaxValidUntilBlockIncrement blocks
+	// before the sync point are being fetched.
+	stateSyncBlocks
+)
+
+// stateSync is the P2P state synchronization module. It allows a fresh node
+// to fetch the state for some recent height (sync point) from the network MPT
+// node by MPT node instead of processing all blocks. Headers are fetched
+// first, then the sync point is selected: if state roots are included into
+// headers it's the height before the last header and its root is taken from
+// the last header, otherwise it's the height of the first validated state
+// root received from the network. After that MPT nodes are fetched by their
+// hashes starting from the root and contract storage is restored from them,
+// MPT nodes are verified by their hashes, so the state is guaranteed to match
+// the root. If state roots are not included into headers, the root's sender
+// and witness are checked against state validators trusted by configuration
+// before any local state is touched. Finally, the last
+// MaxValidUntilBlockIncrement blocks up to the sync point are stored (without
+// processing) and the chain switches to this height continuing with the
+// regular block processing.
+type stateSync struct {
+	lock  sync.RWMutex
+	bc    *Blockchain
+	log   *zap.Logger
+	stage stateSyncStage
+	// root is the state root of the sync point.
+	root *state.MPTRoot
+	// blockHeight is the index of the last block stored during
+	// synchronization.
+	blockHeight uint32
+	billet      *mpt.Billet
+	// validators are state validators trusted to sign the sync point (only
+	// used if state roots are not included into headers).
+	validators keys.PublicKeys
+}
+
+var _ blockchainer.StateSync = (*stateSync)(nil)
+
+// newStateSync creates state synchronization module for the given chain. It
+// continues interrupted synchronization if there is any.
+func newStateSync(bc *Blockchain) (*stateSync, error) {
+	s := &stateSync{
+		bc:  bc,
+		log: bc.log,
+	}
+	if !bc.config.P2PStateExchangeExtensions {
+		return s, nil
+	}
+	if !bc.config.StateRootInHeader {
+		for _, str := range bc.config.StateSyncValidators {
+			pub, err := keys.NewPublicKeyFromString(str)
+			if err != nil {
+				return nil, fmt.Errorf("invalid state synchronization validator: %w", err)
+			}
+			s.validators = append(s.validators, pub)
+		}
+	}
+	data, err := bc.dao.Store.Get(storage.SYSStateSyncPoint.Bytes())
+	if err != nil {
+		if bc.BlockHeight() == 0 {
+			if !bc.config.StateRootInHeader && len(s.validators) == 0 {
+				s.log.Warn("state synchronization is disabled, no trusted state validators configured")
+				return s, nil
+			}
+			s.stage = stateSyncHeaders
+		}
+		return s, nil
+	}
+	sr := new(state.MPTRoot)
+	r := io.NewBinReaderFromBuf(data)
+	sr.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, fmt.Errorf("can't decode state sync point: %w", r.Err)
+	}
+	s.root = sr
+	s.blockHeight = snapshotBlocksStart(sr.Index) - 1
+	if data, err := bc.dao.Store.Get(storage.SYSStateSyncCurrentBlockHeight.Bytes()); err == nil {
+		s.blockHeight = binary.LittleEndian.Uint32(data)
+	}
+	s.billet, err = mpt.NewBillet(sr.Root, bc.config.KeepOnlyLatestState, storage.NewMemCachedStore(bc.dao.Store))
+	if err != nil {
+		return nil, fmt.Errorf("can't restore MPT state: %w", err)
+	}
+	s.stage = stateSyncMPT
+	s.log.Info("continuing state synchronization",
+		zap.Uint32("height", sr.Index),
+		zap.String("root", sr.Root.StringLE()))
+	if err := s.checkMPT(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// IsActive implements blockchainer.StateSync interface and returns true
+// while state synchronization is in progress.
+func (s *stateSync) IsActive() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.stage != stateSyncInactive
+}
+
+// NeedHeaders implements blockchainer.StateSync interface.
+func (s *stateSync) NeedHeaders() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.stage == stateSyncHeaders || s.stage == stateSyncRoot
+}
+
+// NeedMPTNodes implements blockchainer.StateSync interface.
+func (s *stateSync) NeedMPTNodes() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.stage == stateSyncMPT
+}
+
+// NeedBlocks implements blockchainer.StateSync interface.
+func (s *stateSync) NeedBlocks() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.stage == stateSyncBlocks
+}
+
+// BlockHeight implements blockchainer.StateSync interface and returns the
+// index of the last block stored during synchronization.
+func (s *stateSync) BlockHeight() uint32 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.blockHeight
+}
+
+// AddHeaders implements blockchainer.StateSync interface. Headers are always
+// verified, because they're the only source of trust for the state.
+func (s *stateSync) AddHeaders(hdrs ...*block.Header) error {
+	if !s.NeedHeaders() {
+		return nil
+	}
+	return s.bc.addHeaders(true, hdrs...)
+}
+
+// SelectSyncPoint implements blockchainer.StateSync interface. It's called
+// when headers are synchronized with the network. If state roots are
+// included into headers, the sync point is selected and MPT synchronization
+// starts, otherwise the module starts waiting for a validated state root.
+func (s *stateSync) SelectSyncPoint() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stage != stateSyncHeaders {
+		return nil
+	}
+	if !s.bc.config.StateRootInHeader {
+		s.stage = stateSyncRoot
+		s.log.Info("headers are synchronized, waiting for a validated state root")
+		return nil
+	}
+	top := s.bc.HeaderHeight()
+	if top < 2 {
+		s.stage = stateSyncInactive
+		s.log.Info("state synchronization is not needed", zap.Uint32("headerHeight", top))
+		return nil
+	}
+	h, err := s.bc.GetHeader(s.bc.GetHeaderHash(int(top)))
+	if err != nil {
+		return err
+	}
+	return s.start(&state.MPTRoot{Index: top - 1, Root: h.PrevStateRoot})
+}
+
+// AddStateRoot implements blockchainer.StateSync interface. State root with a
+// known header sent by one of the trusted state validators is used as a sync
+// point if the module waits for it, everything else is ignored. An error is
+// returned if such root has invalid witness, local state is not changed then.
+func (s *stateSync) AddStateRoot(sender util.Uint160, sr *state.MPTRoot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stage != stateSyncRoot || sr.Index == 0 || sr.Index > s.bc.HeaderHeight() ||
+		!s.isTrustedSender(sender) {
+		return nil
+	}
+	if err := s.bc.stateRoot.VerifyStateRootWitness(sr, s.validators); err != nil {
+		return fmt.Errorf("invalid state root %d: %w", sr.Index, err)
+	}
+	return s.start(sr)
+}
+
+// isTrustedSender checks whether the given account belongs to one of the
+// trusted state validators.
+func (s *stateSync) isTrustedSender(sender util.Uint160) bool {
+	for _, pub := range s.validators {
+		if pub.GetScriptHash().Equals(sender) {
+			return true
+		}
+	}
+	return false
+}
+
+// start drops genesis state and starts MPT synchronization to the given root.
+func (s *stateSync) start(sr *state.MPTRoot) error {
+	if err := s.bc.resetState(); err != nil {
+		return fmt.Errorf("can't drop genesis state: %w", err)
+	}
+	buf := io.NewBufBinWriter()
+	sr.EncodeBinary(buf.BinWriter)
+	if buf.Err != nil {
+		return buf.Err
+	}
+	if err := s.bc.dao.Store.Put(storage.SYSStateSyncPoint.Bytes(), buf.Bytes()); err != nil {
+		return err
+	}
+	b, err := mpt.NewBillet(sr.Root, s.bc.config.KeepOnlyLatestState, storage.NewMemCachedStore(s.bc.dao.Store))
+	if err != nil {
+		return err
+	}
+	s.root = sr
+	s.blockHeight = snapshotBlocksStart(sr.Index) - 1
+	s.billet = b
+	s.stage = stateSyncMPT
+	s.log.Info("starting MPT synchronization",
+		zap.Uint32("height", sr.Index),
+		zap.String("root", sr.Root.StringLE()))
+	return s.checkMPT()
+}
+
+// GetUnknownMPTNodesBatch implements blockchainer.StateSync interface and
+// returns at most limit hashes of MPT nodes that are still to be fetched.
+func (s *stateSync) GetUnknownMPTNodesBatch(limit int) []util.Uint256 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.stage != stateSyncMPT {
+		return nil
+	}
+	return s.billet.GetMissing(limit)
+}
+
+// AddMPTNodes implements blockchainer.StateSync interface. Nodes are given
+// in their serialized form, unexpected ones (not requested or already
+// received) are ignored.
+func (s *stateSync) AddMPTNodes(nodes [][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stage != stateSyncMPT {
+		return nil
+	}
+	for _, data := range nodes {
+		err := s.billet.RestoreNode(data, func(k, v []byte) {
+			_ = s.billet.Store.Put(append([]byte{byte(storage.STStorage)}, k...), v)
+		})
+		if err != nil && !errors.Is(err, mpt.ErrNodeNotMissing) {
+			return fmt.Errorf("invalid MPT node: %w", err)
+		}
+	}
+	if _, err := s.billet.Store.Persist(); err != nil {
+		return err
+	}
+	return s.checkMPT()
+}
+
+// checkMPT moves to the next stage if MPT is restored completely.
+func (s *stateSync) checkMPT() error {
+	if s.stage != stateSyncMPT || !s.billet.IsComplete() {
+		return nil
+	}
+	s.stage = stateSyncBlocks
+	s.log.Info("MPT is synchronized, fetching blocks", zap.Uint32("height", s.root.Index))
+	return s.checkBlocks()
+}
+
+// AddBlock implements blockchainer.StateSync interface. Only the next
+// expected block is accepted, others are ignored. Blocks are checked against
+// stored headers and stored without processing.
+func (s *stateSync) AddBlock(b *block.Block) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stage != stateSyncBlocks || b.Index != s.blockHeight+1 {
+		return nil
+	}
+	if !b.Hash().Equals(s.bc.GetHeaderHash(int(b.Index))) {
+		return fmt.Errorf("%w: block %d doesn't match stored header", ErrHdrHashMismatch, b.Index)
+	}
+	if !b.MerkleRoot.Equals(b.ComputeMerkleRoot()) {
+		return fmt.Errorf("block %d has invalid MerkleRoot", b.Index)
+	}
+	if err := s.bc.storeBlockData(b, io.NewBufBinWriter()); err != nil {
+		return err
+	}
+	s.blockHeight = b.Index
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, b.Index)
+	if err := s.bc.dao.Store.Put(storage.SYSStateSyncCurrentBlockHeight.Bytes(), data); err != nil {
+		return err
+	}
+	return s.checkBlocks()
+}
+
+// checkBlocks finishes synchronization if all blocks are stored.
+func (s *stateSync) checkBlocks() error {
+	if s.stage != stateSyncBlocks || s.blockHeight != s.root.Index {
+		return nil
+	}
+	if err := s.bc.jumpToState(s.root); err != nil {
+		return fmt.Errorf("can't switch to synchronized state: %w", err)
+	}
+	s.stage = stateSyncInactive
+	s.billet = nil
+	return nil
+}
+
+// GetMPTNode implements blockchainer.StateSync interface and returns
+// serialized MPT node with the given hash. It's used to serve MPT data to
+// other nodes.
+func (s *stateSync) GetMPTNode(h util.Uint256) ([]byte, error) {
+	return s.bc.stateRoot.GetMPTNode(h)
+}
+
+// GetStateSyncModule returns state synchronization module.
+func (bc *Blockchain) GetStateSyncModule() blockchainer.StateSync {
+	return bc.stateSync
+}
+
+// jumpToState makes the chain continue from the state synchronized with the
+// network at the given state root. Blocks up to the root's height must
+// already be stored.
+func (bc *Blockchain) jumpToState(sr *state.MPTRoot) error {
+	bc.addLock.Lock()
+	defer bc.addLock.Unlock()
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	b, err := bc.dao.GetBlock(bc.GetHeaderHash(int(sr.Index)))
+	if err != nil {
+		return err
+	}
+	if err := bc.restoreNativeBalances(sr.Index); err != nil {
+		return fmt.Errorf("can't restore native token balances: %w", err)
+	}
+	if err := bc.stateRoot.AddSnapshotStateRoot(sr); err != nil {
+		return err
+	}
+	if err := bc.dao.StoreAsCurrentBlock(b, nil); err != nil {
+		return err
+	}
+	for _, p := range []storage.KeyPrefix{storage.SYSStateSyncPoint, storage.SYSStateSyncCurrentBlockHeight} {
+		if err := bc.dao.Store.Delete(p.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := bc.stateRoot.Init(sr.Index, bc.config.KeepOnlyLatestState); err != nil {
+		return fmt.Errorf("can't init MPT at height %d: %w", sr.Index, err)
+	}
+	bc.contracts.Designate.InvalidateCache()
+	bc.contracts.Oracle.InvalidateCache()
+	bc.contracts.Policy.InvalidateCache()
+	if bc.contracts.Notary != nil {
+		bc.contracts.Notary.InvalidateCache()
+	}
+	if err := bc.contracts.NEO.InitializeCache(bc, bc.dao); err != nil {
+		return fmt.Errorf("can't init cache for NEO native contract: %w", err)
+	}
+	if err := bc.contracts.Management.InitializeCache(bc.dao); err != nil {
+		return fmt.Errorf("can't init cache for Management native contract: %w", err)
+	}
+	bc.topBlock.Store(b)
+	atomic.StoreUint32(&bc.blockHeight, sr.Index)
+	if err := bc.updateExtensibleWhitelist(sr.Index); err != nil {
+		return err
+	}
+	bc.log.Info("state is synchronized",
+		zap.Uint32("height", sr.Index),
+		zap.String("root", sr.Root.StringLE()))
+	return nil
+}
+
+// resetState removes contract storage with its MPT nodes and transfer
+// tracking data, it's used to replace genesis state with the one from some
+// other source.
+func (bc *Blockchain) resetState() error {
+	var keys [][]byte
+	collect := func(k, _ []byte) {
+		key := make([]byte, len(k))
+		copy(key, k)
+		keys = append(keys, key)
+	}
+	bc.dao.Store.Seek([]byte{byte(storage.DataMPT)}, func(k, v []byte) {
+		// Only nodes, state roots have different keys.
+		if len(k) == 1+util.Uint256Size {
+			collect(k, v)
+		}
+	})
+	for _, p := range []storage.KeyPrefix{storage.STStorage, storage.STNEP11Balances,
+		storage.STNEP11Transfers, storage.STNEP17Balances, storage.STNEP17Transfers} {
+		bc.dao.Store.Seek([]byte{byte(p)}, collect)
+	}
+	for _, k := range keys {
+		if err := bc.dao.Store.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
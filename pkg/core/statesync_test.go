@@ -0,0 +1,208 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestHeaders(t *testing.T, bc *Blockchain) []*block.Header {
+	hdrs := make([]*block.Header, 0, bc.HeaderHeight())
+	for i := uint32(1); i <= bc.HeaderHeight(); i++ {
+		h, err := bc.GetHeader(bc.GetHeaderHash(int(i)))
+		require.NoError(t, err)
+		hdrs = append(hdrs, h)
+	}
+	return hdrs
+}
+
+// syncMPTNodes feeds at most batches batches of MPT nodes from bc to the
+// module (all of them if batches is negative).
+func syncMPTNodes(t *testing.T, bc *Blockchain, module blockchainer.StateSync, batches int) {
+	for ; batches != 0 && module.NeedMPTNodes(); batches-- {
+		hashes := module.GetUnknownMPTNodesBatch(5)
+		require.NotEmpty(t, hashes)
+		nodes := make([][]byte, 0, len(hashes))
+		for _, h := range hashes {
+			node, err := bc.GetStateSyncModule().GetMPTNode(h)
+			require.NoError(t, err)
+			nodes = append(nodes, node)
+		}
+		require.NoError(t, module.AddMPTNodes(nodes))
+	}
+}
+
+func syncBlocks(t *testing.T, bc *Blockchain, module blockchainer.StateSync) {
+	for module.NeedBlocks() {
+		b, err := bc.GetBlock(bc.GetHeaderHash(int(module.BlockHeight() + 1)))
+		require.NoError(t, err)
+		require.NoError(t, module.AddBlock(b))
+	}
+}
+
+// checkStateSynced checks that bcBolt is synchronized to the given height and
+// that it can continue processing blocks from bcSpout.
+func checkStateSynced(t *testing.T, bcSpout, bcBolt *Blockchain, height uint32) {
+	require.False(t, bcBolt.GetStateSyncModule().IsActive())
+	require.Equal(t, height, bcBolt.BlockHeight())
+	expected, err := bcSpout.GetStateModule().GetStateRoot(height)
+	require.NoError(t, err)
+	require.Equal(t, expected.Root, bcBolt.GetStateModule().CurrentLocalStateRoot())
+	for i := height; i < bcSpout.BlockHeight(); i++ {
+		b, err := bcSpout.GetBlock(bcSpout.GetHeaderHash(int(i + 1)))
+		require.NoError(t, err)
+		require.NoError(t, bcBolt.AddBlock(b))
+		require.Equal(t, bcSpout.GetStateModule().CurrentLocalStateRoot(), bcBolt.GetStateModule().CurrentLocalStateRoot())
+	}
+}
+
+func TestStateSync_Disabled(t *testing.T) {
+	bc := newTestChain(t)
+	module := bc.GetStateSyncModule()
+	require.False(t, module.IsActive())
+	require.False(t, module.NeedHeaders())
+	require.NoError(t, module.AddHeaders(&block.Header{Index: 1}))
+	require.NoError(t, module.AddMPTNodes([][]byte{{1, 2, 3}}))
+	require.NoError(t, module.AddBlock(bc.newBlock()))
+	require.Equal(t, uint32(0), bc.HeaderHeight())
+}
+
+func TestStateSync_StateRootInHeader(t *testing.T) {
+	f := func(c *config.Config) {
+		c.ProtocolConfiguration.StateRootInHeader = true
+	}
+	fSync := func(c *config.Config) {
+		f(c)
+		c.ProtocolConfiguration.P2PStateExchangeExtensions = true
+	}
+	bcSpout := newTestChainWithCustomCfg(t, f)
+	acc := util.Uint160{1, 2, 3}
+	transferTokenFromMultisigAccount(t, bcSpout, acc, bcSpout.contracts.GAS.Hash, 1_0000_0000)
+	transferTokenFromMultisigAccount(t, bcSpout, acc, bcSpout.contracts.NEO.Hash, 10)
+	transferTokenFromMultisigAccount(t, bcSpout, acc, bcSpout.contracts.GAS.Hash, 2_0000_0000)
+	height := bcSpout.BlockHeight() - 1
+
+	st := storage.NewMemoryStore()
+	bcBolt := initTestChain(t, st, fSync)
+	module := bcBolt.GetStateSyncModule()
+	require.True(t, module.IsActive())
+	require.True(t, module.NeedHeaders())
+	require.False(t, module.NeedMPTNodes())
+
+	t.Run("block before MPT", func(t *testing.T) {
+		require.NoError(t, module.AddBlock(bcSpout.newBlock()))
+		require.Equal(t, uint32(0), module.BlockHeight())
+	})
+
+	require.NoError(t, module.AddHeaders(getTestHeaders(t, bcSpout)...))
+	require.Equal(t, bcSpout.HeaderHeight(), bcBolt.HeaderHeight())
+	require.NoError(t, module.SelectSyncPoint())
+	require.True(t, module.NeedMPTNodes())
+
+	t.Run("unknown node is ignored", func(t *testing.T) {
+		require.NoError(t, module.AddMPTNodes([][]byte{{byte(2), 0}}))
+	})
+
+	// Interrupt synchronization and continue with the reopened chain.
+	syncMPTNodes(t, bcSpout, module, 1)
+	require.True(t, module.NeedMPTNodes())
+	require.NoError(t, bcBolt.persist())
+	bcBolt = initTestChain(t, st, fSync)
+	module = bcBolt.GetStateSyncModule()
+	require.True(t, module.NeedMPTNodes())
+	syncMPTNodes(t, bcSpout, module, -1)
+	require.True(t, module.NeedBlocks())
+
+	t.Run("unexpected block", func(t *testing.T) {
+		b, err := bcSpout.GetBlock(bcSpout.GetHeaderHash(int(module.BlockHeight() + 2)))
+		require.NoError(t, err)
+		require.NoError(t, module.AddBlock(b))
+		require.True(t, module.NeedBlocks())
+	})
+	syncBlocks(t, bcSpout, module)
+	require.False(t, module.IsActive())
+
+	// Synchronized state is kept after restart.
+	require.NoError(t, bcBolt.persist())
+	bcBolt = newTestChainWithCustomCfgAndStore(t, st, fSync)
+	checkStateSynced(t, bcSpout, bcBolt, height)
+	require.Equal(t, bcSpout.GetUtilityTokenBalance(acc), bcBolt.GetUtilityTokenBalance(acc))
+	expected, _ := bcSpout.GetGoverningTokenBalance(acc)
+	actual, _ := bcBolt.GetGoverningTokenBalance(acc)
+	require.Equal(t, expected, actual)
+}
+
+func TestStateSync_ValidatedStateRoot(t *testing.T) {
+	bcSpout := newTestChain(t)
+	_, pubs, accs := newMajorityMultisigWithGAS(t, 2)
+	fSync := func(c *config.Config) {
+		c.ProtocolConfiguration.P2PStateExchangeExtensions = true
+		for _, pub := range pubs {
+			c.ProtocolConfiguration.StateSyncValidators = append(c.ProtocolConfiguration.StateSyncValidators, hex.EncodeToString(pub.Bytes()))
+		}
+	}
+	bcSpout.setNodesByRole(t, true, noderoles.StateValidator, pubs)
+	acc := util.Uint160{1, 2, 3}
+	transferTokenFromMultisigAccount(t, bcSpout, acc, bcSpout.contracts.GAS.Hash, 1_0000_0000)
+	height := bcSpout.BlockHeight()
+	transferTokenFromMultisigAccount(t, bcSpout, acc, bcSpout.contracts.GAS.Hash, 2_0000_0000)
+
+	getRoot := func(t *testing.T, pubs keys.PublicKeys, accs ...*wallet.Account) *state.MPTRoot {
+		sr, err := bcSpout.GetStateModule().GetStateRoot(height)
+		require.NoError(t, err)
+		testSignStateRoot(t, sr, pubs, accs...)
+		return sr
+	}
+
+	t.Run("no trusted validators", func(t *testing.T) {
+		bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+			c.ProtocolConfiguration.P2PStateExchangeExtensions = true
+		})
+		require.False(t, bc.GetStateSyncModule().IsActive())
+	})
+
+	bcBolt := newTestChainWithCustomCfg(t, fSync)
+	module := bcBolt.GetStateSyncModule()
+	require.NoError(t, module.AddHeaders(getTestHeaders(t, bcSpout)...))
+	require.NoError(t, module.SelectSyncPoint())
+	require.True(t, module.NeedHeaders())
+	require.False(t, module.NeedMPTNodes())
+
+	sender := accs[0].PrivateKey().GetScriptHash()
+	localRoot := bcBolt.GetStateModule().CurrentLocalStateRoot()
+	t.Run("untrusted sender", func(t *testing.T) {
+		_, otherPubs, otherAccs := newMajorityMultisigWithGAS(t, 2)
+		require.NoError(t, module.AddStateRoot(otherAccs[0].PrivateKey().GetScriptHash(), getRoot(t, otherPubs, otherAccs...)))
+		require.False(t, module.NeedMPTNodes())
+		require.Equal(t, localRoot, bcBolt.GetStateModule().CurrentLocalStateRoot())
+	})
+	t.Run("no witness", func(t *testing.T) {
+		sr, err := bcSpout.GetStateModule().GetStateRoot(height)
+		require.NoError(t, err)
+		require.Error(t, module.AddStateRoot(sender, sr))
+		require.False(t, module.NeedMPTNodes())
+	})
+	t.Run("bad signature", func(t *testing.T) {
+		_, otherPubs, otherAccs := newMajorityMultisigWithGAS(t, 2)
+		require.Error(t, module.AddStateRoot(sender, getRoot(t, otherPubs, otherAccs...)))
+		require.False(t, module.NeedMPTNodes())
+		require.True(t, module.NeedHeaders())
+		require.Equal(t, localRoot, bcBolt.GetStateModule().CurrentLocalStateRoot())
+	})
+
+	require.NoError(t, module.AddStateRoot(sender, getRoot(t, pubs, accs...)))
+	syncMPTNodes(t, bcSpout, module, -1)
+	syncBlocks(t, bcSpout, module)
+	checkStateSynced(t, bcSpout, bcBolt, height)
+	require.Equal(t, bcSpout.GetUtilityTokenBalance(acc), bcBolt.GetUtilityTokenBalance(acc))
+}
@@ -131,6 +131,52 @@ func (b *BadgerDBStore) Seek(key []byte, f func(k, v []byte)) {
 	}
 }
 
+// Snapshot implements the Snapshotter interface.
+func (b *BadgerDBStore) Snapshot() (Store, error) {
+	return &readOnlyStore{&badgerDBSnapshot{b.db.NewTransaction(false)}}, nil
+}
+
+// badgerDBSnapshot is a read-only view of BadgerDBStore.
+type badgerDBSnapshot struct {
+	txn *badger.Txn
+}
+
+// Get implements the Store interface.
+func (s *badgerDBSnapshot) Get(key []byte) ([]byte, error) {
+	item, err := s.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// Seek implements the Store interface.
+func (s *badgerDBSnapshot) Seek(key []byte, f func(k, v []byte)) {
+	it := s.txn.NewIterator(badger.IteratorOptions{
+		PrefetchValues: true,
+		PrefetchSize:   100,
+		Prefix:         key,
+	})
+	defer it.Close()
+	for it.Seek(key); it.ValidForPrefix(key); it.Next() {
+		item := it.Item()
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			panic(err)
+		}
+		f(item.Key(), v)
+	}
+}
+
+// Close implements the Store interface.
+func (s *badgerDBSnapshot) Close() error {
+	s.txn.Discard()
+	return nil
+}
+
 // Close releases all db resources.
 func (b *BadgerDBStore) Close() error {
 	return b.db.Close()
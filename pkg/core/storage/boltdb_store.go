@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -118,6 +119,63 @@ func (s *BoltDBStore) Seek(key []byte, f func(k, v []byte)) {
 	}
 }
 
+// Snapshot implements the Snapshotter interface. It's backed by a read-only
+// BoltDB transaction, so it should be closed as soon as possible, long-living
+// snapshots can block writes to the DB.
+func (s *BoltDBStore) Snapshot() (Store, error) {
+	tx, err := s.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyStore{&boltDBSnapshot{tx: tx}}, nil
+}
+
+// boltDBSnapshot is a read-only view of BoltDBStore. Transactions are not
+// thread-safe, so it's protected by a mutex. Keys and values are valid until
+// the transaction is closed, so they're not copied for Seek.
+type boltDBSnapshot struct {
+	mut sync.Mutex
+	tx  *bbolt.Tx
+}
+
+// Get implements the Store interface.
+func (s *boltDBSnapshot) Get(key []byte) ([]byte, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	val := s.tx.Bucket(Bucket).Get(key)
+	if val == nil {
+		return nil, ErrKeyNotFound
+	}
+	// Value from Get is only valid for the lifetime of transaction.
+	var valcopy = make([]byte, len(val))
+	copy(valcopy, val)
+	return valcopy, nil
+}
+
+// Seek implements the Store interface.
+func (s *boltDBSnapshot) Seek(key []byte, f func(k, v []byte)) {
+	var kvs []KeyValue
+	s.mut.Lock()
+	c := s.tx.Bucket(Bucket).Cursor()
+	prefix := util.BytesPrefix(key)
+	for k, v := c.Seek(prefix.Start); k != nil && bytes.Compare(k, prefix.Limit) <= 0; k, v = c.Next() {
+		kvs = append(kvs, KeyValue{Key: k, Value: v})
+	}
+	s.mut.Unlock()
+	// Callback is invoked without lock held for it to be able to use the
+	// snapshot.
+	for _, kv := range kvs {
+		f(kv.Key, kv.Value)
+	}
+}
+
+// Close implements the Store interface.
+func (s *boltDBSnapshot) Close() error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.tx.Rollback()
+}
+
 // Batch implements the Batch interface and returns a boltdb
 // compatible Batch.
 func (s *BoltDBStore) Batch() Batch {
@@ -70,6 +70,44 @@ func (s *LevelDBStore) Seek(key []byte, f func(k, v []byte)) {
 	iter.Release()
 }
 
+// Snapshot implements the Snapshotter interface.
+func (s *LevelDBStore) Snapshot() (Store, error) {
+	snap, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyStore{&levelDBSnapshot{snap}}, nil
+}
+
+// levelDBSnapshot is a read-only view of LevelDBStore.
+type levelDBSnapshot struct {
+	snap *leveldb.Snapshot
+}
+
+// Get implements the Store interface.
+func (s *levelDBSnapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.snap.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		err = ErrKeyNotFound
+	}
+	return value, err
+}
+
+// Seek implements the Store interface.
+func (s *levelDBSnapshot) Seek(key []byte, f func(k, v []byte)) {
+	iter := s.snap.NewIterator(util.BytesPrefix(key), nil)
+	for iter.Next() {
+		f(iter.Key(), iter.Value())
+	}
+	iter.Release()
+}
+
+// Close implements the Store interface.
+func (s *levelDBSnapshot) Close() error {
+	s.snap.Release()
+	return nil
+}
+
 // Batch implements the Batch interface and returns a leveldb
 // compatible Batch.
 func (s *LevelDBStore) Batch() Batch {
@@ -1,5 +1,10 @@
 package storage
 
+import (
+	"bytes"
+	"strings"
+)
+
 // MemCachedStore is a wrapper around persistent store that caches all changes
 // being made for them to be later flushed in one batch.
 type MemCachedStore struct {
@@ -23,6 +28,15 @@ type (
 		Put     []KeyValue
 		Deleted []KeyValue
 	}
+
+	// KeyValueChange represents a change of the value stored by the key,
+	// nil OldValue means that the key didn't exist before and nil NewValue
+	// means that it's deleted.
+	KeyValueChange struct {
+		Key      []byte
+		OldValue []byte
+		NewValue []byte
+	}
 )
 
 // NewMemCachedStore creates a new MemCachedStore object.
@@ -71,6 +85,46 @@ func (s *MemCachedStore) GetBatch() *MemBatch {
 	return &b
 }
 
+// GetChanges returns currently accumulated changes of the keys with the given
+// prefix along with their values from the persistent store. Changes that
+// don't affect the resulting state (like deletion of a missing key) are
+// omitted. Changes are returned in no particular order.
+func (s *MemCachedStore) GetChanges(prefix []byte) []KeyValueChange {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var changes []KeyValueChange
+	add := func(k string, v []byte, deleted bool) {
+		if !strings.HasPrefix(k, string(prefix)) {
+			return
+		}
+		key := []byte(k)
+		old, err := s.ps.Get(key)
+		exists := err == nil
+		switch {
+		case !exists && deleted:
+			return
+		case exists && !deleted && bytes.Equal(old, v):
+			return
+		}
+		ch := KeyValueChange{Key: key}
+		if exists {
+			ch.OldValue = append([]byte{}, old...)
+		}
+		if !deleted {
+			ch.NewValue = append([]byte{}, v...)
+		}
+		changes = append(changes, ch)
+	}
+	for k, v := range s.mem {
+		add(k, v, false)
+	}
+	for k := range s.del {
+		add(k, nil, true)
+	}
+	return changes
+}
+
 // Seek implements the Store interface.
 func (s *MemCachedStore) Seek(key []byte, f func(k, v []byte)) {
 	s.mut.RLock()
@@ -90,6 +144,27 @@ func (s *MemCachedStore) Seek(key []byte, f func(k, v []byte)) {
 	})
 }
 
+// Snapshot implements the Snapshotter interface. It returns
+// ErrSnapshotNotSupported if the persistent store doesn't support snapshots.
+// Cached (not yet persisted) data is copied the same way MemoryStore snapshot
+// does.
+func (s *MemCachedStore) Snapshot() (Store, error) {
+	sn, ok := s.ps.(Snapshotter)
+	if !ok {
+		return nil, ErrSnapshotNotSupported
+	}
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	ps, err := sn.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyStore{&MemCachedStore{
+		MemoryStore: *s.MemoryStore.clone(),
+		ps:          ps,
+	}}, nil
+}
+
 // Persist flushes all the MemoryStore contents into the (supposedly) persistent
 // store ps.
 func (s *MemCachedStore) Persist() (int, error) {
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -177,3 +178,36 @@ func TestCachedSeek(t *testing.T) {
 func newMemCachedStoreForTesting(t *testing.T) Store {
 	return NewMemCachedStore(NewMemoryStore())
 }
+
+func TestMemCachedSnapshot(t *testing.T) {
+	ps := NewMemoryStore()
+	require.NoError(t, ps.Put([]byte("persisted"), []byte{1}))
+	ts := NewMemCachedStore(ps)
+	require.NoError(t, ts.Put([]byte("cached"), []byte{2}))
+	require.NoError(t, ts.Delete([]byte("persisted")))
+
+	snap, err := ts.Snapshot()
+	require.NoError(t, err)
+	_, err = ts.Persist()
+	require.NoError(t, err)
+	require.NoError(t, ts.Put([]byte("cached"), []byte{3}))
+
+	v, err := snap.Get([]byte("cached"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, v)
+	_, err = snap.Get([]byte("persisted"))
+	require.True(t, errors.Is(err, ErrKeyNotFound))
+	require.NoError(t, snap.Close())
+
+	t.Run("not supported", func(t *testing.T) {
+		ts := NewMemCachedStore(&unclosableStore{NewMemoryStore()})
+		_, err := ts.Snapshot()
+		require.True(t, errors.Is(err, ErrSnapshotNotSupported))
+		s, err := NewSnapshot(ts)
+		require.NoError(t, err)
+		require.NoError(t, s.Put([]byte{1}, []byte{2}))
+		require.NoError(t, s.Close())
+		_, err = ts.Get([]byte{1})
+		require.NoError(t, err)
+	})
+}
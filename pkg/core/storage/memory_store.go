@@ -128,6 +128,31 @@ func (s *MemoryStore) seek(key []byte, f func(k, v []byte)) {
 	}
 }
 
+// Snapshot implements the Snapshotter interface. It copies all of the store
+// contents (values are shared), so its cost is proportional to the store size.
+func (s *MemoryStore) Snapshot() (Store, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return &readOnlyStore{s.clone()}, nil
+}
+
+// clone returns a copy of the store, it's supposed to be called with mutex
+// locked. Values are shared between the copies, but they're never modified
+// in-place.
+func (s *MemoryStore) clone() *MemoryStore {
+	c := &MemoryStore{
+		mem: make(map[string][]byte, len(s.mem)),
+		del: make(map[string]bool, len(s.del)),
+	}
+	for k, v := range s.mem {
+		c.mem[k] = v
+	}
+	for k := range s.del {
+		c.del[k] = true
+	}
+	return c
+}
+
 // Batch implements the Batch interface and returns a compatible Batch.
 func (s *MemoryStore) Batch() Batch {
 	return newMemoryBatch()
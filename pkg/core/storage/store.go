@@ -8,19 +8,24 @@ import (
 
 // KeyPrefix constants.
 const (
-	DataBlock        KeyPrefix = 0x01
-	DataTransaction  KeyPrefix = 0x02
-	DataMPT          KeyPrefix = 0x03
-	STAccount        KeyPrefix = 0x40
-	STNotification   KeyPrefix = 0x4d
-	STContractID     KeyPrefix = 0x51
-	STStorage        KeyPrefix = 0x70
-	STNEP17Transfers KeyPrefix = 0x72
-	STNEP17Balances  KeyPrefix = 0x73
-	IXHeaderHashList KeyPrefix = 0x80
-	SYSCurrentBlock  KeyPrefix = 0xc0
-	SYSCurrentHeader KeyPrefix = 0xc1
-	SYSVersion       KeyPrefix = 0xf0
+	DataBlock                      KeyPrefix = 0x01
+	DataTransaction                KeyPrefix = 0x02
+	DataMPT                        KeyPrefix = 0x03
+	STAccount                      KeyPrefix = 0x40
+	STNotification                 KeyPrefix = 0x4d
+	STContractID                   KeyPrefix = 0x51
+	STStorage                      KeyPrefix = 0x70
+	STNEP17Transfers               KeyPrefix = 0x72
+	STNEP17Balances                KeyPrefix = 0x73
+	STNEP11Transfers               KeyPrefix = 0x74
+	STNEP11Balances                KeyPrefix = 0x75
+	STStorageChanges               KeyPrefix = 0x76
+	IXHeaderHashList               KeyPrefix = 0x80
+	SYSCurrentBlock                KeyPrefix = 0xc0
+	SYSCurrentHeader               KeyPrefix = 0xc1
+	SYSStateSyncPoint              KeyPrefix = 0xc2
+	SYSStateSyncCurrentBlockHeight KeyPrefix = 0xc3
+	SYSVersion                     KeyPrefix = 0xf0
 )
 
 const (
@@ -31,9 +36,17 @@ const (
 	MaxStorageValueLen = 65535
 )
 
-// ErrKeyNotFound is an error returned by Store implementations
-// when a certain key is not found.
-var ErrKeyNotFound = errors.New("key not found")
+var (
+	// ErrKeyNotFound is an error returned by Store implementations
+	// when a certain key is not found.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrReadOnly is returned on attempts to modify read-only stores like
+	// snapshots.
+	ErrReadOnly = errors.New("store is read-only")
+	// ErrSnapshotNotSupported is returned by Snapshotter implementations
+	// that depend on other stores not supporting snapshots.
+	ErrSnapshotNotSupported = errors.New("snapshots are not supported")
+)
 
 type (
 	// Store is anything that can persist and retrieve the blockchain.
@@ -58,9 +71,40 @@ type (
 		Put(k, v []byte)
 	}
 
+	// Snapshotter is implemented by Store implementations that can provide
+	// consistent read-only views of their contents.
+	Snapshotter interface {
+		// Snapshot returns a read-only Store with the contents the original
+		// one has at the moment of this call, subsequent changes of the
+		// original Store don't affect it and any attempt to modify the
+		// snapshot itself returns ErrReadOnly. It must be closed when it's
+		// no longer needed, this doesn't affect the original Store.
+		Snapshot() (Store, error)
+	}
+
 	// KeyPrefix is a constant byte added as a prefix for each key
 	// stored.
 	KeyPrefix uint8
+
+	// snapshotReader is the read part of Store implemented by snapshots.
+	snapshotReader interface {
+		Get([]byte) ([]byte, error)
+		Seek(k []byte, f func(k, v []byte))
+		Close() error
+	}
+
+	// readOnlyStore is a Store wrapper for snapshots, it rejects any
+	// modifications.
+	readOnlyStore struct {
+		snapshotReader
+	}
+
+	// unclosableStore is a Store wrapper that doesn't close the
+	// underlying Store, it's returned by NewSnapshot for the stores not
+	// supporting snapshots.
+	unclosableStore struct {
+		Store
+	}
 )
 
 // Bytes returns the bytes representation of KeyPrefix.
@@ -85,6 +129,47 @@ func AppendPrefixInt(k KeyPrefix, n int) []byte {
 	return AppendPrefix(k, b)
 }
 
+// Batch implements the Store interface.
+func (s *readOnlyStore) Batch() Batch {
+	return newMemoryBatch()
+}
+
+// Delete implements the Store interface, it always returns ErrReadOnly.
+func (s *readOnlyStore) Delete(k []byte) error {
+	return ErrReadOnly
+}
+
+// Put implements the Store interface, it always returns ErrReadOnly.
+func (s *readOnlyStore) Put(k, v []byte) error {
+	return ErrReadOnly
+}
+
+// PutBatch implements the Store interface, it always returns ErrReadOnly.
+func (s *readOnlyStore) PutBatch(Batch) error {
+	return ErrReadOnly
+}
+
+// Close implements the Store interface, it does nothing.
+func (s unclosableStore) Close() error {
+	return nil
+}
+
+// NewSnapshot returns a snapshot of the given Store if it supports them (see
+// Snapshotter). Otherwise the Store itself is returned (its view is not
+// consistent then), but the result can be used and closed the same way in both
+// cases.
+func NewSnapshot(s Store) (Store, error) {
+	sn, ok := s.(Snapshotter)
+	if !ok {
+		return unclosableStore{s}, nil
+	}
+	snap, err := sn.Snapshot()
+	if errors.Is(err, ErrSnapshotNotSupported) {
+		return unclosableStore{s}, nil
+	}
+	return snap, err
+}
+
 // NewStore creates storage with preselected in configuration database type.
 func NewStore(cfg DBConfiguration) (Store, error) {
 	var store Store
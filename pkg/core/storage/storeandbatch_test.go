@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"errors"
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -217,6 +219,64 @@ func testStorePutBatchWithDelete(t *testing.T, s Store) {
 	require.NoError(t, s.Close())
 }
 
+func testStoreSnapshot(t *testing.T, s Store) {
+	require.NoError(t, s.Put([]byte("snap/foo"), []byte("bar")))
+	require.NoError(t, s.Put([]byte("snap/fob"), []byte("baz")))
+
+	snap, err := NewSnapshot(s)
+	require.NoError(t, err)
+	_, isSnapshotter := s.(Snapshotter)
+	if isSnapshotter {
+		require.True(t, errors.Is(snap.Put([]byte("snap/foo"), []byte("new")), ErrReadOnly))
+		require.True(t, errors.Is(snap.Delete([]byte("snap/foo")), ErrReadOnly))
+		require.True(t, errors.Is(snap.PutBatch(snap.Batch()), ErrReadOnly))
+	}
+
+	// Modifications can be blocked by an open snapshot for some stores
+	// (BoltDB), so they're done concurrently.
+	modified := make(chan error, 1)
+	go func() {
+		err := s.Put([]byte("snap/foo"), []byte("new"))
+		if err == nil {
+			err = s.Delete([]byte("snap/fob"))
+		}
+		if err == nil {
+			err = s.Put([]byte("snap/fod"), []byte("added"))
+		}
+		modified <- err
+	}()
+	var modErr error
+	select {
+	case modErr = <-modified:
+		modified <- modErr
+	case <-time.After(100 * time.Millisecond):
+	}
+	if isSnapshotter {
+		v, err := snap.Get([]byte("snap/foo"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("bar"), v)
+		v, err = snap.Get([]byte("snap/fob"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("baz"), v)
+		_, err = snap.Get([]byte("snap/fod"))
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+
+		seen := make(map[string]string)
+		snap.Seek([]byte("snap/"), func(k, v []byte) {
+			seen[string(k)] = string(v)
+		})
+		require.Equal(t, map[string]string{"snap/foo": "bar", "snap/fob": "baz"}, seen)
+	}
+	require.NoError(t, snap.Close())
+	require.NoError(t, <-modified)
+
+	// The original store is not affected by snapshot closing.
+	v, err := s.Get([]byte("snap/foo"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), v)
+	require.NoError(t, s.Close())
+}
+
 func TestAllDBs(t *testing.T) {
 	var DBs = []dbSetup{
 		{"BoltDB", newBoltStoreForTesting},
@@ -229,7 +289,7 @@ func TestAllDBs(t *testing.T) {
 	var tests = []dbTestFunction{testStoreClose, testStorePutAndGet,
 		testStoreGetNonExistent, testStorePutBatch, testStoreSeek,
 		testStoreDeleteNonExistent, testStorePutAndDelete,
-		testStorePutBatchWithDelete}
+		testStorePutBatchWithDelete, testStoreSnapshot}
 	for _, db := range DBs {
 		for _, test := range tests {
 			s := db.create(t)
@@ -25,9 +25,14 @@ func NewMerkleTree(hashes []util.Uint256) (*MerkleTree, error) {
 		}
 	}
 
+	root := buildMerkleTree(nodes)
+	depth := 1
+	for n := root; n.leftChild != nil; n = n.leftChild {
+		depth++
+	}
 	return &MerkleTree{
-		root:  buildMerkleTree(nodes),
-		depth: 1,
+		root:  root,
+		depth: depth,
 	}, nil
 }
 
@@ -36,6 +41,54 @@ func (t *MerkleTree) Root() util.Uint256 {
 	return t.root.hash
 }
 
+// Trim prunes the subtrees not containing leaves with flags set, flags[i]
+// corresponds to the i-th tree leaf (missing flags are treated as unset).
+func (t *MerkleTree) Trim(flags []bool) {
+	trimMerkleTree(t.root, 0, t.depth, flags)
+}
+
+func trimMerkleTree(n *MerkleTreeNode, index int, depth int, flags []bool) {
+	if depth == 1 || n.leftChild == nil {
+		return
+	}
+	if depth == 2 {
+		if !isFlagSet(flags, index*2) && !isFlagSet(flags, index*2+1) {
+			n.leftChild = nil
+			n.rightChild = nil
+		}
+		return
+	}
+	trimMerkleTree(n.leftChild, index*2, depth-1, flags)
+	if n.rightChild != n.leftChild {
+		trimMerkleTree(n.rightChild, index*2+1, depth-1, flags)
+	}
+	if n.leftChild.leftChild == nil && n.rightChild.rightChild == nil {
+		n.leftChild = nil
+		n.rightChild = nil
+	}
+}
+
+func isFlagSet(flags []bool, i int) bool {
+	return i < len(flags) && flags[i]
+}
+
+// ToHashArray returns hashes of the tree leaves in the depth-first order. For
+// a trimmed tree pruned subtrees are represented by their root hashes.
+func (t *MerkleTree) ToHashArray() []util.Uint256 {
+	var res []util.Uint256
+	var dfs func(n *MerkleTreeNode)
+	dfs = func(n *MerkleTreeNode) {
+		if n.leftChild == nil {
+			res = append(res, n.hash)
+			return
+		}
+		dfs(n.leftChild)
+		dfs(n.rightChild)
+	}
+	dfs(t.root)
+	return res
+}
+
 func buildMerkleTree(leaves []*MerkleTreeNode) *MerkleTreeNode {
 	if len(leaves) == 0 {
 		panic("length of leaves cannot be zero")
@@ -101,3 +101,35 @@ func TestBuildMerkleTreeWithoutNodes(t *testing.T) {
 	leaves = make([]*MerkleTreeNode, 0)
 	require.Panics(t, func() { buildMerkleTree(leaves) })
 }
+
+func TestMerkleTree_Trim(t *testing.T) {
+	hashes := []util.Uint256{{1}, {2}, {3}, {4}, {5}}
+	parent := func(a, b util.Uint256) util.Uint256 {
+		return DoubleSha256(append(a.BytesBE(), b.BytesBE()...))
+	}
+	h12, h34, h55 := parent(hashes[0], hashes[1]), parent(hashes[2], hashes[3]), parent(hashes[4], hashes[4])
+	h1234, h5555 := parent(h12, h34), parent(h55, h55)
+
+	check := func(t *testing.T, flags []bool, expected []util.Uint256) {
+		merkle, err := NewMerkleTree(hashes)
+		require.NoError(t, err)
+		require.Equal(t, 4, merkle.depth)
+		merkle.Trim(flags)
+		require.Equal(t, expected, merkle.ToHashArray())
+		require.Equal(t, CalcMerkleRoot(append([]util.Uint256{}, hashes...)), merkle.Root())
+	}
+	t.Run("none", func(t *testing.T) {
+		check(t, nil, []util.Uint256{parent(h1234, h5555)})
+	})
+	t.Run("first", func(t *testing.T) {
+		check(t, []bool{true}, []util.Uint256{hashes[0], hashes[1], h34, h5555})
+	})
+	t.Run("last", func(t *testing.T) {
+		check(t, []bool{false, false, false, false, true}, []util.Uint256{h1234, hashes[4], hashes[4], hashes[4], hashes[4]})
+	})
+	t.Run("all", func(t *testing.T) {
+		flags := []bool{true, true, true, true, true}
+		check(t, flags, []util.Uint256{hashes[0], hashes[1], hashes[2], hashes[3],
+			hashes[4], hashes[4], hashes[4], hashes[4]})
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/interop"
 	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
 	"github.com/nspcc-dev/neo-go/pkg/interop/iterator"
+	"github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"
 )
 
 // RecordType represents NameService record type.
@@ -26,104 +27,104 @@ const Hash = "\x6b\x59\x2b\x87\x66\xcc\x45\x8e\xfa\x7a\x90\x47\x56\x62\xcd\x92\x
 
 // Symbol represents `symbol` method of NameService native contract.
 func Symbol() string {
-	return contract.Call(interop.Hash160(Hash), "symbol", contract.NoneFlag).(string)
+	return neogointernal.CallWithToken(Hash, "symbol", int(contract.NoneFlag)).(string)
 }
 
 // Decimals represents `decimals` method of NameService native contract.
 func Decimals() int {
-	return contract.Call(interop.Hash160(Hash), "decimals", contract.NoneFlag).(int)
+	return neogointernal.CallWithToken(Hash, "decimals", int(contract.NoneFlag)).(int)
 }
 
 // TotalSupply represents `totalSupply` method of NameService native contract.
 func TotalSupply() int {
-	return contract.Call(interop.Hash160(Hash), "totalSupply", contract.ReadStates).(int)
+	return neogointernal.CallWithToken(Hash, "totalSupply", int(contract.ReadStates)).(int)
 }
 
 // OwnerOf represents `ownerOf` method of NameService native contract.
 func OwnerOf(tokenID string) interop.Hash160 {
-	return contract.Call(interop.Hash160(Hash), "ownerOf", contract.ReadStates, tokenID).(interop.Hash160)
+	return neogointernal.CallWithToken(Hash, "ownerOf", int(contract.ReadStates), tokenID).(interop.Hash160)
 }
 
 // BalanceOf represents `balanceOf` method of NameService native contract.
 func BalanceOf(owner interop.Hash160) int {
-	return contract.Call(interop.Hash160(Hash), "balanceOf", contract.ReadStates, owner).(int)
+	return neogointernal.CallWithToken(Hash, "balanceOf", int(contract.ReadStates), owner).(int)
 }
 
 // Properties represents `properties` method of NameService native contract.
 func Properties(tokenID string) map[string]interface{} {
-	return contract.Call(interop.Hash160(Hash), "properties", contract.ReadStates, tokenID).(map[string]interface{})
+	return neogointernal.CallWithToken(Hash, "properties", int(contract.ReadStates), tokenID).(map[string]interface{})
 }
 
 // Tokens represents `tokens` method of NameService native contract.
 func Tokens() iterator.Iterator {
-	return contract.Call(interop.Hash160(Hash), "tokens",
-		contract.ReadStates).(iterator.Iterator)
+	return neogointernal.CallWithToken(Hash, "tokens",
+		int(contract.ReadStates)).(iterator.Iterator)
 }
 
 // TokensOf represents `tokensOf` method of NameService native contract.
 func TokensOf(addr interop.Hash160) iterator.Iterator {
-	return contract.Call(interop.Hash160(Hash), "tokensOf",
-		contract.ReadStates, addr).(iterator.Iterator)
+	return neogointernal.CallWithToken(Hash, "tokensOf",
+		int(contract.ReadStates), addr).(iterator.Iterator)
 }
 
 // Transfer represents `transfer` method of NameService native contract.
 func Transfer(to interop.Hash160, tokenID string) bool {
-	return contract.Call(interop.Hash160(Hash), "transfer",
-		contract.ReadStates|contract.States|contract.AllowNotify, to, tokenID).(bool)
+	return neogointernal.CallWithToken(Hash, "transfer",
+		int(contract.ReadStates|contract.States|contract.AllowNotify), to, tokenID).(bool)
 }
 
 // AddRoot represents `addRoot` method of NameService native contract.
 func AddRoot(root string) {
-	contract.Call(interop.Hash160(Hash), "addRoot", contract.States, root)
+	neogointernal.CallWithTokenNoRet(Hash, "addRoot", int(contract.States), root)
 }
 
 // SetPrice represents `setPrice` method of NameService native contract.
 func SetPrice(price int) {
-	contract.Call(interop.Hash160(Hash), "setPrice", contract.States, price)
+	neogointernal.CallWithTokenNoRet(Hash, "setPrice", int(contract.States), price)
 }
 
 // GetPrice represents `getPrice` method of NameService native contract.
 func GetPrice() int {
-	return contract.Call(interop.Hash160(Hash), "getPrice", contract.ReadStates).(int)
+	return neogointernal.CallWithToken(Hash, "getPrice", int(contract.ReadStates)).(int)
 }
 
 // IsAvailable represents `isAvailable` method of NameService native contract.
 func IsAvailable(name string) bool {
-	return contract.Call(interop.Hash160(Hash), "isAvailable", contract.ReadStates, name).(bool)
+	return neogointernal.CallWithToken(Hash, "isAvailable", int(contract.ReadStates), name).(bool)
 }
 
 // Register represents `register` method of NameService native contract.
 func Register(name string, owner interop.Hash160) bool {
-	return contract.Call(interop.Hash160(Hash), "register", contract.States, name, owner).(bool)
+	return neogointernal.CallWithToken(Hash, "register", int(contract.States), name, owner).(bool)
 }
 
 // Renew represents `renew` method of NameService native contract.
 func Renew(name string) int {
-	return contract.Call(interop.Hash160(Hash), "renew", contract.States, name).(int)
+	return neogointernal.CallWithToken(Hash, "renew", int(contract.States), name).(int)
 }
 
 // SetAdmin represents `setAdmin` method of NameService native contract.
 func SetAdmin(name string, admin interop.Hash160) {
-	contract.Call(interop.Hash160(Hash), "setAdmin", contract.States, name, admin)
+	neogointernal.CallWithTokenNoRet(Hash, "setAdmin", int(contract.States), name, admin)
 }
 
 // SetRecord represents `setRecord` method of NameService native contract.
 func SetRecord(name string, recType RecordType, data string) {
-	contract.Call(interop.Hash160(Hash), "setRecord", contract.States, name, recType, data)
+	neogointernal.CallWithTokenNoRet(Hash, "setRecord", int(contract.States), name, recType, data)
 }
 
 // GetRecord represents `getRecord` method of NameService native contract.
 // It returns `nil` if record is missing.
 func GetRecord(name string, recType RecordType) []byte {
-	return contract.Call(interop.Hash160(Hash), "getRecord", contract.ReadStates, name, recType).([]byte)
+	return neogointernal.CallWithToken(Hash, "getRecord", int(contract.ReadStates), name, recType).([]byte)
 }
 
 // DeleteRecord represents `deleteRecord` method of NameService native contract.
 func DeleteRecord(name string, recType RecordType) {
-	contract.Call(interop.Hash160(Hash), "deleteRecord", contract.States, name, recType)
+	neogointernal.CallWithTokenNoRet(Hash, "deleteRecord", int(contract.States), name, recType)
 }
 
 // Resolve represents `resolve` method of NameService native contract.
 func Resolve(name string, recType RecordType) []byte {
-	return contract.Call(interop.Hash160(Hash), "resolve", contract.ReadStates, name, recType).([]byte)
+	return neogointernal.CallWithToken(Hash, "resolve", int(contract.ReadStates), name, recType).([]byte)
 }
@@ -8,6 +8,7 @@ package roles
 import (
 	"github.com/nspcc-dev/neo-go/pkg/interop"
 	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"
 )
 
 // Hash represents RoleManagement contract hash.
@@ -27,12 +28,12 @@ const (
 
 // GetDesignatedByRole represents `getDesignatedByRole` method of RoleManagement native contract.
 func GetDesignatedByRole(r Role, height uint32) []interop.PublicKey {
-	return contract.Call(interop.Hash160(Hash), "getDesignatedByRole",
-		contract.ReadStates, r, height).([]interop.PublicKey)
+	return neogointernal.CallWithToken(Hash, "getDesignatedByRole",
+		int(contract.ReadStates), r, height).([]interop.PublicKey)
 }
 
 // DesignateAsRole represents `designateAsRole` method of RoleManagement native contract.
 func DesignateAsRole(r Role, pubs []interop.PublicKey) {
-	contract.Call(interop.Hash160(Hash), "designateAsRole",
-		contract.States, r, pubs)
+	neogointernal.CallWithTokenNoRet(Hash, "designateAsRole",
+		int(contract.States), r, pubs)
 }
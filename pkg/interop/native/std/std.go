@@ -5,8 +5,8 @@ It implements various useful conversion functions.
 package std
 
 import (
-	"github.com/nspcc-dev/neo-go/pkg/interop"
 	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"
 )
 
 // Hash represents StdLib contract hash.
@@ -17,14 +17,14 @@ const Hash = "\xc0\xef\x39\xce\xe0\xe4\xe9\x25\xc6\xc2\xa0\x6a\x79\xe1\x44\x0d\x
 // from interop package) and allows to save them in storage or pass into Notify
 // and then Deserialize them on the next run or in the external event receiver.
 func Serialize(item interface{}) []byte {
-	return contract.Call(interop.Hash160(Hash), "serialize", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "serialize", int(contract.NoneFlag),
 		item).([]byte)
 }
 
 // Deserialize calls `deserialize` method of StdLib native contract and unpacks
 // previously serialized value from a byte slice, it's the opposite of Serialize.
 func Deserialize(b []byte) interface{} {
-	return contract.Call(interop.Hash160(Hash), "deserialize", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "deserialize", int(contract.NoneFlag),
 		b)
 }
 
@@ -39,7 +39,7 @@ func Deserialize(b []byte) interface{} {
 // []interface{} -> json array
 // map[type1]type2 -> json object with string keys marshaled as strings (not base64).
 func JSONSerialize(item interface{}) []byte {
-	return contract.Call(interop.Hash160(Hash), "jsonSerialize", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "jsonSerialize", int(contract.NoneFlag),
 		item).([]byte)
 }
 
@@ -52,7 +52,7 @@ func JSONSerialize(item interface{}) []byte {
 // arrays -> []interface{}
 // maps -> map[string]interface{}
 func JSONDeserialize(data []byte) interface{} {
-	return contract.Call(interop.Hash160(Hash), "jsonDeserialize", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "jsonDeserialize", int(contract.NoneFlag),
 		data)
 }
 
@@ -60,14 +60,14 @@ func JSONDeserialize(data []byte) interface{} {
 // given byte slice into a base64 string and returns byte representation of this
 // string.
 func Base64Encode(b []byte) string {
-	return contract.Call(interop.Hash160(Hash), "base64Encode", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "base64Encode", int(contract.NoneFlag),
 		b).(string)
 }
 
 // Base64Decode calls `base64Decode` method of StdLib native contract and decodes
 // given base64 string represented as a byte slice into byte slice.
 func Base64Decode(b []byte) []byte {
-	return contract.Call(interop.Hash160(Hash), "base64Decode", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "base64Decode", int(contract.NoneFlag),
 		b).([]byte)
 }
 
@@ -75,27 +75,27 @@ func Base64Decode(b []byte) []byte {
 // given byte slice into a base58 string and returns byte representation of this
 // string.
 func Base58Encode(b []byte) string {
-	return contract.Call(interop.Hash160(Hash), "base58Encode", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "base58Encode", int(contract.NoneFlag),
 		b).(string)
 }
 
 // Base58Decode calls `base58Decode` method of StdLib native contract and decodes
 // given base58 string represented as a byte slice into a new byte slice.
 func Base58Decode(b []byte) []byte {
-	return contract.Call(interop.Hash160(Hash), "base58Decode", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "base58Decode", int(contract.NoneFlag),
 		b).([]byte)
 }
 
 // Itoa converts num in a given base to string. Base should be either 10 or 16.
 // It uses `itoa` method of StdLib native contract.
 func Itoa(num int, base int) string {
-	return contract.Call(interop.Hash160(Hash), "itoa", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "itoa", int(contract.NoneFlag),
 		num, base).(string)
 }
 
 // Atoi converts string to a number in a given base. Base should be either 10 or 16.
 // It uses `atoi` method of StdLib native contract.
 func Atoi(s string, base int) int {
-	return contract.Call(interop.Hash160(Hash), "atoi", contract.NoneFlag,
+	return neogointernal.CallWithToken(Hash, "atoi", int(contract.NoneFlag),
 		s, base).(int)
 }
@@ -0,0 +1,14 @@
+package neogointernal
+
+// CallWithToken performs contract call using CALLT instruction. Script hash
+// (20 bytes in BE form), method and flags must be constants, they're stored
+// in the NEF method token the instruction refers to. It can only be used for
+// methods returning some value.
+func CallWithToken(scriptHash string, method string, flags int, args ...interface{}) interface{} {
+	return nil
+}
+
+// CallWithTokenNoRet is a version of CallWithToken that does not return anything,
+// it can only be used for methods not returning any value.
+func CallWithTokenNoRet(scriptHash string, method string, flags int, args ...interface{}) {
+}
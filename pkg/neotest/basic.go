@@ -0,0 +1,449 @@
+package neotest
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/fee"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+// Executor is a wrapper over chain state.
+type Executor struct {
+	Chain         *core.Blockchain
+	Validator     Signer
+	Committee     Signer
+	CommitteeHash util.Uint160
+	// coverage is the collector contracts deployed by the executor are
+	// registered in (if enabled).
+	coverage *coverage.Collector
+}
+
+// DefaultAccountGAS is the amount of GAS transferred to the accounts created
+// with Executor.NewAccount and to the committee account if it has no GAS.
+const DefaultAccountGAS = 100_0000_0000
+
+// nonce is used to make transactions created by different tests unique.
+var nonce uint32
+
+// Nonce returns unique number that can be used as nonce for new transactions.
+func Nonce() uint32 {
+	return atomic.AddUint32(&nonce, 1)
+}
+
+// NewExecutor creates new executor instance from provided blockchain, validator
+// and committee. Validator must be the standby validators multisignature account
+// (which owns all NEO and GAS after genesis), it's used to sign blocks. If
+// committee account has no GAS it's funded from the validator's one, so that
+// it can pay for its own transactions.
+func NewExecutor(t *testing.T, bc *core.Blockchain, validator, committee Signer) *Executor {
+	script, err := smartcontract.CreateDefaultMultiSigRedeemScript(bc.GetStandByValidators())
+	require.NoError(t, err)
+	require.Equal(t, script, validator.Script(), "validator doesn't match chain configuration")
+
+	e := &Executor{
+		Chain:         bc,
+		Validator:     validator,
+		Committee:     committee,
+		CommitteeHash: committee.ScriptHash(),
+	}
+	if bc.GetUtilityTokenBalance(e.CommitteeHash).Sign() == 0 {
+		tx := e.NewTx(t, []Signer{validator}, bc.UtilityTokenHash(), "transfer",
+			validator.ScriptHash(), e.CommitteeHash, int64(DefaultAccountGAS), nil)
+		e.AddNewBlock(t, tx)
+		e.CheckHalt(t, tx.Hash(), stackitem.NewBool(true))
+	}
+	return e
+}
+
+// EnableCoverage makes the chain count instructions executed by all of its VMs
+// in the given collector. Contracts deployed by the executor after this call
+// are registered there (if they have debug information), so that coverage
+// profile can be produced for them with c.Profile. The same collector can be
+// used by several executors.
+func (e *Executor) EnableCoverage(c *coverage.Collector) {
+	e.coverage = c
+	e.Chain.SetOnExecHook(c.OnExec)
+}
+
+// TopBlock returns block with the highest index.
+func (e *Executor) TopBlock(t *testing.T) *block.Block {
+	b, err := e.Chain.GetBlock(e.Chain.GetHeaderHash(int(e.Chain.BlockHeight())))
+	require.NoError(t, err)
+	return b
+}
+
+// NativeHash returns native contract hash by name.
+func (e *Executor) NativeHash(t *testing.T, name string) util.Uint160 {
+	h, err := e.Chain.GetNativeContractScriptHash(name)
+	require.NoError(t, err)
+	return h
+}
+
+// ContractHash returns contract hash by ID.
+func (e *Executor) ContractHash(t *testing.T, id int32) util.Uint160 {
+	h, err := e.Chain.GetContractScriptHash(id)
+	require.NoError(t, err)
+	return h
+}
+
+// NEOHash returns hash of the NEO native contract.
+func (e *Executor) NEOHash() util.Uint160 {
+	return e.Chain.GoverningTokenHash()
+}
+
+// GASHash returns hash of the GAS native contract.
+func (e *Executor) GASHash() util.Uint160 {
+	return e.Chain.UtilityTokenHash()
+}
+
+// NewUnsignedTx creates new unsigned transaction which invokes method of contract with hash.
+func (e *Executor) NewUnsignedTx(t *testing.T, hash util.Uint160, method string, args ...interface{}) *transaction.Transaction {
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, hash, method, callflag.All, args...)
+	require.NoError(t, w.Err)
+
+	return e.newScriptTx(w.Bytes())
+}
+
+func (e *Executor) newScriptTx(script []byte) *transaction.Transaction {
+	tx := transaction.New(script, 0)
+	tx.Nonce = Nonce()
+	tx.ValidUntilBlock = e.Chain.BlockHeight() + 1
+	return tx
+}
+
+// NewTx creates new transaction which invokes contract method.
+// Transaction is signed by signer, the first one is the sender paying fees.
+// System fee is calculated via test invocation.
+func (e *Executor) NewTx(t *testing.T, signers []Signer,
+	hash util.Uint160, method string, args ...interface{}) *transaction.Transaction {
+	tx := e.NewUnsignedTx(t, hash, method, args...)
+	return e.SignTx(t, tx, -1, signers...)
+}
+
+// SignTx signs a transaction using provided signers. Signers are added with
+// the Global scope, the first one is the sender paying fees. If sysFee is
+// negative, system fee is calculated via test invocation of the transaction.
+func (e *Executor) SignTx(t *testing.T, tx *transaction.Transaction, sysFee int64, signers ...Signer) *transaction.Transaction {
+	for _, acc := range signers {
+		tx.Signers = append(tx.Signers, transaction.Signer{
+			Account: acc.ScriptHash(),
+			Scopes:  transaction.Global,
+		})
+	}
+	AddNetworkFee(e.Chain, tx, signers...)
+	AddSystemFee(e.Chain, tx, sysFee)
+
+	for _, acc := range signers {
+		require.NoError(t, acc.SignTx(e.Chain.GetConfig().Magic, tx))
+	}
+	return tx
+}
+
+// NewAccount returns new signer holding 100.0 GAS (or given amount of GAS
+// in 10^-8 units). This method advances the chain by one block with a
+// transfer transaction.
+func (e *Executor) NewAccount(t *testing.T, expectedGASBalance ...int64) SingleSigner {
+	acc, err := wallet.NewAccount()
+	require.NoError(t, err)
+
+	amount := int64(DefaultAccountGAS)
+	if len(expectedGASBalance) != 0 {
+		amount = expectedGASBalance[0]
+	}
+	tx := e.NewTx(t, []Signer{e.Validator}, e.GASHash(), "transfer",
+		e.Validator.ScriptHash(), acc.Contract.ScriptHash(), amount, nil)
+	e.AddNewBlock(t, tx)
+	e.CheckHalt(t, tx.Hash(), stackitem.NewBool(true))
+	return NewSingleSigner(acc)
+}
+
+// DeployContract deploys contract to bc using validator account. It also
+// checks that precalculated contract hash matches the actual one. data is an
+// optional argument passed to contract's _deploy method.
+func (e *Executor) DeployContract(t *testing.T, c *Contract, data interface{}) util.Uint256 {
+	return e.DeployContractBy(t, e.Validator, c, data)
+}
+
+// DeployContractBy deploys contract to bc using the provided signer as
+// a sender. Contract must be compiled for this sender.
+func (e *Executor) DeployContractBy(t *testing.T, signer Signer, c *Contract, data interface{}) util.Uint256 {
+	tx := e.NewDeployTxBy(t, signer, c, data)
+	e.AddNewBlock(t, tx)
+	e.CheckHalt(t, tx.Hash())
+
+	// Check that precalculated hash matches the real one.
+	require.NotNil(t, e.Chain.GetContractState(c.Hash),
+		"contract hash doesn't match the sender it was compiled for")
+	return tx.Hash()
+}
+
+// DeployContractCheckFAULT deploys contract to bc using
+// validator account. It checks that deploy transaction FAULTed with the
+// specified error.
+func (e *Executor) DeployContractCheckFAULT(t *testing.T, c *Contract, data interface{}, errMessage string) {
+	tx := e.NewDeployTx(t, c, data)
+	e.AddNewBlock(t, tx)
+	e.CheckFault(t, tx.Hash(), errMessage)
+}
+
+// NewDeployTx returns new deployment transaction for the contract signed by
+// the validator account.
+func (e *Executor) NewDeployTx(t *testing.T, c *Contract, data interface{}) *transaction.Transaction {
+	return e.NewDeployTxBy(t, e.Validator, c, data)
+}
+
+// NewDeployTxBy returns new deployment transaction for the contract signed by
+// the provided signer.
+func (e *Executor) NewDeployTxBy(t *testing.T, signer Signer, c *Contract, data interface{}) *transaction.Transaction {
+	rawManifest, err := json.Marshal(c.Manifest)
+	require.NoError(t, err)
+
+	neb, err := c.NEF.Bytes()
+	require.NoError(t, err)
+
+	args := []interface{}{neb, rawManifest}
+	if data != nil {
+		args = append(args, data)
+	}
+	if e.coverage != nil && c.DebugInfo != nil {
+		e.coverage.AddContract(c.Hash, c.DebugInfo)
+	}
+	tx := e.NewUnsignedTx(t, e.Chain.ManagementContractHash(), "deploy", args...)
+	return e.SignTx(t, tx, -1, signer)
+}
+
+// InvokeScript adds transaction with the specified script to the chain and
+// returns its hash. It does no faults check.
+func (e *Executor) InvokeScript(t *testing.T, script []byte, signers []Signer) util.Uint256 {
+	tx := e.PrepareInvocation(t, script, signers)
+	e.AddNewBlock(t, tx)
+	return tx.Hash()
+}
+
+// PrepareInvocation creates transaction with the specified script and signs it
+// by the provided signer.
+func (e *Executor) PrepareInvocation(t *testing.T, script []byte, signers []Signer) *transaction.Transaction {
+	tx := e.newScriptTx(script)
+	return e.SignTx(t, tx, -1, signers...)
+}
+
+// InvokeScriptCheckHALT adds transaction with the specified script to the
+// chain and checks it's HALTed with the specified items on stack.
+func (e *Executor) InvokeScriptCheckHALT(t *testing.T, script []byte, signers []Signer, stack ...stackitem.Item) {
+	hash := e.InvokeScript(t, script, signers)
+	e.CheckHalt(t, hash, stack...)
+}
+
+// InvokeScriptCheckFAULT adds transaction with the specified script to the
+// chain and checks it's FAULTed with the specified error.
+func (e *Executor) InvokeScriptCheckFAULT(t *testing.T, script []byte, signers []Signer, errMessage string) util.Uint256 {
+	hash := e.InvokeScript(t, script, signers)
+	e.CheckFault(t, hash, errMessage)
+	return hash
+}
+
+// CheckHalt checks that transaction persisted with HALT state and the
+// specified items on stack (if any are given).
+func (e *Executor) CheckHalt(t *testing.T, h util.Uint256, stack ...stackitem.Item) *state.AppExecResult {
+	aer := e.GetTxExecResult(t, h)
+	require.Equal(t, vm.HaltState, aer.VMState, aer.FaultException)
+	if len(stack) != 0 {
+		require.Equal(t, stack, aer.Stack)
+	}
+	return aer
+}
+
+// CheckFault checks that transaction persisted with FAULT state.
+// Raised exception is also checked to contain s as a substring.
+func (e *Executor) CheckFault(t *testing.T, h util.Uint256, s string) {
+	aer := e.GetTxExecResult(t, h)
+	require.Equal(t, vm.FaultState, aer.VMState)
+	require.True(t, strings.Contains(aer.FaultException, s),
+		"expected: %s, got: %s", s, aer.FaultException)
+}
+
+// CheckTxNotificationEvent checks that the specified event was emitted at
+// the specified position during transaction script execution. Negative
+// index corresponds to backwards enumeration.
+func (e *Executor) CheckTxNotificationEvent(t *testing.T, h util.Uint256, index int, expected state.NotificationEvent) {
+	aer := e.GetTxExecResult(t, h)
+	l := len(aer.Events)
+	if index < 0 {
+		index += l
+	}
+	require.True(t, 0 <= index && index < l, "invalid event index %d (%d events emitted)", index, l)
+	require.Equal(t, expected, aer.Events[index])
+}
+
+// CheckGASConsumed checks that transaction execution has consumed the
+// specified amount of GAS (in 10^-8 units).
+func (e *Executor) CheckGASConsumed(t *testing.T, h util.Uint256, expected int64) {
+	aer := e.GetTxExecResult(t, h)
+	require.Equal(t, expected, aer.GasConsumed)
+}
+
+// CheckGASBalance ensures that provided account owns specified amount of GAS.
+func (e *Executor) CheckGASBalance(t *testing.T, acc util.Uint160, expected *big.Int) {
+	actual := e.Chain.GetUtilityTokenBalance(acc)
+	require.Equal(t, 0, expected.Cmp(actual), "invalid GAS balance: expected %s, got %s", expected, actual)
+}
+
+// EnsureGASBalance ensures that provided account owns amount of GAS that
+// satisfies provided condition.
+func (e *Executor) EnsureGASBalance(t *testing.T, acc util.Uint160, isOk func(balance *big.Int) bool) {
+	actual := e.Chain.GetUtilityTokenBalance(acc)
+	require.True(t, isOk(actual), "unexpected GAS balance: %s", actual)
+}
+
+// AddNewBlock creates a new block from provided transactions, signs it by
+// validators and adds it to the chain.
+func (e *Executor) AddNewBlock(t *testing.T, txs ...*transaction.Transaction) *block.Block {
+	b := e.NewUnsignedBlock(t, txs...)
+	e.SignBlock(b)
+	require.NoError(t, e.Chain.AddBlock(b))
+	return b
+}
+
+// NewUnsignedBlock creates a new unsigned block from txs.
+func (e *Executor) NewUnsignedBlock(t *testing.T, txs ...*transaction.Transaction) *block.Block {
+	lastBlock := e.TopBlock(t)
+	b := &block.Block{
+		Header: block.Header{
+			NextConsensus: e.Validator.ScriptHash(),
+			Script: transaction.Witness{
+				VerificationScript: e.Validator.Script(),
+			},
+			Timestamp: lastBlock.Timestamp + 1,
+		},
+		Transactions: txs,
+	}
+	if e.Chain.GetConfig().StateRootInHeader {
+		b.StateRootEnabled = true
+		sr, err := e.Chain.GetStateModule().GetStateRoot(e.Chain.BlockHeight())
+		require.NoError(t, err)
+		b.PrevStateRoot = sr.Root
+	}
+	b.PrevHash = lastBlock.Hash()
+	b.Index = e.Chain.BlockHeight() + 1
+	b.RebuildMerkleRoot()
+	return b
+}
+
+// GenerateNewBlocks adds specified number of empty blocks to the chain.
+func (e *Executor) GenerateNewBlocks(t *testing.T, count int) []*block.Block {
+	blocks := make([]*block.Block, count)
+	for i := 0; i < count; i++ {
+		blocks[i] = e.AddNewBlock(t)
+	}
+	return blocks
+}
+
+// SignBlock add validators signature to b.
+func (e *Executor) SignBlock(b *block.Block) *block.Block {
+	invoc := e.Validator.SignHashable(uint32(e.Chain.GetConfig().Magic), b)
+	b.Script.InvocationScript = invoc
+	return b
+}
+
+// AddBlockCheckHalt is a convenient wrapper over AddNewBlock and CheckHalt.
+func (e *Executor) AddBlockCheckHalt(t *testing.T, txs ...*transaction.Transaction) *block.Block {
+	b := e.AddNewBlock(t, txs...)
+	for _, tx := range txs {
+		e.CheckHalt(t, tx.Hash())
+	}
+	return b
+}
+
+// GetTransaction returns transaction and its height by the specified hash.
+func (e *Executor) GetTransaction(t *testing.T, h util.Uint256) (*transaction.Transaction, uint32) {
+	tx, height, err := e.Chain.GetTransaction(h)
+	require.NoError(t, err)
+	return tx, height
+}
+
+// GetBlockByIndex returns block by the specified index.
+func (e *Executor) GetBlockByIndex(t *testing.T, idx int) *block.Block {
+	h := e.Chain.GetHeaderHash(idx)
+	require.NotEmpty(t, h)
+	b, err := e.Chain.GetBlock(h)
+	require.NoError(t, err)
+	return b
+}
+
+// GetTxExecResult returns application execution results for the specified
+// transaction.
+func (e *Executor) GetTxExecResult(t *testing.T, h util.Uint256) *state.AppExecResult {
+	aer, err := e.Chain.GetAppExecResults(h, trigger.Application)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(aer))
+	return &aer[0]
+}
+
+// AddNetworkFee adds network fee to the transaction calculated for the
+// given signers verification scripts.
+func AddNetworkFee(bc *core.Blockchain, tx *transaction.Transaction, signers ...Signer) {
+	baseFee := bc.GetBaseExecFee()
+	size := io.GetVarSize(tx)
+	for _, sgr := range signers {
+		netFee, sizeDelta := fee.Calculate(baseFee, sgr.Script())
+		tx.NetworkFee += netFee
+		size += sizeDelta
+	}
+	tx.NetworkFee += int64(size) * bc.FeePerByte()
+}
+
+// AddSystemFee adds system fee to the transaction. If negative value is
+// provided, then system fee is calculated via test invocation (if it fails,
+// fee consumed up to the failure point is used).
+func AddSystemFee(bc *core.Blockchain, tx *transaction.Transaction, sysFee int64) {
+	if sysFee >= 0 {
+		tx.SystemFee = sysFee
+		return
+	}
+	v, _ := TestInvoke(bc, tx) // ignore error to support failing transactions
+	if v != nil {
+		tx.SystemFee = v.GasConsumed()
+	}
+}
+
+// TestInvoke creates a test VM with dummy block and executes transaction in it.
+// Chain state is not changed.
+func TestInvoke(bc *core.Blockchain, tx *transaction.Transaction) (*vm.VM, error) {
+	lastBlock, err := bc.GetBlock(bc.GetHeaderHash(int(bc.BlockHeight())))
+	if err != nil {
+		return nil, err
+	}
+	b := &block.Block{
+		Header: block.Header{
+			Index:     bc.BlockHeight() + 1,
+			Timestamp: lastBlock.Timestamp + 1,
+		},
+	}
+
+	// Transaction hash is cached on the first use and fees and witnesses
+	// are not yet final here, so use a copy of it for execution.
+	ttx := *tx
+	v, _ := bc.GetTestVM(trigger.Application, &ttx, b)
+	v.LoadScriptWithFlags(tx.Script, callflag.All)
+	err = v.Run()
+	return v, err
+}
@@ -0,0 +1,182 @@
+package neotest_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/neotest"
+	"github.com/nspcc-dev/neo-go/pkg/neotest/chain"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+const ownedSrc = `package owned
+	import (
+		"github.com/nspcc-dev/neo-go/pkg/interop"
+		"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+		"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+	)
+	const ownerKey = "owner"
+	func _deploy(data interface{}, isUpdate bool) {
+		if !isUpdate {
+			storage.Put(storage.GetContext(), ownerKey, data.(interop.Hash160))
+		}
+	}
+	func SetValue(v int) bool {
+		ctx := storage.GetContext()
+		owner := storage.Get(ctx, ownerKey).(interop.Hash160)
+		if !runtime.CheckWitness(owner) {
+			panic("not an owner")
+		}
+		storage.Put(ctx, "value", v)
+		runtime.Notify("Set", owner, v)
+		return true
+	}
+	func GetValue() int {
+		return storage.Get(storage.GetReadOnlyContext(), "value").(int)
+	}`
+
+func compileOwned(t *testing.T, e *neotest.Executor) *neotest.Contract {
+	return neotest.CompileSource(t, e.Validator.ScriptHash(), strings.NewReader(ownedSrc), &compiler.Options{
+		Name: "owned",
+		ContractEvents: []manifest.Event{{
+			Name: "Set",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("owner", smartcontract.Hash160Type),
+				manifest.NewParameter("value", smartcontract.IntegerType),
+			},
+		}},
+	})
+}
+
+func TestExecutor(t *testing.T) {
+	bc, acc := chain.NewSingle(t)
+	e := neotest.NewExecutor(t, bc, acc, acc)
+
+	owner := e.NewAccount(t)
+	e.CheckGASBalance(t, owner.ScriptHash(), big.NewInt(neotest.DefaultAccountGAS))
+
+	c := compileOwned(t, e)
+	e.DeployContract(t, c, owner.ScriptHash())
+	require.NotNil(t, bc.GetContractState(c.Hash))
+
+	inv := e.NewInvoker(c.Hash, owner)
+	h := inv.Invoke(t, true, "setValue", 42)
+	e.CheckTxNotificationEvent(t, h, 0, state.NotificationEvent{
+		ScriptHash: c.Hash,
+		Name:       "Set",
+		Item: stackitem.NewArray([]stackitem.Item{
+			stackitem.NewByteArray(owner.ScriptHash().BytesBE()),
+			stackitem.Make(42),
+		}),
+	})
+
+	tx, _ := e.GetTransaction(t, h)
+	e.CheckGASConsumed(t, h, tx.SystemFee)
+	e.EnsureGASBalance(t, owner.ScriptHash(), func(balance *big.Int) bool {
+		fee := big.NewInt(tx.SystemFee + tx.NetworkFee)
+		return balance.Cmp(new(big.Int).Sub(big.NewInt(neotest.DefaultAccountGAS), fee)) == 0
+	})
+	inv.Invoke(t, 42, "getValue")
+
+	t.Run("another signer", func(t *testing.T) {
+		acc := e.NewAccount(t)
+		inv.WithSigners(acc).InvokeFail(t, "not an owner", "setValue", 1)
+		inv.WithSigners(acc).CheckInvokeFail(t, "not an owner", "setValue", 1)
+		inv.Invoke(t, 42, "getValue")
+	})
+	t.Run("test invocation", func(t *testing.T) {
+		height := bc.BlockHeight()
+		s, err := inv.TestInvoke(t, "setValue", 7)
+		require.NoError(t, err)
+		require.Equal(t, 1, s.Len())
+		require.Equal(t, stackitem.NewBool(true), s.Pop().Item())
+		require.Equal(t, height, bc.BlockHeight())
+		inv.Invoke(t, 42, "getValue")
+	})
+	t.Run("invalid sender", func(t *testing.T) {
+		other := e.NewAccount(t)
+		c := neotest.CompileSource(t, other.ScriptHash(), strings.NewReader(ownedSrc), &compiler.Options{
+			Name:          "owned",
+			NoEventsCheck: true,
+		})
+		e.DeployContractBy(t, other, c, owner.ScriptHash())
+		e.DeployContractCheckFAULT(t, c, owner.ScriptHash(), "contract already exists")
+	})
+}
+
+func TestMultiValidators(t *testing.T) {
+	bc, validators, committee := chain.NewMulti(t)
+	e := neotest.NewExecutor(t, bc, validators, committee)
+	require.NotEqual(t, validators.ScriptHash(), e.CommitteeHash)
+
+	policyHash := e.NativeHash(t, nativenames.Policy)
+	e.ValidatorInvoker(policyHash).InvokeFail(t, "invalid committee signature", "setFeePerByte", 100)
+	e.CommitteeInvoker(policyHash).Invoke(t, stackitem.Null{}, "setFeePerByte", 100)
+	e.CommitteeInvoker(policyHash).Invoke(t, 100, "getFeePerByte")
+
+	blocks := e.GenerateNewBlocks(t, 2)
+	require.Equal(t, blocks[1], e.TopBlock(t))
+	require.Equal(t, blocks[0].Hash(), e.GetBlockByIndex(t, int(blocks[0].Index)).Hash())
+}
+
+func TestCustomConfig(t *testing.T) {
+	bc, validators, committee := chain.NewMultiWithCustomConfig(t, func(cfg *config.ProtocolConfiguration) {
+		cfg.ValidatorsCount = 1
+		cfg.StateRootInHeader = true
+	})
+	require.Equal(t, 1, len(bc.GetStandByValidators()))
+
+	e := neotest.NewExecutor(t, bc, validators, committee)
+	e.AddNewBlock(t)
+	require.True(t, e.TopBlock(t).StateRootEnabled)
+}
+
+func TestCompileFile(t *testing.T) {
+	bc, acc := chain.NewSingle(t)
+	e := neotest.NewExecutor(t, bc, acc, acc)
+
+	c := neotest.CompileFile(t, e.CommitteeHash, "../../examples/storage", "../../examples/storage/storage.yml")
+	require.Equal(t, "Storage example", c.Manifest.Name)
+	require.True(t, c == neotest.CompileFile(t, e.CommitteeHash, "../../examples/storage", "../../examples/storage/storage.yml"))
+	e.DeployContract(t, c, nil)
+
+	inv := e.CommitteeInvoker(c.Hash)
+	inv.Invoke(t, []byte("key"), "put", []byte("key"), []byte("value"))
+	inv.Invoke(t, []byte("value"), "get", []byte("key"))
+}
+
+func TestCoverage(t *testing.T) {
+	bc, acc := chain.NewSingle(t)
+	e := neotest.NewExecutor(t, bc, acc, acc)
+	cc := coverage.NewCollector()
+	e.EnableCoverage(cc)
+
+	c := neotest.CompileFile(t, e.CommitteeHash, "../../examples/storage", "../../examples/storage/storage.yml")
+	e.DeployContract(t, c, nil)
+	p := cc.Profile()
+	var src string
+	for _, f := range p.Files() {
+		if strings.HasSuffix(f, "examples/storage/storage.go") {
+			src = f
+		}
+	}
+	require.NotEmpty(t, src)
+	require.Equal(t, float64(0), p.Coverage(src))
+
+	inv := e.CommitteeInvoker(c.Hash)
+	inv.Invoke(t, []byte("key"), "put", []byte("key"), []byte("value"))
+	partial := cc.Profile().Coverage(src)
+	require.True(t, partial > 0)
+
+	inv.Invoke(t, []byte("value"), "get", []byte("key"))
+	require.True(t, cc.Profile().Coverage(src) > partial)
+}
@@ -0,0 +1,130 @@
+/*
+Package chain contains functions creating new test blockchain instances.
+Blockchains are created with the in-memory storage and are running until the
+end of the test they're created for.
+*/
+package chain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testchain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/neotest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	// MaxTraceableBlocks is the default MaxTraceableBlocks setting used for test chains.
+	MaxTraceableBlocks = 1000
+	// SecondsPerBlock is the default SecondsPerBlock setting used for test chains.
+	SecondsPerBlock = 1
+)
+
+// NewSingle creates new blockchain instance with a single validator (who's
+// also the only committee member) and returns it along with the validator
+// signer.
+func NewSingle(t *testing.T) (*core.Blockchain, neotest.Signer) {
+	return NewSingleWithCustomConfig(t, nil)
+}
+
+// NewSingleWithCustomConfig is similar to NewSingle, but allows to override
+// the default configuration.
+func NewSingleWithCustomConfig(t *testing.T, f func(*config.ProtocolConfiguration)) (*core.Blockchain, neotest.Signer) {
+	priv := testchain.PrivateKeyByID(0)
+	cfg := newConfig(keys.PublicKeys{priv.PublicKey()}, 1)
+	if f != nil {
+		f(&cfg)
+	}
+
+	bc := newChain(t, cfg)
+
+	acc := wallet.NewAccountFromPrivateKey(priv)
+	require.NoError(t, acc.ConvertMultisig(1, keys.PublicKeys{priv.PublicKey()}))
+	return bc, neotest.NewMultiSigner(acc)
+}
+
+// NewMulti creates new blockchain instance with four validators and six
+// committee members (the same ones unit test network uses), otherwise not
+// differing much from NewSingle. It returns validators and committee
+// multisignature signers.
+func NewMulti(t *testing.T) (*core.Blockchain, neotest.Signer, neotest.Signer) {
+	return NewMultiWithCustomConfig(t, nil)
+}
+
+// NewMultiWithCustomConfig is similar to NewMulti except it allows to override
+// the default configuration. Validators count can be changed and committee
+// can be reduced or reordered, but committee members must be taken from the
+// default committee as only their keys are known.
+func NewMultiWithCustomConfig(t *testing.T, f func(*config.ProtocolConfiguration)) (*core.Blockchain, neotest.Signer, neotest.Signer) {
+	privs := make(map[string]*keys.PrivateKey, testchain.CommitteeSize())
+	committee := make(keys.PublicKeys, testchain.CommitteeSize())
+	for i := range committee {
+		priv := testchain.PrivateKeyByID(i)
+		committee[i] = priv.PublicKey()
+		privs[hex.EncodeToString(committee[i].Bytes())] = priv
+	}
+	cfg := newConfig(committee, testchain.ValidatorsCount)
+	if f != nil {
+		f(&cfg)
+	}
+
+	committee = committee[:0]
+	for _, s := range cfg.StandbyCommittee {
+		priv, ok := privs[s]
+		require.True(t, ok, "unknown committee member %s", s)
+		committee = append(committee, priv.PublicKey())
+	}
+	require.True(t, 0 < cfg.ValidatorsCount && cfg.ValidatorsCount <= len(committee),
+		"invalid validators count")
+
+	bc := newChain(t, cfg)
+
+	validators := committee[:cfg.ValidatorsCount].Copy()
+	valSigner := newMultiSigner(t, privs, validators, smartcontract.GetDefaultHonestNodeCount(len(validators)))
+	comSigner := newMultiSigner(t, privs, committee, smartcontract.GetMajorityHonestNodeCount(len(committee)))
+	return bc, valSigner, comSigner
+}
+
+func newMultiSigner(t *testing.T, privs map[string]*keys.PrivateKey, pubs keys.PublicKeys, m int) neotest.Signer {
+	accs := make([]*wallet.Account, len(pubs))
+	for i, pub := range pubs {
+		accs[i] = wallet.NewAccountFromPrivateKey(privs[hex.EncodeToString(pub.Bytes())])
+	}
+	for _, acc := range accs {
+		require.NoError(t, acc.ConvertMultisig(m, pubs))
+	}
+	return neotest.NewMultiSigner(accs...)
+}
+
+func newConfig(committee keys.PublicKeys, validatorsCount int) config.ProtocolConfiguration {
+	cfg := config.ProtocolConfiguration{
+		Magic:              netmode.UnitTestNet,
+		MaxTraceableBlocks: MaxTraceableBlocks,
+		SecondsPerBlock:    SecondsPerBlock,
+		StandbyCommittee:   make([]string, len(committee)),
+		ValidatorsCount:    validatorsCount,
+		VerifyBlocks:       true,
+		VerifyTransactions: true,
+	}
+	for i := range committee {
+		cfg.StandbyCommittee[i] = hex.EncodeToString(committee[i].Bytes())
+	}
+	return cfg
+}
+
+func newChain(t *testing.T, cfg config.ProtocolConfiguration) *core.Blockchain {
+	bc, err := core.NewBlockchain(storage.NewMemoryStore(), cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	go bc.Run()
+	t.Cleanup(bc.Close)
+	return bc
+}
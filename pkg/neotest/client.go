@@ -0,0 +1,120 @@
+package neotest
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+// ContractInvoker is a client for specific contract.
+type ContractInvoker struct {
+	*Executor
+	Hash    util.Uint160
+	Signers []Signer
+}
+
+// NewInvoker creates new ContractInvoker for contract with hash h and specified signers.
+func (e *Executor) NewInvoker(h util.Uint160, signers ...Signer) *ContractInvoker {
+	return &ContractInvoker{
+		Executor: e,
+		Hash:     h,
+		Signers:  signers,
+	}
+}
+
+// CommitteeInvoker creates new ContractInvoker for contract with hash h and committee multisignature signer.
+func (e *Executor) CommitteeInvoker(h util.Uint160) *ContractInvoker {
+	return e.NewInvoker(h, e.Committee)
+}
+
+// ValidatorInvoker creates new ContractInvoker for contract with hash h and validators multisignature signer.
+func (e *Executor) ValidatorInvoker(h util.Uint160) *ContractInvoker {
+	return e.NewInvoker(h, e.Validator)
+}
+
+// WithSigners creates new client with the provided signer.
+func (c *ContractInvoker) WithSigners(signers ...Signer) *ContractInvoker {
+	newC := *c
+	newC.Signers = signers
+	return &newC
+}
+
+// TestInvoke creates test VM and invokes method with args without
+// persisting anything to the chain.
+func (c *ContractInvoker) TestInvoke(t *testing.T, method string, args ...interface{}) (*vm.Stack, error) {
+	tx := c.PrepareInvokeNoSign(t, method, args...)
+	for _, acc := range c.Signers {
+		tx.Signers = append(tx.Signers, transaction.Signer{
+			Account: acc.ScriptHash(),
+			Scopes:  transaction.Global,
+		})
+	}
+	v, err := TestInvoke(c.Chain, tx)
+	if err != nil {
+		return nil, err
+	}
+	return v.Estack(), nil
+}
+
+// PrepareInvoke creates new invocation transaction.
+func (c *ContractInvoker) PrepareInvoke(t *testing.T, method string, args ...interface{}) *transaction.Transaction {
+	return c.Executor.NewTx(t, c.Signers, c.Hash, method, args...)
+}
+
+// PrepareInvokeNoSign creates new unsigned invocation transaction.
+func (c *ContractInvoker) PrepareInvokeNoSign(t *testing.T, method string, args ...interface{}) *transaction.Transaction {
+	return c.Executor.NewUnsignedTx(t, c.Hash, method, args...)
+}
+
+// Invoke invokes method with args, persists transaction and checks the result.
+// Result is converted via stackitem.Make unless it's already an item.
+// Returns transaction hash.
+func (c *ContractInvoker) Invoke(t *testing.T, result interface{}, method string, args ...interface{}) util.Uint256 {
+	tx := c.PrepareInvoke(t, method, args...)
+	c.AddNewBlock(t, tx)
+	c.CheckHalt(t, tx.Hash(), stackitem.Make(result))
+	return tx.Hash()
+}
+
+// InvokeAndCheck invokes method with args, persists transaction and checks
+// the resulting stack using provided function. Returns transaction hash.
+func (c *ContractInvoker) InvokeAndCheck(t *testing.T, checkResult func(t *testing.T, stack []stackitem.Item),
+	method string, args ...interface{}) util.Uint256 {
+	tx := c.PrepareInvoke(t, method, args...)
+	c.AddNewBlock(t, tx)
+	aer := c.CheckHalt(t, tx.Hash())
+	if checkResult != nil {
+		checkResult(t, aer.Stack)
+	}
+	return tx.Hash()
+}
+
+// InvokeWithFeeFail is like InvokeFail but sets custom system fee for the transaction.
+func (c *ContractInvoker) InvokeWithFeeFail(t *testing.T, message string, sysFee int64, method string, args ...interface{}) util.Uint256 {
+	tx := c.PrepareInvokeNoSign(t, method, args...)
+	c.Executor.SignTx(t, tx, sysFee, c.Signers...)
+	c.AddNewBlock(t, tx)
+	c.CheckFault(t, tx.Hash(), message)
+	return tx.Hash()
+}
+
+// InvokeFail invokes method with args, persists transaction and checks the
+// error message. Returns transaction hash.
+func (c *ContractInvoker) InvokeFail(t *testing.T, message string, method string, args ...interface{}) util.Uint256 {
+	tx := c.PrepareInvoke(t, method, args...)
+	c.AddNewBlock(t, tx)
+	c.CheckFault(t, tx.Hash(), message)
+	return tx.Hash()
+}
+
+// CheckInvokeFail is like InvokeFail, but it doesn't persist anything to the
+// chain, the method is only test-invoked.
+func (c *ContractInvoker) CheckInvokeFail(t *testing.T, message string, method string, args ...interface{}) {
+	_, err := c.TestInvoke(t, method, args...)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), message)
+}
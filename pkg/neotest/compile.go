@@ -0,0 +1,94 @@
+package neotest
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/cli/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+// Contract contains contract info for deployment.
+type Contract struct {
+	Hash      util.Uint160
+	NEF       *nef.File
+	Manifest  *manifest.Manifest
+	DebugInfo *compiler.DebugInfo
+}
+
+type contractKey struct {
+	sender util.Uint160
+	path   string
+}
+
+var (
+	// contracts caches compiled contracts from FS across multiple tests.
+	contracts   = make(map[contractKey]*Contract)
+	contractsMu sync.Mutex
+)
+
+// CompileSource compiles contract from reader and returns it's NEF, manifest
+// and hash for the given sender. Options must contain at least contract name
+// for the manifest.
+func CompileSource(t *testing.T, sender util.Uint160, src io.Reader, opts *compiler.Options) *Contract {
+	ne, di, err := compiler.CompileWithDebugInfo(opts.Name, src)
+	require.NoError(t, err)
+
+	m, err := compiler.CreateManifest(di, opts)
+	require.NoError(t, err)
+
+	return &Contract{
+		Hash:      state.CreateContractHash(sender, ne.Checksum, m.Name),
+		NEF:       ne,
+		Manifest:  m,
+		DebugInfo: di,
+	}
+}
+
+// CompileFile compiles contract from file (or directory) and returns it's NEF,
+// manifest and hash for the given sender. Contract configuration (name,
+// events, permissions and so on) is read from the configPath YAML file.
+// Compiled contracts are cached, so it's cheap to compile the same contract
+// in multiple tests.
+func CompileFile(t *testing.T, sender util.Uint160, srcPath string, configPath string) *Contract {
+	contractsMu.Lock()
+	defer contractsMu.Unlock()
+
+	key := contractKey{sender: sender, path: srcPath}
+	if c, ok := contracts[key]; ok {
+		return c
+	}
+
+	ne, di, err := compiler.CompileWithDebugInfo(srcPath, nil)
+	require.NoError(t, err)
+
+	conf, err := smartcontract.ParseContractConfig(configPath)
+	require.NoError(t, err)
+
+	o := &compiler.Options{}
+	o.Name = conf.Name
+	o.ContractEvents = conf.Events
+	o.ContractSupportedStandards = conf.SupportedStandards
+	o.SafeMethods = conf.SafeMethods
+	o.Permissions = conf.GetPermissions()
+	o.Trusts = conf.GetTrusts()
+	o.Groups, err = conf.GetGroups()
+	require.NoError(t, err)
+	m, err := compiler.CreateManifest(di, o)
+	require.NoError(t, err)
+
+	c := &Contract{
+		Hash:      state.CreateContractHash(sender, ne.Checksum, m.Name),
+		NEF:       ne,
+		Manifest:  m,
+		DebugInfo: di,
+	}
+	contracts[key] = c
+	return c
+}
@@ -0,0 +1,49 @@
+/*
+Package neotest provides framework for automated contract testing.
+It can be used to test contracts written in any language (compiled to NEF),
+but it has some helpers for Go contracts compiled by the neo-go compiler.
+
+Contracts are executed in-process on a real core.Blockchain instance with
+in-memory storage, so tests can be run with plain `go test` and there is no
+need for any external node.
+
+Executor
+
+Executor is a wrapper over the blockchain instance that can create, sign and
+persist transactions and blocks. Chain instances along with the validators
+and committee signers are created using functions from the chain subpackage,
+NewSingle for a single-node setup and NewMulti for a four validators and six
+committee members setup (the same one that unit test network has):
+
+	bc, acc := chain.NewSingle(t)
+	e := neotest.NewExecutor(t, bc, acc, acc)
+
+Every transaction created by Executor is included into a separate block
+immediately, system fee is calculated via test invocation unless specified
+explicitly. Executor can also create new accounts funded with GAS
+(NewAccount) and check transaction execution results (CheckHalt, CheckFault,
+CheckTxNotificationEvent, CheckGASConsumed).
+
+Contracts
+
+Go contracts can be compiled with CompileFile (using the same YAML
+configuration file that `contract compile` command uses) or CompileSource.
+Contract hash depends on its sender, so sender is to be specified at the
+compilation stage (usually it's e.Validator.ScriptHash() or
+e.CommitteeHash):
+
+	c := neotest.CompileFile(t, e.Validator.ScriptHash(), "./contract", "./contract/neo-go.yml")
+	e.DeployContract(t, c, nil)
+
+ContractInvoker
+
+ContractInvoker is a client for the specific contract bound to some set of
+signers (the first one of them pays fees), it allows to invoke methods and
+check their results in a single line:
+
+	inv := e.NewInvoker(c.Hash, e.NewAccount(t))
+	inv.Invoke(t, true, "transfer", from, to, 10, nil)
+	inv.InvokeFail(t, "insufficient funds", "withdraw", 1000)
+	inv.WithSigners(e.Committee).Invoke(t, stackitem.Null{}, "setOwner", owner)
+*/
+package neotest
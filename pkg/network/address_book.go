@@ -0,0 +1,78 @@
+package network
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// addressBook is the persistent part of DefaultDiscovery state.
+type addressBook struct {
+	Addresses []string        `json:"addresses"`
+	Bans      []BannedAddress `json:"bans"`
+}
+
+// LoadAddressBook loads known addresses and bans from the file at the given
+// path (saved previously with SaveAddressBook). Addresses are added to the
+// pool of addresses to connect to, expired bans are ignored.
+func (d *DefaultDiscovery) LoadAddressBook(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var ab addressBook
+	if err := json.Unmarshal(data, &ab); err != nil {
+		return err
+	}
+	now := time.Now()
+	d.lock.Lock()
+	for _, b := range ab.Bans {
+		if now.Before(b.Until) {
+			d.bans[hostOf(b.Address)] = b
+		}
+	}
+	d.lock.Unlock()
+	d.BackFill(ab.Addresses...)
+	return nil
+}
+
+// SaveAddressBook saves known (good, connected and unconnected) addresses
+// and active bans to the file at the given path, expired bans are dropped.
+func (d *DefaultDiscovery) SaveAddressBook(path string) error {
+	ab := addressBook{
+		Bans: d.BannedPeers(),
+	}
+	d.lock.RLock()
+	known := make(map[string]bool, len(d.goodAddrs)+len(d.connectedAddrs)+len(d.unconnectedAddrs))
+	for addr := range d.goodAddrs {
+		known[addr] = true
+	}
+	for addr := range d.connectedAddrs {
+		known[addr] = true
+	}
+	for addr := range d.unconnectedAddrs {
+		known[addr] = true
+	}
+	for addr := range known {
+		if !d.isBanned(addr) {
+			ab.Addresses = append(ab.Addresses, addr)
+		}
+	}
+	d.lock.RUnlock()
+	sort.Strings(ab.Addresses)
+	sort.Slice(ab.Bans, func(i, j int) bool { return ab.Bans[i].Address < ab.Bans[j].Address })
+
+	data, err := json.MarshalIndent(ab, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to the temporary file first, so that the book is not corrupted
+	// if the node is stopped in the middle of the process.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
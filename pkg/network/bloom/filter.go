@@ -0,0 +1,111 @@
+/*
+Package bloom implements bloom filter used by light clients to request only
+the data they're interested in (transactions sent by particular accounts)
+from the nodes they're connected to. It's compatible with the C# node
+implementation, so the same filters can be used with both.
+*/
+package bloom
+
+import (
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+)
+
+// seedStep is the difference between seeds of hash functions used by the
+// filter.
+const seedStep = 0xFBA4C795
+
+// Filter is a bloom filter with k hash functions (MurmurHash3 with different
+// seeds) over a bit array. It's not safe for concurrent use.
+type Filter struct {
+	bits  []byte
+	m     uint32
+	k     uint8
+	tweak uint32
+}
+
+// New returns new empty filter with m bits (rounded up to the whole number
+// of bytes) and k hash functions, tweak is added to hash function seeds.
+func New(m int, k uint8, tweak uint32) (*Filter, error) {
+	if m <= 0 {
+		return nil, errors.New("filter size must be positive")
+	}
+	return NewFromBytes(make([]byte, (m+7)/8), k, tweak)
+}
+
+// NewFromBytes returns filter with the given bit array (every byte contains
+// 8 bits starting from the least significant one), k hash functions and tweak.
+// The bit array is copied.
+func NewFromBytes(b []byte, k uint8, tweak uint32) (*Filter, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty filter")
+	}
+	if k == 0 {
+		return nil, errors.New("no hash functions")
+	}
+	bs := make([]byte, len(b))
+	copy(bs, b)
+	return &Filter{
+		bits:  bs,
+		m:     uint32(len(b)) * 8,
+		k:     k,
+		tweak: tweak,
+	}, nil
+}
+
+// Bytes returns filter bit array.
+func (f *Filter) Bytes() []byte {
+	return f.bits
+}
+
+// K returns the number of hash functions used by the filter.
+func (f *Filter) K() uint8 {
+	return f.k
+}
+
+// Tweak returns filter tweak.
+func (f *Filter) Tweak() uint32 {
+	return f.tweak
+}
+
+// bit returns the index of the bit for the data and i-th hash function.
+func (f *Filter) bit(data []byte, i uint8) uint32 {
+	return murmur32(data, uint32(i)*seedStep+f.tweak) % f.m
+}
+
+// Add adds data to the filter.
+func (f *Filter) Add(data []byte) {
+	for i := uint8(0); i < f.k; i++ {
+		n := f.bit(data, i)
+		f.bits[n/8] |= 1 << (n % 8)
+	}
+}
+
+// Check returns true if the data may have been added to the filter and false
+// if it definitely wasn't.
+func (f *Filter) Check(data []byte) bool {
+	for i := uint8(0); i < f.k; i++ {
+		n := f.bit(data, i)
+		if f.bits[n/8]&(1<<(n%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchTransaction checks whether the transaction matches the filter, that is
+// the filter contains either its hash or the script hash of any of its
+// signers.
+func (f *Filter) MatchTransaction(tx *transaction.Transaction) bool {
+	h := tx.Hash()
+	if f.Check(h.BytesBE()) {
+		return true
+	}
+	for i := range tx.Signers {
+		if f.Check(tx.Signers[i].Account.BytesBE()) {
+			return true
+		}
+	}
+	return false
+}
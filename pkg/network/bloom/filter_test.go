@@ -0,0 +1,72 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(0, 1, 0)
+	require.Error(t, err)
+	_, err = New(8, 0, 0)
+	require.Error(t, err)
+	_, err = NewFromBytes(nil, 1, 0)
+	require.Error(t, err)
+
+	f, err := New(9, 3, 123)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(f.Bytes()))
+	require.Equal(t, uint8(3), f.K())
+	require.Equal(t, uint32(123), f.Tweak())
+}
+
+func TestFilterAddCheck(t *testing.T) {
+	f, err := New(1024, 5, 42)
+	require.NoError(t, err)
+
+	data := [][]byte{{}, {1, 2, 3}, random.Bytes(20), random.Bytes(32)}
+	for _, d := range data {
+		require.False(t, f.Check(d))
+		f.Add(d)
+		require.True(t, f.Check(d))
+	}
+
+	t.Run("restored", func(t *testing.T) {
+		restored, err := NewFromBytes(f.Bytes(), f.K(), f.Tweak())
+		require.NoError(t, err)
+		for _, d := range data {
+			require.True(t, restored.Check(d))
+		}
+	})
+	t.Run("different tweak", func(t *testing.T) {
+		other, err := NewFromBytes(f.Bytes(), f.K(), f.Tweak()+1)
+		require.NoError(t, err)
+		var matched int
+		for _, d := range data {
+			if other.Check(d) {
+				matched++
+			}
+		}
+		require.True(t, matched < len(data))
+	})
+}
+
+func TestFilterMatchTransaction(t *testing.T) {
+	tx := transaction.New([]byte{1}, 0)
+	tx.Signers = []transaction.Signer{{Account: random.Uint160()}, {Account: random.Uint160()}}
+
+	f, err := New(1024, 5, 0)
+	require.NoError(t, err)
+	require.False(t, f.MatchTransaction(tx))
+
+	f.Add(tx.Signers[1].Account.BytesBE())
+	require.True(t, f.MatchTransaction(tx))
+
+	f, err = New(1024, 5, 0)
+	require.NoError(t, err)
+	f.Add(tx.Hash().BytesBE())
+	require.True(t, f.MatchTransaction(tx))
+}
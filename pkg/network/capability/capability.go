@@ -26,9 +26,14 @@ func (cs *Capabilities) EncodeBinary(br *io.BinWriter) {
 // checkUniqueCapabilities checks whether payload capabilities have unique type.
 func (cs Capabilities) checkUniqueCapabilities() error {
 	err := errors.New("capabilities with the same type are not allowed")
-	var isFullNode, isTCP, isWS bool
+	var isFullNode, isTCP, isWS, isCompact bool
 	for _, cap := range cs {
 		switch cap.Type {
+		case CompactBlocks:
+			if isCompact {
+				return err
+			}
+			isCompact = true
 		case FullNode:
 			if isFullNode {
 				return err
@@ -63,6 +68,8 @@ func (c *Capability) DecodeBinary(br *io.BinReader) {
 		c.Data = &Node{}
 	case TCPServer, WSServer:
 		c.Data = &Server{}
+	case CompactBlocks:
+		c.Data = &Compact{}
 	default:
 		br.Err = errors.New("unknown node capability type")
 		return
@@ -110,3 +117,19 @@ func (s *Server) DecodeBinary(br *io.BinReader) {
 func (s *Server) EncodeBinary(bw *io.BinWriter) {
 	bw.WriteU16LE(s.Port)
 }
+
+// Compact represents compact block relay capability with the version of
+// compact block protocol supported
+type Compact struct {
+	Version byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *Compact) DecodeBinary(br *io.BinReader) {
+	c.Version = br.ReadB()
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *Compact) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteB(c.Version)
+}
@@ -10,4 +10,7 @@ const (
 	WSServer Type = 0x02
 	// FullNode represents full node capability type
 	FullNode Type = 0x10
+	// CompactBlocks represents compact block relay capability type (neo-go
+	// extension)
+	CompactBlocks Type = 0xf0
 )
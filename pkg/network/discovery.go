@@ -1,6 +1,7 @@
 package network
 
 import (
+	"net"
 	"sync"
 	"time"
 
@@ -10,6 +11,12 @@ import (
 const (
 	maxPoolSize = 200
 	connRetries = 3
+
+	// BanScore is the misbehaviour score peer is banned at.
+	BanScore = 100
+	// ScoreDecayInterval is the time it takes for one misbehaviour score
+	// point to expire.
+	ScoreDecayInterval = time.Minute
 )
 
 // Discoverer is an interface that is responsible for maintaining
@@ -26,6 +33,13 @@ type Discoverer interface {
 	UnconnectedPeers() []string
 	BadPeers() []string
 	GoodPeers() []AddressWithCapabilities
+	Penalize(string, int) bool
+	Ban(string, time.Duration, string)
+	Unban(string) bool
+	IsBanned(string) bool
+	BannedPeers() []BannedAddress
+	LoadAddressBook(string) error
+	SaveAddressBook(string) error
 }
 
 // AddressWithCapabilities represents node address with its capabilities
@@ -34,6 +48,20 @@ type AddressWithCapabilities struct {
 	Capabilities capability.Capabilities
 }
 
+// peerScore is the misbehaviour score of the host.
+type peerScore struct {
+	score   int
+	updated time.Time
+}
+
+// BannedAddress represents banned host (all of its ports are banned) with
+// the ban reason and expiration time.
+type BannedAddress struct {
+	Address string    `json:"address"`
+	Reason  string    `json:"reason"`
+	Until   time.Time `json:"until"`
+}
+
 // DefaultDiscovery default implementation of the Discoverer interface.
 type DefaultDiscovery struct {
 	seeds            []string
@@ -49,6 +77,9 @@ type DefaultDiscovery struct {
 	isDead           bool
 	requestCh        chan int
 	pool             chan string
+	// scores and bans are indexed by host.
+	scores map[string]peerScore
+	bans   map[string]BannedAddress
 }
 
 // NewDefaultDiscovery returns a new DefaultDiscovery.
@@ -62,6 +93,8 @@ func NewDefaultDiscovery(addrs []string, dt time.Duration, ts Transporter) *Defa
 		goodAddrs:        make(map[string]capability.Capabilities),
 		unconnectedAddrs: make(map[string]int),
 		attempted:        make(map[string]bool),
+		scores:           make(map[string]peerScore),
+		bans:             make(map[string]BannedAddress),
 		requestCh:        make(chan int),
 		pool:             make(chan string, maxPoolSize),
 	}
@@ -79,7 +112,7 @@ func (d *DefaultDiscovery) BackFill(addrs ...string) {
 	d.lock.Lock()
 	for _, addr := range addrs {
 		if d.badAddrs[addr] || d.connectedAddrs[addr] ||
-			d.unconnectedAddrs[addr] > 0 {
+			d.unconnectedAddrs[addr] > 0 || d.isBanned(addr) {
 			continue
 		}
 		d.unconnectedAddrs[addr] = connRetries
@@ -156,6 +189,9 @@ func (d *DefaultDiscovery) GoodPeers() []AddressWithCapabilities {
 	d.lock.RLock()
 	addrs := make([]AddressWithCapabilities, 0, len(d.goodAddrs))
 	for addr, cap := range d.goodAddrs {
+		if d.isBanned(addr) {
+			continue
+		}
 		addrs = append(addrs, AddressWithCapabilities{
 			Address:      addr,
 			Capabilities: cap,
@@ -190,6 +226,106 @@ func (d *DefaultDiscovery) RegisterConnectedAddr(addr string) {
 	d.lock.Unlock()
 }
 
+// Penalize adds the given score to the misbehaviour score of the address
+// host and returns true if it reaches BanScore (the score is reset then and
+// the host is expected to be banned).
+func (d *DefaultDiscovery) Penalize(addr string, score int) bool {
+	host := hostOf(addr)
+	now := time.Now()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	ps := d.scores[host]
+	decay := int(now.Sub(ps.updated) / ScoreDecayInterval)
+	if decay < ps.score {
+		ps.score -= decay
+		ps.updated = ps.updated.Add(time.Duration(decay) * ScoreDecayInterval)
+	} else {
+		ps.score = 0
+		ps.updated = now
+	}
+	ps.score += score
+	if ps.score < BanScore {
+		d.scores[host] = ps
+		return false
+	}
+	delete(d.scores, host)
+	return true
+}
+
+// Ban bans the address host for the given duration, banned hosts are not
+// connected to and not advertised to other peers.
+func (d *DefaultDiscovery) Ban(addr string, dur time.Duration, reason string) {
+	host := hostOf(addr)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.bans[host] = BannedAddress{
+		Address: host,
+		Reason:  reason,
+		Until:   time.Now().Add(dur),
+	}
+	delete(d.scores, host)
+	for a := range d.unconnectedAddrs {
+		if hostOf(a) == host {
+			delete(d.unconnectedAddrs, a)
+		}
+	}
+	for a := range d.goodAddrs {
+		if hostOf(a) == host {
+			delete(d.goodAddrs, a)
+		}
+	}
+}
+
+// Unban removes the ban of the address host and returns false if it wasn't
+// banned.
+func (d *DefaultDiscovery) Unban(addr string) bool {
+	host := hostOf(addr)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	banned := d.isBanned(host)
+	delete(d.bans, host)
+	return banned
+}
+
+// IsBanned checks whether the address host is banned.
+func (d *DefaultDiscovery) IsBanned(addr string) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.isBanned(addr)
+}
+
+// isBanned checks whether the address host is banned, it must be called with
+// the lock held.
+func (d *DefaultDiscovery) isBanned(addr string) bool {
+	b, ok := d.bans[hostOf(addr)]
+	return ok && time.Now().Before(b.Until)
+}
+
+// BannedPeers returns all currently banned hosts, expired bans are removed.
+func (d *DefaultDiscovery) BannedPeers() []BannedAddress {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	res := make([]BannedAddress, 0, len(d.bans))
+	for host, b := range d.bans {
+		if !d.isBanned(host) {
+			delete(d.bans, host)
+			continue
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
+// hostOf returns the host part of the address (or the whole address if it
+// has no port).
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
 func (d *DefaultDiscovery) tryAddress(addr string) {
 	err := d.transport.Dial(addr, d.dialTimeout)
 	d.lock.Lock()
@@ -233,7 +369,7 @@ func (d *DefaultDiscovery) run() {
 			case addr := <-d.pool:
 				updatePoolCountMetric(d.PoolCount())
 				d.lock.Lock()
-				if !d.connectedAddrs[addr] && !d.attempted[addr] {
+				if !d.connectedAddrs[addr] && !d.attempted[addr] && !d.isBanned(addr) {
 					d.attempted[addr] = true
 					go d.tryAddress(addr)
 					requested--
@@ -243,7 +379,7 @@ func (d *DefaultDiscovery) run() {
 				var added int
 				d.lock.Lock()
 				for _, addr := range d.seeds {
-					if !d.connectedAddrs[addr] {
+					if !d.connectedAddrs[addr] && !d.isBanned(addr) {
 						delete(d.badAddrs, addr)
 						d.unconnectedAddrs[addr] = connRetries
 						d.pushToPoolOrDrop(addr)
@@ -1,8 +1,12 @@
 package network
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"sort"
 	"sync/atomic"
 	"testing"
@@ -209,3 +213,107 @@ func TestSeedDiscovery(t *testing.T) {
 		}
 	}
 }
+
+func TestDefaultDiscoveryBans(t *testing.T) {
+	ts := &fakeTransp{}
+	ts.dialCh = make(chan string)
+	d := NewDefaultDiscovery(nil, time.Second/2, ts)
+	t.Cleanup(d.Close)
+
+	d.RegisterGoodAddr("1.1.1.1:10333", nil)
+	d.BackFill("1.1.1.1:20333", "2.2.2.2:10333")
+	require.Equal(t, 2, len(d.UnconnectedPeers()))
+
+	require.False(t, d.Penalize("1.1.1.1:10333", BanScore-1))
+	require.True(t, d.Penalize("1.1.1.1:20333", 1))
+	// The score is reset.
+	require.False(t, d.Penalize("1.1.1.1:10333", BanScore-1))
+
+	d.Ban("1.1.1.1:10333", time.Hour, "test")
+	require.True(t, d.IsBanned("1.1.1.1"))
+	require.True(t, d.IsBanned("1.1.1.1:42"))
+	require.False(t, d.IsBanned("2.2.2.2:10333"))
+	banned := d.BannedPeers()
+	require.Equal(t, 1, len(banned))
+	require.Equal(t, "1.1.1.1", banned[0].Address)
+	require.Equal(t, "test", banned[0].Reason)
+
+	// Banned addresses are forgotten and not added again.
+	require.Equal(t, []string{"2.2.2.2:10333"}, d.UnconnectedPeers())
+	require.Equal(t, 0, len(d.GoodPeers()))
+	d.BackFill("1.1.1.1:30333")
+	require.Equal(t, []string{"2.2.2.2:10333"}, d.UnconnectedPeers())
+
+	require.True(t, d.Unban("1.1.1.1"))
+	require.False(t, d.Unban("1.1.1.1"))
+	require.False(t, d.IsBanned("1.1.1.1:10333"))
+
+	t.Run("expiration", func(t *testing.T) {
+		d.Ban("3.3.3.3", time.Millisecond, "test")
+		require.Eventually(t, func() bool { return !d.IsBanned("3.3.3.3") }, time.Second, time.Millisecond)
+		require.Equal(t, 0, len(d.BannedPeers()))
+		d.lock.RLock()
+		require.Equal(t, 0, len(d.bans))
+		d.lock.RUnlock()
+	})
+	t.Run("decay", func(t *testing.T) {
+		d.Penalize("4.4.4.4", BanScore-1)
+		d.lock.Lock()
+		ps := d.scores["4.4.4.4"]
+		ps.updated = ps.updated.Add(-ScoreDecayInterval)
+		d.scores["4.4.4.4"] = ps
+		d.lock.Unlock()
+		require.False(t, d.Penalize("4.4.4.4", 1))
+		require.True(t, d.Penalize("4.4.4.4", 1))
+	})
+}
+
+func TestAddressBook(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "neogo.addressbook")
+	require.NoError(t, os.Mkdir(dir, os.ModePerm))
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "book.json")
+
+	ts := &fakeTransp{}
+	ts.dialCh = make(chan string)
+	d := NewDefaultDiscovery(nil, time.Second/2, ts)
+	t.Cleanup(d.Close)
+	d.RegisterGoodAddr("1.1.1.1:10333", nil)
+	d.BackFill("2.2.2.2:10333", "3.3.3.3:10333")
+	d.Ban("3.3.3.3", time.Hour, "test")
+	d.Ban("4.4.4.4", time.Hour, "another test")
+	d.Ban("5.5.5.5", -time.Hour, "expired")
+	require.NoError(t, d.SaveAddressBook(path))
+
+	d2 := NewDefaultDiscovery(nil, time.Second/2, ts)
+	t.Cleanup(d2.Close)
+	require.True(t, errors.Is(d2.LoadAddressBook(filepath.Join(dir, "unknown.json")), os.ErrNotExist))
+	require.NoError(t, d2.LoadAddressBook(path))
+	addrs := d2.UnconnectedPeers()
+	sort.Strings(addrs)
+	require.Equal(t, []string{"1.1.1.1:10333", "2.2.2.2:10333"}, addrs)
+	banned := d2.BannedPeers()
+	sort.Slice(banned, func(i, j int) bool { return banned[i].Address < banned[j].Address })
+	require.Equal(t, 2, len(banned))
+	require.Equal(t, "3.3.3.3", banned[0].Address)
+	require.Equal(t, "test", banned[0].Reason)
+	require.Equal(t, "4.4.4.4", banned[1].Address)
+	require.True(t, d2.IsBanned("4.4.4.4:10333"))
+
+	t.Run("expired bans are not loaded", func(t *testing.T) {
+		data, err := json.Marshal(addressBook{Bans: []BannedAddress{
+			{Address: "5.5.5.5", Reason: "expired", Until: time.Now().Add(-time.Hour)},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path, data, 0644))
+		d3 := NewDefaultDiscovery(nil, time.Second/2, ts)
+		t.Cleanup(d3.Close)
+		require.NoError(t, d3.LoadAddressBook(path))
+		d3.lock.RLock()
+		require.Equal(t, 0, len(d3.bans))
+		d3.lock.RUnlock()
+	})
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("not a json"), 0644))
+	require.Error(t, d2.LoadAddressBook(path))
+}
@@ -25,10 +25,31 @@ func New(bc blockchainer.Blockchainer) *Pool {
 }
 
 var (
+	// ErrInvalidWitness is returned for payloads with witness that can't be
+	// verified.
+	ErrInvalidWitness   = errors.New("invalid witness")
 	errDisallowedSender = errors.New("disallowed sender")
 	errInvalidHeight    = errors.New("invalid height")
 )
 
+// witnessError wraps witness verification error, it matches
+// ErrInvalidWitness.
+type witnessError struct {
+	err error
+}
+
+func (e witnessError) Error() string {
+	return ErrInvalidWitness.Error() + ": " + e.err.Error()
+}
+
+func (e witnessError) Unwrap() error {
+	return e.err
+}
+
+func (e witnessError) Is(target error) bool {
+	return target == ErrInvalidWitness
+}
+
 // Add adds extensible payload to the pool.
 // First return value specifies if payload was new.
 // Second one is nil if and only if payload is valid.
@@ -50,7 +71,7 @@ func (p *Pool) Add(e *payload.Extensible) (bool, error) {
 
 func (p *Pool) verify(e *payload.Extensible) (bool, error) {
 	if err := p.chain.VerifyWitness(e.Sender, e, &e.Witness, extensibleVerifyMaxGAS); err != nil {
-		return false, err
+		return false, witnessError{err}
 	}
 	h := p.chain.BlockHeight()
 	if h < e.ValidBlockStart || e.ValidBlockEnd <= h {
@@ -20,6 +20,8 @@ func TestAddGet(t *testing.T) {
 	t.Run("invalid witness", func(t *testing.T) {
 		ep := &payload.Extensible{ValidBlockEnd: 100, Sender: util.Uint160{0x42}}
 		p.testAdd(t, false, errVerification, ep)
+		_, err := p.Add(ep)
+		require.True(t, errors.Is(err, ErrInvalidWitness))
 	})
 	t.Run("disallowed sender", func(t *testing.T) {
 		ep := &payload.Extensible{ValidBlockEnd: 100, Sender: util.Uint160{0x41}}
@@ -10,8 +10,10 @@ import (
 
 	"github.com/nspcc-dev/neo-go/internal/fakechain"
 	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
@@ -23,6 +25,8 @@ type testDiscovery struct {
 	connected    []string
 	unregistered []string
 	backfill     []string
+	scores       map[string]int
+	bans         map[string]BannedAddress
 }
 
 func newTestDiscovery([]string, time.Duration, Transporter) Discoverer { return new(testDiscovery) }
@@ -62,6 +66,47 @@ func (d *testDiscovery) BadPeers() []string {
 	return d.bad
 }
 func (d *testDiscovery) GoodPeers() []AddressWithCapabilities { return []AddressWithCapabilities{} }
+func (d *testDiscovery) Penalize(addr string, score int) bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.scores == nil {
+		d.scores = make(map[string]int)
+	}
+	d.scores[hostOf(addr)] += score
+	return d.scores[hostOf(addr)] >= BanScore
+}
+func (d *testDiscovery) Ban(addr string, dur time.Duration, reason string) {
+	d.Lock()
+	defer d.Unlock()
+	if d.bans == nil {
+		d.bans = make(map[string]BannedAddress)
+	}
+	d.bans[hostOf(addr)] = BannedAddress{Address: hostOf(addr), Reason: reason, Until: time.Now().Add(dur)}
+}
+func (d *testDiscovery) Unban(addr string) bool {
+	d.Lock()
+	defer d.Unlock()
+	_, ok := d.bans[hostOf(addr)]
+	delete(d.bans, hostOf(addr))
+	return ok
+}
+func (d *testDiscovery) IsBanned(addr string) bool {
+	d.Lock()
+	defer d.Unlock()
+	_, ok := d.bans[hostOf(addr)]
+	return ok
+}
+func (d *testDiscovery) BannedPeers() []BannedAddress {
+	d.Lock()
+	defer d.Unlock()
+	res := make([]BannedAddress, 0, len(d.bans))
+	for _, b := range d.bans {
+		res = append(res, b)
+	}
+	return res
+}
+func (d *testDiscovery) LoadAddressBook(string) error { return nil }
+func (d *testDiscovery) SaveAddressBook(string) error { return nil }
 
 var defaultMessageHandler = func(t *testing.T, msg *Message) {}
 
@@ -76,7 +121,9 @@ type localPeer struct {
 	messageHandler func(t *testing.T, msg *Message)
 	pingSent       int
 	getAddrSent    int
+	getDataSent    map[util.Uint256]bool
 	droppedWith    atomic.Value
+	filter         *bloom.Filter
 }
 
 func newLocalPeer(t *testing.T, s *Server) *localPeer {
@@ -186,6 +233,30 @@ func (p *localPeer) CanProcessAddr() bool {
 	p.getAddrSent--
 	return p.getAddrSent >= 0
 }
+func (p *localPeer) AddGetDataSent(hashes []util.Uint256) {
+	if p.getDataSent == nil {
+		p.getDataSent = make(map[util.Uint256]bool)
+	}
+	for _, h := range hashes {
+		p.getDataSent[h] = true
+	}
+}
+func (p *localPeer) CanProcessNotFound(hashes []util.Uint256) bool {
+	var requested bool
+	for _, h := range hashes {
+		if p.getDataSent[h] {
+			delete(p.getDataSent, h)
+			requested = true
+		}
+	}
+	return requested
+}
+func (p *localPeer) Filter() *bloom.Filter {
+	return p.filter
+}
+func (p *localPeer) SetFilter(f *bloom.Filter) {
+	p.filter = f
+}
 
 func newTestServer(t *testing.T, serverConfig ServerConfig) *Server {
 	s, err := newServerFromConstructors(serverConfig, fakechain.NewFakeChain(), zaptest.NewLogger(t),
@@ -0,0 +1,405 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// memoryQueueSize is the number of written and not yet delivered packets
+// memory connection can hold before blocking the writer.
+const memoryQueueSize = 256
+
+var (
+	errMemoryUnreachable = errors.New("address is unreachable")
+	errMemoryAddrInUse   = errors.New("address is already in use")
+)
+
+// MemoryNetwork connects servers using MemoryTransport within a single
+// process. It allows to simulate network conditions like latency, packet loss
+// and partitioning. Random decisions (like which packets to drop) are made
+// by every connection using its own pseudo-random generator seeded with the
+// network seed and connection addresses, so they're reproducible for the same
+// sequence of packets sent over the connection no matter what happens with
+// the other ones. It's safe for concurrent use.
+type MemoryNetwork struct {
+	lock      sync.RWMutex
+	listeners map[string]*MemoryTransport
+	nextPort  int
+	latency   time.Duration
+	lossRate  float64
+	seed      int64
+	// groups maps addresses to partition groups, addresses from different
+	// groups can't communicate (not listed ones are in group 0).
+	groups map[string]int
+	// changed is closed (and replaced) when partitioning changes.
+	changed chan struct{}
+}
+
+// MemoryTransport is Transporter implementation for the MemoryNetwork.
+type MemoryTransport struct {
+	network  *MemoryNetwork
+	server   *Server
+	bindAddr string
+
+	lock sync.RWMutex
+	addr string
+	quit chan struct{}
+}
+
+// memoryAddr is the net.Addr of the memory network node.
+type memoryAddr string
+
+// memoryConn is one side of the in-memory connection, it delivers written
+// packets to the other side with the network's latency unless they're lost
+// or the other side is behind the partition.
+type memoryConn struct {
+	net.Conn
+	network *MemoryNetwork
+	local   memoryAddr
+	remote  memoryAddr
+
+	randLock sync.Mutex
+	rand     *rand.Rand
+
+	queue     chan memoryPacket
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+type memoryPacket struct {
+	data []byte
+	at   time.Time
+}
+
+// NewMemoryNetwork returns new memory network with no latency, packet loss
+// and partitions using the given seed for random decisions.
+func NewMemoryNetwork(seed int64) *MemoryNetwork {
+	return &MemoryNetwork{
+		listeners: make(map[string]*MemoryTransport),
+		nextPort:  1,
+		seed:      seed,
+		groups:    make(map[string]int),
+		changed:   make(chan struct{}),
+	}
+}
+
+// SetLatency sets the delay for packets sent after this call.
+func (n *MemoryNetwork) SetLatency(d time.Duration) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.latency = d
+}
+
+// SetLossRate sets the probability (from 0 to 1) of losing every single
+// packet (that is a whole network message) sent after this call.
+func (n *MemoryNetwork) SetLossRate(r float64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.lossRate = r
+}
+
+// Partition splits the network into the given groups of addresses, nodes from
+// different groups can't establish new connections (dialing fails after the
+// timeout) and packets sent over the existing ones are not delivered until
+// partitioning is removed (just like TCP retransmits them). Addresses not
+// mentioned form one more group.
+func (n *MemoryNetwork) Partition(groups ...[]string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.groups = make(map[string]int)
+	for i, g := range groups {
+		for _, addr := range g {
+			n.groups[addr] = i + 1
+		}
+	}
+	close(n.changed)
+	n.changed = make(chan struct{})
+}
+
+// Heal removes network partitioning.
+func (n *MemoryNetwork) Heal() {
+	n.Partition()
+}
+
+// reachable checks whether nodes with the given addresses can communicate,
+// the channel returned is closed when it can change.
+func (n *MemoryNetwork) reachable(a, b memoryAddr) (bool, <-chan struct{}) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	return n.groups[string(a)] == n.groups[string(b)], n.changed
+}
+
+// route returns packet delivery time or false if it's lost. The loss
+// decision is made using the given function returning random numbers.
+func (n *MemoryNetwork) route(random func() float64) (time.Time, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	if n.lossRate > 0 && random() < n.lossRate {
+		return time.Time{}, false
+	}
+	return time.Now().Add(n.latency), true
+}
+
+// connSeed returns the seed for random decisions made by the connection from
+// the local address to the remote one.
+func (n *MemoryNetwork) connSeed(local, remote memoryAddr) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(local + ">" + remote))
+	return n.seed ^ int64(h.Sum64())
+}
+
+// listen registers the transport at the given address, zero port is replaced
+// with some unused one.
+func (n *MemoryNetwork) listen(t *MemoryTransport, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if port == "0" || port == "" {
+		for {
+			addr = net.JoinHostPort(host, strconv.Itoa(n.nextPort))
+			n.nextPort++
+			if n.listeners[addr] == nil {
+				break
+			}
+		}
+	}
+	if n.listeners[addr] != nil {
+		return "", fmt.Errorf("%w: %s", errMemoryAddrInUse, addr)
+	}
+	n.listeners[addr] = t
+	return addr, nil
+}
+
+func (n *MemoryNetwork) unlisten(addr string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	delete(n.listeners, addr)
+}
+
+// dial connects the transport to the one listening at the given address and
+// returns local connection side.
+func (n *MemoryNetwork) dial(from *MemoryTransport, addr string, timeout time.Duration) (net.Conn, error) {
+	n.lock.RLock()
+	remote := n.listeners[addr]
+	n.lock.RUnlock()
+	local := memoryAddr(from.Address())
+	if local == "" {
+		// Not listening yet, but the address is known.
+		local = memoryAddr(from.bindAddr)
+	}
+	if remote == nil {
+		return nil, fmt.Errorf("%w: %s", errMemoryUnreachable, addr)
+	}
+	if ok, _ := n.reachable(local, memoryAddr(addr)); !ok {
+		// Like TCP connection to the host behind the partition, it fails
+		// only after the timeout.
+		timer := time.NewTimer(timeout)
+		select {
+		case <-timer.C:
+		case <-from.quit:
+			timer.Stop()
+		}
+		return nil, fmt.Errorf("%w: %s", errMemoryUnreachable, addr)
+	}
+	c1, c2 := net.Pipe()
+	lc := newMemoryConn(n, c1, local, memoryAddr(addr))
+	rc := newMemoryConn(n, c2, memoryAddr(addr), local)
+	p := NewTCPPeer(rc, remote.server)
+	go p.handleConn()
+	return lc, nil
+}
+
+// NewMemoryTransport returns a new MemoryTransport for the server that will
+// accept connections at the given address in the given memory network.
+// Address must be in the "host:port" form, zero port means some unused one.
+func NewMemoryTransport(n *MemoryNetwork, s *Server, bindAddr string) *MemoryTransport {
+	return &MemoryTransport{
+		network:  n,
+		server:   s,
+		bindAddr: bindAddr,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Dial implements the Transporter interface.
+func (t *MemoryTransport) Dial(addr string, timeout time.Duration) error {
+	conn, err := t.network.dial(t, addr, timeout)
+	if err != nil {
+		return err
+	}
+	p := NewTCPPeer(conn, t.server)
+	go p.handleConn()
+	return nil
+}
+
+// Accept implements the Transporter interface, it registers the transport in
+// the network and waits for it to be closed.
+func (t *MemoryTransport) Accept() {
+	addr, err := t.network.listen(t, t.bindAddr)
+	if err != nil {
+		t.server.log.Panic("memory transport listen error", zap.Error(err))
+		return
+	}
+	t.lock.Lock()
+	t.addr = addr
+	t.lock.Unlock()
+	<-t.quit
+	t.network.unlisten(addr)
+}
+
+// Close implements the Transporter interface.
+func (t *MemoryTransport) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	select {
+	case <-t.quit:
+	default:
+		close(t.quit)
+	}
+}
+
+// Proto implements the Transporter interface.
+func (t *MemoryTransport) Proto() string {
+	return "memory"
+}
+
+// Address implements the Transporter interface.
+func (t *MemoryTransport) Address() string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.addr
+}
+
+// Network implements net.Addr interface.
+func (a memoryAddr) Network() string {
+	return "memory"
+}
+
+// String implements net.Addr interface.
+func (a memoryAddr) String() string {
+	return string(a)
+}
+
+func newMemoryConn(n *MemoryNetwork, c net.Conn, local, remote memoryAddr) *memoryConn {
+	mc := &memoryConn{
+		Conn:    c,
+		network: n,
+		local:   local,
+		remote:  remote,
+		rand:    rand.New(rand.NewSource(n.connSeed(local, remote))),
+		queue:   make(chan memoryPacket, memoryQueueSize),
+		done:    make(chan struct{}),
+	}
+	go mc.deliver()
+	return mc
+}
+
+// deliver writes queued packets to the underlying connection when it's time
+// to do so.
+func (c *memoryConn) deliver() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case pkt := <-c.queue:
+			if d := time.Until(pkt.at); d > 0 {
+				timer := time.NewTimer(d)
+				select {
+				case <-c.done:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			for {
+				ok, changed := c.network.reachable(c.local, c.remote)
+				if ok {
+					break
+				}
+				select {
+				case <-c.done:
+					return
+				case <-changed:
+				}
+			}
+			if _, err := c.Conn.Write(pkt.data); err != nil {
+				_ = c.Close()
+				return
+			}
+		}
+	}
+}
+
+// Write implements net.Conn interface, it queues the data for delivery
+// (which can be delayed or not happen at all) and never blocks unless the
+// queue is full.
+func (c *memoryConn) Write(b []byte) (int, error) {
+	at, ok := c.network.route(c.random)
+	if !ok {
+		return len(b), nil
+	}
+	select {
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	data := make([]byte, len(b))
+	copy(data, b)
+	select {
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	case c.queue <- memoryPacket{data: data, at: at}:
+		return len(b), nil
+	}
+}
+
+// random returns the next pseudo-random number of the connection.
+func (c *memoryConn) random() float64 {
+	c.randLock.Lock()
+	defer c.randLock.Unlock()
+	return c.rand.Float64()
+}
+
+// Close implements net.Conn interface.
+func (c *memoryConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.Conn.Close()
+	})
+	return err
+}
+
+// LocalAddr implements net.Conn interface.
+func (c *memoryConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr implements net.Conn interface.
+func (c *memoryConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline implements net.Conn interface, it only sets read deadline as
+// writes never block for long.
+func (c *memoryConn) SetDeadline(t time.Time) error {
+	return c.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline implements net.Conn interface, it does nothing as writes
+// never block for long.
+func (c *memoryConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
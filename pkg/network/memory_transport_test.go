@@ -0,0 +1,172 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/fakechain"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newMemoryTestServer(t *testing.T, n *MemoryNetwork, addr string, seeds ...string) *Server {
+	cfg := ServerConfig{
+		UserAgent:         "/test/",
+		Seeds:             seeds,
+		MinPeers:          1,
+		AttemptConnPeers:  1,
+		DialTimeout:       time.Second,
+		ProtoTickInterval: time.Second,
+		PingInterval:      time.Minute,
+		PingTimeout:       time.Minute,
+	}
+	s, err := newServerFromConstructors(cfg, fakechain.NewFakeChain(), zaptest.NewLogger(t),
+		func(s *Server) Transporter { return NewMemoryTransport(n, s, addr) },
+		newFakeConsensus, newDefaultDiscovery)
+	require.NoError(t, err)
+	ch := startWithChannel(s)
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-ch
+	})
+	return s
+}
+
+func TestMemoryTransport(t *testing.T) {
+	n := NewMemoryNetwork(0)
+	s1 := newMemoryTestServer(t, n, "127.0.0.1:20001")
+	require.Eventually(t, func() bool { return s1.transport.Address() != "" }, time.Second, time.Millisecond)
+	require.Equal(t, "127.0.0.1:20001", s1.transport.Address())
+	require.Equal(t, "memory", s1.transport.Proto())
+	port, err := s1.Port()
+	require.NoError(t, err)
+	require.Equal(t, uint16(20001), port)
+
+	s2 := newMemoryTestServer(t, n, "127.0.0.1:20002", "127.0.0.1:20001")
+	require.Eventually(t, func() bool {
+		return s1.HandshakedPeersCount() == 1 && s2.HandshakedPeersCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"127.0.0.1:20002"}, s1.ConnectedPeers())
+	require.Equal(t, []string{"127.0.0.1:20001"}, s2.ConnectedPeers())
+
+	t.Run("address in use", func(t *testing.T) {
+		_, err := n.listen(nil, "127.0.0.1:20001")
+		require.True(t, errors.Is(err, errMemoryAddrInUse))
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		tr := NewMemoryTransport(n, s2, "127.0.0.1:0")
+		require.True(t, errors.Is(tr.Dial("127.0.0.1:20003", time.Second), errMemoryUnreachable))
+
+		n.Partition([]string{"127.0.0.1:20001"})
+		start := time.Now()
+		require.True(t, errors.Is(tr.Dial("127.0.0.1:20001", 50*time.Millisecond), errMemoryUnreachable))
+		require.True(t, time.Since(start) >= 50*time.Millisecond)
+		n.Heal()
+	})
+}
+
+func TestMemoryConnReproducibleLoss(t *testing.T) {
+	losses := func(n *MemoryNetwork, c *memoryConn) []bool {
+		res := make([]bool, 20)
+		for i := range res {
+			_, res[i] = n.route(c.random)
+		}
+		return res
+	}
+	n1, n2 := NewMemoryNetwork(42), NewMemoryNetwork(42)
+	n1.SetLossRate(0.5)
+	n2.SetLossRate(0.5)
+	c1, c2 := newMemoryConnPair(n1)
+	c3, c4 := newMemoryConnPair(n2)
+	t.Cleanup(func() {
+		c1.Close()
+		c2.Close()
+		c3.Close()
+		c4.Close()
+	})
+
+	_ = losses(n2, c4) // Other connections don't affect the decisions.
+	expected := losses(n1, c1)
+	require.Contains(t, expected, true)
+	require.Contains(t, expected, false)
+	require.Equal(t, expected, losses(n2, c3))
+	require.NotEqual(t, expected, losses(n1, c2))
+}
+
+func newMemoryConnPair(n *MemoryNetwork) (*memoryConn, *memoryConn) {
+	c1, c2 := net.Pipe()
+	return newMemoryConn(n, c1, "127.0.0.1:1", "127.0.0.1:2"),
+		newMemoryConn(n, c2, "127.0.0.1:2", "127.0.0.1:1")
+}
+
+func readWithTimeout(t *testing.T, c net.Conn, n int, timeout time.Duration) ([]byte, error) {
+	require.NoError(t, c.SetDeadline(time.Now().Add(timeout)))
+	buf := make([]byte, n)
+	_, err := io.ReadFull(c, buf)
+	return buf, err
+}
+
+func TestMemoryConn(t *testing.T) {
+	n := NewMemoryNetwork(0)
+	c1, c2 := newMemoryConnPair(n)
+	t.Cleanup(func() {
+		c1.Close()
+		c2.Close()
+	})
+	require.Equal(t, "127.0.0.1:1", c1.LocalAddr().String())
+	require.Equal(t, "127.0.0.1:2", c1.RemoteAddr().String())
+	require.Equal(t, "memory", c1.RemoteAddr().Network())
+
+	_, err := c1.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	b, err := readWithTimeout(t, c2, 3, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, b)
+
+	t.Run("latency", func(t *testing.T) {
+		n.SetLatency(100 * time.Millisecond)
+		defer n.SetLatency(0)
+		start := time.Now()
+		_, err := c2.Write([]byte{4, 5})
+		require.NoError(t, err)
+		b, err := readWithTimeout(t, c1, 2, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []byte{4, 5}, b)
+		require.True(t, time.Since(start) >= 100*time.Millisecond)
+	})
+	t.Run("loss", func(t *testing.T) {
+		n.SetLossRate(1)
+		_, err := c1.Write([]byte{6})
+		require.NoError(t, err)
+		n.SetLossRate(0)
+		_, err = c1.Write([]byte{7})
+		require.NoError(t, err)
+		b, err := readWithTimeout(t, c2, 1, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []byte{7}, b)
+	})
+	t.Run("partition", func(t *testing.T) {
+		n.Partition([]string{"127.0.0.1:1"}, []string{"127.0.0.1:2"})
+		_, err := c1.Write([]byte{8})
+		require.NoError(t, err)
+		_, err = readWithTimeout(t, c2, 1, 100*time.Millisecond)
+		require.Error(t, err)
+
+		n.Heal()
+		_, err = c1.Write([]byte{9})
+		require.NoError(t, err)
+		b, err := readWithTimeout(t, c2, 2, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []byte{8, 9}, b)
+	})
+	t.Run("closed", func(t *testing.T) {
+		require.NoError(t, c1.Close())
+		_, err := c1.Write([]byte{10})
+		require.Error(t, err)
+		_, err = c2.Read(make([]byte, 1))
+		require.Error(t, err)
+	})
+}
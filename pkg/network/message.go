@@ -34,6 +34,10 @@ type Message struct {
 	StateRootInHeader bool
 }
 
+// errUnknownCommand is returned when decoding a message with an unknown
+// command, such messages are skipped after the handshake.
+var errUnknownCommand = errors.New("can't decode command")
+
 // MessageFlag represents compression level of message payload
 type MessageFlag byte
 
@@ -72,6 +76,12 @@ const (
 	CMDExtensible                   = CommandType(payload.ExtensibleType)
 	CMDP2PNotaryRequest             = CommandType(payload.P2PNotaryRequestType)
 	CMDReject           CommandType = 0x2f
+	CMDGetMPTData       CommandType = 0x51 // 0x5.. commands are used for extensions (P2PNotary, state exchange cmds)
+	CMDMPTData          CommandType = 0x52
+	CMDCompactBlock     CommandType = 0x53
+	CMDGetBlockTxn      CommandType = 0x54
+	CMDBlockTxn         CommandType = 0x55
+	CMDSendCompact      CommandType = 0x56
 
 	// SPV protocol
 	CMDFilterLoad  CommandType = 0x30
@@ -157,12 +167,28 @@ func (m *Message) decodePayload() error {
 		p = &transaction.Transaction{}
 	case CMDMerkleBlock:
 		p = &payload.MerkleBlock{}
+	case CMDFilterLoad:
+		p = &payload.FilterLoad{}
+	case CMDFilterAdd:
+		p = &payload.FilterAdd{}
 	case CMDPing, CMDPong:
 		p = &payload.Ping{}
 	case CMDNotFound:
 		p = &payload.Inventory{}
+	case CMDGetMPTData:
+		p = &payload.MPTInventory{}
+	case CMDMPTData:
+		p = &payload.MPTData{}
+	case CMDCompactBlock:
+		p = &payload.CompactBlock{StateRootInHeader: m.StateRootInHeader}
+	case CMDGetBlockTxn:
+		p = &payload.GetBlockTxn{}
+	case CMDBlockTxn:
+		p = &payload.BlockTxn{}
+	case CMDSendCompact:
+		p = &payload.SendCompact{}
 	default:
-		return fmt.Errorf("can't decode command %s", m.Command.String())
+		return fmt.Errorf("%w %s", errUnknownCommand, m.Command.String())
 	}
 	p.DecodeBinary(r)
 	if r.Err == nil || r.Err == payload.ErrTooManyHeaders {
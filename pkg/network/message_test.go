@@ -216,8 +216,8 @@ func TestEncodeDecodeMerkleBlock(t *testing.T) {
 	t.Run("bad, invalid TxCount", func(t *testing.T) {
 		testEncodeDecodeFail(t, CMDMerkleBlock, &payload.MerkleBlock{
 			Header:  base,
-			TxCount: 2,
-			Hashes:  []util.Uint256{random.Uint256()},
+			TxCount: 1,
+			Hashes:  []util.Uint256{random.Uint256(), random.Uint256()},
 			Flags:   []byte{0},
 		})
 	})
@@ -230,6 +230,52 @@ func TestEncodeDecodeNotFound(t *testing.T) {
 	})
 }
 
+func TestEncodeDecodeGetMPTData(t *testing.T) {
+	testEncodeDecode(t, CMDGetMPTData, &payload.MPTInventory{
+		Hashes: []util.Uint256{random.Uint256(), random.Uint256()},
+	})
+}
+
+func TestEncodeDecodeMPTData(t *testing.T) {
+	testEncodeDecode(t, CMDMPTData, &payload.MPTData{
+		Nodes: [][]byte{{1, 2, 3}, {4, 5}},
+	})
+}
+
+func TestEncodeDecodeCompactBlock(t *testing.T) {
+	t.Run("compact block", func(t *testing.T) {
+		testEncodeDecode(t, CMDCompactBlock, payload.NewCompactBlock(newDummyBlock(12, 3), rand.Uint64()))
+	})
+	t.Run("get block transactions", func(t *testing.T) {
+		testEncodeDecode(t, CMDGetBlockTxn, payload.NewGetBlockTxn(random.Uint256(), []uint16{1, 2}))
+	})
+	t.Run("block transactions", func(t *testing.T) {
+		testEncodeDecode(t, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    random.Uint256(),
+			Transactions: []*transaction.Transaction{newDummyTx(), newDummyTx()},
+		})
+	})
+	t.Run("send compact", func(t *testing.T) {
+		testEncodeDecode(t, CMDSendCompact, payload.NewSendCompact())
+	})
+}
+
+func TestEncodeDecodeFilters(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		testEncodeDecode(t, CMDFilterLoad, &payload.FilterLoad{
+			Filter: random.Bytes(64),
+			K:      3,
+			Tweak:  42,
+		})
+	})
+	t.Run("add", func(t *testing.T) {
+		testEncodeDecode(t, CMDFilterAdd, &payload.FilterAdd{Data: random.Bytes(20)})
+	})
+	t.Run("clear", func(t *testing.T) {
+		testEncodeDecode(t, CMDFilterClear, payload.NewNullPayload())
+	})
+}
+
 func TestInvalidMessages(t *testing.T) {
 	t.Run("CMDBlock, empty payload", func(t *testing.T) {
 		testEncodeDecodeFail(t, CMDBlock, payload.NullPayload{})
@@ -245,6 +291,16 @@ func TestInvalidMessages(t *testing.T) {
 	t.Run("invalid command", func(t *testing.T) {
 		testEncodeDecodeFail(t, CommandType(0xFF), &payload.Version{Magic: netmode.UnitTestNet})
 	})
+	t.Run("unknown command is skipped", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		require.NoError(t, NewMessage(CommandType(0xFF), &payload.Version{Magic: netmode.UnitTestNet}).Encode(w.BinWriter))
+		require.NoError(t, NewMessage(CMDSendCompact, payload.NewSendCompact()).Encode(w.BinWriter))
+		r := io.NewBinReaderFromBuf(w.Bytes())
+		require.True(t, errors.Is((&Message{}).Decode(r), errUnknownCommand))
+		m := &Message{}
+		require.NoError(t, m.Decode(r))
+		require.Equal(t, CMDSendCompact, m.Command)
+	})
 	t.Run("very big payload size", func(t *testing.T) {
 		m := NewMessage(CMDBlock, nil)
 		w := io.NewBufBinWriter()
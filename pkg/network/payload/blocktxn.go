@@ -0,0 +1,42 @@
+package payload
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// BlockTxn payload contains block transactions requested with GetBlockTxn
+// (in the same order).
+type BlockTxn struct {
+	BlockHash    util.Uint256
+	Transactions []*transaction.Transaction
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *BlockTxn) DecodeBinary(br *io.BinReader) {
+	p.BlockHash.DecodeBinary(br)
+	n := br.ReadVarUint()
+	if br.Err != nil {
+		return
+	}
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	p.Transactions = make([]*transaction.Transaction, n)
+	for i := range p.Transactions {
+		p.Transactions[i] = new(transaction.Transaction)
+		p.Transactions[i].DecodeBinary(br)
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *BlockTxn) EncodeBinary(bw *io.BinWriter) {
+	p.BlockHash.EncodeBinary(bw)
+	bw.WriteVarUint(uint64(len(p.Transactions)))
+	for _, tx := range p.Transactions {
+		tx.EncodeBinary(bw)
+	}
+}
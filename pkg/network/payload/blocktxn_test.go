@@ -0,0 +1,40 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTxn_EncodeDecodeBinary(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		tx := transaction.New([]byte{1, 2, 3}, 1)
+		tx.Signers = []transaction.Signer{{Account: util.Uint160{1}}}
+		tx.Scripts = []transaction.Witness{{}}
+		p := &BlockTxn{
+			BlockHash:    util.Uint256{1, 2, 3},
+			Transactions: []*transaction.Transaction{tx},
+		}
+		data, err := testserdes.EncodeBinary(p)
+		require.NoError(t, err)
+		actual := new(BlockTxn)
+		require.NoError(t, testserdes.DecodeBinary(data, actual))
+		require.Equal(t, p.BlockHash, actual.BlockHash)
+		require.Equal(t, 1, len(actual.Transactions))
+		require.Equal(t, tx.Hash(), actual.Transactions[0].Hash())
+	})
+	t.Run("too many transactions", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		w.WriteBytes(make([]byte, util.Uint256Size))
+		w.WriteVarUint(block.MaxTransactionsPerBlock + 1)
+		require.NoError(t, w.Err)
+		err := testserdes.DecodeBinary(w.Bytes(), new(BlockTxn))
+		require.True(t, errors.Is(err, block.ErrMaxContentsPerBlock))
+	})
+}
@@ -0,0 +1,91 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// ShortIDSize is the size of short transaction ID in bytes.
+const ShortIDSize = 6
+
+// CompactBlock is a block announcement containing block header and short
+// IDs of block transactions, so that the receiver can reconstruct the block
+// from its mempool.
+type CompactBlock struct {
+	*block.Header
+	// Nonce is used to calculate short transaction IDs, it's different for
+	// every block, so that IDs collisions can't be precomputed.
+	Nonce    uint64
+	ShortIDs []uint64
+	// StateRootInHeader specifies whether header contains state root.
+	StateRootInHeader bool
+}
+
+// ShortID returns short ID of the transaction with the given hash, it's the
+// first ShortIDSize bytes of SHA256(nonce || hash).
+func ShortID(h util.Uint256, nonce uint64) uint64 {
+	var buf [8 + util.Uint256Size]byte
+	binary.LittleEndian.PutUint64(buf[:], nonce)
+	copy(buf[8:], h.BytesBE())
+	sum := hash.Sha256(buf[:])
+	var id [8]byte
+	copy(id[:], sum[:ShortIDSize])
+	return binary.LittleEndian.Uint64(id[:])
+}
+
+// NewCompactBlock returns compact block for the given block using the given
+// nonce for short IDs.
+func NewCompactBlock(b *block.Block, nonce uint64) *CompactBlock {
+	c := &CompactBlock{
+		Header:            &b.Header,
+		Nonce:             nonce,
+		ShortIDs:          make([]uint64, len(b.Transactions)),
+		StateRootInHeader: b.StateRootEnabled,
+	}
+	for i, tx := range b.Transactions {
+		c.ShortIDs[i] = ShortID(tx.Hash(), nonce)
+	}
+	return c
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *CompactBlock) DecodeBinary(br *io.BinReader) {
+	c.Header = &block.Header{StateRootEnabled: c.StateRootInHeader}
+	c.Header.DecodeBinary(br)
+	c.Nonce = br.ReadU64LE()
+	n := br.ReadVarUint()
+	if br.Err != nil {
+		return
+	}
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	c.ShortIDs = make([]uint64, n)
+	var id [8]byte
+	for i := range c.ShortIDs {
+		br.ReadBytes(id[:ShortIDSize])
+		c.ShortIDs[i] = binary.LittleEndian.Uint64(id[:])
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *CompactBlock) EncodeBinary(bw *io.BinWriter) {
+	if c.Header == nil {
+		bw.Err = errors.New("compact block has no header")
+		return
+	}
+	c.Header.EncodeBinary(bw)
+	bw.WriteU64LE(c.Nonce)
+	bw.WriteVarUint(uint64(len(c.ShortIDs)))
+	var id [8]byte
+	for _, sid := range c.ShortIDs {
+		binary.LittleEndian.PutUint64(id[:], sid)
+		bw.WriteBytes(id[:ShortIDSize])
+	}
+}
@@ -0,0 +1,58 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortID(t *testing.T) {
+	h1, h2 := util.Uint256{1}, util.Uint256{2}
+	require.Equal(t, ShortID(h1, 42), ShortID(h1, 42))
+	require.NotEqual(t, ShortID(h1, 42), ShortID(h2, 42))
+	require.NotEqual(t, ShortID(h1, 42), ShortID(h1, 43))
+	require.True(t, ShortID(h1, 42) < 1<<(8*ShortIDSize))
+}
+
+func TestCompactBlock_EncodeDecodeBinary(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		b := block.New(false)
+		b.Header = *newDumbBlock()
+		b.Transactions = []*transaction.Transaction{
+			transaction.New([]byte{1}, 1),
+			transaction.New([]byte{2}, 2),
+		}
+		_ = b.Hash()
+		cb := NewCompactBlock(b, 42)
+		require.Equal(t, 2, len(cb.ShortIDs))
+		require.Equal(t, ShortID(b.Transactions[1].Hash(), 42), cb.ShortIDs[1])
+		testserdes.EncodeDecodeBinary(t, cb, new(CompactBlock))
+	})
+	t.Run("state root in header", func(t *testing.T) {
+		h := newDumbBlock()
+		h.StateRootEnabled = true
+		h.PrevStateRoot = util.Uint256{1, 2, 3}
+		_ = h.Hash()
+		cb := &CompactBlock{Header: h, Nonce: 1, ShortIDs: []uint64{}, StateRootInHeader: true}
+		testserdes.EncodeDecodeBinary(t, cb, &CompactBlock{StateRootInHeader: true})
+	})
+	t.Run("too many transactions", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		newDumbBlock().EncodeBinary(w.BinWriter)
+		w.WriteU64LE(1)
+		w.WriteVarUint(block.MaxTransactionsPerBlock + 1)
+		require.NoError(t, w.Err)
+		err := testserdes.DecodeBinary(w.Bytes(), new(CompactBlock))
+		require.True(t, errors.Is(err, block.ErrMaxContentsPerBlock))
+	})
+	t.Run("no header", func(t *testing.T) {
+		_, err := testserdes.EncodeBinary(new(CompactBlock))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package payload
+
+import (
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+// Bloom filter limits.
+const (
+	// MaxFilterSize is the maximum size of the filter bit array in bytes.
+	MaxFilterSize = 36000
+	// MaxFilterK is the maximum number of filter hash functions.
+	MaxFilterK = 50
+	// MaxFilterAddSize is the maximum size of the element added to the filter.
+	MaxFilterAddSize = 520
+)
+
+// FilterLoad payload sets bloom filter for the peer connection, see
+// bloom.Filter for details.
+type FilterLoad struct {
+	// Filter is filter bit array.
+	Filter []byte
+	// K is the number of hash functions.
+	K uint8
+	// Tweak is added to hash function seeds.
+	Tweak uint32
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *FilterLoad) DecodeBinary(br *io.BinReader) {
+	p.Filter = br.ReadVarBytes(MaxFilterSize)
+	p.K = br.ReadB()
+	if br.Err == nil && p.K > MaxFilterK {
+		br.Err = errors.New("too many hash functions")
+	}
+	p.Tweak = br.ReadU32LE()
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *FilterLoad) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(p.Filter)
+	bw.WriteB(p.K)
+	bw.WriteU32LE(p.Tweak)
+}
+
+// FilterAdd payload adds an element to the bloom filter of the peer
+// connection.
+type FilterAdd struct {
+	Data []byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *FilterAdd) DecodeBinary(br *io.BinReader) {
+	p.Data = br.ReadVarBytes(MaxFilterAddSize)
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *FilterAdd) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(p.Data)
+}
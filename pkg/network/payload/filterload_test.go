@@ -0,0 +1,38 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterLoad_EncodeDecodeBinary(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		expected := &FilterLoad{
+			Filter: random.Bytes(100),
+			K:      MaxFilterK,
+			Tweak:  123,
+		}
+		testserdes.EncodeDecodeBinary(t, expected, new(FilterLoad))
+	})
+	t.Run("too big filter", func(t *testing.T) {
+		data, err := testserdes.EncodeBinary(&FilterLoad{Filter: make([]byte, MaxFilterSize+1), K: 1})
+		require.NoError(t, err)
+		require.Error(t, testserdes.DecodeBinary(data, new(FilterLoad)))
+	})
+	t.Run("too many hash functions", func(t *testing.T) {
+		data, err := testserdes.EncodeBinary(&FilterLoad{Filter: []byte{1}, K: MaxFilterK + 1})
+		require.NoError(t, err)
+		require.Error(t, testserdes.DecodeBinary(data, new(FilterLoad)))
+	})
+}
+
+func TestFilterAdd_EncodeDecodeBinary(t *testing.T) {
+	testserdes.EncodeDecodeBinary(t, &FilterAdd{Data: random.Bytes(MaxFilterAddSize)}, new(FilterAdd))
+
+	data, err := testserdes.EncodeBinary(&FilterAdd{Data: make([]byte, MaxFilterAddSize+1)})
+	require.NoError(t, err)
+	require.Error(t, testserdes.DecodeBinary(data, new(FilterAdd)))
+}
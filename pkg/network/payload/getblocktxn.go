@@ -0,0 +1,49 @@
+package payload
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// GetBlockTxn payload requests transactions of the block (missing ones after
+// compact block reconstruction) by their indexes.
+type GetBlockTxn struct {
+	BlockHash util.Uint256
+	Indexes   []uint16
+}
+
+// NewGetBlockTxn returns GetBlockTxn payload for the given block hash and
+// transaction indexes.
+func NewGetBlockTxn(h util.Uint256, indexes []uint16) *GetBlockTxn {
+	return &GetBlockTxn{
+		BlockHash: h,
+		Indexes:   indexes,
+	}
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *GetBlockTxn) DecodeBinary(br *io.BinReader) {
+	p.BlockHash.DecodeBinary(br)
+	n := br.ReadVarUint()
+	if br.Err != nil {
+		return
+	}
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	p.Indexes = make([]uint16, n)
+	for i := range p.Indexes {
+		p.Indexes[i] = br.ReadU16LE()
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *GetBlockTxn) EncodeBinary(bw *io.BinWriter) {
+	p.BlockHash.EncodeBinary(bw)
+	bw.WriteVarUint(uint64(len(p.Indexes)))
+	for _, i := range p.Indexes {
+		bw.WriteU16LE(i)
+	}
+}
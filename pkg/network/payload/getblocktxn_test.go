@@ -0,0 +1,27 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBlockTxn_EncodeDecodeBinary(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		p := NewGetBlockTxn(util.Uint256{1, 2, 3}, []uint16{0, 5, 65535})
+		testserdes.EncodeDecodeBinary(t, p, new(GetBlockTxn))
+	})
+	t.Run("too many indexes", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		w.WriteBytes(make([]byte, util.Uint256Size))
+		w.WriteVarUint(block.MaxTransactionsPerBlock + 1)
+		require.NoError(t, w.Err)
+		err := testserdes.DecodeBinary(w.Bytes(), new(GetBlockTxn))
+		require.True(t, errors.Is(err, block.ErrMaxContentsPerBlock))
+	})
+}
@@ -1,9 +1,9 @@
 package payload
 
 import (
-	"errors"
-
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 )
@@ -27,13 +27,25 @@ func (m *MerkleBlock) DecodeBinary(br *io.BinReader) {
 		return
 	}
 	m.TxCount = txCount
-	br.ReadArray(&m.Hashes, m.TxCount)
-	if txCount != len(m.Hashes) {
-		br.Err = errors.New("invalid tx count")
+	if txCount == 0 {
+		txCount = 1
 	}
+	br.ReadArray(&m.Hashes, maxMerkleHashes(txCount))
 	m.Flags = br.ReadVarBytes((txCount + 7) / 8)
 }
 
+// maxMerkleHashes returns the maximum number of hashes in the merkle block with
+// n transactions. It's the number of leaves in the tree with the last node of
+// every level duplicated, C# node limits it by n which makes it fail to decode
+// some of the blocks it sends.
+func maxMerkleHashes(n int) int {
+	res := 1
+	for res < n {
+		res <<= 1
+	}
+	return res
+}
+
 // EncodeBinary implements Serializable interface.
 func (m *MerkleBlock) EncodeBinary(bw *io.BinWriter) {
 	m.Header.EncodeBinary(bw)
@@ -42,3 +54,33 @@ func (m *MerkleBlock) EncodeBinary(bw *io.BinWriter) {
 	bw.WriteArray(m.Hashes)
 	bw.WriteVarBytes(m.Flags)
 }
+
+// NewMerkleBlock returns merkle block for the given block in the format used by
+// C# node. Flags mark transactions that match the given function (bit i is set
+// if i-th transaction matches) and Hashes contain the leaves of the merkle tree
+// with subtrees not containing matching transactions pruned, so that merkle
+// root can be checked without getting all transaction hashes.
+func NewMerkleBlock(b *block.Block, match func(*transaction.Transaction) bool) *MerkleBlock {
+	m := &MerkleBlock{
+		Header:  &b.Header,
+		TxCount: len(b.Transactions),
+		Hashes:  []util.Uint256{},
+		Flags:   make([]byte, (len(b.Transactions)+7)/8),
+	}
+	if len(b.Transactions) == 0 {
+		return m
+	}
+	hashes := make([]util.Uint256, len(b.Transactions))
+	flags := make([]bool, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		hashes[i] = tx.Hash()
+		if match(tx) {
+			flags[i] = true
+			m.Flags[i/8] |= 1 << (i % 8)
+		}
+	}
+	tree, _ := hash.NewMerkleTree(hashes) // Can't fail for non-empty hashes.
+	tree.Trim(flags)
+	m.Hashes = tree.ToHashArray()
+	return m
+}
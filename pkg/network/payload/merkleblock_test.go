@@ -69,3 +69,36 @@ func TestMerkleBlock_EncodeDecodeBinary(t *testing.T) {
 		require.Error(t, testserdes.DecodeBinary(data, new(MerkleBlock)))
 	})
 }
+
+func TestNewMerkleBlock(t *testing.T) {
+	b := &block.Block{Header: *newDumbBlock()}
+	_ = b.Hash()
+	for i := 0; i < 10; i++ {
+		b.Transactions = append(b.Transactions, transaction.New([]byte{byte(i)}, 0))
+	}
+	m := NewMerkleBlock(b, func(tx *transaction.Transaction) bool {
+		return tx.Script[0]%3 == 0
+	})
+	require.Equal(t, 10, m.TxCount)
+	require.Equal(t, []byte{0b01001001, 0b00000010}, m.Flags)
+	for i := range b.Transactions {
+		if i%3 == 0 {
+			require.Contains(t, m.Hashes, b.Transactions[i].Hash())
+		}
+	}
+	testserdes.EncodeDecodeBinary(t, m, new(MerkleBlock))
+
+	t.Run("no matches", func(t *testing.T) {
+		m := NewMerkleBlock(b, func(*transaction.Transaction) bool { return false })
+		require.Equal(t, []byte{0, 0}, m.Flags)
+		require.Equal(t, []util.Uint256{b.ComputeMerkleRoot()}, m.Hashes)
+	})
+	t.Run("empty block", func(t *testing.T) {
+		b := &block.Block{Header: *newDumbBlock()}
+		_ = b.Hash()
+		m := NewMerkleBlock(b, func(*transaction.Transaction) bool { return true })
+		require.Equal(t, 0, m.TxCount)
+		require.Equal(t, []util.Uint256{}, m.Hashes)
+		testserdes.EncodeDecodeBinary(t, m, new(MerkleBlock))
+	})
+}
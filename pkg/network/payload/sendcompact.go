@@ -0,0 +1,31 @@
+package payload
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+// CompactBlockVersion is the version of compact block relay protocol
+// supported.
+const CompactBlockVersion = 0
+
+// SendCompact payload is sent after the handshake to notify the peer that
+// compact blocks can be relayed to the node.
+type SendCompact struct {
+	Version byte
+}
+
+// NewSendCompact returns SendCompact payload for the supported version of
+// compact block relay.
+func NewSendCompact() *SendCompact {
+	return &SendCompact{Version: CompactBlockVersion}
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *SendCompact) DecodeBinary(br *io.BinReader) {
+	p.Version = br.ReadB()
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *SendCompact) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteB(p.Version)
+}
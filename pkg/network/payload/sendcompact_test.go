@@ -0,0 +1,11 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+)
+
+func TestSendCompact_EncodeDecodeBinary(t *testing.T) {
+	testserdes.EncodeDecodeBinary(t, NewSendCompact(), new(SendCompact))
+}
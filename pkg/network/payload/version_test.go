@@ -35,6 +35,12 @@ func TestVersionEncodeDecode(t *testing.T) {
 				StartHeight: height,
 			},
 		},
+		{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{
+				Version: 0,
+			},
+		},
 	}
 
 	version := NewVersion(magic, id, useragent, capabilities)
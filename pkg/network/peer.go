@@ -3,7 +3,9 @@ package network
 import (
 	"net"
 
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 )
 
 // Peer represents a network node neo-go is connected to.
@@ -81,4 +83,16 @@ type Peer interface {
 	// CanProcessAddr checks whether an addr command is expected to come from
 	// this peer and can be processed.
 	CanProcessAddr() bool
+	// AddGetDataSent is to inform local peer context that the given hashes
+	// were requested from this peer with getdata command.
+	AddGetDataSent([]util.Uint256)
+	// CanProcessNotFound checks whether a notfound command mentioning the
+	// given hashes is an answer to getdata sent to this peer, requested
+	// hashes are forgotten.
+	CanProcessNotFound([]util.Uint256) bool
+
+	// Filter returns bloom filter set by the peer (nil if there is none).
+	Filter() *bloom.Filter
+	// SetFilter sets bloom filter for the peer, nil clears it.
+	SetFilter(*bloom.Filter)
 }
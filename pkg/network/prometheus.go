@@ -38,6 +38,47 @@ var (
 			Namespace: "neogo",
 		},
 	)
+
+	syncTargetHeight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Highest block height reported by peers",
+			Name:      "sync_target_height",
+			Namespace: "neogo",
+		},
+	)
+
+	syncRate = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of blocks per second added during synchronization",
+			Name:      "sync_rate",
+			Namespace: "neogo",
+		},
+	)
+
+	syncPendingRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of outstanding block requests",
+			Name:      "sync_pending_requests",
+			Namespace: "neogo",
+		},
+	)
+
+	syncRequestTimeouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of timed out block requests",
+			Name:      "sync_request_timeouts_total",
+			Namespace: "neogo",
+		},
+	)
+
+	syncPeerBlocks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Help:      "Number of requested blocks received from peer",
+			Name:      "sync_peer_blocks",
+			Namespace: "neogo",
+		},
+		[]string{"peer"},
+	)
 )
 
 func init() {
@@ -46,6 +87,11 @@ func init() {
 		servAndNodeVersion,
 		poolCount,
 		blockQueueLength,
+		syncTargetHeight,
+		syncRate,
+		syncPendingRequests,
+		syncRequestTimeouts,
+		syncPeerBlocks,
 	)
 }
 
@@ -60,6 +106,27 @@ func updatePoolCountMetric(pCount int) {
 func updatePeersConnectedMetric(pConnected int) {
 	peersConnected.Set(float64(pConnected))
 }
+func updateSyncTargetMetric(height uint32) {
+	syncTargetHeight.Set(float64(height))
+}
+
+func updateSyncRateMetric(rate float64) {
+	syncRate.Set(rate)
+}
+
+func updateSyncMetrics(pending int, timeouts int) {
+	syncPendingRequests.Set(float64(pending))
+	syncRequestTimeouts.Add(float64(timeouts))
+}
+
+func updatePeerSyncMetric(addr string, blocks uint64) {
+	syncPeerBlocks.WithLabelValues(addr).Set(float64(blocks))
+}
+
+func deletePeerSyncMetric(addr string) {
+	syncPeerBlocks.DeleteLabelValues(addr)
+}
+
 func setServerAndNodeVersions(nodeVer string, serverID string) {
 	servAndNodeVersion.WithLabelValues("Node version: ", nodeVer).Add(0)
 	servAndNodeVersion.WithLabelValues("Server id: ", serverID).Add(0)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	mrand "math/rand"
 	"net"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -16,7 +17,10 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
 	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/extpool"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
@@ -35,6 +39,27 @@ const (
 	defaultMaxPeers         = 100
 	maxBlockBatch           = 200
 	minPoolCount            = 30
+	defaultBanDuration      = 24 * time.Hour
+	// maxPendingCompactBlocks is the number of compact blocks waiting for
+	// missing transactions at the same time.
+	maxPendingCompactBlocks = 16
+	// compactBlockTimeout is the time a peer is given to send transactions
+	// missing in the compact block, the full block is requested after it.
+	compactBlockTimeout = 5 * time.Second
+)
+
+// Misbehaviour penalties, peer is banned when the sum of its penalties
+// reaches BanScore (every penalty point expires in ScoreDecayInterval).
+const (
+	// InvalidMessagePenalty is applied to peers sending malformed or invalid
+	// messages.
+	InvalidMessagePenalty = 50
+	// SlowResponsePenalty is applied to peers not answering pings or block
+	// requests in time.
+	SlowResponsePenalty = 20
+	// UselessInventoryPenalty is applied to peers not able to provide the
+	// data they have (replying with notfound to getdata requests).
+	UselessInventoryPenalty = 5
 )
 
 var (
@@ -44,10 +69,30 @@ var (
 	errInvalidNetwork   = errors.New("invalid network")
 	errMaxPeers         = errors.New("max peers reached")
 	errServerShutdown   = errors.New("server shutdown")
+	errBanned           = errors.New("peer is banned")
+	errNotFound         = errors.New("requested inventory not found")
+	errBlockTimeout     = errors.New("block request timed out")
 	errInvalidInvType   = errors.New("invalid inventory type")
 	errInvalidHashStart = errors.New("invalid requested HashStart")
+	errInvalidTxIndex   = errors.New("invalid block transaction index")
+	errInvalidBlockTxn  = errors.New("unexpected number of block transactions")
 )
 
+// misbehaviourError is the peer disconnection reason caused by its
+// misbehaviour, it's penalized.
+type misbehaviourError struct {
+	penalty int
+	err     error
+}
+
+func (e misbehaviourError) Error() string {
+	return e.err.Error()
+}
+
+func (e misbehaviourError) Unwrap() error {
+	return e.err
+}
+
 type (
 	// Server represents the local Node in the network. Its transport could
 	// be of any kind.
@@ -68,6 +113,7 @@ type (
 		chain             blockchainer.Blockchainer
 		bQueue            *blockQueue
 		consensus         consensus.Service
+		stateSync         blockchainer.StateSync
 		notaryRequestPool *mempool.Pool
 		extensiblePool    *extpool.Pool
 		notaryFeer        NotaryFeer
@@ -76,8 +122,16 @@ type (
 		lock  sync.RWMutex
 		peers map[Peer]bool
 
-		// lastRequestedHeight contains last requested height.
-		lastRequestedHeight atomic.Uint32
+		// syncMgr distributes block requests between peers.
+		syncMgr *syncManager
+
+		// compactPeers are peers that announced compact block support
+		// with CMDSendCompact, compactBlocks are compact blocks waiting for
+		// the transactions missing in the mempool, both are protected by
+		// compactLock.
+		compactLock   sync.Mutex
+		compactPeers  map[Peer]bool
+		compactBlocks map[util.Uint256]*pendingCompactBlock
 
 		register   chan Peer
 		unregister chan peerDrop
@@ -97,6 +151,16 @@ type (
 		peer   Peer
 		reason error
 	}
+
+	// pendingCompactBlock is a block reconstructed from the compact block
+	// except for transactions with missing indexes requested from peer,
+	// the full block is requested after the deadline.
+	pendingCompactBlock struct {
+		block    *block.Block
+		peer     Peer
+		missing  []uint16
+		deadline time.Time
+	}
 )
 
 func randomID() uint32 {
@@ -112,6 +176,14 @@ func NewServer(config ServerConfig, chain blockchainer.Blockchainer, log *zap.Lo
 	}, consensus.NewService, newDefaultDiscovery)
 }
 
+// NewServerWithTransport returns a new Server similar to the one NewServer
+// creates, but using the Transporter made by the given function (that
+// accepts the server it's made for) instead of TCP one.
+func NewServerWithTransport(config ServerConfig, chain blockchainer.Blockchainer, log *zap.Logger,
+	newTransport func(*Server) Transporter) (*Server, error) {
+	return newServerFromConstructors(config, chain, log, newTransport, consensus.NewService, newDefaultDiscovery)
+}
+
 func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchainer, log *zap.Logger,
 	newTransport func(*Server) Transporter,
 	newConsensus func(consensus.Config) (consensus.Service, error),
@@ -131,8 +203,11 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 		register:          make(chan Peer),
 		unregister:        make(chan peerDrop),
 		peers:             make(map[Peer]bool),
+		compactPeers:      make(map[Peer]bool),
+		compactBlocks:     make(map[util.Uint256]*pendingCompactBlock),
 		syncReached:       atomic.NewBool(false),
 		extensiblePool:    extpool.New(chain),
+		stateSync:         chain.GetStateSyncModule(),
 		log:               log,
 		transactions:      make(chan *transaction.Transaction, 64),
 	}
@@ -165,7 +240,9 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 	} else if config.P2PNotaryCfg.Enabled {
 		return nil, errors.New("P2PSigExtensions are disabled, but Notary service is enable")
 	}
+	s.syncMgr = newSyncManager()
 	s.bQueue = newBlockQueue(maxBlockBatch, chain, log, func(b *block.Block) {
+		s.syncMgr.blockAdded(b.Index, time.Now())
 		if !s.syncReached.Load() {
 			s.tryStartServices()
 		}
@@ -240,12 +317,23 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 		s.AttemptConnPeers = defaultAttemptConnPeers
 	}
 
+	if s.BanDuration <= 0 {
+		s.BanDuration = defaultBanDuration
+	}
+
 	s.transport = newTransport(s)
 	s.discovery = newDiscovery(
 		s.Seeds,
 		s.DialTimeout,
 		s.transport,
 	)
+	if s.AddressBookPath != "" {
+		err := s.discovery.LoadAddressBook(s.AddressBookPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.discovery.Close()
+			return nil, fmt.Errorf("can't load address book: %w", err)
+		}
+	}
 
 	return s, nil
 }
@@ -277,6 +365,7 @@ func (s *Server) Shutdown() {
 	s.log.Info("shutting down server", zap.Int("peers", s.PeerCount()))
 	s.transport.Close()
 	s.discovery.Close()
+	s.saveAddressBook()
 	s.consensus.Shutdown()
 	for p := range s.Peers() {
 		p.Disconnect(errServerShutdown)
@@ -316,6 +405,78 @@ func (s *Server) BadPeers() []string {
 	return s.discovery.BadPeers()
 }
 
+// BannedPeers returns a list of currently banned hosts.
+func (s *Server) BannedPeers() []BannedAddress {
+	return s.discovery.BannedPeers()
+}
+
+// BanPeer bans the host of the given address (which can be specified with or
+// without a port) for the given duration (or configured BanDuration if it's
+// not positive) and disconnects all peers from this host.
+func (s *Server) BanPeer(addr string, d time.Duration, reason string) {
+	if d <= 0 {
+		d = s.BanDuration
+	}
+	host := hostOf(addr)
+	s.discovery.Ban(host, d, reason)
+	s.log.Info("peer banned", zap.String("addr", host), zap.String("reason", reason), zap.Duration("duration", d))
+	for p := range s.Peers() {
+		if hostOf(p.RemoteAddr().String()) == host {
+			go p.Disconnect(errBanned)
+		}
+	}
+	s.saveAddressBook()
+}
+
+// UnbanPeer removes the ban of the address host, it returns false if the host
+// wasn't banned.
+func (s *Server) UnbanPeer(addr string) bool {
+	ok := s.discovery.Unban(addr)
+	if ok {
+		s.saveAddressBook()
+	}
+	return ok
+}
+
+// misbehaviourPenalty returns the penalty for the peer disconnected with the
+// given reason.
+func misbehaviourPenalty(reason error) int {
+	var m misbehaviourError
+	switch {
+	case errors.As(reason, &m):
+		return m.penalty
+	case errors.Is(reason, errPingPong):
+		return SlowResponsePenalty
+	}
+	return 0
+}
+
+// invalidData marks the error caused by the peer sending invalid data, such
+// peers are penalized.
+func invalidData(err error) error {
+	return misbehaviourError{penalty: InvalidMessagePenalty, err: err}
+}
+
+// penalize increases misbehaviour score of the peer and bans it if the score
+// is too high.
+func (s *Server) penalize(p Peer, penalty int, reason error) {
+	addr := p.RemoteAddr().String()
+	s.log.Debug("peer misbehaviour", zap.String("addr", addr), zap.Int("penalty", penalty), zap.Error(reason))
+	if s.discovery.Penalize(addr, penalty) {
+		s.BanPeer(addr, 0, reason.Error())
+	}
+}
+
+// saveAddressBook saves the address book if it's configured.
+func (s *Server) saveAddressBook() {
+	if s.AddressBookPath == "" {
+		return
+	}
+	if err := s.discovery.SaveAddressBook(s.AddressBookPath); err != nil {
+		s.log.Warn("can't save address book", zap.Error(err))
+	}
+}
+
 // ConnectedPeers returns a list of currently connected peers.
 func (s *Server) ConnectedPeers() []string {
 	s.lock.RLock()
@@ -349,7 +510,9 @@ func (s *Server) run() {
 			s.lock.Unlock()
 			peerCount := s.PeerCount()
 			s.log.Info("new peer connected", zap.Stringer("addr", p.RemoteAddr()), zap.Int("peerCount", peerCount))
-			if peerCount > s.MaxPeers {
+			if s.discovery.IsBanned(p.RemoteAddr().String()) {
+				go p.Disconnect(errBanned)
+			} else if peerCount > s.MaxPeers {
 				s.lock.RLock()
 				// Pick a random peer and drop connection to it.
 				for peer := range s.peers {
@@ -370,6 +533,11 @@ func (s *Server) run() {
 					zap.Stringer("addr", drop.peer.RemoteAddr()),
 					zap.String("reason", drop.reason.Error()),
 					zap.Int("peerCount", s.PeerCount()))
+				s.syncMgr.removePeer(drop.peer)
+				s.removeCompactPeer(drop.peer)
+				if penalty := misbehaviourPenalty(drop.reason); penalty > 0 {
+					s.penalize(drop.peer, penalty, drop.reason)
+				}
 				addr := drop.peer.PeerAddr().String()
 				if drop.reason == errIdenticalID {
 					s.discovery.RegisterBadAddr(addr)
@@ -412,6 +580,12 @@ func (s *Server) run() {
 // runProto is a goroutine that manages server-wide protocol events.
 func (s *Server) runProto() {
 	pingTimer := time.NewTimer(s.PingInterval)
+	var compactTimer <-chan time.Time
+	if s.CompactBlocks {
+		t := time.NewTicker(compactBlockTimeout / 2)
+		defer t.Stop()
+		compactTimer = t.C
+	}
 	for {
 		prevHeight := s.chain.BlockHeight()
 		select {
@@ -425,6 +599,8 @@ func (s *Server) runProto() {
 				}
 			}
 			pingTimer.Reset(s.PingInterval)
+		case now := <-compactTimer:
+			s.expireCompactBlocks(now)
 		}
 	}
 }
@@ -513,6 +689,14 @@ func (s *Server) getVersionMsg() (*Message, error) {
 			},
 		})
 	}
+	if s.CompactBlocks {
+		capabilities = append(capabilities, capability.Capability{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{
+				Version: payload.CompactBlockVersion,
+			},
+		})
+	}
 	payload := payload.NewVersion(
 		s.Net,
 		s.id,
@@ -530,6 +714,12 @@ func (s *Server) getVersionMsg() (*Message, error) {
 // peers, but the problem is that they can lie to us and send whatever height
 // they want to.
 func (s *Server) IsInSync() bool {
+	return s.isInSync(s.chain.BlockHeight())
+}
+
+// isInSync checks whether the given height (of blocks or headers) is in sync
+// with the network the same way IsInSync does.
+func (s *Server) isInSync(height uint32) bool {
 	var peersNumber int
 	var notHigher int
 
@@ -537,13 +727,11 @@ func (s *Server) IsInSync() bool {
 		return true
 	}
 
-	ourLastBlock := s.chain.BlockHeight()
-
 	s.lock.RLock()
 	for p := range s.peers {
 		if p.Handshaked() {
 			peersNumber++
-			if ourLastBlock >= p.LastBlockIndex() {
+			if height >= p.LastBlockIndex() {
 				notHigher++
 			}
 		}
@@ -590,7 +778,238 @@ func (s *Server) handleVersionCmd(p Peer, version *payload.Version) error {
 
 // handleBlockCmd processes the received block received from its peer.
 func (s *Server) handleBlockCmd(p Peer, block *block.Block) error {
-	return s.bQueue.putBlock(block)
+	if s.stateSync.IsActive() {
+		return s.stateSync.AddBlock(block)
+	}
+	completed := s.syncMgr.blockReceived(p, block.Index, time.Now())
+	err := s.bQueue.putBlock(block)
+	if err != nil || !completed {
+		return err
+	}
+	return s.requestBlocks(p)
+}
+
+// hasCompactCapability returns true if the peer has advertised compact block
+// relay capability of the version supported by us.
+func hasCompactCapability(p Peer) bool {
+	ver := p.Version()
+	if ver == nil {
+		return false
+	}
+	for _, c := range ver.Capabilities {
+		if c.Type == capability.CompactBlocks {
+			cc, ok := c.Data.(*capability.Compact)
+			return ok && cc.Version == payload.CompactBlockVersion
+		}
+	}
+	return false
+}
+
+// supportsCompactBlocks returns true if compact blocks can be relayed to the
+// peer (both sides have them enabled and the peer has sent CMDSendCompact).
+func (s *Server) supportsCompactBlocks(p Peer) bool {
+	if !s.CompactBlocks {
+		return false
+	}
+	s.compactLock.Lock()
+	defer s.compactLock.Unlock()
+	return s.compactPeers[p]
+}
+
+// handleSendCompactCmd marks the peer as supporting compact blocks, it's
+// ignored if they're disabled, the peer hasn't advertised the capability or
+// the version is not known to us.
+func (s *Server) handleSendCompactCmd(p Peer, sc *payload.SendCompact) error {
+	if !s.CompactBlocks || !hasCompactCapability(p) || sc.Version != payload.CompactBlockVersion {
+		return nil
+	}
+	s.compactLock.Lock()
+	s.compactPeers[p] = true
+	s.compactLock.Unlock()
+	return nil
+}
+
+// handleCompactBlockCmd processes the compact block received from the peer,
+// the block is reconstructed from mempool transactions and the ones missing
+// there are requested from the peer.
+func (s *Server) handleCompactBlockCmd(p Peer, cb *payload.CompactBlock) error {
+	if !s.CompactBlocks {
+		return errors.New("CompactBlockCMD was received, but CompactBlocks are disabled")
+	}
+	// Blocks too far ahead can't be verified yet, they're fetched with the
+	// regular synchronization.
+	if s.stateSync.IsActive() || cb.Index <= s.chain.BlockHeight() || cb.Index > s.chain.HeaderHeight()+1 {
+		return nil
+	}
+	h := cb.Hash()
+	now := time.Now()
+	s.compactLock.Lock()
+	pb, pending := s.compactBlocks[h]
+	pending = pending && now.Before(pb.deadline)
+	s.compactLock.Unlock()
+	if pending {
+		return nil
+	}
+
+	ids := make(map[uint64]int, len(cb.ShortIDs))
+	for i, id := range cb.ShortIDs {
+		if _, ok := ids[id]; ok {
+			// Short IDs collision, the block can't be reconstructed.
+			return s.requestFullBlock(p, h)
+		}
+		ids[id] = i
+	}
+	b := block.New(s.stateRootInHeader)
+	b.Header = *cb.Header
+	b.Transactions = make([]*transaction.Transaction, len(cb.ShortIDs))
+	for _, tx := range s.chain.GetMemPool().GetVerifiedTransactions() {
+		if i, ok := ids[payload.ShortID(tx.Hash(), cb.Nonce)]; ok {
+			b.Transactions[i] = tx
+		}
+	}
+	var missing []uint16
+	for i := range b.Transactions {
+		if b.Transactions[i] == nil {
+			missing = append(missing, uint16(i))
+		}
+	}
+	if len(missing) == 0 {
+		return s.completeCompactBlock(p, b)
+	}
+
+	s.compactLock.Lock()
+	if _, ok := s.compactBlocks[h]; !ok && len(s.compactBlocks) >= maxPendingCompactBlocks {
+		s.compactLock.Unlock()
+		return s.requestFullBlock(p, h)
+	}
+	s.compactBlocks[h] = &pendingCompactBlock{
+		block:    b,
+		peer:     p,
+		missing:  missing,
+		deadline: now.Add(compactBlockTimeout),
+	}
+	s.compactLock.Unlock()
+	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockTxn, payload.NewGetBlockTxn(h, missing)))
+}
+
+// handleGetBlockTxnCmd processes the request for transactions of the block
+// previously relayed as a compact one.
+func (s *Server) handleGetBlockTxnCmd(p Peer, req *payload.GetBlockTxn) error {
+	if !s.CompactBlocks {
+		return errors.New("GetBlockTxnCMD was received, but CompactBlocks are disabled")
+	}
+	b, err := s.chain.GetBlock(req.BlockHash)
+	if err != nil {
+		return p.EnqueueP2PMessage(NewMessage(CMDNotFound,
+			payload.NewInventory(payload.BlockType, []util.Uint256{req.BlockHash})))
+	}
+	resp := &payload.BlockTxn{
+		BlockHash:    req.BlockHash,
+		Transactions: make([]*transaction.Transaction, len(req.Indexes)),
+	}
+	for i, idx := range req.Indexes {
+		if int(idx) >= len(b.Transactions) {
+			return invalidData(errInvalidTxIndex)
+		}
+		resp.Transactions[i] = b.Transactions[idx]
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDBlockTxn, resp))
+}
+
+// handleBlockTxnCmd completes the pending compact block with the transactions
+// received from the peer.
+func (s *Server) handleBlockTxnCmd(p Peer, resp *payload.BlockTxn) error {
+	if !s.CompactBlocks {
+		return errors.New("BlockTxnCMD was received, but CompactBlocks are disabled")
+	}
+	s.compactLock.Lock()
+	pb, ok := s.compactBlocks[resp.BlockHash]
+	if ok && pb.peer == p {
+		delete(s.compactBlocks, resp.BlockHash)
+	}
+	s.compactLock.Unlock()
+	if !ok || pb.peer != p {
+		// Not requested or already received from somewhere else.
+		return nil
+	}
+	if len(resp.Transactions) != len(pb.missing) {
+		return invalidData(errInvalidBlockTxn)
+	}
+	for i, idx := range pb.missing {
+		pb.block.Transactions[idx] = resp.Transactions[i]
+	}
+	return s.completeCompactBlock(p, pb.block)
+}
+
+// completeCompactBlock passes the reconstructed block to the block queue if
+// its transactions match the header and requests the full block otherwise.
+func (s *Server) completeCompactBlock(p Peer, b *block.Block) error {
+	if b.ComputeMerkleRoot() != b.MerkleRoot {
+		return s.requestFullBlock(p, b.Hash())
+	}
+	return s.handleBlockCmd(p, b)
+}
+
+// requestFullBlock requests the block that can't be reconstructed from the
+// compact one.
+func (s *Server) requestFullBlock(p Peer, h util.Uint256) error {
+	p.AddGetDataSent([]util.Uint256{h})
+	return p.EnqueueP2PMessage(NewMessage(CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{h})))
+}
+
+// expireCompactBlocks drops pending compact blocks that haven't got missing
+// transactions before the deadline and requests full blocks for them from
+// some other peer having them (or the same one if there is no such peer).
+func (s *Server) expireCompactBlocks(now time.Time) {
+	var expired []*pendingCompactBlock
+	s.compactLock.Lock()
+	for h, pb := range s.compactBlocks {
+		if !now.Before(pb.deadline) {
+			delete(s.compactBlocks, h)
+			expired = append(expired, pb)
+		}
+	}
+	s.compactLock.Unlock()
+	if len(expired) == 0 {
+		return
+	}
+	peers := s.Peers()
+	for _, pb := range expired {
+		p := pb.peer
+		for other := range peers {
+			if other != pb.peer && other.Handshaked() && other.LastBlockIndex() >= pb.block.Index {
+				p = other
+				break
+			}
+		}
+		if err := s.requestFullBlock(p, pb.block.Hash()); err != nil {
+			s.log.Debug("failed to request full block", zap.Stringer("addr", p.RemoteAddr()), zap.Error(err))
+		}
+	}
+}
+
+// removeCompactPeer forgets compact block support of the disconnected peer
+// and drops pending compact blocks waiting for transactions from it.
+func (s *Server) removeCompactPeer(p Peer) {
+	s.compactLock.Lock()
+	defer s.compactLock.Unlock()
+	delete(s.compactPeers, p)
+	for h, pb := range s.compactBlocks {
+		if pb.peer == p {
+			delete(s.compactBlocks, h)
+		}
+	}
+}
+
+// removeCompactBlocks drops pending compact blocks up to the given index.
+func (s *Server) removeCompactBlocks(index uint32) {
+	s.compactLock.Lock()
+	defer s.compactLock.Unlock()
+	for h, pb := range s.compactBlocks {
+		if pb.block.Index <= index {
+			delete(s.compactBlocks, h)
+		}
+	}
 }
 
 // handlePing processes ping request.
@@ -647,6 +1066,7 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 		if err != nil {
 			return err
 		}
+		p.AddGetDataSent(reqHashes)
 		if inv.Type == payload.ExtensibleType {
 			return p.EnqueueHPPacket(true, pkt)
 		}
@@ -655,6 +1075,16 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 	return nil
 }
 
+// handleNotFoundCmd processes the notfound reply to our getdata request, the
+// peer is penalized if it can't provide the data it has announced (broadcasted
+// requests can be answered with notfound by any peer).
+func (s *Server) handleNotFoundCmd(p Peer, inv *payload.Inventory) error {
+	if p.CanProcessNotFound(inv.Hashes) {
+		s.penalize(p, UselessInventoryPenalty, errNotFound)
+	}
+	return nil
+}
+
 // handleMempoolCmd handles getmempool command.
 func (s *Server) handleMempoolCmd(p Peer) error {
 	txs := s.chain.GetMemPool().GetVerifiedTransactions()
@@ -690,10 +1120,15 @@ func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 			}
 		case payload.BlockType:
 			b, err := s.chain.GetBlock(hash)
-			if err == nil {
-				msg = NewMessage(CMDBlock, b)
-			} else {
+			if err != nil {
 				notFound = append(notFound, hash)
+			} else if f := p.Filter(); f != nil {
+				err = s.sendMerkleBlock(p, b, f)
+				if err != nil {
+					return err
+				}
+			} else {
+				msg = NewMessage(CMDBlock, b)
 			}
 		case payload.ExtensibleType:
 			if cp := s.extensiblePool.Get(hash); cp != nil {
@@ -726,6 +1161,55 @@ func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 	return nil
 }
 
+// sendMerkleBlock sends merkle block for the given block followed by its
+// transactions matching the filter to the peer.
+func (s *Server) sendMerkleBlock(p Peer, b *block.Block, f *bloom.Filter) error {
+	var matched []*transaction.Transaction
+	mb := payload.NewMerkleBlock(b, func(tx *transaction.Transaction) bool {
+		if f.MatchTransaction(tx) {
+			matched = append(matched, tx)
+			return true
+		}
+		return false
+	})
+	err := p.EnqueueP2PMessage(NewMessage(CMDMerkleBlock, mb))
+	if err != nil {
+		return err
+	}
+	for _, tx := range matched {
+		err = p.EnqueueP2PMessage(NewMessage(CMDTX, tx))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleFilterLoadCmd sets bloom filter for the peer.
+func (s *Server) handleFilterLoadCmd(p Peer, fl *payload.FilterLoad) error {
+	f, err := bloom.NewFromBytes(fl.Filter, fl.K, fl.Tweak)
+	if err != nil {
+		return invalidData(fmt.Errorf("invalid filter: %w", err))
+	}
+	p.SetFilter(f)
+	return nil
+}
+
+// handleFilterAddCmd adds an element to the peer's bloom filter, it's ignored
+// if there is no filter set.
+func (s *Server) handleFilterAddCmd(p Peer, fa *payload.FilterAdd) error {
+	if f := p.Filter(); f != nil {
+		f.Add(fa.Data)
+	}
+	return nil
+}
+
+// handleFilterClearCmd removes the peer's bloom filter.
+func (s *Server) handleFilterClearCmd(p Peer) error {
+	p.SetFilter(nil)
+	return nil
+}
+
 // handleGetBlocksCmd processes the getblocks request.
 func (s *Server) handleGetBlocksCmd(p Peer, gb *payload.GetBlocks) error {
 	count := gb.Count
@@ -805,13 +1289,65 @@ func (s *Server) handleGetHeadersCmd(p Peer, gh *payload.GetBlockByIndex) error
 	return p.EnqueueP2PMessage(msg)
 }
 
+// handleHeadersCmd processes headers received during state synchronization.
+func (s *Server) handleHeadersCmd(p Peer, h *payload.Headers) error {
+	if !s.stateSync.NeedHeaders() {
+		return nil
+	}
+	if err := s.stateSync.AddHeaders(h.Hdrs...); err != nil {
+		return err
+	}
+	return s.requestStateSync(p)
+}
+
+// handleGetMPTDataCmd processes the getmptdata request.
+func (s *Server) handleGetMPTDataCmd(p Peer, inv *payload.MPTInventory) error {
+	if !s.chain.GetConfig().P2PStateExchangeExtensions {
+		return errors.New("GetMPTDataCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	resp := payload.MPTData{}
+	for _, h := range inv.Hashes {
+		node, err := s.stateSync.GetMPTNode(h)
+		if err != nil {
+			continue
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+	if len(resp.Nodes) == 0 {
+		return nil
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDMPTData, &resp))
+}
+
+// handleMPTDataCmd processes MPT nodes received during state synchronization.
+func (s *Server) handleMPTDataCmd(p Peer, data *payload.MPTData) error {
+	if !s.chain.GetConfig().P2PStateExchangeExtensions {
+		return errors.New("MPTDataCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	if !s.stateSync.NeedMPTNodes() {
+		return nil
+	}
+	if err := s.stateSync.AddMPTNodes(data.Nodes); err != nil {
+		return err
+	}
+	return s.requestStateSync(p)
+}
+
 // handleExtensibleCmd processes received extensible payload.
 func (s *Server) handleExtensibleCmd(e *payload.Extensible) error {
 	if !s.syncReached.Load() {
+		// State roots can be used for state synchronization, they're
+		// checked by the state synchronization module.
+		if s.stateSync.IsActive() && e.Category == stateroot.Category {
+			return s.addStateSyncRoot(e)
+		}
 		return nil
 	}
 	ok, err := s.extensiblePool.Add(e)
 	if err != nil {
+		if errors.Is(err, extpool.ErrInvalidWitness) {
+			return invalidData(err)
+		}
 		return err
 	}
 	if !ok { // payload is already in cache
@@ -826,7 +1362,7 @@ func (s *Server) handleExtensibleCmd(e *payload.Extensible) error {
 			return err
 		}
 	default:
-		return errors.New("invalid category")
+		return invalidData(errors.New("invalid category"))
 	}
 
 	msg := NewMessage(CMDInv, payload.NewInventory(payload.ExtensibleType, []util.Uint256{e.Hash()}))
@@ -838,6 +1374,21 @@ func (s *Server) handleExtensibleCmd(e *payload.Extensible) error {
 	return nil
 }
 
+// addStateSyncRoot passes state root from the given extensible payload to the
+// state synchronization module which checks its sender and witness.
+func (s *Server) addStateSyncRoot(e *payload.Extensible) error {
+	m := new(stateroot.Message)
+	r := io.NewBinReaderFromBuf(e.Data)
+	m.DecodeBinary(r)
+	if r.Err != nil {
+		return r.Err
+	}
+	if m.Type != stateroot.RootT {
+		return nil
+	}
+	return s.stateSync.AddStateRoot(e.Sender, m.Payload.(*state.MPTRoot))
+}
+
 // handleTxCmd processes received transaction.
 // It never returns an error.
 func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
@@ -903,7 +1454,7 @@ func (s *Server) broadcastP2PNotaryRequestPayload(_ *transaction.Transaction, da
 // handleAddrCmd will process received addresses.
 func (s *Server) handleAddrCmd(p Peer, addrs *payload.AddressList) error {
 	if !p.CanProcessAddr() {
-		return errors.New("unexpected addr received")
+		return invalidData(errors.New("unexpected addr received"))
 	}
 	dups := make(map[string]bool)
 	for _, a := range addrs.Addrs {
@@ -932,44 +1483,83 @@ func (s *Server) handleGetAddrCmd(p Peer) error {
 	return p.EnqueueP2PMessage(NewMessage(CMDAddr, alist))
 }
 
-// requestBlocks sends a CMDGetBlockByIndex message to the peer
-// to sync up in blocks. A maximum of maxBlockBatch will
-// send at once. Two things we need to take care of:
-// 1. If possible, blocks should be fetched in parallel.
-//    height..+500 to one peer, height+500..+1000 to another etc.
-// 2. Every block must eventually be fetched even if peer sends no answer.
-// Thus the following algorithm is used:
-// 1. Block range is divided into chunks of payload.MaxHashesCount.
-// 2. Send requests for chunk in increasing order.
-// 3. After all requests were sent, request random height.
+// requestBlocks sends a CMDGetBlockByIndex message to the peer to sync up in
+// blocks. Missing blocks are split into ranges by syncManager and every peer
+// gets its own ones, so blocks are fetched in parallel from all peers that are
+// higher than us. Requests that have no progress for some time are reassigned
+// to other peers and peers responsible for them are penalized.
 func (s *Server) requestBlocks(p Peer) error {
-	var currHeight = s.chain.BlockHeight()
-	var peerHeight = p.LastBlockIndex()
-	var needHeight uint32
-	// lastRequestedHeight can only be increased.
-	for {
-		old := s.lastRequestedHeight.Load()
-		if old <= currHeight {
-			needHeight = currHeight + 1
-			if !s.lastRequestedHeight.CAS(old, needHeight) {
-				continue
-			}
-		} else if old < currHeight+(blockCacheSize-payload.MaxHashesCount) {
-			needHeight = currHeight + 1
-			if peerHeight > old+payload.MaxHashesCount {
-				needHeight = old + payload.MaxHashesCount
-				if !s.lastRequestedHeight.CAS(old, needHeight) {
-					continue
-				}
+	if s.stateSync.IsActive() {
+		return s.requestStateSync(p)
+	}
+	now := time.Now()
+	for _, slow := range s.syncMgr.expire(now) {
+		s.penalize(slow, SlowResponsePenalty, errBlockTimeout)
+	}
+	updateSyncTargetMetric(s.syncTarget())
+	start, count, ok := s.syncMgr.next(p, s.chain.BlockHeight(), p.LastBlockIndex(), now)
+	if !ok {
+		return nil
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, payload.NewGetBlockByIndex(start, count)))
+}
+
+// syncTarget returns the highest block height reported by handshaked peers
+// (or our own height if it's higher).
+func (s *Server) syncTarget() uint32 {
+	target := s.chain.BlockHeight()
+	s.lock.RLock()
+	for p := range s.peers {
+		if p.Handshaked() && p.LastBlockIndex() > target {
+			target = p.LastBlockIndex()
+		}
+	}
+	s.lock.RUnlock()
+	return target
+}
+
+// SyncProgress returns the current state of block synchronization.
+func (s *Server) SyncProgress() SyncProgress {
+	var (
+		res   = SyncProgress{Height: s.chain.BlockHeight()}
+		peers []Peer
+	)
+	s.lock.RLock()
+	for p := range s.peers {
+		if p.Handshaked() {
+			peers = append(peers, p)
+		}
+	}
+	s.lock.RUnlock()
+	res.Target = s.syncTarget()
+	s.syncMgr.progress(&res, peers, time.Now())
+	return res
+}
+
+// requestStateSync sends the next request needed for state synchronization
+// to the peer: headers are requested first up to the height known to the
+// network, then MPT nodes and the last blocks before the sync point.
+func (s *Server) requestStateSync(p Peer) error {
+	switch {
+	case s.stateSync.NeedHeaders():
+		headerHeight := s.chain.HeaderHeight()
+		if headerHeight >= p.LastBlockIndex() {
+			if s.isInSync(headerHeight) {
+				return s.stateSync.SelectSyncPoint()
 			}
-		} else {
-			index := mrand.Intn(blockCacheSize / payload.MaxHashesCount)
-			needHeight = currHeight + 1 + uint32(index*payload.MaxHashesCount)
+			return nil
+		}
+		return p.EnqueueP2PMessage(NewMessage(CMDGetHeaders, payload.NewGetBlockByIndex(headerHeight+1, -1)))
+	case s.stateSync.NeedMPTNodes():
+		hashes := s.stateSync.GetUnknownMPTNodesBatch(payload.MaxMPTHashesCount)
+		if len(hashes) == 0 {
+			return nil
 		}
-		break
+		return p.EnqueueP2PMessage(NewMessage(CMDGetMPTData, payload.NewMPTInventory(hashes)))
+	case s.stateSync.NeedBlocks():
+		return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, payload.NewGetBlockByIndex(s.stateSync.BlockHeight()+1, -1)))
 	}
-	payload := payload.NewGetBlockByIndex(needHeight, -1)
-	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, payload))
+	return nil
 }
 
 // handleMessage processes the given message.
@@ -981,7 +1571,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 	if peer.Handshaked() {
 		if inv, ok := msg.Payload.(*payload.Inventory); ok {
 			if !inv.Type.Valid(s.chain.P2PSigExtensionsEnabled()) || len(inv.Hashes) == 0 {
-				return errInvalidInvType
+				return invalidData(errInvalidInvType)
 			}
 		}
 		switch msg.Command {
@@ -1003,15 +1593,39 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDGetHeaders:
 			gh := msg.Payload.(*payload.GetBlockByIndex)
 			return s.handleGetHeadersCmd(peer, gh)
+		case CMDHeaders:
+			h := msg.Payload.(*payload.Headers)
+			return s.handleHeadersCmd(peer, h)
+		case CMDGetMPTData:
+			inv := msg.Payload.(*payload.MPTInventory)
+			return s.handleGetMPTDataCmd(peer, inv)
+		case CMDMPTData:
+			data := msg.Payload.(*payload.MPTData)
+			return s.handleMPTDataCmd(peer, data)
 		case CMDInv:
 			inventory := msg.Payload.(*payload.Inventory)
 			return s.handleInvCmd(peer, inventory)
+		case CMDNotFound:
+			inventory := msg.Payload.(*payload.Inventory)
+			return s.handleNotFoundCmd(peer, inventory)
 		case CMDMempool:
 			// no payload
 			return s.handleMempoolCmd(peer)
 		case CMDBlock:
 			block := msg.Payload.(*block.Block)
 			return s.handleBlockCmd(peer, block)
+		case CMDCompactBlock:
+			cb := msg.Payload.(*payload.CompactBlock)
+			return s.handleCompactBlockCmd(peer, cb)
+		case CMDGetBlockTxn:
+			req := msg.Payload.(*payload.GetBlockTxn)
+			return s.handleGetBlockTxnCmd(peer, req)
+		case CMDBlockTxn:
+			resp := msg.Payload.(*payload.BlockTxn)
+			return s.handleBlockTxnCmd(peer, resp)
+		case CMDSendCompact:
+			sc := msg.Payload.(*payload.SendCompact)
+			return s.handleSendCompactCmd(peer, sc)
 		case CMDExtensible:
 			cp := msg.Payload.(*payload.Extensible)
 			return s.handleExtensibleCmd(cp)
@@ -1021,6 +1635,15 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDP2PNotaryRequest:
 			r := msg.Payload.(*payload.P2PNotaryRequest)
 			return s.handleP2PNotaryRequestCmd(r)
+		case CMDFilterLoad:
+			fl := msg.Payload.(*payload.FilterLoad)
+			return s.handleFilterLoadCmd(peer, fl)
+		case CMDFilterAdd:
+			fa := msg.Payload.(*payload.FilterAdd)
+			return s.handleFilterAddCmd(peer, fa)
+		case CMDFilterClear:
+			// no payload
+			return s.handleFilterClearCmd(peer)
 		case CMDPing:
 			ping := msg.Payload.(*payload.Ping)
 			return s.handlePing(peer, ping)
@@ -1028,7 +1651,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 			pong := msg.Payload.(*payload.Ping)
 			return s.handlePong(peer, pong)
 		case CMDVersion, CMDVerack:
-			return fmt.Errorf("received '%s' after the handshake", msg.Command.String())
+			return invalidData(fmt.Errorf("received '%s' after the handshake", msg.Command.String()))
 		}
 	} else {
 		switch msg.Command {
@@ -1040,11 +1663,19 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 			if err != nil {
 				return err
 			}
+			// Peers not advertising the capability may not know
+			// CMDSendCompact and drop the connection on it.
+			if s.CompactBlocks && hasCompactCapability(peer) {
+				err = peer.EnqueueP2PMessage(NewMessage(CMDSendCompact, payload.NewSendCompact()))
+				if err != nil {
+					return err
+				}
+			}
 			go peer.StartProtocol()
 
 			s.tryStartServices()
 		default:
-			return fmt.Errorf("received '%s' during handshake", msg.Command.String())
+			return invalidData(fmt.Errorf("received '%s' during handshake", msg.Command.String()))
 		}
 	}
 	return nil
@@ -1170,8 +1801,15 @@ func (s *Server) relayBlocksLoop() {
 			// Filter out nodes that are more current (avoid spamming the network
 			// during initial sync).
 			s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
-				return p.Handshaked() && p.LastBlockIndex() < b.Index
+				return p.Handshaked() && p.LastBlockIndex() < b.Index && !s.supportsCompactBlocks(p)
 			})
+			if s.CompactBlocks {
+				msg = NewMessage(CMDCompactBlock, payload.NewCompactBlock(b, mrand.Uint64()))
+				s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+					return p.Handshaked() && p.LastBlockIndex() < b.Index && s.supportsCompactBlocks(p)
+				})
+			}
+			s.removeCompactBlocks(b.Index)
 			s.extensiblePool.RemoveStale(b.Index)
 		}
 	}
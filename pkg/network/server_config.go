@@ -58,6 +58,21 @@ type (
 		// Time to wait for pong(response for sent ping request).
 		PingTimeout time.Duration
 
+		// AddressBookPath is the path to the file known addresses and bans
+		// are stored in between node restarts, they're not stored if it's
+		// empty.
+		AddressBookPath string
+
+		// BanDuration is the time misbehaving peers are banned for. Bans
+		// apply to the whole host (IP address), not a single port.
+		// When this is 0, the default duration of 24 hours will be used.
+		BanDuration time.Duration
+
+		// CompactBlocks enables compact block relay with peers supporting
+		// it. It's an extension not known to other node implementations,
+		// so the capability is only advertised when it's enabled.
+		CompactBlocks bool
+
 		// Level of the internal logger.
 		LogLevel zapcore.Level
 
@@ -100,6 +115,9 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 		ProtoTickInterval: appConfig.ProtoTickInterval * time.Second,
 		PingInterval:      appConfig.PingInterval * time.Second,
 		PingTimeout:       appConfig.PingTimeout * time.Second,
+		AddressBookPath:   appConfig.AddressBookPath,
+		BanDuration:       appConfig.BanDuration * time.Second,
+		CompactBlocks:     appConfig.CompactBlocks,
 		MaxPeers:          appConfig.MaxPeers,
 		AttemptConnPeers:  appConfig.AttemptConnPeers,
 		MinPeers:          appConfig.MinPeers,
@@ -17,6 +17,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/util"
@@ -181,58 +182,182 @@ func TestServerRegisterPeer(t *testing.T) {
 
 }
 
+func TestBanPeer(t *testing.T) {
+	s := newTestServer(t, ServerConfig{})
+	ch := startWithChannel(s)
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-ch
+	})
+	require.Equal(t, defaultBanDuration, s.BanDuration)
+
+	newPeer := func(ip string, port int) *localPeer {
+		p := newLocalPeer(t, s)
+		p.netaddr.IP = net.ParseIP(ip)
+		p.netaddr.Port = port
+		s.register <- p
+		return p
+	}
+	dropped := func(p *localPeer) error {
+		err, _ := p.droppedWith.Load().(error)
+		return err
+	}
+
+	t.Run("misbehaviour", func(t *testing.T) {
+		p1 := newPeer("1.1.1.1", 1)
+		p2 := newPeer("1.1.1.1", 2)
+		require.Eventually(t, func() bool { return s.PeerCount() == 2 }, time.Second, 10*time.Millisecond)
+
+		// Ping timeouts are not enough to be banned at once.
+		p1.Disconnect(errPingPong)
+		require.Eventually(t, func() bool { return s.PeerCount() == 1 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, 0, len(s.BannedPeers()))
+
+		p1 = newPeer("1.1.1.1", 1)
+		p1.Disconnect(misbehaviourError{penalty: InvalidMessagePenalty, err: errInvalidInvType})
+		p1 = newPeer("1.1.1.1", 1)
+		p1.Disconnect(misbehaviourError{penalty: InvalidMessagePenalty, err: errInvalidInvType})
+		require.Eventually(t, func() bool { return len(s.BannedPeers()) == 1 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, "1.1.1.1", s.BannedPeers()[0].Address)
+		require.Equal(t, errInvalidInvType.Error(), s.BannedPeers()[0].Reason)
+
+		// All peers from the banned host are disconnected.
+		require.Eventually(t, func() bool { return errors.Is(dropped(p2), errBanned) }, time.Second, 10*time.Millisecond)
+
+		// And new ones are not accepted.
+		p3 := newPeer("1.1.1.1", 3)
+		require.Eventually(t, func() bool { return errors.Is(dropped(p3), errBanned) }, time.Second, 10*time.Millisecond)
+
+		require.True(t, s.UnbanPeer("1.1.1.1:3"))
+		require.False(t, s.UnbanPeer("1.1.1.1"))
+		require.Equal(t, 0, len(s.BannedPeers()))
+	})
+	t.Run("notfound", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.netaddr.IP = net.ParseIP("2.2.2.2")
+		p.handshaked = true
+		inv := payload.NewInventory(payload.TXType, []util.Uint256{{1}})
+		// Not requested from this peer.
+		for i := 0; i < BanScore/UselessInventoryPenalty; i++ {
+			require.NoError(t, s.handleMessage(p, NewMessage(CMDNotFound, inv)))
+		}
+		require.Equal(t, 0, len(s.BannedPeers()))
+
+		for i := 0; i < BanScore/UselessInventoryPenalty-1; i++ {
+			p.AddGetDataSent(inv.Hashes)
+			require.NoError(t, s.handleMessage(p, NewMessage(CMDNotFound, inv)))
+		}
+		require.Equal(t, 0, len(s.BannedPeers()))
+		p.AddGetDataSent(inv.Hashes)
+		require.NoError(t, s.handleMessage(p, NewMessage(CMDNotFound, inv)))
+		require.Equal(t, 1, len(s.BannedPeers()))
+		require.Equal(t, errNotFound.Error(), s.BannedPeers()[0].Reason)
+		require.True(t, s.UnbanPeer("2.2.2.2"))
+	})
+	t.Run("penalties", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		// Invalid data is penalized.
+		err := s.handleMessage(p, NewMessage(CMDInv, payload.NewInventory(0xFF, []util.Uint256{{1}})))
+		require.True(t, errors.Is(err, errInvalidInvType))
+		require.Equal(t, InvalidMessagePenalty, misbehaviourPenalty(err))
+		err = s.handleMessage(p, NewMessage(CMDVerack, payload.NewNullPayload()))
+		require.Equal(t, InvalidMessagePenalty, misbehaviourPenalty(err))
+		// Other errors are not, they can be caused by races or node
+		// configuration.
+		err = s.handleMessage(p, NewMessage(CMDBlockTxn, &payload.BlockTxn{}))
+		require.Error(t, err)
+		require.Equal(t, 0, misbehaviourPenalty(err))
+	})
+	t.Run("manual", func(t *testing.T) {
+		p := newPeer("3.3.3.3", 1)
+		s.BanPeer("3.3.3.3", time.Hour, "test")
+		require.Eventually(t, func() bool { return errors.Is(dropped(p), errBanned) }, time.Second, 10*time.Millisecond)
+		banned := s.BannedPeers()
+		require.Equal(t, 1, len(banned))
+		require.Equal(t, "test", banned[0].Reason)
+		require.True(t, banned[0].Until.After(time.Now().Add(time.Hour-time.Minute)))
+	})
+}
+
 func TestGetBlocksByIndex(t *testing.T) {
 	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/"})
-	ps := make([]*localPeer, 10)
-	expectsCmd := make([]CommandType, 10)
-	expectedHeight := make([][]uint32, 10)
-	start := s.chain.BlockHeight()
+	ps := make([]*localPeer, 4)
+	requested := make([][]uint32, len(ps))
 	for i := range ps {
 		i := i
 		ps[i] = newLocalPeer(t, s)
+		ps[i].netaddr.Port = i + 1
+		ps[i].handshaked = true
+		ps[i].lastBlockIndex = 5000
 		ps[i].messageHandler = func(t *testing.T, msg *Message) {
-			require.Equal(t, expectsCmd[i], msg.Command)
-			if expectsCmd[i] == CMDGetBlockByIndex {
-				p, ok := msg.Payload.(*payload.GetBlockByIndex)
-				require.True(t, ok)
-				require.Contains(t, expectedHeight[i], p.IndexStart)
-				expectsCmd[i] = CMDPong
-			} else if expectsCmd[i] == CMDPong {
-				expectsCmd[i] = CMDGetBlockByIndex
-			}
+			require.Equal(t, CMDGetBlockByIndex, msg.Command)
+			p, ok := msg.Payload.(*payload.GetBlockByIndex)
+			require.True(t, ok)
+			require.True(t, 0 < p.Count && p.Count <= blockRequestSize)
+			requested[i] = append(requested[i], p.IndexStart)
 		}
-		expectsCmd[i] = CMDGetBlockByIndex
-		expectedHeight[i] = []uint32{start + 1}
+		s.peers[ps[i]] = true
 	}
-	go s.transport.Accept()
-
-	nonce := uint32(0)
-	checkPingRespond := func(t *testing.T, peerIndex int, peerHeight uint32, hs ...uint32) {
-		nonce++
-		expectedHeight[peerIndex] = hs
-		require.NoError(t, s.handlePing(ps[peerIndex], payload.NewPing(peerHeight, nonce)))
+	checkRequested := func(t *testing.T, i int, hs ...uint32) {
+		require.Equal(t, hs, requested[i])
+		requested[i] = nil
+	}
+	sendBlocks := func(t *testing.T, i int, start, end uint32) {
+		for h := start; h <= end; h++ {
+			b := block.New(false)
+			b.Index = h
+			require.NoError(t, s.handleBlockCmd(ps[i], b))
+		}
 	}
 
-	// Send all requests for all chunks.
-	checkPingRespond(t, 0, 5000, 1)
-	checkPingRespond(t, 1, 5000, 1+payload.MaxHashesCount)
-	checkPingRespond(t, 2, 5000, 1+2*payload.MaxHashesCount)
-	checkPingRespond(t, 3, 5000, 1+3*payload.MaxHashesCount)
-
-	// Receive some blocks.
-	s.chain.(*fakechain.FakeChain).Blockheight = 2123
+	// Every peer gets its own ranges.
+	for i := range ps {
+		for j := 0; j < maxPeerBlockRequests+1; j++ {
+			require.NoError(t, s.requestBlocks(ps[i]))
+		}
+	}
+	for i := range ps {
+		start := uint32(1 + i*maxPeerBlockRequests*blockRequestSize)
+		checkRequested(t, i, start, start+blockRequestSize)
+	}
 
-	// Minimum chunk has priority.
-	checkPingRespond(t, 5, 5000, 2124)
-	checkPingRespond(t, 6, 5000, 2624)
-	// Request minimal height for peers behind.
-	checkPingRespond(t, 7, 3100, 2124)
-	checkPingRespond(t, 8, 5000, 3124)
-	checkPingRespond(t, 9, 5000, 3624)
-	// Request random height after that.
-	checkPingRespond(t, 1, 5000, 2124, 2624, 3124, 3624)
-	checkPingRespond(t, 2, 5000, 2124, 2624, 3124, 3624)
-	checkPingRespond(t, 3, 5000, 2124, 2624, 3124, 3624)
+	// Completed request is followed by the next one.
+	sendBlocks(t, 1, 201, 299)
+	checkRequested(t, 1)
+	sendBlocks(t, 1, 300, 300)
+	checkRequested(t, 1, 801)
+	// Blocks not requested from the peer are not accounted.
+	sendBlocks(t, 2, 1, 100)
+	checkRequested(t, 2)
+
+	progress := s.SyncProgress()
+	require.Equal(t, uint32(0), progress.Height)
+	require.Equal(t, uint32(5000), progress.Target)
+	require.Equal(t, 2*len(ps), progress.Pending)
+	require.Equal(t, len(ps), len(progress.Peers))
+	require.Equal(t, uint64(0), progress.Peers[0].Blocks)
+	require.Equal(t, uint64(100), progress.Peers[1].Blocks)
+	require.Equal(t, 2, progress.Peers[1].Pending)
+
+	// Requests of disconnected peer are given to others.
+	s.syncMgr.removePeer(ps[0])
+	delete(s.peers, ps[0])
+	sendBlocks(t, 1, 101, 200)
+	checkRequested(t, 1)
+	sendBlocks(t, 3, 601, 700)
+	checkRequested(t, 3, 1)
+
+	// Blocks already in the chain are not requested and peers behind
+	// get the blocks they have only.
+	s.chain.(*fakechain.FakeChain).Blockheight = 850
+	ps[2].lastBlockIndex = 950
+	sendBlocks(t, 2, 401, 500)
+	checkRequested(t, 2, 901)
+	sendBlocks(t, 2, 901, 950)
+	checkRequested(t, 2)
+	require.NoError(t, s.requestBlocks(ps[2]))
+	checkRequested(t, 2)
 }
 
 func TestSendVersion(t *testing.T) {
@@ -555,6 +680,75 @@ func TestGetData(t *testing.T) {
 	})
 }
 
+func TestFilters(t *testing.T) {
+	s := startTestServer(t)
+	b := newDummyBlock(2, 4)
+	s.chain.(*fakechain.FakeChain).PutBlock(b)
+
+	var (
+		merkle   *payload.MerkleBlock
+		blk      *block.Block
+		received []*transaction.Transaction
+	)
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		switch msg.Command {
+		case CMDMerkleBlock:
+			merkle = msg.Payload.(*payload.MerkleBlock)
+		case CMDBlock:
+			blk = msg.Payload.(*block.Block)
+		case CMDTX:
+			received = append(received, msg.Payload.(*transaction.Transaction))
+		}
+	}
+	getBlock := func(t *testing.T) {
+		merkle, blk, received = nil, nil, nil
+		s.testHandleMessage(t, p, CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		msg := NewMessage(CMDFilterLoad, &payload.FilterLoad{Filter: []byte{}, K: 1})
+		require.Error(t, s.handleMessage(p, msg))
+		require.Nil(t, p.Filter())
+	})
+	t.Run("add without filter", func(t *testing.T) {
+		s.testHandleMessage(t, p, CMDFilterAdd, &payload.FilterAdd{Data: []byte{1, 2, 3}})
+		require.Nil(t, p.Filter())
+	})
+
+	s.testHandleMessage(t, p, CMDFilterLoad, &payload.FilterLoad{Filter: make([]byte, 128), K: 5, Tweak: 42})
+	require.NotNil(t, p.Filter())
+	t.Run("no matches", func(t *testing.T) {
+		getBlock(t)
+		require.Nil(t, blk)
+		require.NotNil(t, merkle)
+		require.Equal(t, 4, merkle.TxCount)
+		require.Equal(t, []byte{0}, merkle.Flags)
+		require.Equal(t, b.ComputeMerkleRoot(), hash.CalcMerkleRoot(merkle.Hashes))
+		require.Equal(t, 0, len(received))
+	})
+	t.Run("match by signer", func(t *testing.T) {
+		s.testHandleMessage(t, p, CMDFilterAdd, &payload.FilterAdd{Data: b.Transactions[1].Signers[0].Account.BytesBE()})
+		getBlock(t)
+		require.Equal(t, []byte{0b0010}, merkle.Flags)
+		require.Equal(t, []*transaction.Transaction{b.Transactions[1]}, received)
+	})
+	t.Run("match by hash", func(t *testing.T) {
+		s.testHandleMessage(t, p, CMDFilterAdd, &payload.FilterAdd{Data: b.Transactions[3].Hash().BytesBE()})
+		getBlock(t)
+		require.Equal(t, []byte{0b1010}, merkle.Flags)
+		require.Equal(t, []*transaction.Transaction{b.Transactions[1], b.Transactions[3]}, received)
+	})
+	t.Run("clear", func(t *testing.T) {
+		s.testHandleMessage(t, p, CMDFilterClear, payload.NewNullPayload())
+		require.Nil(t, p.Filter())
+		getBlock(t)
+		require.Nil(t, merkle)
+		require.Equal(t, b, blk)
+	})
+}
+
 func initGetBlocksTest(t *testing.T) (*Server, []*block.Block) {
 	s := startTestServer(t)
 
@@ -861,6 +1055,225 @@ func TestMemPool(t *testing.T) {
 	require.ElementsMatch(t, expected, actual)
 }
 
+func TestCompactBlock(t *testing.T) {
+	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/", CompactBlocks: true})
+	ch := startWithChannel(s)
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-ch
+	})
+	bc := s.chain.(*fakechain.FakeChain)
+
+	var msgs []*Message
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		msgs = append(msgs, msg)
+	}
+	// newBlock creates the next block with the given number of transactions
+	// and puts the first inPool of them into the mempool.
+	newBlock := func(t *testing.T, txCount, inPool int) *block.Block {
+		b := block.New(false)
+		b.Index = bc.BlockHeight() + 1
+		b.PrevHash = random.Uint256()
+		b.Transactions = make([]*transaction.Transaction, txCount)
+		for i := range b.Transactions {
+			b.Transactions[i] = newDummyTx()
+			if i < inPool {
+				require.NoError(t, bc.Pool.Add(b.Transactions[i], &feerStub{blockHeight: 10}))
+			}
+		}
+		b.RebuildMerkleRoot()
+		b.Hash()
+		return b
+	}
+	waitForBlock := func(t *testing.T, b *block.Block) {
+		require.Eventually(t, func() bool { return bc.BlockHeight() == b.Index }, time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("negotiation", func(t *testing.T) {
+		ver, err := newTestServer(t, ServerConfig{CompactBlocks: true}).getVersionMsg()
+		require.NoError(t, err)
+		require.Contains(t, ver.Payload.(*payload.Version).Capabilities, capability.Capability{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{Version: payload.CompactBlockVersion},
+		})
+
+		t.Run("no capability", func(t *testing.T) {
+			var sent []*Message
+			p := newLocalPeer(t, s)
+			p.messageHandler = func(t *testing.T, msg *Message) {
+				sent = append(sent, msg)
+			}
+			s.testHandleMessage(t, p, CMDVerack, payload.NewNullPayload())
+			require.Equal(t, 0, len(sent))
+			s.testHandleMessage(t, p, CMDSendCompact, payload.NewSendCompact())
+			require.False(t, s.supportsCompactBlocks(p))
+		})
+
+		var sent []*Message
+		p := newLocalPeer(t, s)
+		p.version = &payload.Version{Capabilities: capability.Capabilities{{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{Version: payload.CompactBlockVersion},
+		}}}
+		p.messageHandler = func(t *testing.T, msg *Message) {
+			sent = append(sent, msg)
+		}
+		s.testHandleMessage(t, p, CMDVerack, payload.NewNullPayload())
+		require.Equal(t, 1, len(sent))
+		require.Equal(t, CMDSendCompact, sent[0].Command)
+		require.False(t, s.supportsCompactBlocks(p))
+
+		s.testHandleMessage(t, p, CMDSendCompact, &payload.SendCompact{Version: payload.CompactBlockVersion + 1})
+		require.False(t, s.supportsCompactBlocks(p))
+		s.testHandleMessage(t, p, CMDSendCompact, payload.NewSendCompact())
+		require.True(t, s.supportsCompactBlocks(p))
+
+		s.removeCompactPeer(p)
+		require.False(t, s.supportsCompactBlocks(p))
+	})
+	t.Run("from mempool", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 3, 3)
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		waitForBlock(t, b)
+		require.Equal(t, 0, len(msgs))
+	})
+	t.Run("missing transactions", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 3, 1)
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		require.Equal(t, 1, len(msgs))
+		require.Equal(t, CMDGetBlockTxn, msgs[0].Command)
+		req := msgs[0].Payload.(*payload.GetBlockTxn)
+		require.Equal(t, b.Hash(), req.BlockHash)
+		require.Equal(t, []uint16{1, 2}, req.Indexes)
+
+		// Response is expected from the same peer only.
+		s.testHandleMessage(t, nil, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: b.Transactions[1:],
+		})
+		require.Equal(t, b.Index-1, bc.BlockHeight())
+
+		err := s.handleMessage(p, NewMessage(CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: b.Transactions[2:],
+		}))
+		require.True(t, errors.Is(err, errInvalidBlockTxn))
+
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		s.testHandleMessage(t, p, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: b.Transactions[1:],
+		})
+		waitForBlock(t, b)
+	})
+	t.Run("invalid transactions", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 2, 1)
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		s.testHandleMessage(t, p, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: []*transaction.Transaction{newDummyTx()},
+		})
+		require.Equal(t, 2, len(msgs))
+		require.Equal(t, CMDGetData, msgs[1].Command)
+		require.Equal(t, []util.Uint256{b.Hash()}, msgs[1].Payload.(*payload.Inventory).Hashes)
+		require.Equal(t, b.Index-1, bc.BlockHeight())
+
+		s.testHandleMessage(t, p, CMDBlock, b)
+		waitForBlock(t, b)
+	})
+	t.Run("too far ahead", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 2, 1)
+		b.Index++
+		b.RebuildMerkleRoot()
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		require.Equal(t, 0, len(msgs))
+	})
+	t.Run("timeout", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 3, 1)
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42))
+		require.Equal(t, 1, len(msgs))
+		require.Equal(t, CMDGetBlockTxn, msgs[0].Command)
+
+		var fullReq []*Message
+		other := newLocalPeer(t, s)
+		other.handshaked = true
+		other.lastBlockIndex = b.Index
+		other.messageHandler = func(t *testing.T, msg *Message) {
+			// Pings are sent to this peer concurrently.
+			if msg.Command == CMDGetData {
+				fullReq = append(fullReq, msg)
+			}
+		}
+		s.lock.Lock()
+		s.peers[other] = true
+		s.lock.Unlock()
+		t.Cleanup(func() {
+			s.lock.Lock()
+			delete(s.peers, other)
+			s.lock.Unlock()
+		})
+
+		s.expireCompactBlocks(time.Now())
+		require.Equal(t, 0, len(fullReq))
+		// Full block is requested from the other peer having it.
+		s.expireCompactBlocks(time.Now().Add(compactBlockTimeout))
+		require.Equal(t, 1, len(fullReq))
+		require.Equal(t, CMDGetData, fullReq[0].Command)
+		require.Equal(t, []util.Uint256{b.Hash()}, fullReq[0].Payload.(*payload.Inventory).Hashes)
+
+		// The same block can be received as a compact one again.
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 43))
+		require.Equal(t, 2, len(msgs))
+		require.Equal(t, CMDGetBlockTxn, msgs[1].Command)
+
+		// Pending blocks are dropped on disconnection.
+		s.removeCompactPeer(p)
+		s.compactLock.Lock()
+		require.Equal(t, 0, len(s.compactBlocks))
+		s.compactLock.Unlock()
+
+		s.testHandleMessage(t, other, CMDBlock, b)
+		waitForBlock(t, b)
+	})
+	t.Run("get transactions", func(t *testing.T) {
+		msgs = nil
+		b := newBlock(t, 3, 0)
+		s.testHandleMessage(t, p, CMDGetBlockTxn, payload.NewGetBlockTxn(b.Hash(), []uint16{0, 2}))
+		require.Equal(t, 1, len(msgs))
+		require.Equal(t, CMDNotFound, msgs[0].Command)
+
+		s.testHandleMessage(t, p, CMDBlock, b)
+		waitForBlock(t, b)
+		s.testHandleMessage(t, p, CMDGetBlockTxn, payload.NewGetBlockTxn(b.Hash(), []uint16{0, 2}))
+		require.Equal(t, 2, len(msgs))
+		require.Equal(t, CMDBlockTxn, msgs[1].Command)
+		resp := msgs[1].Payload.(*payload.BlockTxn)
+		require.Equal(t, b.Hash(), resp.BlockHash)
+		require.Equal(t, 2, len(resp.Transactions))
+		require.Equal(t, b.Transactions[0].Hash(), resp.Transactions[0].Hash())
+		require.Equal(t, b.Transactions[2].Hash(), resp.Transactions[1].Hash())
+
+		err := s.handleMessage(p, NewMessage(CMDGetBlockTxn, payload.NewGetBlockTxn(b.Hash(), []uint16{3})))
+		require.True(t, errors.Is(err, errInvalidTxIndex))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		s := startTestServer(t)
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		b := newBlock(t, 1, 1)
+		require.Error(t, s.handleMessage(p, NewMessage(CMDCompactBlock, payload.NewCompactBlock(b, 42))))
+		s.testHandleMessage(t, p, CMDSendCompact, payload.NewSendCompact())
+		require.False(t, s.supportsCompactBlocks(p))
+	})
+}
+
 func TestVerifyNotaryRequest(t *testing.T) {
 	bc := fakechain.NewFakeChain()
 	bc.MaxVerificationGAS = 10
@@ -900,3 +1313,142 @@ func TestVerifyNotaryRequest(t *testing.T) {
 		require.NoError(t, verifyNotaryRequest(bc, nil, r))
 	})
 }
+
+func TestGetMPTData(t *testing.T) {
+	t.Run("P2PStateExchangeExtensions off", func(t *testing.T) {
+		s := startTestServer(t)
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		msg := NewMessage(CMDGetMPTData, &payload.MPTInventory{
+			Hashes: []util.Uint256{{1, 2, 3}},
+		})
+		require.Error(t, s.handleMessage(p, msg))
+	})
+	t.Run("good", func(t *testing.T) {
+		s := startTestServer(t)
+		bc := s.chain.(*fakechain.FakeChain)
+		bc.P2PStateExchangeExtensions = true
+		node := []byte{1, 2, 3}
+		bc.StateSync.MPTNodes[util.Uint256{1}] = node
+
+		var actual *payload.MPTData
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		p.messageHandler = func(t *testing.T, msg *Message) {
+			if msg.Command == CMDMPTData {
+				actual = msg.Payload.(*payload.MPTData)
+			}
+		}
+		s.testHandleMessage(t, p, CMDGetMPTData, &payload.MPTInventory{
+			Hashes: []util.Uint256{{2}},
+		})
+		require.Nil(t, actual)
+
+		s.testHandleMessage(t, p, CMDGetMPTData, &payload.MPTInventory{
+			Hashes: []util.Uint256{{1}, {2}},
+		})
+		require.NotNil(t, actual)
+		require.Equal(t, [][]byte{node}, actual.Nodes)
+	})
+}
+
+func TestMPTData(t *testing.T) {
+	t.Run("P2PStateExchangeExtensions off", func(t *testing.T) {
+		s := startTestServer(t)
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		msg := NewMessage(CMDMPTData, &payload.MPTData{
+			Nodes: [][]byte{{1, 2, 3}},
+		})
+		require.Error(t, s.handleMessage(p, msg))
+	})
+	t.Run("good", func(t *testing.T) {
+		s := startTestServer(t)
+		bc := s.chain.(*fakechain.FakeChain)
+		bc.P2PStateExchangeExtensions = true
+		nodes := [][]byte{{1, 2, 3}, {4, 5, 6}}
+
+		// Not needed, ignored.
+		s.testHandleMessage(t, nil, CMDMPTData, &payload.MPTData{Nodes: nodes})
+		require.Nil(t, bc.StateSync.AddedMPTNodes)
+
+		bc.StateSync.IsActiveFlag = true
+		bc.StateSync.NeedMPTNodesFlag = true
+		bc.StateSync.MPTNodes[util.Uint256{7}] = []byte{7}
+
+		var actual *payload.MPTInventory
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		p.messageHandler = func(t *testing.T, msg *Message) {
+			if msg.Command == CMDGetMPTData {
+				actual = msg.Payload.(*payload.MPTInventory)
+			}
+		}
+		s.testHandleMessage(t, p, CMDMPTData, &payload.MPTData{Nodes: nodes})
+		require.Equal(t, nodes, bc.StateSync.AddedMPTNodes)
+		require.NotNil(t, actual)
+		require.Equal(t, []util.Uint256{{7}}, actual.Hashes)
+	})
+}
+
+func TestStateSyncRequests(t *testing.T) {
+	s := startTestServer(t)
+	bc := s.chain.(*fakechain.FakeChain)
+	ss := bc.StateSync
+	ss.IsActiveFlag = true
+
+	var actual []*Message
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.lastBlockIndex = 10
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		actual = append(actual, msg)
+	}
+
+	t.Run("headers", func(t *testing.T) {
+		ss.NeedHeadersFlag = true
+		actual = nil
+		require.NoError(t, s.requestBlocks(p))
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, CMDGetHeaders, actual[0].Command)
+		require.Equal(t, payload.NewGetBlockByIndex(1, -1), actual[0].Payload)
+
+		hdrs := []*block.Header{{Index: 1}, {Index: 2}}
+		actual = nil
+		s.testHandleMessage(t, p, CMDHeaders, &payload.Headers{Hdrs: hdrs})
+		require.Equal(t, hdrs, ss.Headers)
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, CMDGetHeaders, actual[0].Command)
+
+		atomic2.StoreUint32(&bc.Blockheight, 10)
+		actual = nil
+		require.NoError(t, s.requestBlocks(p))
+		require.Equal(t, 0, len(actual))
+		require.Equal(t, 1, ss.SyncPointCalls)
+		ss.NeedHeadersFlag = false
+	})
+	t.Run("MPT nodes", func(t *testing.T) {
+		ss.NeedMPTNodesFlag = true
+		ss.MPTNodes[util.Uint256{1}] = []byte{1}
+		actual = nil
+		require.NoError(t, s.requestBlocks(p))
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, CMDGetMPTData, actual[0].Command)
+		require.Equal(t, []util.Uint256{{1}}, actual[0].Payload.(*payload.MPTInventory).Hashes)
+		ss.NeedMPTNodesFlag = false
+	})
+	t.Run("blocks", func(t *testing.T) {
+		ss.NeedBlocksFlag = true
+		ss.Height = 5
+		actual = nil
+		require.NoError(t, s.requestBlocks(p))
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, CMDGetBlockByIndex, actual[0].Command)
+		require.Equal(t, payload.NewGetBlockByIndex(6, -1), actual[0].Payload)
+
+		b := block.New(false)
+		b.Index = 6
+		s.testHandleMessage(t, nil, CMDBlock, b)
+		require.Equal(t, []*block.Block{b}, ss.Blocks)
+	})
+}
@@ -0,0 +1,417 @@
+/*
+Package simulator allows to run a network of several neo-go nodes within a
+single process for testing purposes. Nodes are connected via in-memory
+transport (see network.MemoryNetwork), so network conditions like latency,
+packet loss and partitions can be simulated. Every node has its own in-memory
+chain (the same one neotest/chain package creates, with four validators and
+six committee members) and validator nodes have wallets to participate in
+consensus, so multi-node scenarios (like dBFT view changes or state root
+exchange) can be tested with plain `go test`.
+*/
+package simulator
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/testchain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// Default simulation parameters.
+const (
+	// DefaultTimePerBlock is the default block interval used by consensus.
+	DefaultTimePerBlock = 200 * time.Millisecond
+	// WalletPassword is the password of validator node wallets.
+	WalletPassword = "one"
+	// basePort is the port of the first node, others use the following ones.
+	basePort = 20333
+	// startTimeout is the time given to a node to connect to the others.
+	startTimeout = 10 * time.Second
+	// txTimeout is the time given to a transaction to be accepted by nodes.
+	txTimeout = 30 * time.Second
+)
+
+// Options are the network simulation options, zero value is valid and
+// creates network of validators only.
+type Options struct {
+	// Nodes is the number of nodes in the network, the first ones are
+	// validators, so it can't be less than validators count (which is used
+	// if it's zero).
+	Nodes int
+	// Seed is used for random decisions made by the memory network.
+	Seed int64
+	// TimePerBlock is the block interval used by consensus, DefaultTimePerBlock
+	// is used if it's zero.
+	TimePerBlock time.Duration
+	// ProtocolConfig allows to adjust the protocol configuration used by all
+	// nodes (validators count can be reduced, but committee can't be changed).
+	ProtocolConfig func(*config.ProtocolConfiguration)
+	// ServerConfig allows to adjust server configuration of the i-th node
+	// (to enable services like state root or notary for example).
+	ServerConfig func(i int, cfg *network.ServerConfig)
+}
+
+// Network is a simulated network of nodes.
+type Network struct {
+	t *testing.T
+	// Memory is the in-memory network nodes are connected with, it can be
+	// used to change network conditions.
+	Memory *network.MemoryNetwork
+	// Nodes are the network nodes, validators go first.
+	Nodes []*Node
+}
+
+// Node is a single node of the network.
+type Node struct {
+	// Address is the node address in the memory network.
+	Address string
+	// Chain is the node's blockchain.
+	Chain *core.Blockchain
+	// Server is the node's network server.
+	Server *network.Server
+	// Wallet is the wallet configuration of the validator node, it's nil for
+	// other nodes. The wallet contains the validator account only.
+	Wallet *config.Wallet
+	// Key is the validator node key, it's nil for other nodes.
+	Key *keys.PrivateKey
+
+	started bool
+	errCh   chan error
+	// done is closed when the server is stopped.
+	done chan struct{}
+}
+
+// testLog passes node logs to the test until the network is stopped. Some
+// node goroutines can still be finishing after that, but logging to the
+// completed test is not allowed.
+type testLog struct {
+	*testing.T
+	lock    sync.RWMutex
+	stopped bool
+}
+
+// Logf implements zaptest.TestingT interface.
+func (l *testLog) Logf(format string, args ...interface{}) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	if !l.stopped {
+		l.T.Logf(format, args...)
+	}
+}
+
+func (l *testLog) stop() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.stopped = true
+}
+
+// New creates a new network with the given options, nodes are not started.
+// Nodes are stopped and all resources are released when the test finishes.
+func New(t *testing.T, opts Options) *Network {
+	cfg := config.ProtocolConfiguration{
+		Magic:              netmode.UnitTestNet,
+		MaxTraceableBlocks: 1000,
+		SecondsPerBlock:    1,
+		StandbyCommittee:   make([]string, testchain.CommitteeSize()),
+		ValidatorsCount:    testchain.ValidatorsCount,
+		VerifyBlocks:       true,
+		VerifyTransactions: true,
+	}
+	for i := range cfg.StandbyCommittee {
+		cfg.StandbyCommittee[i] = hex.EncodeToString(testchain.PrivateKeyByID(i).PublicKey().Bytes())
+	}
+	if opts.ProtocolConfig != nil {
+		opts.ProtocolConfig(&cfg)
+	}
+	require.True(t, 0 < cfg.ValidatorsCount && cfg.ValidatorsCount <= testchain.CommitteeSize(),
+		"invalid validators count")
+	if opts.Nodes == 0 {
+		opts.Nodes = cfg.ValidatorsCount
+	}
+	require.True(t, opts.Nodes >= cfg.ValidatorsCount, "there should be at least as many nodes as validators")
+	if opts.TimePerBlock == 0 {
+		opts.TimePerBlock = DefaultTimePerBlock
+	}
+
+	// Cleanup functions are called in reverse order, so logs are stopped
+	// after all nodes.
+	tl := &testLog{T: t}
+	t.Cleanup(tl.stop)
+
+	walletDir, err := ioutil.TempDir("", "simulator")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(walletDir) })
+
+	n := &Network{
+		t:      t,
+		Memory: network.NewMemoryNetwork(opts.Seed),
+		Nodes:  make([]*Node, opts.Nodes),
+	}
+	addrs := make([]string, opts.Nodes)
+	for i := range addrs {
+		addrs[i] = "127.0.0.1:" + strconv.Itoa(basePort+i)
+	}
+	for i := range n.Nodes {
+		node := &Node{
+			Address: addrs[i],
+			errCh:   make(chan error, 1),
+			done:    make(chan struct{}),
+		}
+		if i < cfg.ValidatorsCount {
+			node.Key = testchain.PrivateKeyByID(i)
+			node.Wallet = newWallet(t, filepath.Join(walletDir, "wallet"+strconv.Itoa(i)+".json"), node.Key)
+		}
+
+		// Every node connects to the preceding ones only (see Start).
+		seeds := addrs[:i]
+		serverCfg := network.ServerConfig{
+			UserAgent:         "/neo-go-simulator/",
+			Address:           "127.0.0.1",
+			Port:              uint16(basePort + i),
+			Net:               cfg.Magic,
+			Relay:             true,
+			Seeds:             seeds,
+			MinPeers:          1,
+			AttemptConnPeers:  len(seeds),
+			MaxPeers:          100,
+			DialTimeout:       time.Second,
+			ProtoTickInterval: opts.TimePerBlock,
+			PingInterval:      time.Minute,
+			PingTimeout:       time.Minute,
+			Wallet:            node.Wallet,
+			TimePerBlock:      opts.TimePerBlock,
+		}
+		if opts.ServerConfig != nil {
+			opts.ServerConfig(i, &serverCfg)
+		}
+
+		log := zaptest.NewLogger(tl).Named(node.Address)
+		node.Chain, err = core.NewBlockchain(storage.NewMemoryStore(), cfg, log)
+		require.NoError(t, err)
+		go node.Chain.Run()
+		t.Cleanup(node.Chain.Close)
+
+		addr := node.Address
+		node.Server, err = network.NewServerWithTransport(serverCfg, node.Chain, log,
+			func(s *network.Server) network.Transporter {
+				return network.NewMemoryTransport(n.Memory, s, addr)
+			})
+		require.NoError(t, err)
+		n.Nodes[i] = node
+	}
+	t.Cleanup(n.stop)
+	return n
+}
+
+// newWallet creates wallet with the account for the given key.
+func newWallet(t *testing.T, path string, key *keys.PrivateKey) *config.Wallet {
+	w, err := wallet.NewWallet(path)
+	require.NoError(t, err)
+	acc := wallet.NewAccountFromPrivateKey(key)
+	require.NoError(t, acc.Encrypt(WalletPassword))
+	w.AddAccount(acc)
+	require.NoError(t, w.Save())
+	w.Close()
+	return &config.Wallet{Path: path, Password: WalletPassword}
+}
+
+// Start starts all nodes of the network one by one, every node is started
+// after the previous one is connected to some other node. Simultaneous
+// connection attempts from both sides can leave nodes disconnected, so this
+// makes initial connection process more predictable.
+func (n *Network) Start() {
+	for i := range n.Nodes {
+		n.StartNode(i)
+		if i > 0 {
+			n.WaitForPeers(1, startTimeout, n.Nodes[i])
+		}
+	}
+}
+
+// StartNode starts the i-th node if it's not started yet. Stopped nodes can't
+// be started again.
+func (n *Network) StartNode(i int) {
+	node := n.Nodes[i]
+	if node.started {
+		return
+	}
+	node.started = true
+	go func() {
+		node.Server.Start(node.errCh)
+		close(node.done)
+	}()
+}
+
+// stop shuts down all started nodes and waits for them to stop, so that
+// nothing is logged after the test is finished.
+func (n *Network) stop() {
+	for _, node := range n.Nodes {
+		if node.started {
+			node.Server.Shutdown()
+			<-node.done
+		}
+	}
+}
+
+// Validators returns validator nodes.
+func (n *Network) Validators() []*Node {
+	var res []*Node
+	for _, node := range n.Nodes {
+		if node.Wallet != nil {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+// Addresses returns addresses of the given nodes (or all nodes if none are
+// given) that can be used for network partitioning.
+func (n *Network) Addresses(nodes ...*Node) []string {
+	if len(nodes) == 0 {
+		nodes = n.Nodes
+	}
+	res := make([]string, len(nodes))
+	for i := range nodes {
+		res[i] = nodes[i].Address
+	}
+	return res
+}
+
+// WaitForHeight waits for the given nodes (or all nodes if none are given) to
+// reach the given block height and fails the test if they don't do so in the
+// specified time.
+func (n *Network) WaitForHeight(height uint32, timeout time.Duration, nodes ...*Node) {
+	if len(nodes) == 0 {
+		nodes = n.Nodes
+	}
+	require.Eventually(n.t, func() bool {
+		for _, node := range nodes {
+			if node.Chain.BlockHeight() < height {
+				return false
+			}
+		}
+		return true
+	}, timeout, 10*time.Millisecond, "nodes haven't reached height %d", height)
+}
+
+// WaitForPeers waits for the given nodes (or all nodes if none are given) to
+// have at least the specified number of handshaked peers and fails the test
+// if they don't do so in the specified time.
+func (n *Network) WaitForPeers(count int, timeout time.Duration, nodes ...*Node) {
+	if len(nodes) == 0 {
+		nodes = n.Nodes
+	}
+	require.Eventually(n.t, func() bool {
+		for _, node := range nodes {
+			if node.Server.HandshakedPeersCount() < count {
+				return false
+			}
+		}
+		return true
+	}, timeout, 10*time.Millisecond, "nodes don't have %d peers", count)
+}
+
+// WaitForTx waits for the transaction to be persisted by the given nodes (or
+// all started nodes if none are given) and fails the test if it doesn't happen
+// in the specified time or if the transaction is not executed successfully.
+func (n *Network) WaitForTx(h util.Uint256, timeout time.Duration, nodes ...*Node) {
+	if len(nodes) == 0 {
+		nodes = n.started()
+	}
+	require.Eventually(n.t, func() bool {
+		for _, node := range nodes {
+			if _, err := node.Chain.GetAppExecResults(h, trigger.Application); err != nil {
+				return false
+			}
+		}
+		return true
+	}, timeout, 10*time.Millisecond, "transaction %s is not persisted", h.StringLE())
+	aers, err := nodes[0].Chain.GetAppExecResults(h, trigger.Application)
+	require.NoError(n.t, err)
+	require.Equal(n.t, vm.HaltState, aers[0].VMState, aers[0].FaultException)
+}
+
+// SendTx relays the transaction via the given node and waits for it to be
+// accepted by all started nodes.
+func (n *Network) SendTx(node *Node, tx *transaction.Transaction) {
+	require.NoError(n.t, node.Server.RelayTxn(tx))
+	n.WaitForTx(tx.Hash(), txTimeout)
+}
+
+// NewCommitteeTx returns a transaction with the given script signed by the
+// validators (paying fees with the GAS they get in the genesis block) and by
+// the committee.
+func (n *Network) NewCommitteeTx(script []byte) *transaction.Transaction {
+	chain := n.Nodes[0].Chain
+	tx := transaction.New(script, 10_0000_0000)
+	tx.NetworkFee = 1_0000_0000
+	tx.ValidUntilBlock = chain.BlockHeight() + 100
+	tx.Signers = []transaction.Signer{
+		{
+			Account: testchain.MultisigScriptHash(),
+			Scopes:  transaction.CalledByEntry,
+		},
+		{
+			Account: testchain.CommitteeScriptHash(),
+			Scopes:  transaction.CalledByEntry,
+		},
+	}
+	require.NoError(n.t, testchain.SignTx(chain, tx))
+	tx.Scripts = append(tx.Scripts, transaction.Witness{
+		InvocationScript:   testchain.SignCommittee(tx),
+		VerificationScript: testchain.CommitteeVerificationScript(),
+	})
+	return tx
+}
+
+// Designate designates the given validator nodes for the role and waits for
+// the designation to be accepted by all started nodes. Designation takes
+// effect from the next block.
+func (n *Network) Designate(role noderoles.Role, nodes ...*Node) {
+	pubs := make([]interface{}, len(nodes))
+	for i := range nodes {
+		require.NotNil(n.t, nodes[i].Key, "only validators can be designated")
+		pubs[i] = nodes[i].Key.PublicKey().Bytes()
+	}
+	h, err := n.Nodes[0].Chain.GetNativeContractScriptHash(nativenames.Designation)
+	require.NoError(n.t, err)
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, h, "designateAsRole", callflag.States|callflag.AllowNotify, int64(role), pubs)
+	require.NoError(n.t, w.Err)
+	n.SendTx(n.Nodes[0], n.NewCommitteeTx(w.Bytes()))
+}
+
+// started returns started nodes.
+func (n *Network) started() []*Node {
+	var res []*Node
+	for _, node := range n.Nodes {
+		if node.started {
+			res = append(res, node)
+		}
+	}
+	return res
+}
@@ -0,0 +1,273 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/testchain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensus(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{Nodes: 5})
+	require.Equal(t, 4, len(n.Validators()))
+	require.Nil(t, n.Nodes[4].Wallet)
+
+	n.Memory.SetLatency(10 * time.Millisecond)
+	n.Start()
+	n.WaitForHeight(3, 20*time.Second)
+
+	h := n.Nodes[0].Chain.GetHeaderHash(3)
+	for _, node := range n.Nodes[1:] {
+		require.Equal(t, h, node.Chain.GetHeaderHash(3))
+	}
+}
+
+func TestPartition(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{})
+	n.Start()
+	n.WaitForHeight(1, 20*time.Second)
+
+	// dBFT with 4 validators can't make progress with two of them cut off.
+	vals := n.Validators()
+	n.Memory.Partition(n.Addresses(vals[:2]...), n.Addresses(vals[2:]...))
+	time.Sleep(5 * DefaultTimePerBlock)
+	h := n.Nodes[0].Chain.BlockHeight()
+	time.Sleep(10 * DefaultTimePerBlock)
+	for _, node := range n.Nodes {
+		require.True(t, node.Chain.BlockHeight() <= h+1)
+	}
+
+	n.Memory.Heal()
+	n.WaitForHeight(h+2, 60*time.Second)
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{Nodes: 5})
+	vals := n.Validators()
+	for i := range vals {
+		n.StartNode(i)
+		if i > 0 {
+			n.WaitForPeers(1, startTimeout, vals[i])
+		}
+	}
+	n.WaitForHeight(20, 30*time.Second, vals...)
+
+	// The last node joins later and catches up with the others.
+	late := n.Nodes[4]
+	n.StartNode(4)
+	n.WaitForPeers(len(vals), startTimeout, late)
+	n.WaitForHeight(20, 30*time.Second, late)
+
+	progress := late.Server.SyncProgress()
+	require.True(t, progress.Target >= 20)
+	require.Equal(t, len(vals), len(progress.Peers))
+	var blocks uint64
+	for _, p := range progress.Peers {
+		blocks += p.Blocks
+	}
+	require.True(t, blocks > 0)
+}
+
+func TestCompactBlocks(t *testing.T) {
+	t.Parallel()
+	// Validators and the first non-validator node relay compact blocks, the
+	// last node doesn't support them, so it gets inventories.
+	n := New(t, Options{
+		Nodes: 6,
+		ServerConfig: func(i int, cfg *network.ServerConfig) {
+			cfg.CompactBlocks = i < 5
+		},
+	})
+	n.Start()
+	n.WaitForHeight(5, 30*time.Second)
+
+	h := n.Nodes[0].Chain.GetHeaderHash(5)
+	for _, node := range n.Nodes[1:] {
+		require.Equal(t, h, node.Chain.GetHeaderHash(5))
+	}
+}
+
+func TestViewChange(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{})
+	n.Start()
+	n.WaitForHeight(1, 20*time.Second)
+
+	// The isolated validator is the primary of every fourth block at view 0,
+	// others have to change view to accept blocks from the backup primary.
+	vals := n.Validators()
+	n.Memory.Partition(n.Addresses(vals[0]))
+	chain := vals[1].Chain
+	h := chain.BlockHeight() + 1
+	n.WaitForHeight(h+len32(vals), 60*time.Second, vals[1:]...)
+	var changed bool
+	for i := h + 1; i <= h+len32(vals); i++ {
+		hdr, err := chain.GetHeader(chain.GetHeaderHash(int(i)))
+		require.NoError(t, err)
+		if uint32(hdr.PrimaryIndex) != i%len32(vals) {
+			changed = true
+		}
+	}
+	require.True(t, changed, "no view changes")
+
+	n.Memory.Heal()
+	n.WaitForHeight(h+len32(vals), 30*time.Second)
+}
+
+func len32(nodes []*Node) uint32 {
+	return uint32(len(nodes))
+}
+
+func TestPacketLoss(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{
+		Seed: 1,
+		// Lost block inventories are only recovered after pings, so they
+		// should be sent more often than by default.
+		ServerConfig: func(i int, cfg *network.ServerConfig) {
+			cfg.PingInterval = time.Second
+		},
+	})
+	n.Start()
+	n.WaitForHeight(1, 20*time.Second)
+
+	// Lost messages are recovered by dBFT and block requests.
+	n.Memory.SetLossRate(0.1)
+	h := n.Nodes[0].Chain.BlockHeight()
+	n.WaitForHeight(h+3, 60*time.Second)
+}
+
+func TestNotary(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{
+		ProtocolConfig: func(cfg *config.ProtocolConfiguration) {
+			cfg.P2PSigExtensions = true
+		},
+		ServerConfig: func(i int, cfg *network.ServerConfig) {
+			if i == 0 {
+				cfg.P2PNotaryCfg = config.P2PNotary{Enabled: true, UnlockWallet: *cfg.Wallet}
+			}
+		},
+	})
+	n.Start()
+	vals := n.Validators()
+	n.Designate(noderoles.P2PNotary, vals[0])
+
+	chain := n.Nodes[0].Chain
+	gasHash, err := chain.GetNativeContractScriptHash(nativenames.Gas)
+	require.NoError(t, err)
+	notaryHash := chain.GetNotaryContractScriptHash()
+
+	// Requester pays for the main transaction and its deposit pays for the
+	// fallback one.
+	requester := wallet.NewAccountFromPrivateKey(vals[1].Key)
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, gasHash, "transfer", callflag.All,
+		testchain.MultisigScriptHash(), requester.Contract.ScriptHash(), 10_0000_0000, nil)
+	emit.Opcodes(w.BinWriter, opcode.ASSERT)
+	emit.AppCall(w.BinWriter, gasHash, "transfer", callflag.All,
+		testchain.MultisigScriptHash(), notaryHash, 10_0000_0000,
+		[]interface{}{requester.Contract.ScriptHash(), int64(chain.BlockHeight() + 1000)})
+	emit.Opcodes(w.BinWriter, opcode.ASSERT)
+	require.NoError(t, w.Err)
+	n.SendTx(n.Nodes[0], n.NewCommitteeTx(w.Bytes()))
+
+	main := transaction.New([]byte{byte(opcode.RET)}, 100_0000)
+	main.NetworkFee = 1_0000_0000
+	main.ValidUntilBlock = chain.BlockHeight() + 20
+	main.Signers = []transaction.Signer{
+		{Account: requester.Contract.ScriptHash()},
+		{Account: notaryHash},
+	}
+	main.Attributes = []transaction.Attribute{{
+		Type:  transaction.NotaryAssistedT,
+		Value: &transaction.NotaryAssisted{NKeys: 1},
+	}}
+	main.Scripts = []transaction.Witness{
+		{
+			InvocationScript:   append([]byte{byte(opcode.PUSHDATA1), 64}, requester.PrivateKey().SignHashable(uint32(netmode.UnitTestNet), main)...),
+			VerificationScript: requester.GetVerificationScript(),
+		},
+		{}, // Notary witness is added by the notary node.
+	}
+
+	fallback := transaction.New([]byte{byte(opcode.RET)}, 100_0000)
+	fallback.NetworkFee = 1_0000_0000
+	fallback.ValidUntilBlock = main.ValidUntilBlock
+	fallback.Signers = []transaction.Signer{
+		{Account: notaryHash},
+		{Account: requester.Contract.ScriptHash()},
+	}
+	fallback.Attributes = []transaction.Attribute{
+		{
+			Type:  transaction.NotaryAssistedT,
+			Value: &transaction.NotaryAssisted{NKeys: 0},
+		},
+		{
+			Type:  transaction.NotValidBeforeT,
+			Value: &transaction.NotValidBefore{Height: main.ValidUntilBlock - 5},
+		},
+		{
+			Type:  transaction.ConflictsT,
+			Value: &transaction.Conflicts{Hash: main.Hash()},
+		},
+	}
+	fallback.Scripts = []transaction.Witness{{
+		InvocationScript:   append([]byte{byte(opcode.PUSHDATA1), 64}, make([]byte, 64)...),
+		VerificationScript: []byte{},
+	}}
+	require.NoError(t, requester.SignTx(netmode.UnitTestNet, fallback))
+
+	req := &payload.P2PNotaryRequest{
+		MainTransaction:     main,
+		FallbackTransaction: fallback,
+	}
+	req.Witness = transaction.Witness{
+		InvocationScript:   append([]byte{byte(opcode.PUSHDATA1), 64}, requester.PrivateKey().SignHashable(uint32(netmode.UnitTestNet), req)...),
+		VerificationScript: requester.GetVerificationScript(),
+	}
+
+	// The request is sent to the node that is not a notary, it's relayed to
+	// the notary node which completes the main transaction.
+	require.NoError(t, vals[3].Server.RelayP2PNotaryRequest(req))
+	n.WaitForTx(main.Hash(), txTimeout)
+}
+
+func TestStateRoot(t *testing.T) {
+	t.Parallel()
+	n := New(t, Options{
+		ServerConfig: func(i int, cfg *network.ServerConfig) {
+			cfg.StateRootCfg = config.StateRoot{Enabled: true, UnlockWallet: *cfg.Wallet}
+		},
+	})
+	n.Start()
+	n.Designate(noderoles.StateValidator, n.Validators()...)
+
+	// State roots are signed by the majority of validators and exchanged via
+	// extensible payloads.
+	h := n.Nodes[0].Chain.BlockHeight() + 1
+	require.Eventually(t, func() bool {
+		for _, node := range n.Nodes {
+			if node.Chain.GetStateModule().CurrentValidatedHeight() < h {
+				return false
+			}
+		}
+		return true
+	}, 30*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,335 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// blockRequestSize is the number of blocks requested from a peer with a
+	// single CMDGetBlockByIndex message during synchronization.
+	blockRequestSize = 100
+	// maxPeerBlockRequests is the number of outstanding block requests a
+	// single peer can have.
+	maxPeerBlockRequests = 2
+	// blockRequestTimeout is the time a peer is given to send the next
+	// requested block, the request is reassigned to some other peer after
+	// that.
+	blockRequestTimeout = 10 * time.Second
+	// syncRateInterval is the interval sync rate is calculated for.
+	syncRateInterval = time.Second
+)
+
+type (
+	// syncManager splits the range of blocks missing locally into chunks and
+	// tracks requests for them made to different peers, so that blocks are
+	// fetched in parallel. Requests that don't progress in time are
+	// reassigned to other peers, received blocks are reordered by blockQueue.
+	syncManager struct {
+		lock sync.Mutex
+		// requests are sorted by their start index, they cover the
+		// range being synchronized with no overlaps.
+		requests []*blockRequest
+		peers    map[Peer]*peerSyncStats
+		timeout  time.Duration
+
+		rate       float64
+		rateHeight uint32
+		rateStart  time.Time
+	}
+
+	// blockRequest is a request for [start, end] range of blocks.
+	blockRequest struct {
+		start uint32
+		end   uint32
+		// next is the next block expected from the peer.
+		next uint32
+		// peer is the peer the request is assigned to, it's nil for
+		// requests that need to be reassigned and requests that are
+		// completed.
+		peer Peer
+		// failed is the last peer the request has timed out with.
+		failed   Peer
+		done     bool
+		deadline time.Time
+	}
+
+	// peerSyncStats contains synchronization statistics for a peer.
+	peerSyncStats struct {
+		// addr is the peer address used for metrics.
+		addr     string
+		blocks   uint64
+		pending  int
+		timeouts int
+	}
+
+	// SyncProgress describes the state of block synchronization.
+	SyncProgress struct {
+		// Height is the current chain height.
+		Height uint32
+		// Target is the highest block height reported by peers.
+		Target uint32
+		// Rate is the number of blocks per second added to the chain
+		// recently.
+		Rate float64
+		// Pending is the number of outstanding block requests.
+		Pending int
+		// Peers contain synchronization statistics for handshaked peers.
+		Peers []PeerSyncProgress
+	}
+
+	// PeerSyncProgress describes the contribution of a peer to block
+	// synchronization.
+	PeerSyncProgress struct {
+		Address  string
+		Height   uint32
+		Blocks   uint64
+		Pending  int
+		Timeouts int
+	}
+)
+
+func newSyncManager() *syncManager {
+	return &syncManager{
+		peers:   make(map[Peer]*peerSyncStats),
+		timeout: blockRequestTimeout,
+	}
+}
+
+// stats returns statistics of the given peer creating them if needed, it
+// must be called with the lock held.
+func (m *syncManager) stats(p Peer) *peerSyncStats {
+	st, ok := m.peers[p]
+	if !ok {
+		st = new(peerSyncStats)
+		m.peers[p] = st
+	}
+	return st
+}
+
+// cleanup removes requests for blocks that are already in the chain and
+// requests completed long ago without chain progress (some blocks could be
+// rejected by the chain or dropped by the queue, so they are requested
+// again). It must be called with the lock held.
+func (m *syncManager) cleanup(height uint32, now time.Time) {
+	var i int
+	for _, r := range m.requests {
+		if r.end <= height {
+			m.unassign(r)
+			continue
+		}
+		if r.done && r.start <= height+1 && now.After(r.deadline) {
+			continue
+		}
+		m.requests[i] = r
+		i++
+	}
+	for j := i; j < len(m.requests); j++ {
+		m.requests[j] = nil
+	}
+	m.requests = m.requests[:i]
+	if len(m.requests) != 0 && m.requests[0].start <= height {
+		m.requests[0].start = height + 1
+		if m.requests[0].next < m.requests[0].start {
+			m.requests[0].next = m.requests[0].start
+		}
+	}
+}
+
+// unassign detaches the request from its peer, it must be called with the
+// lock held.
+func (m *syncManager) unassign(r *blockRequest) {
+	if r.peer == nil {
+		return
+	}
+	if st, ok := m.peers[r.peer]; ok {
+		st.pending--
+	}
+	r.peer = nil
+}
+
+// expire reassigns requests that have no progress for the timeout and
+// returns the list of peers they were assigned to.
+func (m *syncManager) expire(now time.Time) []Peer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var res []Peer
+	for _, r := range m.requests {
+		if r.peer == nil || r.done || !now.After(r.deadline) {
+			continue
+		}
+		p := r.peer
+		m.stats(p).timeouts++
+		m.unassign(r)
+		r.failed = p
+		// Don't give this range to the same peer again for a while.
+		r.deadline = now.Add(m.timeout)
+		res = append(res, p)
+	}
+	updateSyncMetrics(m.pendingCount(), len(res))
+	return res
+}
+
+// next returns the next range of blocks to request from the given peer that
+// has blocks up to peerHeight, ok is false if there is nothing to request.
+func (m *syncManager) next(p Peer, height, peerHeight uint32, now time.Time) (start uint32, count int16, ok bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cleanup(height, now)
+	st := m.stats(p)
+	if st.pending >= maxPeerBlockRequests {
+		return 0, 0, false
+	}
+	limit := height + blockCacheSize
+	if peerHeight < limit {
+		limit = peerHeight
+	}
+	assign := func(r *blockRequest) (uint32, int16, bool) {
+		r.peer = p
+		r.deadline = now.Add(m.timeout)
+		st.pending++
+		updateSyncMetrics(m.pendingCount(), 0)
+		return r.next, int16(r.end - r.next + 1), true
+	}
+
+	// Retries go first, they're the ones blocking the queue.
+	for _, r := range m.requests {
+		if r.peer == nil && !r.done && r.end <= limit && (r.failed != p || now.After(r.deadline)) {
+			return assign(r)
+		}
+	}
+	// Then the first gap in the requested range.
+	var (
+		pos = height + 1
+		i   int
+	)
+	for ; i < len(m.requests) && m.requests[i].start <= pos; i++ {
+		pos = m.requests[i].end + 1
+	}
+	if pos > limit {
+		return 0, 0, false
+	}
+	end := pos + blockRequestSize - 1
+	if end > limit {
+		end = limit
+	}
+	if i < len(m.requests) && m.requests[i].start <= end {
+		end = m.requests[i].start - 1
+	}
+	nr := &blockRequest{start: pos, end: end, next: pos}
+	m.requests = append(m.requests, nil)
+	copy(m.requests[i+1:], m.requests[i:])
+	m.requests[i] = nr
+	return assign(nr)
+}
+
+// blockReceived accounts the block with the given index received from the
+// peer, it returns true if some request of this peer is completed.
+func (m *syncManager) blockReceived(p Peer, index uint32, now time.Time) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	i := sort.Search(len(m.requests), func(i int) bool { return m.requests[i].end >= index })
+	if i == len(m.requests) {
+		return false
+	}
+	r := m.requests[i]
+	if r.peer == nil || r.peer != p || index < r.next {
+		return false
+	}
+	st := m.stats(p)
+	if st.addr == "" {
+		st.addr = p.PeerAddr().String()
+	}
+	st.blocks += uint64(index - r.next + 1)
+	updatePeerSyncMetric(st.addr, st.blocks)
+	r.next = index + 1
+	r.deadline = now.Add(m.timeout)
+	if r.next <= r.end {
+		return false
+	}
+	m.unassign(r)
+	r.done = true
+	updateSyncMetrics(m.pendingCount(), 0)
+	return true
+}
+
+// blockAdded updates sync rate after the block with the given index is
+// added to the chain.
+func (m *syncManager) blockAdded(index uint32, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.rateStart.IsZero() {
+		m.rateStart, m.rateHeight = now, index
+		return
+	}
+	if elapsed := now.Sub(m.rateStart); elapsed >= syncRateInterval {
+		m.rate = float64(index-m.rateHeight) / elapsed.Seconds()
+		m.rateStart, m.rateHeight = now, index
+		updateSyncRateMetric(m.rate)
+	}
+}
+
+// removePeer unassigns all requests of the disconnected peer.
+func (m *syncManager) removePeer(p Peer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, r := range m.requests {
+		if r.peer == p {
+			m.unassign(r)
+		}
+		if r.failed == p {
+			r.failed = nil
+		}
+	}
+	if st, ok := m.peers[p]; ok && st.addr != "" {
+		deletePeerSyncMetric(st.addr)
+	}
+	delete(m.peers, p)
+	updateSyncMetrics(m.pendingCount(), 0)
+}
+
+// pendingCount returns the number of outstanding requests, it must be called
+// with the lock held.
+func (m *syncManager) pendingCount() int {
+	var n int
+	for _, r := range m.requests {
+		if r.peer != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// progress fills the given sync progress with the manager's data for the
+// given peers.
+func (m *syncManager) progress(res *SyncProgress, peers []Peer, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	res.Rate = m.rate
+	// No blocks were added for a while.
+	if now.Sub(m.rateStart) > 2*syncRateInterval {
+		res.Rate = 0
+	}
+	res.Pending = m.pendingCount()
+	res.Peers = make([]PeerSyncProgress, 0, len(peers))
+	for _, p := range peers {
+		pp := PeerSyncProgress{
+			Address: p.PeerAddr().String(),
+			Height:  p.LastBlockIndex(),
+		}
+		if st, ok := m.peers[p]; ok {
+			pp.Blocks = st.blocks
+			pp.Pending = st.pending
+			pp.Timeouts = st.timeouts
+		}
+		res.Peers = append(res.Peers, pp)
+	}
+	sort.Slice(res.Peers, func(i, j int) bool { return res.Peers[i].Address < res.Peers[j].Address })
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncManagerTimeouts(t *testing.T) {
+	var (
+		s   = newTestServer(t, ServerConfig{})
+		m   = newSyncManager()
+		p1  = newLocalPeer(t, s)
+		p2  = newLocalPeer(t, s)
+		now = time.Now()
+	)
+	checkNext := func(t *testing.T, p Peer, height, peerHeight uint32, at time.Time, start uint32, count int16) {
+		actualStart, actualCount, ok := m.next(p, height, peerHeight, at)
+		require.True(t, ok)
+		require.Equal(t, start, actualStart)
+		require.Equal(t, count, actualCount)
+	}
+
+	checkNext(t, p1, 0, 1000, now, 1, blockRequestSize)
+	checkNext(t, p2, 0, 1000, now, 101, blockRequestSize)
+	require.Nil(t, m.expire(now.Add(m.timeout/2)))
+
+	// p2 makes progress, p1 doesn't.
+	require.False(t, m.blockReceived(p2, 101, now.Add(m.timeout/2)))
+	require.Equal(t, []Peer{p1}, m.expire(now.Add(m.timeout+time.Millisecond)))
+	require.Equal(t, 1, m.peers[p1].timeouts)
+	require.Equal(t, 0, m.peers[p1].pending)
+
+	// Timed out request is not given to the same peer for a while.
+	checkNext(t, p1, 0, 1000, now.Add(m.timeout+time.Millisecond), 201, blockRequestSize)
+	checkNext(t, p2, 0, 1000, now.Add(m.timeout+time.Millisecond), 1, blockRequestSize)
+	require.Equal(t, 2, m.peers[p2].pending)
+	_, _, ok := m.next(p2, 0, 1000, now.Add(m.timeout+time.Millisecond))
+	require.False(t, ok)
+
+	// Completed request is requested again if the chain doesn't move.
+	for i := uint32(1); i < blockRequestSize; i++ {
+		require.False(t, m.blockReceived(p2, i, now))
+	}
+	require.True(t, m.blockReceived(p2, blockRequestSize, now))
+	require.Equal(t, uint64(blockRequestSize+1), m.peers[p2].blocks)
+	checkNext(t, p2, 0, 1000, now, 301, blockRequestSize)
+
+	// Requests of disconnected peers go first, then the completed one that
+	// didn't move the chain for a while.
+	m.removePeer(p1)
+	p3 := newLocalPeer(t, s)
+	checkNext(t, p3, 0, 1000, now.Add(2*m.timeout), 201, blockRequestSize)
+	checkNext(t, p3, 0, 1000, now.Add(2*m.timeout), 1, blockRequestSize)
+
+	// Chain progress removes old requests.
+	checkNext(t, p1, 150, 1000, now, 401, blockRequestSize)
+	require.Equal(t, uint32(151), m.requests[0].start)
+	require.Equal(t, 1, m.peers[p3].pending)
+}
+
+func TestSyncManagerRate(t *testing.T) {
+	var (
+		s   = newTestServer(t, ServerConfig{})
+		m   = newSyncManager()
+		now = time.Now()
+		res SyncProgress
+	)
+	m.blockAdded(10, now)
+	m.blockAdded(20, now.Add(syncRateInterval/2))
+	m.progress(&res, nil, now.Add(syncRateInterval/2))
+	require.Equal(t, float64(0), res.Rate)
+	m.blockAdded(30, now.Add(2*syncRateInterval))
+	m.progress(&res, []Peer{newLocalPeer(t, s)}, now.Add(2*syncRateInterval))
+	require.Equal(t, float64(10), res.Rate)
+	require.Equal(t, 1, len(res.Peers))
+	m.progress(&res, nil, now.Add(10*syncRateInterval))
+	require.Equal(t, float64(0), res.Rate)
+}
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
@@ -26,6 +28,9 @@ const (
 	requestQueueSize   = 32
 	p2pMsgQueueSize    = 16
 	hpRequestQueueSize = 4
+
+	// getDataExpiration is the time getdata requests are remembered for.
+	getDataExpiration = time.Minute
 )
 
 var (
@@ -62,10 +67,15 @@ type TCPPeer struct {
 
 	// track outstanding getaddr requests.
 	getAddrSent atomic.Int32
+	// hashes requested with getdata and request times.
+	getDataSent map[util.Uint256]time.Time
 
 	// number of sent pings.
 	pingSent  int
 	pingTimer *time.Timer
+
+	// bloom filter set by the peer.
+	filter *bloom.Filter
 }
 
 // NewTCPPeer returns a TCPPeer structure based on the given connection.
@@ -171,7 +181,15 @@ func (p *TCPPeer) handleConn() {
 			if err == payload.ErrTooManyHeaders {
 				p.server.log.Warn("not all headers were processed")
 				r.Err = nil
+			} else if errors.Is(err, errUnknownCommand) && p.Handshaked() {
+				// Extensions not known to us are ignored, the payload
+				// is read completely, so the next message can be decoded.
+				p.server.log.Debug("skipping unknown message", zap.Stringer("addr", p.RemoteAddr()), zap.Error(err))
+				continue
 			} else if err != nil {
+				if r.Err == nil { // It's not an I/O error, but malformed message.
+					err = invalidData(err)
+				}
 				break
 			}
 			if err = p.server.handleMessage(p, msg); err != nil {
@@ -254,7 +272,7 @@ func (p *TCPPeer) StartProtocol() {
 		zap.Uint32("startHeight", p.lastBlockIndex),
 		zap.Uint32("id", p.Version().Nonce))
 
-	p.server.discovery.RegisterGoodAddr(p.PeerAddr().String(), p.version.Capabilities)
+	p.server.discovery.RegisterGoodAddr(p.PeerAddr().String(), p.Version().Capabilities)
 	if p.server.chain.BlockHeight() < p.LastBlockIndex() {
 		err = p.server.requestBlocks(p)
 		if err != nil {
@@ -388,8 +406,9 @@ func (p *TCPPeer) RemoteAddr() net.Addr {
 // PeerAddr implements the Peer interface.
 func (p *TCPPeer) PeerAddr() net.Addr {
 	remote := p.conn.RemoteAddr()
+	version := p.Version()
 	// The network can be non-tcp in unit tests.
-	if p.version == nil || remote.Network() != "tcp" {
+	if version == nil || remote.Network() != "tcp" {
 		return p.RemoteAddr()
 	}
 	host, _, err := net.SplitHostPort(remote.String())
@@ -397,7 +416,7 @@ func (p *TCPPeer) PeerAddr() net.Addr {
 		return p.RemoteAddr()
 	}
 	var port uint16
-	for _, cap := range p.version.Capabilities {
+	for _, cap := range version.Capabilities {
 		if cap.Type == capability.TCPServer {
 			port = cap.Data.(*capability.Server).Port
 		}
@@ -424,6 +443,8 @@ func (p *TCPPeer) Disconnect(err error) {
 
 // Version implements the Peer interface.
 func (p *TCPPeer) Version() *payload.Version {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
 	return p.version
 }
 
@@ -488,3 +509,50 @@ func (p *TCPPeer) CanProcessAddr() bool {
 	v := p.getAddrSent.Dec()
 	return v >= 0
 }
+
+// AddGetDataSent implements the Peer interface, requests older than
+// getDataExpiration are forgotten.
+func (p *TCPPeer) AddGetDataSent(hashes []util.Uint256) {
+	now := time.Now()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.getDataSent == nil {
+		p.getDataSent = make(map[util.Uint256]time.Time)
+	}
+	for h, t := range p.getDataSent {
+		if now.Sub(t) > getDataExpiration {
+			delete(p.getDataSent, h)
+		}
+	}
+	for _, h := range hashes {
+		p.getDataSent[h] = now
+	}
+}
+
+// CanProcessNotFound implements the Peer interface.
+func (p *TCPPeer) CanProcessNotFound(hashes []util.Uint256) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var requested bool
+	for _, h := range hashes {
+		if _, ok := p.getDataSent[h]; ok {
+			delete(p.getDataSent, h)
+			requested = true
+		}
+	}
+	return requested
+}
+
+// Filter implements the Peer interface.
+func (p *TCPPeer) Filter() *bloom.Filter {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.filter
+}
+
+// SetFilter implements the Peer interface.
+func (p *TCPPeer) SetFilter(f *bloom.Filter) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.filter = f
+}
@@ -51,6 +51,9 @@ type Options struct {
 	CACert         string
 	DialTimeout    time.Duration
 	RequestTimeout time.Duration
+	// Reconnect enables automatic reconnection and subscription restoration
+	// for WSClient, it's ignored by Client. Nil value disables reconnection.
+	Reconnect *ReconnectOptions
 }
 
 // cache stores cache values for the RPC client methods
@@ -27,21 +27,30 @@ Supported methods
 	getblocksysfee
 	getconnectioncount
 	getcontractstate
+	getnep11balances
+	getnep11properties
+	getnep11transfers
 	getnep17balances
 	getnep17transfers
 	getpeers
 	getrawmempool
 	getrawtransaction
 	getstorage
+	getstoragehistoric
 	gettransactionheight
 	getunclaimedgas
 	getvalidators
 	getversion
 	invoke
 	invokefunction
+	invokefunctionhistoric
 	invokescript
+	invokescripthistoric
 	sendrawtransaction
 	submitblock
+	terminatesession
+	tracetransaction
+	traverseiterator
 	validateaddress
 
 Unsupported methods
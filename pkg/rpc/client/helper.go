@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/rpc"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
@@ -95,3 +96,34 @@ func topMapFromStack(st []stackitem.Item) (*stackitem.Map, error) {
 	}
 	return st[index].(*stackitem.Map), nil
 }
+
+// topIteratorValuesFromInvoke returns all values of the iterator from the top
+// of the invocation result stack. If the iterator is stored in a server-side
+// session it's traversed completely and the session is terminated afterwards,
+// otherwise values unwrapped by the server are returned (an error is returned
+// if they're truncated).
+func (c *Client) topIteratorValuesFromInvoke(res *result.Invoke) ([]stackitem.Item, error) {
+	index := len(res.Stack) - 1 // top stack element is last in the array
+	iter, ok := res.Stack[index].Value().(result.Iterator)
+	if !ok {
+		return nil, fmt.Errorf("invalid stack item type: %s", res.Stack[index].Type())
+	}
+	if iter.ID == "" {
+		if iter.Truncated {
+			return nil, errors.New("iterator values are truncated by the server")
+		}
+		return iter.Values, nil
+	}
+	defer func() { _, _ = c.TerminateSession(res.Session) }()
+	var values []stackitem.Item
+	for {
+		items, err := c.TraverseIterator(res.Session, iter.ID, rpc.DefaultMaxIteratorResultItems)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return values, nil
+		}
+		values = append(values, items...)
+	}
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
 )
 
 // nepDecimals invokes `decimals` NEP* method on a specified contract.
@@ -48,15 +49,15 @@ func (c *Client) nepTotalSupply(tokenHash util.Uint160) (int64, error) {
 }
 
 // nepBalanceOf invokes `balanceOf` NEP* method on a specified contract.
-func (c *Client) nepBalanceOf(tokenHash, acc util.Uint160, tokenID *string) (int64, error) {
+func (c *Client) nepBalanceOf(tokenHash, acc util.Uint160, tokenID []byte) (int64, error) {
 	params := []smartcontract.Parameter{{
 		Type:  smartcontract.Hash160Type,
 		Value: acc,
 	}}
 	if tokenID != nil {
 		params = append(params, smartcontract.Parameter{
-			Type:  smartcontract.StringType,
-			Value: *tokenID,
+			Type:  smartcontract.ByteArrayType,
+			Value: tokenID,
 		})
 	}
 	result, err := c.InvokeFunction(tokenHash, "balanceOf", params, nil)
@@ -70,3 +71,20 @@ func (c *Client) nepBalanceOf(tokenHash, acc util.Uint160, tokenID *string) (int
 
 	return topIntFromStack(result.Stack)
 }
+
+// nepTokenInfo returns full NEP* token info.
+func (c *Client) nepTokenInfo(tokenHash util.Uint160, standard string) (*wallet.Token, error) {
+	cs, err := c.GetContractStateByHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := c.nepSymbol(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := c.nepDecimals(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.NewToken(tokenHash, cs.Manifest.Name, symbol, decimals, standard), nil
+}
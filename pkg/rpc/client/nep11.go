@@ -8,6 +8,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
@@ -35,6 +36,41 @@ func (c *Client) NEP11BalanceOf(tokenHash, owner util.Uint160) (int64, error) {
 	return c.nepBalanceOf(tokenHash, owner, nil)
 }
 
+// NEP11TokenInfo returns full NEP11 token info.
+func (c *Client) NEP11TokenInfo(tokenHash util.Uint160) (*wallet.Token, error) {
+	return c.nepTokenInfo(tokenHash, manifest.NEP11StandardName)
+}
+
+// NEP11TokensOf invokes `tokensOf` NEP11 method on a specified contract and
+// returns IDs of all tokens owned by the specified account.
+func (c *Client) NEP11TokensOf(tokenHash, owner util.Uint160) ([][]byte, error) {
+	result, err := c.InvokeFunction(tokenHash, "tokensOf", []smartcontract.Parameter{
+		{
+			Type:  smartcontract.Hash160Type,
+			Value: owner,
+		},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = getInvocationError(result)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.topIteratorValuesFromInvoke(result)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([][]byte, len(items))
+	for i := range items {
+		ids[i], err = items[i].TryBytes()
+		if err != nil {
+			return nil, fmt.Errorf("invalid token ID #%d: %w", i, err)
+		}
+	}
+	return ids, nil
+}
+
 // TransferNEP11 creates an invocation transaction that invokes 'transfer' method
 // on a given token to move the whole NEP11 token with the specified token ID to
 // given account and sends it to the network returning just a hash of it.
@@ -43,7 +79,7 @@ func (c *Client) TransferNEP11(acc *wallet.Account, to util.Uint160,
 	if !c.initDone {
 		return util.Uint256{}, errNetworkNotInitialized
 	}
-	tx, err := c.createNEP11TransferTx(acc, tokenHash, gas, cosigners, to, tokenID)
+	tx, err := c.CreateNEP11TransferTx(acc, tokenHash, gas, cosigners, to, tokenID)
 	if err != nil {
 		return util.Uint256{}, err
 	}
@@ -51,14 +87,16 @@ func (c *Client) TransferNEP11(acc *wallet.Account, to util.Uint160,
 	return c.SignAndPushTx(tx, acc, cosigners)
 }
 
-// createNEP11TransferTx is an internal helper for TransferNEP11 and
-// TransferNEP11D which creates an invocation transaction for the
+// CreateNEP11TransferTx creates an invocation transaction for the
 // 'transfer' method of a given contract (token) to move the whole (or the
 // specified amount of) NEP11 token with the specified token ID to given account
 // and returns it. The returned transaction is not signed.
+// It's used by TransferNEP11 and TransferNEP11D, token ID can be passed either
+// as a string or as a byte slice (both are emitted as byte arrays).
 // `args` for TransferNEP11:  to util.Uint160, tokenID string;
-// `args` for TransferNEP11D: from, to util.Uint160, amount int64, tokenID string.
-func (c *Client) createNEP11TransferTx(acc *wallet.Account, tokenHash util.Uint160,
+// `args` for TransferNEP11D: from, to util.Uint160, amount int64 (or *big.Int),
+// tokenID string.
+func (c *Client) CreateNEP11TransferTx(acc *wallet.Account, tokenHash util.Uint160,
 	gas int64, cosigners []SignerAccount, args ...interface{}) (*transaction.Transaction, error) {
 	w := io.NewBufBinWriter()
 	emit.AppCall(w.BinWriter, tokenHash, "transfer", callflag.All, args...)
@@ -86,8 +124,8 @@ func (c *Client) createNEP11TransferTx(acc *wallet.Account, tokenHash util.Uint1
 func (c *Client) NEP11NDOwnerOf(tokenHash util.Uint160, tokenID string) (util.Uint160, error) {
 	result, err := c.InvokeFunction(tokenHash, "ownerOf", []smartcontract.Parameter{
 		{
-			Type:  smartcontract.StringType,
-			Value: tokenID,
+			Type:  smartcontract.ByteArrayType,
+			Value: []byte(tokenID),
 		},
 	}, nil)
 	if err != nil {
@@ -118,7 +156,7 @@ func (c *Client) TransferNEP11D(acc *wallet.Account, to util.Uint160,
 	if err != nil {
 		return util.Uint256{}, fmt.Errorf("bad account address: %w", err)
 	}
-	tx, err := c.createNEP11TransferTx(acc, tokenHash, gas, cosigners, acc.Address, from, to, amount, tokenID)
+	tx, err := c.CreateNEP11TransferTx(acc, tokenHash, gas, cosigners, from, to, amount, tokenID)
 	if err != nil {
 		return util.Uint256{}, err
 	}
@@ -129,7 +167,41 @@ func (c *Client) TransferNEP11D(acc *wallet.Account, to util.Uint160,
 // NEP11DBalanceOf invokes `balanceOf` divisible NEP11 method on a
 // specified contract.
 func (c *Client) NEP11DBalanceOf(tokenHash, owner util.Uint160, tokenID string) (int64, error) {
-	return c.nepBalanceOf(tokenHash, owner, &tokenID)
+	return c.nepBalanceOf(tokenHash, owner, []byte(tokenID))
+}
+
+// NEP11DOwnerOf invokes `ownerOf` divisible NEP11 method with the specified
+// token ID on a specified contract and returns all owners of this token.
+func (c *Client) NEP11DOwnerOf(tokenHash util.Uint160, tokenID string) ([]util.Uint160, error) {
+	result, err := c.InvokeFunction(tokenHash, "ownerOf", []smartcontract.Parameter{
+		{
+			Type:  smartcontract.ByteArrayType,
+			Value: []byte(tokenID),
+		},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = getInvocationError(result)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.topIteratorValuesFromInvoke(result)
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]util.Uint160, len(items))
+	for i := range items {
+		bs, err := items[i].TryBytes()
+		if err != nil {
+			return nil, fmt.Errorf("invalid owner #%d: %w", i, err)
+		}
+		owners[i], err = util.Uint160DecodeBytesBE(bs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid owner #%d: %w", i, err)
+		}
+	}
+	return owners, nil
 }
 
 // Divisible NFT methods section end.
@@ -140,8 +212,8 @@ func (c *Client) NEP11DBalanceOf(tokenHash, owner util.Uint160, tokenID string)
 // specified contract.
 func (c *Client) NEP11Properties(tokenHash util.Uint160, tokenID string) (*stackitem.Map, error) {
 	result, err := c.InvokeFunction(tokenHash, "properties", []smartcontract.Parameter{{
-		Type:  smartcontract.StringType,
-		Value: tokenID,
+		Type:  smartcontract.ByteArrayType,
+		Value: []byte(tokenID),
 	}}, nil)
 	if err != nil {
 		return nil, err
@@ -2,8 +2,11 @@ package client
 
 import (
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
@@ -23,11 +26,30 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 )
 
 var errNetworkNotInitialized = errors.New("RPC client network is not initialized")
 
+// BanPeer bans the host of the given address on the node for the specified
+// duration (node's default ban duration is used if it's zero) with the given
+// reason. All peers from this host are disconnected. This call requires
+// PeerBansEnabled RPC server option to be enabled on the node.
+func (c *Client) BanPeer(address string, duration time.Duration, reason string) error {
+	var (
+		params = request.NewRawParams(address, int64(duration/time.Second), reason)
+		resp   bool
+	)
+	if err := c.performRequest("banpeer", params, &resp); err != nil {
+		return err
+	}
+	if !resp {
+		return errors.New("banpeer returned false")
+	}
+	return nil
+}
+
 // CalculateNetworkFee calculates network fee for transaction. The transaction may
 // have empty witnesses for contract signers and may have only verification scripts
 // filled for standard sig/multisig signers.
@@ -57,6 +79,18 @@ func (c *Client) GetApplicationLog(hash util.Uint256, trig *trigger.Type) (*resu
 	return resp, nil
 }
 
+// GetBannedPeers returns the list of hosts currently banned by the node.
+func (c *Client) GetBannedPeers() ([]result.BannedPeer, error) {
+	var (
+		params = request.NewRawParams()
+		resp   []result.BannedPeer
+	)
+	if err := c.performRequest("getbannedpeers", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetBestBlockHash returns the hash of the tallest block in the main chain.
 func (c *Client) GetBestBlockHash() (util.Uint256, error) {
 	var resp = util.Uint256{}
@@ -278,12 +312,46 @@ func (c *Client) GetNEP17Balances(address util.Uint160) (*result.NEP17Balances,
 	return resp, nil
 }
 
-// GetNEP17Transfers is a wrapper for getnep17transfers RPC. Address parameter
-// is mandatory, while all the others are optional. Start and stop parameters
-// are supported since neo-go 0.77.0 and limit and page since neo-go 0.78.0.
-// These parameters are positional in the JSON-RPC call, you can't specify limit
-// and not specify start/stop for example.
-func (c *Client) GetNEP17Transfers(address string, start, stop *uint32, limit, page *int) (*result.NEP17Transfers, error) {
+// GetNEP11Balances is a wrapper for getnep11balances RPC.
+func (c *Client) GetNEP11Balances(address util.Uint160) (*result.NEP11Balances, error) {
+	params := request.NewRawParams(address.StringLE())
+	resp := new(result.NEP11Balances)
+	if err := c.performRequest("getnep11balances", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNEP11Properties is a wrapper for getnep11properties RPC. It returns
+// properties of the NEP11 token with the given ID, well-known properties
+// (see result.KnownNEP11Properties) are returned as strings, all the others
+// are base64-encoded byte strings (or nil for Null values).
+func (c *Client) GetNEP11Properties(asset util.Uint160, tokenID []byte) (map[string]interface{}, error) {
+	params := request.NewRawParams(asset.StringLE(), hex.EncodeToString(tokenID))
+	resp := make(map[string]interface{})
+	if err := c.performRequest("getnep11properties", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNEP11Transfers is a wrapper for getnep11transfers RPC. Address parameter
+// is mandatory, while all the others are optional. These parameters are
+// positional in the JSON-RPC call, you can't specify limit and not specify
+// start/stop for example.
+func (c *Client) GetNEP11Transfers(address string, start, stop *uint32, limit, page *int) (*result.NEP11Transfers, error) {
+	params, err := packTransfersParams(address, start, stop, limit, page)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(result.NEP11Transfers)
+	if err := c.performRequest("getnep11transfers", *params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func packTransfersParams(address string, start, stop *uint32, limit, page *int) (*request.RawParams, error) {
 	params := request.NewRawParams(address)
 	if start != nil {
 		params.Values = append(params.Values, *start)
@@ -303,8 +371,21 @@ func (c *Client) GetNEP17Transfers(address string, start, stop *uint32, limit, p
 	} else if stop != nil || limit != nil || page != nil {
 		return nil, errors.New("bad parameters")
 	}
+	return &params, nil
+}
+
+// GetNEP17Transfers is a wrapper for getnep17transfers RPC. Address parameter
+// is mandatory, while all the others are optional. Start and stop parameters
+// are supported since neo-go 0.77.0 and limit and page since neo-go 0.78.0.
+// These parameters are positional in the JSON-RPC call, you can't specify limit
+// and not specify start/stop for example.
+func (c *Client) GetNEP17Transfers(address string, start, stop *uint32, limit, page *int) (*result.NEP17Transfers, error) {
+	params, err := packTransfersParams(address, start, stop, limit, page)
+	if err != nil {
+		return nil, err
+	}
 	resp := new(result.NEP17Transfers)
-	if err := c.performRequest("getnep17transfers", params, resp); err != nil {
+	if err := c.performRequest("getnep17transfers", *params, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -376,17 +457,55 @@ func (c *Client) GetRawTransactionVerbose(hash util.Uint256) (*result.Transactio
 
 // GetStorageByID returns the stored value, according to the contract ID and the stored key.
 func (c *Client) GetStorageByID(id int32, key []byte) ([]byte, error) {
-	return c.getStorage(request.NewRawParams(id, base64.StdEncoding.EncodeToString(key)))
+	return c.getStorage("getstorage", request.NewRawParams(id, base64.StdEncoding.EncodeToString(key)))
 }
 
 // GetStorageByHash returns the stored value, according to the contract script hash and the stored key.
 func (c *Client) GetStorageByHash(hash util.Uint160, key []byte) ([]byte, error) {
-	return c.getStorage(request.NewRawParams(hash.StringLE(), base64.StdEncoding.EncodeToString(key)))
+	return c.getStorage("getstorage", request.NewRawParams(hash.StringLE(), base64.StdEncoding.EncodeToString(key)))
+}
+
+// GetStorageByIDAtHeight returns the value stored at the specified block height,
+// according to the contract ID and the stored key.
+func (c *Client) GetStorageByIDAtHeight(height uint32, id int32, key []byte) ([]byte, error) {
+	return c.getStorage("getstoragehistoric", request.NewRawParams(height, id, base64.StdEncoding.EncodeToString(key)))
+}
+
+// GetStorageByHashAtHeight returns the value stored at the specified block
+// height, according to the contract script hash and the stored key.
+func (c *Client) GetStorageByHashAtHeight(height uint32, hash util.Uint160, key []byte) ([]byte, error) {
+	return c.getStorage("getstoragehistoric", request.NewRawParams(height, hash.StringLE(), base64.StdEncoding.EncodeToString(key)))
 }
 
-func (c *Client) getStorage(params request.RawParams) ([]byte, error) {
+// GetStorageByIDWithState returns the value stored in the state with the
+// specified state root hash, according to the contract ID and the stored key.
+func (c *Client) GetStorageByIDWithState(stateroot util.Uint256, id int32, key []byte) ([]byte, error) {
+	return c.getStorage("getstoragehistoric", request.NewRawParams(stateroot.StringLE(), id, base64.StdEncoding.EncodeToString(key)))
+}
+
+// GetStorageByHashWithState returns the value stored in the state with the
+// specified state root hash, according to the contract script hash and the
+// stored key.
+func (c *Client) GetStorageByHashWithState(stateroot util.Uint256, hash util.Uint160, key []byte) ([]byte, error) {
+	return c.getStorage("getstoragehistoric", request.NewRawParams(stateroot.StringLE(), hash.StringLE(), base64.StdEncoding.EncodeToString(key)))
+}
+
+func (c *Client) getStorage(method string, params request.RawParams) ([]byte, error) {
 	var resp []byte
-	if err := c.performRequest("getstorage", params, &resp); err != nil {
+	if err := c.performRequest(method, params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetSyncProgress returns the state of node's block synchronization with the
+// contribution of every connected peer.
+func (c *Client) GetSyncProgress() (*result.SyncProgress, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.SyncProgress)
+	)
+	if err := c.performRequest("getsyncprogress", params, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -455,6 +574,40 @@ func (c *Client) InvokeFunction(contract util.Uint160, operation string, params
 	return c.invokeSomething("invokefunction", p, signers)
 }
 
+// InvokeScriptAtHeight returns the result of the given script after running it
+// true the VM using the state at the specified block height.
+// NOTE: This is a test invoke and will not affect the blockchain.
+func (c *Client) InvokeScriptAtHeight(height uint32, script []byte, signers []transaction.Signer) (*result.Invoke, error) {
+	var p = request.NewRawParams(height, script)
+	return c.invokeSomething("invokescripthistoric", p, signers)
+}
+
+// InvokeScriptWithState returns the result of the given script after running it
+// true the VM using the state with the specified state root hash.
+// NOTE: This is a test invoke and will not affect the blockchain.
+func (c *Client) InvokeScriptWithState(stateroot util.Uint256, script []byte, signers []transaction.Signer) (*result.Invoke, error) {
+	var p = request.NewRawParams(stateroot.StringLE(), script)
+	return c.invokeSomething("invokescripthistoric", p, signers)
+}
+
+// InvokeFunctionAtHeight returns the results after calling the smart contract
+// scripthash with the given operation and parameters using the state at the
+// specified block height.
+// NOTE: this is test invoke and will not affect the blockchain.
+func (c *Client) InvokeFunctionAtHeight(height uint32, contract util.Uint160, operation string, params []smartcontract.Parameter, signers []transaction.Signer) (*result.Invoke, error) {
+	var p = request.NewRawParams(height, contract.StringLE(), operation, params)
+	return c.invokeSomething("invokefunctionhistoric", p, signers)
+}
+
+// InvokeFunctionWithState returns the results after calling the smart contract
+// scripthash with the given operation and parameters using the state with the
+// specified state root hash.
+// NOTE: this is test invoke and will not affect the blockchain.
+func (c *Client) InvokeFunctionWithState(stateroot util.Uint256, contract util.Uint160, operation string, params []smartcontract.Parameter, signers []transaction.Signer) (*result.Invoke, error) {
+	var p = request.NewRawParams(stateroot.StringLE(), contract.StringLE(), operation, params)
+	return c.invokeSomething("invokefunctionhistoric", p, signers)
+}
+
 // InvokeContractVerify returns the results after calling `verify` method of the smart contract
 // with the given parameters under verification trigger type.
 // NOTE: this is test invoke and will not affect the blockchain.
@@ -756,6 +909,61 @@ func (c *Client) SubmitP2PNotaryRequest(req *payload.P2PNotaryRequest) (util.Uin
 	return resp.Hash, nil
 }
 
+// TerminateSession closes iterator session with the given ID on the server,
+// so that its iterators are released without waiting for session expiration.
+// It returns true if the session was found and terminated.
+func (c *Client) TerminateSession(sessionID string) (bool, error) {
+	var (
+		params = request.NewRawParams(sessionID)
+		resp   bool
+	)
+	if err := c.performRequest("terminatesession", params, &resp); err != nil {
+		return false, err
+	}
+	return resp, nil
+}
+
+// TraceTransaction re-executes the transaction with the given hash in the
+// context it was originally executed in and returns the invocation result
+// with execution trace. Trace limits are taken from cfg if it's not nil,
+// server defaults are used otherwise.
+func (c *Client) TraceTransaction(hash util.Uint256, cfg *request.TraceConfig) (*result.Invoke, error) {
+	var (
+		params = request.NewRawParams(hash.StringLE())
+		resp   = new(result.Invoke)
+	)
+	if cfg != nil {
+		params.Values = append(params.Values, cfg)
+	}
+	if err := c.performRequest("tracetransaction", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TraverseIterator returns at most maxItemsCount next values of the iterator
+// with the given ID from the session with the given ID. Session and iterator
+// IDs are taken from the invocation result (see result.Iterator), an empty
+// list is returned when the iterator has no more values.
+func (c *Client) TraverseIterator(sessionID, iteratorID string, maxItemsCount int) ([]stackitem.Item, error) {
+	var (
+		params = request.NewRawParams(sessionID, iteratorID, maxItemsCount)
+		resp   []json.RawMessage
+	)
+	if err := c.performRequest("traverseiterator", params, &resp); err != nil {
+		return nil, err
+	}
+	items := make([]stackitem.Item, len(resp))
+	for i := range resp {
+		item, err := stackitem.FromJSONWithTypes(resp[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal iterator value #%d: %w", i, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
 // ValidateAddress verifies that the address is a correct NEO address.
 func (c *Client) ValidateAddress(address string) error {
 	var (
@@ -853,6 +1061,20 @@ func (c *Client) AddNetworkFee(tx *transaction.Transaction, extraFee int64, accs
 	return nil
 }
 
+// UnbanPeer removes the ban of the given host (address can be specified with
+// or without a port) on the node. It returns false if the host wasn't banned.
+// This call requires PeerBansEnabled RPC server option to be enabled on the node.
+func (c *Client) UnbanPeer(address string) (bool, error) {
+	var (
+		params = request.NewRawParams(address)
+		resp   bool
+	)
+	if err := c.performRequest("unbanpeer", params, &resp); err != nil {
+		return false, err
+	}
+	return resp, nil
+}
+
 // GetNetwork returns the network magic of the RPC node client connected to.
 func (c *Client) GetNetwork() netmode.Magic {
 	return c.network
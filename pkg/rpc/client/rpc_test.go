@@ -110,6 +110,37 @@ func getTxMoveNeo() *result.TransactionOutputRaw {
 // published in official C# JSON-RPC API v2.10.3 reference
 // (see https://docs.neo.org/docs/en-us/reference/rpc/latest-version/api.html)
 var rpcClientTestCases = map[string][]rpcClientTestCase{
+	"banpeer": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return nil, c.BanPeer("172.200.0.1", time.Hour, "spam")
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				// no error expected
+				return nil
+			},
+		},
+	},
+	"getbannedpeers": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetBannedPeers()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":[{"address":"172.200.0.1","reason":"spam","until":"2021-06-01T12:00:00Z"}]}`,
+			result: func(c *Client) interface{} {
+				return []result.BannedPeer{
+					{
+						Address: "172.200.0.1",
+						Reason:  "spam",
+						Until:   time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+					},
+				}
+			},
+		},
+	},
 	"getapplicationlog": {
 		{
 			name: "positive",
@@ -524,6 +555,91 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getnep11balances": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				hash, err := util.Uint160DecodeStringLE("1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3")
+				if err != nil {
+					panic(err)
+				}
+				return c.GetNEP11Balances(hash)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"balance":[{"assethash":"a48b6e1291ba24211ad11bb90ae2a10bf1fcd5a8","tokens":[{"tokenid":"abcdef","amount":"1","lastupdatedblock":251604}]}],"address":"NcEkNmgWmf7HQVQvzhxpengpnt4DXjmZLe"}}`,
+			result: func(c *Client) interface{} {
+				hash, err := util.Uint160DecodeStringLE("a48b6e1291ba24211ad11bb90ae2a10bf1fcd5a8")
+				if err != nil {
+					panic(err)
+				}
+				return &result.NEP11Balances{
+					Balances: []result.NEP11AssetBalance{{
+						Asset: hash,
+						Tokens: []result.NEP11TokenBalance{{
+							ID:          "abcdef",
+							Amount:      "1",
+							LastUpdated: 251604,
+						}},
+					}},
+					Address: "NcEkNmgWmf7HQVQvzhxpengpnt4DXjmZLe",
+				}
+			},
+		},
+	},
+	"getnep11properties": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				hash, err := util.Uint160DecodeStringLE("1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3")
+				if err != nil {
+					panic(err)
+				}
+				return c.GetNEP11Properties(hash, []byte("neo.com"))
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"name":"neo.com","expiration":"HvkNCD8B"}}`,
+			result: func(c *Client) interface{} {
+				return map[string]interface{}{
+					"name":       "neo.com",
+					"expiration": "HvkNCD8B",
+				}
+			},
+		},
+	},
+	"getnep11transfers": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetNEP11Transfers("NcEkNmgWmf7HQVQvzhxpengpnt4DXjmZLe", nil, nil, nil, nil)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"sent":[],"received":[{"timestamp":1555651816,"assethash":"600c4f5200db36177e3e8a09e9f18e2fc7d12a0f","transferaddress":"NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP","amount":"1","blockindex":436036,"transfernotifyindex":0,"txhash":"df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58","tokenid":"abcdef"}],"address":"NcEkNmgWmf7HQVQvzhxpengpnt4DXjmZLe"}}`,
+			result: func(c *Client) interface{} {
+				assetHash, err := util.Uint160DecodeStringLE("600c4f5200db36177e3e8a09e9f18e2fc7d12a0f")
+				if err != nil {
+					panic(err)
+				}
+				txHash, err := util.Uint256DecodeStringLE("df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58")
+				if err != nil {
+					panic(err)
+				}
+				return &result.NEP11Transfers{
+					Sent: []result.NEP11Transfer{},
+					Received: []result.NEP11Transfer{
+						{
+							NEP17Transfer: result.NEP17Transfer{
+								Timestamp: 1555651816,
+								Asset:     assetHash,
+								Address:   "NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP",
+								Amount:    "1",
+								Index:     436036,
+								TxHash:    txHash,
+							},
+							ID: "abcdef",
+						},
+					},
+					Address: "NcEkNmgWmf7HQVQvzhxpengpnt4DXjmZLe",
+				}
+			},
+		},
+	},
 	"getnep17balances": {
 		{
 			name: "positive",
@@ -616,6 +732,32 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getsyncprogress": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetSyncProgress()
+			},
+			serverResponse: `{"id":1,"jsonrpc":"2.0","result":{"height":100,"target":1000,"rate":12.5,"pending":3,"peers":[{"address":"172.200.0.1:20333","height":1000,"blocks":50,"pending":2,"timeouts":1}]}}`,
+			result: func(c *Client) interface{} {
+				return &result.SyncProgress{
+					Height:  100,
+					Target:  1000,
+					Rate:    12.5,
+					Pending: 3,
+					Peers: []result.PeerSyncProgress{
+						{
+							Address:  "172.200.0.1:20333",
+							Height:   1000,
+							Blocks:   50,
+							Pending:  2,
+							Timeouts: 1,
+						},
+					},
+				}
+			},
+		},
+	},
 	"getrawmempool": {
 		{
 			name: "positive",
@@ -710,6 +852,32 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getstoragehistoric": {
+		{
+			name: "by hash at height",
+			invoke: func(c *Client) (interface{}, error) {
+				hash, err := util.Uint160DecodeStringLE("03febccf81ac85e3d795bc5cbd4e84e907812aa3")
+				if err != nil {
+					panic(err)
+				}
+				return c.GetStorageByHashAtHeight(5, hash, []byte("Peter"))
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":"TGlu"}`,
+			result: func(c *Client) interface{} {
+				return []byte("Lin")
+			},
+		},
+		{
+			name: "by ID with state",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStorageByIDWithState(util.Uint256{1, 2, 3}, -1, []byte("Peter"))
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":"TGlu"}`,
+			result: func(c *Client) interface{} {
+				return []byte("Lin")
+			},
+		},
+	},
 	"gettransactionheight": {
 		{
 			name: "positive",
@@ -895,6 +1063,31 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"invokescripthistoric": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				script, err := base64.StdEncoding.DecodeString("AARuYW1lZyQFjl4bYAiEfNZicoVJCIqe6CGR")
+				if err != nil {
+					panic(err)
+				}
+				return c.InvokeScriptAtHeight(5, script, nil)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"script":"AARuYW1lZyQFjl4bYAiEfNZicoVJCIqe6CGR","state":"HALT","gasconsumed":"16100000","stack":[{"type":"ByteString","value":"TkVQNSBHQVM="}],"tx":null}}`,
+			result: func(c *Client) interface{} {
+				script, err := base64.StdEncoding.DecodeString("AARuYW1lZyQFjl4bYAiEfNZicoVJCIqe6CGR")
+				if err != nil {
+					panic(err)
+				}
+				return &result.Invoke{
+					State:       "HALT",
+					GasConsumed: 16100000,
+					Script:      script,
+					Stack:       []stackitem.Item{stackitem.NewByteArray([]byte("NEP5 GAS"))},
+				}
+			},
+		},
+	},
 	"invokecontractverify": {
 		{
 			name: "positive",
@@ -971,6 +1164,79 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"terminatesession": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.TerminateSession("01020304-0506-4708-890a-0b0c0d0e0f10")
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				return true
+			},
+		},
+	},
+	"tracetransaction": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				h, err := util.Uint256DecodeStringLE("1bdea8f80eb5bd97fade38d5e7fb93b02c9d3e01394e9f4324218132293f7ea6")
+				if err != nil {
+					panic(err)
+				}
+				return c.TraceTransaction(h, &request.TraceConfig{MaxSteps: 1})
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"state":"HALT","gasconsumed":"30","script":"EQ==","stack":[{"type":"Integer","value":"1"}],"trace":{"steps":[{"ip":0,"opcode":"PUSH1","contract":"0x2e7a2f2fd9ec7a94cfb5c3d3c3b6d1a4c8d1e0e8","depth":1,"gasconsumed":"30","popped":0,"pushed":[{"type":"Integer","value":"1"}]}]}}}`,
+			result: func(c *Client) interface{} {
+				h, err := util.Uint160DecodeStringLE("2e7a2f2fd9ec7a94cfb5c3d3c3b6d1a4c8d1e0e8")
+				if err != nil {
+					panic(err)
+				}
+				return &result.Invoke{
+					State:       "HALT",
+					GasConsumed: 30,
+					Script:      []byte{byte(opcode.PUSH1)},
+					Stack:       []stackitem.Item{stackitem.NewBigInteger(big.NewInt(1))},
+					Trace: &result.Trace{
+						Steps: []result.TraceStep{{
+							OpCode:      opcode.PUSH1,
+							Contract:    h,
+							Depth:       1,
+							GasConsumed: 30,
+							Pushed:      []json.RawMessage{json.RawMessage(`{"type":"Integer","value":"1"}`)},
+						}},
+					},
+				}
+			},
+		},
+	},
+	"traverseiterator": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.TraverseIterator("01020304-0506-4708-890a-0b0c0d0e0f10", "11121314-1516-4718-892a-2b2c2d2e2f20", 2)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":[{"type":"Integer","value":"1"},{"type":"ByteString","value":"dGVzdA=="}]}`,
+			result: func(c *Client) interface{} {
+				return []stackitem.Item{
+					stackitem.NewBigInteger(big.NewInt(1)),
+					stackitem.NewByteArray([]byte("test")),
+				}
+			},
+		},
+	},
+	"unbanpeer": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.UnbanPeer("172.200.0.1:20333")
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				return true
+			},
+		},
+	},
 	"validateaddress": {
 		{
 			name: "positive",
@@ -1368,12 +1634,24 @@ var rpcClientErrorCases = map[string][]rpcClientErrorCase{
 				return c.GetNEP17Transfers("", nil, nil, nil, nil)
 			},
 		},
+		{
+			name: "getbannedpeers_unmarshalling_error",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetBannedPeers()
+			},
+		},
 		{
 			name: "getpeers_unmarshalling_error",
 			invoke: func(c *Client) (interface{}, error) {
 				return c.GetPeers()
 			},
 		},
+		{
+			name: "getsyncprogress_unmarshalling_error",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetSyncProgress()
+			},
+		},
 		{
 			name: "getrawmempool_unmarshalling_error",
 			invoke: func(c *Client) (interface{}, error) {
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -26,25 +29,94 @@ type WSClient struct {
 	// it wants to use subscription mechanism, failing to do so will cause
 	// WSClient to block even regular requests. This channel is not buffered.
 	// In case of protocol error or upon connection closure this channel will
-	// be closed, so make sure to handle this.
+	// be closed, so make sure to handle this. If reconnection is enabled via
+	// Options.Reconnect, connection loss doesn't close this channel, instead
+	// a ReconnectedEventID notification is sent after successful connection
+	// restoration and the channel is only closed when client gives up
+	// reconnecting or is closed.
 	Notifications chan Notification
 
-	ws            *websocket.Conn
-	done          chan struct{}
-	responses     chan *response.Raw
-	requests      chan *request.Raw
-	shutdown      chan struct{}
-	subscriptions map[string]bool
+	endpoint  string
+	dialer    websocket.Dialer
+	reconnect *ReconnectOptions
+
+	connLock sync.RWMutex
+	conn     *wsConn
+
+	done     chan struct{}
+	shutdown chan struct{}
+
+	// subscriptionsLock protects subscriptions and lastSubID, it's also
+	// held during subscription requests, so that subscriptions can't be
+	// lost while they're being restored after reconnection.
+	subscriptionsLock sync.Mutex
+	subscriptions     map[string]*wsSubscription
+	lastSubID         uint64
+
+	lastBlockLock sync.Mutex
+	lastBlock     *uint32
+}
+
+// ReconnectOptions defines WSClient behavior in case of connection loss. When
+// connection is lost the client tries to establish it again with an
+// exponential backoff between attempts. Once connected, it restores all active
+// subscriptions and sends ReconnectedEventID notification with *ConnectionGap
+// value, so that the user can fetch data that was missed.
+type ReconnectOptions struct {
+	// Attempts is the maximum number of consecutive failed reconnection
+	// attempts after which the client gives up and closes its Notifications
+	// channel. Zero value means no limit.
+	Attempts int
+	// MinDelay is the delay before the first reconnection attempt, it's
+	// doubled after each failed attempt. Default is 1 second.
+	MinDelay time.Duration
+	// MaxDelay is the maximum delay between reconnection attempts. Default
+	// is 1 minute.
+	MaxDelay time.Duration
+}
+
+// ConnectionGap is a value of ReconnectedEventID notification (it's delivered
+// as a pointer). It describes the period of time when the client was
+// disconnected, all events from this period are lost, but blocks can be
+// fetched with GetBlockByIndex.
+type ConnectionGap struct {
+	// LastBlockIndex is the index of the last block received via block_added
+	// notification before connection loss. It's nil if no blocks were
+	// received.
+	LastBlockIndex *uint32
+	// Height is the server's blockchain height right after subscriptions
+	// were restored. Blocks after LastBlockIndex up to Height (inclusive)
+	// are missed, although some of them can still be delivered via
+	// notifications if they were added during restoration.
+	Height uint32
 }
 
 // Notification represents server-generated notification for client subscriptions.
-// Value can be one of block.Block, result.ApplicationLog, result.NotificationEvent
-// or transaction.Transaction based on Type.
+// Value can be one of block.Block, result.ApplicationLog, result.NotificationEvent,
+// transaction.Transaction or *ConnectionGap based on Type.
 type Notification struct {
 	Type  response.EventID
 	Value interface{}
 }
 
+// wsConn is a single websocket connection with its requests/responses
+// channels, WSClient replaces it upon reconnection.
+type wsConn struct {
+	ws        *websocket.Conn
+	lost      chan struct{}
+	requests  chan *request.Raw
+	responses chan *response.Raw
+}
+
+// wsSubscription is an active subscription that can be restored after
+// reconnection.
+type wsSubscription struct {
+	// id is a subscription ID used by the server, it can be different from
+	// the one returned to the user if reconnection is enabled.
+	id     string
+	params request.RawParams
+}
+
 // requestResponse is a combined type for request and response since we can get
 // any of them here.
 type requestResponse struct {
@@ -65,8 +137,16 @@ const (
 
 	// Write deadline.
 	wsWriteLimit = wsPingPeriod / 2
+
+	// Default reconnection delays.
+	defaultReconnectMinDelay = time.Second
+	defaultReconnectMaxDelay = time.Minute
 )
 
+// errConnectionLost is returned for requests that can't be completed because
+// of connection loss.
+var errConnectionLost = errors.New("connection lost")
+
 // NewWS returns a new WSClient ready to use (with established websocket
 // connection). You need to use websocket URL for it like `ws://1.2.3.4/ws`.
 // You should call Init method to initialize network magic the client is
@@ -88,19 +168,41 @@ func NewWS(ctx context.Context, endpoint string, opts Options) (*WSClient, error
 		Client:        *cl,
 		Notifications: make(chan Notification),
 
-		ws:            ws,
+		endpoint:      endpoint,
+		dialer:        dialer,
+		conn:          newWSConn(ws),
 		shutdown:      make(chan struct{}),
 		done:          make(chan struct{}),
-		responses:     make(chan *response.Raw),
-		requests:      make(chan *request.Raw),
-		subscriptions: make(map[string]bool),
+		subscriptions: make(map[string]*wsSubscription),
+	}
+	if opts.Reconnect != nil {
+		r := *opts.Reconnect
+		if r.MinDelay <= 0 {
+			r.MinDelay = defaultReconnectMinDelay
+		}
+		if r.MaxDelay <= 0 {
+			r.MaxDelay = defaultReconnectMaxDelay
+		}
+		if r.MaxDelay < r.MinDelay {
+			r.MaxDelay = r.MinDelay
+		}
+		wsc.reconnect = &r
 	}
-	go wsc.wsReader()
-	go wsc.wsWriter()
+	go wsc.wsReader(wsc.conn)
+	go wsc.wsWriter(wsc.conn)
 	wsc.requestF = wsc.makeWsRequest
 	return wsc, nil
 }
 
+func newWSConn(ws *websocket.Conn) *wsConn {
+	return &wsConn{
+		ws:        ws,
+		lost:      make(chan struct{}),
+		requests:  make(chan *request.Raw),
+		responses: make(chan *response.Raw),
+	}
+}
+
 // Close closes connection to the remote side rendering this client instance
 // unusable.
 func (c *WSClient) Close() {
@@ -112,92 +214,250 @@ func (c *WSClient) Close() {
 	<-c.done
 }
 
-func (c *WSClient) wsReader() {
-	c.ws.SetReadLimit(wsReadLimit)
-	c.ws.SetPongHandler(func(string) error { c.ws.SetReadDeadline(time.Now().Add(wsPongLimit)); return nil })
-readloop:
+func (c *WSClient) getConn() *wsConn {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	return c.conn
+}
+
+func (c *WSClient) setConn(conn *wsConn) {
+	c.connLock.Lock()
+	c.conn = conn
+	c.connLock.Unlock()
+}
+
+func (c *WSClient) isShutdown() bool {
+	select {
+	case <-c.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *WSClient) wsReader(conn *wsConn) {
+	for conn != nil {
+		c.readLoop(conn)
+		close(conn.lost)
+		if c.reconnect == nil || c.isShutdown() {
+			break
+		}
+		conn = c.restoreConnection()
+	}
+	close(c.done)
+	close(c.Notifications)
+}
+
+// readLoop processes incoming messages until connection error or protocol
+// violation.
+func (c *WSClient) readLoop(conn *wsConn) {
+	conn.ws.SetReadLimit(wsReadLimit)
+	conn.ws.SetPongHandler(func(string) error { conn.ws.SetReadDeadline(time.Now().Add(wsPongLimit)); return nil })
 	for {
-		rr := new(requestResponse)
-		c.ws.SetReadDeadline(time.Now().Add(wsPongLimit))
-		err := c.ws.ReadJSON(rr)
+		conn.ws.SetReadDeadline(time.Now().Add(wsPongLimit))
+		ntf, resp, err := c.readMessage(conn.ws)
 		if err != nil {
 			// Timeout/connection loss/malformed response.
 			break
 		}
-		if rr.RawID == nil && rr.Method != "" {
-			event, err := response.GetEventIDFromString(rr.Method)
+		if ntf != nil {
+			c.Notifications <- *ntf
+		} else {
+			conn.responses <- resp
+		}
+	}
+}
+
+// readMessage reads a single message from the websocket and returns either
+// notification or response.
+func (c *WSClient) readMessage(ws *websocket.Conn) (*Notification, *response.Raw, error) {
+	rr := new(requestResponse)
+	err := ws.ReadJSON(rr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rr.RawID == nil && rr.Method != "" {
+		event, err := response.GetEventIDFromString(rr.Method)
+		if err != nil || event == response.ReconnectedEventID {
+			return nil, nil, errors.New("bad event received")
+		}
+		var slice []json.RawMessage
+		err = json.Unmarshal(rr.RawParams, &slice)
+		if err != nil || (event != response.MissedEventID && len(slice) != 1) {
+			return nil, nil, errors.New("bad event received")
+		}
+		var val interface{}
+		switch event {
+		case response.BlockEventID:
+			val = block.New(c.StateRootInHeader())
+		case response.TransactionEventID:
+			val = &transaction.Transaction{}
+		case response.NotificationEventID:
+			val = new(state.NotificationEvent)
+		case response.ExecutionEventID:
+			val = new(state.AppExecResult)
+		case response.MissedEventID:
+			// No value.
+		default:
+			return nil, nil, errors.New("bad event received")
+		}
+		if event != response.MissedEventID {
+			err = json.Unmarshal(slice[0], val)
 			if err != nil {
-				// Bad event received.
-				break
+				return nil, nil, fmt.Errorf("bad event received: %w", err)
+			}
+		}
+		if event == response.BlockEventID {
+			c.setLastBlock(val.(*block.Block).Index)
+		}
+		return &Notification{event, val}, nil, nil
+	} else if rr.RawID != nil && (rr.Error != nil || rr.Result != nil) {
+		resp := new(response.Raw)
+		resp.ID = rr.RawID
+		resp.JSONRPC = rr.JSONRPC
+		resp.Error = rr.Error
+		resp.Result = rr.Result
+		return nil, resp, nil
+	}
+	// Malformed response, neither valid request, nor valid response.
+	return nil, nil, errors.New("malformed response")
+}
+
+func (c *WSClient) setLastBlock(index uint32) {
+	c.lastBlockLock.Lock()
+	c.lastBlock = &index
+	c.lastBlockLock.Unlock()
+}
+
+func (c *WSClient) getLastBlock() *uint32 {
+	c.lastBlockLock.Lock()
+	defer c.lastBlockLock.Unlock()
+	if c.lastBlock == nil {
+		return nil
+	}
+	index := *c.lastBlock
+	return &index
+}
+
+// restoreConnection tries to reconnect to the server with exponential backoff
+// between attempts. It returns new connection with restored subscriptions or
+// nil if the client is closed or gave up reconnecting.
+func (c *WSClient) restoreConnection() *wsConn {
+	var delay = c.reconnect.MinDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-c.shutdown:
+			return nil
+		case <-time.After(delay):
+		}
+		ws, _, err := c.dialer.Dial(c.endpoint, nil)
+		if err == nil {
+			conn := newWSConn(ws)
+			gap, pending, err := c.restoreSubscriptions(conn)
+			if err == nil {
+				c.Notifications <- Notification{Type: response.ReconnectedEventID, Value: gap}
+				for _, ntf := range pending {
+					c.Notifications <- ntf
+				}
+				c.setConn(conn)
+				go c.wsWriter(conn)
+				return conn
 			}
-			var slice []json.RawMessage
-			err = json.Unmarshal(rr.RawParams, &slice)
-			if err != nil || (event != response.MissedEventID && len(slice) != 1) {
-				// Bad event received.
-				break
+			ws.Close()
+		}
+		if c.reconnect.Attempts != 0 && attempt >= c.reconnect.Attempts {
+			return nil
+		}
+		delay *= 2
+		if delay > c.reconnect.MaxDelay {
+			delay = c.reconnect.MaxDelay
+		}
+	}
+}
+
+// restoreSubscriptions reissues all active subscriptions via the given
+// connection and gets current server's blockchain height. It's done
+// synchronously before starting wsWriter for this connection, so no other
+// requests can interfere. Notifications received during this process are
+// returned to be sent after ReconnectedEventID.
+func (c *WSClient) restoreSubscriptions(conn *wsConn) (*ConnectionGap, []Notification, error) {
+	var pending []Notification
+
+	call := func(method string, params request.RawParams, v interface{}) error {
+		conn.ws.SetWriteDeadline(time.Now().Add(c.opts.RequestTimeout))
+		err := conn.ws.WriteJSON(&request.Raw{
+			JSONRPC:   request.JSONRPCVersion,
+			Method:    method,
+			RawParams: params.Values,
+			ID:        1,
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			conn.ws.SetReadDeadline(time.Now().Add(c.opts.RequestTimeout))
+			ntf, resp, err := c.readMessage(conn.ws)
+			if err != nil {
+				return err
 			}
-			var val interface{}
-			switch event {
-			case response.BlockEventID:
-				val = block.New(c.StateRootInHeader())
-			case response.TransactionEventID:
-				val = &transaction.Transaction{}
-			case response.NotificationEventID:
-				val = new(state.NotificationEvent)
-			case response.ExecutionEventID:
-				val = new(state.AppExecResult)
-			case response.MissedEventID:
-				// No value.
-			default:
-				// Bad event received.
-				break readloop
+			if ntf != nil {
+				pending = append(pending, *ntf)
+				continue
 			}
-			if event != response.MissedEventID {
-				err = json.Unmarshal(slice[0], val)
-				if err != nil {
-					// Bad event received.
-					break
-				}
+			if resp.Error != nil {
+				return resp.Error
 			}
-			c.Notifications <- Notification{event, val}
-		} else if rr.RawID != nil && (rr.Error != nil || rr.Result != nil) {
-			resp := new(response.Raw)
-			resp.ID = rr.RawID
-			resp.JSONRPC = rr.JSONRPC
-			resp.Error = rr.Error
-			resp.Result = rr.Result
-			c.responses <- resp
-		} else {
-			// Malformed response, neither valid request, nor valid response.
-			break
+			return json.Unmarshal(resp.Result, v)
 		}
 	}
-	close(c.done)
-	close(c.responses)
-	close(c.Notifications)
+
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+	var ids = make(map[string]string, len(c.subscriptions))
+	for userID, sub := range c.subscriptions {
+		var id string
+		if err := call("subscribe", sub.params, &id); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore subscription: %w", err)
+		}
+		ids[userID] = id
+	}
+	var count uint32
+	if err := call("getblockcount", request.NewRawParams(), &count); err != nil {
+		return nil, nil, fmt.Errorf("failed to get block count: %w", err)
+	}
+	for userID, id := range ids {
+		c.subscriptions[userID].id = id
+	}
+	gap := &ConnectionGap{
+		LastBlockIndex: c.getLastBlock(),
+	}
+	if count > 0 {
+		gap.Height = count - 1
+	}
+	return gap, pending, nil
 }
 
-func (c *WSClient) wsWriter() {
+func (c *WSClient) wsWriter(conn *wsConn) {
 	pingTicker := time.NewTicker(wsPingPeriod)
-	defer c.ws.Close()
+	defer conn.ws.Close()
 	defer pingTicker.Stop()
 	for {
 		select {
 		case <-c.shutdown:
 			return
-		case <-c.done:
+		case <-conn.lost:
 			return
-		case req, ok := <-c.requests:
+		case req, ok := <-conn.requests:
 			if !ok {
 				return
 			}
-			c.ws.SetWriteDeadline(time.Now().Add(c.opts.RequestTimeout))
-			if err := c.ws.WriteJSON(req); err != nil {
+			conn.ws.SetWriteDeadline(time.Now().Add(c.opts.RequestTimeout))
+			if err := conn.ws.WriteJSON(req); err != nil {
 				return
 			}
 		case <-pingTicker.C:
-			c.ws.SetWriteDeadline(time.Now().Add(wsWriteLimit))
-			if err := c.ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+			conn.ws.SetWriteDeadline(time.Now().Add(wsWriteLimit))
+			if err := conn.ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
 				return
 			}
 		}
@@ -206,15 +466,20 @@ func (c *WSClient) wsWriter() {
 }
 
 func (c *WSClient) makeWsRequest(r *request.Raw) (*response.Raw, error) {
+	conn := c.getConn()
 	select {
 	case <-c.done:
-		return nil, errors.New("connection lost")
-	case c.requests <- r:
+		return nil, errConnectionLost
+	case <-conn.lost:
+		return nil, errConnectionLost
+	case conn.requests <- r:
 	}
 	select {
 	case <-c.done:
-		return nil, errors.New("connection lost")
-	case resp := <-c.responses:
+		return nil, errConnectionLost
+	case <-conn.lost:
+		return nil, errConnectionLost
+	case resp := <-conn.responses:
 		return resp, nil
 	}
 }
@@ -222,20 +487,34 @@ func (c *WSClient) makeWsRequest(r *request.Raw) (*response.Raw, error) {
 func (c *WSClient) performSubscription(params request.RawParams) (string, error) {
 	var resp string
 
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+
 	if err := c.performRequest("subscribe", params, &resp); err != nil {
 		return "", err
 	}
-	c.subscriptions[resp] = true
-	return resp, nil
+	var id = resp
+	if c.reconnect != nil {
+		// Server-side IDs change after reconnection, so we use our own
+		// ones that stay valid for the whole client lifetime.
+		c.lastSubID++
+		id = strconv.FormatUint(c.lastSubID, 10)
+	}
+	c.subscriptions[id] = &wsSubscription{id: resp, params: params}
+	return id, nil
 }
 
 func (c *WSClient) performUnsubscription(id string) error {
 	var resp bool
 
-	if !c.subscriptions[id] {
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+
+	sub, ok := c.subscriptions[id]
+	if !ok {
 		return errors.New("no subscription with this ID")
 	}
-	if err := c.performRequest("unsubscribe", request.NewRawParams(id), &resp); err != nil {
+	if err := c.performRequest("unsubscribe", request.NewRawParams(sub.id), &resp); err != nil {
 		return err
 	}
 	if !resp {
@@ -301,7 +580,14 @@ func (c *WSClient) Unsubscribe(id string) error {
 
 // UnsubscribeAll removes all active subscriptions of current client.
 func (c *WSClient) UnsubscribeAll() error {
+	c.subscriptionsLock.Lock()
+	var ids = make([]string, 0, len(c.subscriptions))
 	for id := range c.subscriptions {
+		ids = append(ids, id)
+	}
+	c.subscriptionsLock.Unlock()
+
+	for _, id := range ids {
 		err := c.performUnsubscription(id)
 		if err != nil {
 			return err
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -73,13 +75,13 @@ func TestWSClientUnsubscription(t *testing.T) {
 	var cases = map[string]responseCheck{
 		"good": {`{"jsonrpc": "2.0", "id": 1, "result": true}`, func(t *testing.T, wsc *WSClient) {
 			// We can't really subscribe using this stub server, so set up wsc internals.
-			wsc.subscriptions["0"] = true
+			wsc.subscriptions["0"] = &wsSubscription{id: "0"}
 			err := wsc.Unsubscribe("0")
 			require.NoError(t, err)
 		}},
 		"all": {`{"jsonrpc": "2.0", "id": 1, "result": true}`, func(t *testing.T, wsc *WSClient) {
 			// We can't really subscribe using this stub server, so set up wsc internals.
-			wsc.subscriptions["0"] = true
+			wsc.subscriptions["0"] = &wsSubscription{id: "0"}
 			err := wsc.UnsubscribeAll()
 			require.NoError(t, err)
 			require.Equal(t, 0, len(wsc.subscriptions))
@@ -90,13 +92,13 @@ func TestWSClientUnsubscription(t *testing.T) {
 		}},
 		"error returned": {`{"jsonrpc": "2.0", "id": 1, "error":{"code":-32602,"message":"Invalid Params"}}`, func(t *testing.T, wsc *WSClient) {
 			// We can't really subscribe using this stub server, so set up wsc internals.
-			wsc.subscriptions["0"] = true
+			wsc.subscriptions["0"] = &wsSubscription{id: "0"}
 			err := wsc.Unsubscribe("0")
 			require.Error(t, err)
 		}},
 		"false returned": {`{"jsonrpc": "2.0", "id": 1, "result": false}`, func(t *testing.T, wsc *WSClient) {
 			// We can't really subscribe using this stub server, so set up wsc internals.
-			wsc.subscriptions["0"] = true
+			wsc.subscriptions["0"] = &wsSubscription{id: "0"}
 			err := wsc.Unsubscribe("0")
 			require.Error(t, err)
 		}},
@@ -159,6 +161,88 @@ func TestWSClientEvents(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestWSClientReconnect(t *testing.T) {
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/ws" || req.Method != "GET" {
+			return
+		}
+		var upgrader = websocket.Upgrader{}
+		ws, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		expect := func(method string, id string) {
+			ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+			r := request.In{}
+			require.NoError(t, ws.ReadJSON(&r))
+			require.Equal(t, method, r.Method)
+			params, err := r.Params()
+			require.NoError(t, err)
+			if id != "" {
+				s, err := params.Value(0).GetString()
+				require.NoError(t, err)
+				require.Equal(t, id, s)
+			}
+		}
+		send := func(msg string) {
+			ws.SetWriteDeadline(time.Now().Add(2 * time.Second))
+			require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(msg)))
+		}
+		if atomic.AddInt32(&connections, 1) == 1 {
+			expect("subscribe", "block_added")
+			send(`{"jsonrpc": "2.0", "id": 1, "result": "0"}`)
+			send(fmt.Sprintf(`{"jsonrpc":"2.0","method":"block_added","params":[%s]}`, b1Verbose))
+			// Drop the connection.
+			return
+		}
+		expect("subscribe", "block_added")
+		send(`{"jsonrpc": "2.0", "id": 1, "result": "5"}`)
+		expect("getblockcount", "")
+		send(`{"jsonrpc": "2.0", "id": 1, "result": 10}`)
+		expect("unsubscribe", "5")
+		send(`{"jsonrpc": "2.0", "id": 1, "result": true}`)
+		ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, _ = ws.ReadMessage()
+	}))
+	t.Cleanup(srv.Close)
+
+	wsc, err := NewWS(context.TODO(), httpURLtoWS(srv.URL), Options{
+		Reconnect: &ReconnectOptions{Attempts: 3, MinDelay: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	wsc.network = netmode.UnitTestNet
+
+	id, err := wsc.SubscribeForNewBlocks(nil)
+	require.NoError(t, err)
+	require.Equal(t, "1", id)
+
+	receive := func() Notification {
+		select {
+		case ntf, ok := <-wsc.Notifications:
+			require.True(t, ok)
+			return ntf
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+		return Notification{}
+	}
+	ntf := receive()
+	require.Equal(t, response.BlockEventID, ntf.Type)
+
+	ntf = receive()
+	require.Equal(t, response.ReconnectedEventID, ntf.Type)
+	gap, ok := ntf.Value.(*ConnectionGap)
+	require.True(t, ok)
+	require.NotNil(t, gap.LastBlockIndex)
+	require.Equal(t, uint32(1), *gap.LastBlockIndex)
+	require.Equal(t, uint32(9), gap.Height)
+
+	// Client-side subscription ID is still valid.
+	require.NoError(t, wsc.Unsubscribe(id))
+	wsc.Close()
+}
+
 func TestWSExecutionVMStateCheck(t *testing.T) {
 	// Will answer successfully if request slips through.
 	srv := initTestServer(t, `{"jsonrpc": "2.0", "id": 1, "result": "55aaff00"}`)
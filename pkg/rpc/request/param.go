@@ -57,6 +57,24 @@ type (
 	ExecutionFilter struct {
 		State string `json:"state"`
 	}
+	// TraceConfig is a wrapper structure for execution trace settings used by
+	// invocation calls. MaxSteps is the maximum number of the last executed
+	// instructions to return and MaxDepth is the maximum invocation depth of
+	// traced instructions, zero values mean server defaults (no depth limit).
+	TraceConfig struct {
+		MaxSteps int `json:"maxsteps,omitempty"`
+		MaxDepth int `json:"maxdepth,omitempty"`
+	}
+	// InvokeOptions is a wrapper structure for additional invocation result
+	// settings used by invocation calls. Execution trace is collected if Trace
+	// is specified, contract storage changes made by the invocation are
+	// returned if StorageChanges is set and executed instructions counters
+	// (that can be used for code coverage) are returned if Coverage is set.
+	InvokeOptions struct {
+		Trace          *TraceConfig `json:"trace,omitempty"`
+		StorageChanges bool         `json:"storagechanges,omitempty"`
+		Coverage       bool         `json:"coverage,omitempty"`
+	}
 	// SignerWithWitness represents transaction's signer with the corresponding witness.
 	SignerWithWitness struct {
 		transaction.Signer
@@ -75,6 +93,8 @@ const (
 	TxFilterT
 	NotificationFilterT
 	ExecutionFilterT
+	TraceConfigT
+	InvokeOptionsT
 	SignerWithWitnessT
 )
 
@@ -224,6 +244,30 @@ func (p Param) GetSignerWithWitness() (SignerWithWitness, error) {
 	return c, nil
 }
 
+// GetTraceConfig returns TraceConfig value of the parameter.
+func (p *Param) GetTraceConfig() (TraceConfig, error) {
+	if p == nil {
+		return TraceConfig{}, errMissingParameter
+	}
+	c, ok := p.Value.(TraceConfig)
+	if !ok {
+		return TraceConfig{}, errors.New("not a trace config")
+	}
+	return c, nil
+}
+
+// GetInvokeOptions returns InvokeOptions value of the parameter.
+func (p *Param) GetInvokeOptions() (InvokeOptions, error) {
+	if p == nil {
+		return InvokeOptions{}, errMissingParameter
+	}
+	o, ok := p.Value.(InvokeOptions)
+	if !ok {
+		return InvokeOptions{}, errors.New("not invocation options")
+	}
+	return o, nil
+}
+
 // GetSignersWithWitnesses returns a slice of SignerWithWitness with CalledByEntry
 // scope from array of Uint160 or array of serialized transaction.Signer stored
 // in the parameter.
@@ -272,6 +316,8 @@ func (p *Param) UnmarshalJSON(data []byte) error {
 		{TxFilterT, &TxFilter{}},
 		{NotificationFilterT, &NotificationFilter{}},
 		{ExecutionFilterT, &ExecutionFilter{}},
+		{TraceConfigT, &TraceConfig{}},
+		{InvokeOptionsT, &InvokeOptions{}},
 		{SignerWithWitnessT, &signerWithWitnessAux{}},
 		{ArrayT, &[]Param{}},
 	}
@@ -307,6 +353,10 @@ func (p *Param) UnmarshalJSON(data []byte) error {
 				} else {
 					continue
 				}
+			case *TraceConfig:
+				p.Value = *val
+			case *InvokeOptions:
+				p.Value = *val
 			case *signerWithWitnessAux:
 				aux := *val
 				p.Value = SignerWithWitness{
@@ -24,6 +24,8 @@ func TestParam_UnmarshalJSON(t *testing.T) {
                  {"name": "my_pretty_notification"},
                  {"contract": "f84d6a337fbc3d3a201d41da99e86b479e7a2554", "name":"my_pretty_notification"},
                  {"state": "HALT"},
+                 {"maxsteps": 10, "maxdepth": 2},
+                 {"trace": {"maxsteps": 10}, "storagechanges": true},
                  {"account": "0xcadb3dc2faa3ef14a13b619c9a43124755aa2569"},
                  [{"account": "0xcadb3dc2faa3ef14a13b619c9a43124755aa2569", "scopes": "Global"}]]`
 	contr, err := util.Uint160DecodeStringLE("f84d6a337fbc3d3a201d41da99e86b479e7a2554")
@@ -103,6 +105,14 @@ func TestParam_UnmarshalJSON(t *testing.T) {
 			Type:  ExecutionFilterT,
 			Value: ExecutionFilter{State: "HALT"},
 		},
+		{
+			Type:  TraceConfigT,
+			Value: TraceConfig{MaxSteps: 10, MaxDepth: 2},
+		},
+		{
+			Type:  InvokeOptionsT,
+			Value: InvokeOptions{Trace: &TraceConfig{MaxSteps: 10}, StorageChanges: true},
+		},
 		{
 			Type: SignerWithWitnessT,
 			Value: SignerWithWitness{
@@ -265,6 +275,30 @@ func TestParamGetFuncParam(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestParamGetTraceConfig(t *testing.T) {
+	tc := TraceConfig{MaxSteps: 10}
+	p := Param{TraceConfigT, tc}
+	actual, err := p.GetTraceConfig()
+	require.NoError(t, err)
+	require.Equal(t, tc, actual)
+
+	p = Param{TraceConfigT, 42}
+	_, err = p.GetTraceConfig()
+	require.Error(t, err)
+}
+
+func TestParamGetInvokeOptions(t *testing.T) {
+	o := InvokeOptions{StorageChanges: true}
+	p := Param{InvokeOptionsT, o}
+	actual, err := p.GetInvokeOptions()
+	require.NoError(t, err)
+	require.Equal(t, o, actual)
+
+	p = Param{TraceConfigT, TraceConfig{}}
+	_, err = p.GetInvokeOptions()
+	require.Error(t, err)
+}
+
 func TestParamGetBytesHex(t *testing.T) {
 	in := "602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7"
 	inb, _ := hex.DecodeString(in)
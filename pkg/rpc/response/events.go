@@ -22,6 +22,9 @@ const (
 	NotificationEventID
 	// ExecutionEventID is used for `transaction_executed` events.
 	ExecutionEventID
+	// ReconnectedEventID is generated by the client (it's never sent by the
+	// server) to notify user of connection restoration.
+	ReconnectedEventID EventID = 254
 	// MissedEventID notifies user of missed events.
 	MissedEventID EventID = 255
 )
@@ -37,6 +40,8 @@ func (e EventID) String() string {
 		return "notification_from_execution"
 	case ExecutionEventID:
 		return "transaction_executed"
+	case ReconnectedEventID:
+		return "connection_restored"
 	case MissedEventID:
 		return "event_missed"
 	default:
@@ -55,6 +60,8 @@ func GetEventIDFromString(s string) (EventID, error) {
 		return NotificationEventID, nil
 	case "transaction_executed":
 		return ExecutionEventID, nil
+	case "connection_restored":
+		return ReconnectedEventID, nil
 	case "event_missed":
 		return MissedEventID, nil
 	default:
@@ -15,19 +15,24 @@ type ApplicationLog struct {
 	Container     util.Uint256
 	IsTransaction bool
 	Executions    []state.Execution
+	// StorageChanges are contract storage changes made by the transaction,
+	// they're only present if the node saves them.
+	StorageChanges []state.StorageChange
 }
 
 // applicationLogAux is an auxiliary struct for ApplicationLog JSON marshalling.
 type applicationLogAux struct {
-	TxHash     *util.Uint256     `json:"txid,omitempty"`
-	BlockHash  *util.Uint256     `json:"blockhash,omitempty"`
-	Executions []json.RawMessage `json:"executions"`
+	TxHash         *util.Uint256         `json:"txid,omitempty"`
+	BlockHash      *util.Uint256         `json:"blockhash,omitempty"`
+	Executions     []json.RawMessage     `json:"executions"`
+	StorageChanges []state.StorageChange `json:"storagechanges,omitempty"`
 }
 
 // MarshalJSON implements implements json.Marshaler interface.
 func (l ApplicationLog) MarshalJSON() ([]byte, error) {
 	result := &applicationLogAux{
-		Executions: make([]json.RawMessage, len(l.Executions)),
+		Executions:     make([]json.RawMessage, len(l.Executions)),
+		StorageChanges: l.StorageChanges,
 	}
 	if l.IsTransaction {
 		result.TxHash = &l.Container
@@ -64,7 +69,7 @@ func (l *ApplicationLog) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("failed to unmarshal execution #%d: %w", i, err)
 		}
 	}
-
+	l.StorageChanges = aux.StorageChanges
 	return nil
 }
 
@@ -0,0 +1,11 @@
+package result
+
+import "time"
+
+// BannedPeer is the host banned by the node, it's returned by `getbannedpeers`
+// RPC call.
+type BannedPeer struct {
+	Address string    `json:"address"`
+	Reason  string    `json:"reason"`
+	Until   time.Time `json:"until"`
+}
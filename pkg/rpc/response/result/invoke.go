@@ -2,8 +2,11 @@ package result
 
 import (
 	"encoding/json"
+	"errors"
 
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 )
 
@@ -16,15 +19,104 @@ type Invoke struct {
 	Stack          []stackitem.Item
 	FaultException string
 	Transaction    *transaction.Transaction
+	// Session is an identifier of the server-side session holding iterators
+	// returned by this invocation, it's empty if there are no such iterators
+	// or sessions are disabled on the server.
+	Session string
+	// Trace is an execution trace, it's only present if it was requested.
+	Trace *Trace
+	// StorageChanges are contract storage changes made by the invocation,
+	// they're only present if they were requested.
+	StorageChanges []state.StorageChange
+	// Coverage contains executed instructions counters for every contract
+	// involved, it's only present if it was requested.
+	Coverage []ContractCoverage
 }
 
+// ContractCoverage contains the number of times every executed instruction
+// of the contract (specified by its offset) was executed.
+type ContractCoverage struct {
+	Contract util.Uint160 `json:"contract"`
+	Hits     map[int]int  `json:"hits"`
+}
+
+// Iterator is a VM iterator returned in the invocation result stack (as a
+// value of stackitem.Interop). When iterator sessions are enabled on the server
+// it only has an ID that can be used with traverseiterator call, otherwise it
+// contains iterator values (at most MaxIteratorResultItems of them) and
+// Truncated flag is set if there are more values available.
+type Iterator struct {
+	ID        string
+	Values    []stackitem.Item
+	Truncated bool
+}
+
+type iteratorAux struct {
+	Type      string            `json:"type"`
+	Interface string            `json:"interface"`
+	ID        string            `json:"id,omitempty"`
+	Values    []json.RawMessage `json:"iterator,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// iteratorInterfaceName is a name of interface used for iterators in JSON.
+const iteratorInterfaceName = "IIterator"
+
 type invokeAux struct {
-	State          string          `json:"state"`
-	GasConsumed    int64           `json:"gasconsumed,string"`
-	Script         []byte          `json:"script"`
-	Stack          json.RawMessage `json:"stack"`
-	FaultException string          `json:"exception,omitempty"`
-	Transaction    []byte          `json:"tx,omitempty"`
+	State          string                `json:"state"`
+	GasConsumed    int64                 `json:"gasconsumed,string"`
+	Script         []byte                `json:"script"`
+	Stack          json.RawMessage       `json:"stack"`
+	FaultException string                `json:"exception,omitempty"`
+	Transaction    []byte                `json:"tx,omitempty"`
+	Session        string                `json:"session,omitempty"`
+	Trace          *Trace                `json:"trace,omitempty"`
+	StorageChanges []state.StorageChange `json:"storagechanges,omitempty"`
+	Coverage       []ContractCoverage    `json:"coverage,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Iterator) MarshalJSON() ([]byte, error) {
+	aux := &iteratorAux{
+		Type:      stackitem.InteropT.String(),
+		Interface: iteratorInterfaceName,
+		ID:        r.ID,
+		Truncated: r.Truncated,
+	}
+	if r.ID == "" {
+		aux.Values = make([]json.RawMessage, len(r.Values))
+		for i := range r.Values {
+			data, err := stackitem.ToJSONWithTypes(r.Values[i])
+			if err != nil {
+				return nil, err
+			}
+			aux.Values[i] = data
+		}
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Iterator) UnmarshalJSON(data []byte) error {
+	aux := new(iteratorAux)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	if aux.Type != stackitem.InteropT.String() || aux.Interface != iteratorInterfaceName {
+		return errors.New("not an iterator")
+	}
+	values := make([]stackitem.Item, len(aux.Values))
+	for i := range aux.Values {
+		item, err := stackitem.FromJSONWithTypes(aux.Values[i])
+		if err != nil {
+			return err
+		}
+		values[i] = item
+	}
+	r.ID = aux.ID
+	r.Values = values
+	r.Truncated = aux.Truncated
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler.
@@ -32,7 +124,15 @@ func (r Invoke) MarshalJSON() ([]byte, error) {
 	var st json.RawMessage
 	arr := make([]json.RawMessage, len(r.Stack))
 	for i := range arr {
-		data, err := stackitem.ToJSONWithTypes(r.Stack[i])
+		var (
+			data []byte
+			err  error
+		)
+		if iter, ok := r.Stack[i].Value().(Iterator); ok {
+			data, err = json.Marshal(iter)
+		} else {
+			data, err = stackitem.ToJSONWithTypes(r.Stack[i])
+		}
 		if err != nil {
 			st = []byte(`"error: recursive reference"`)
 			break
@@ -58,6 +158,10 @@ func (r Invoke) MarshalJSON() ([]byte, error) {
 		Stack:          st,
 		FaultException: r.FaultException,
 		Transaction:    txbytes,
+		Session:        r.Session,
+		Trace:          r.Trace,
+		StorageChanges: r.StorageChanges,
+		Coverage:       r.Coverage,
 	})
 }
 
@@ -72,6 +176,11 @@ func (r *Invoke) UnmarshalJSON(data []byte) error {
 	if err = json.Unmarshal(aux.Stack, &arr); err == nil {
 		st := make([]stackitem.Item, len(arr))
 		for i := range arr {
+			var iter Iterator
+			if iter.UnmarshalJSON(arr[i]) == nil {
+				st[i] = stackitem.NewInterop(iter)
+				continue
+			}
 			st[i], err = stackitem.FromJSONWithTypes(arr[i])
 			if err != nil {
 				break
@@ -93,5 +202,9 @@ func (r *Invoke) UnmarshalJSON(data []byte) error {
 	r.State = aux.State
 	r.FaultException = aux.FaultException
 	r.Transaction = tx
+	r.Session = aux.Session
+	r.Trace = aux.Trace
+	r.StorageChanges = aux.StorageChanges
+	r.Coverage = aux.Coverage
 	return nil
 }
@@ -6,8 +6,10 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/stretchr/testify/require"
 )
@@ -45,3 +47,153 @@ func TestInvoke_MarshalJSON(t *testing.T) {
 	require.NoError(t, json.Unmarshal(data, actual))
 	require.Equal(t, result, actual)
 }
+
+func TestInvoke_MarshalJSONIterators(t *testing.T) {
+	result := &Invoke{
+		State:       "HALT",
+		GasConsumed: 1000,
+		Script:      []byte{10},
+		Stack: []stackitem.Item{
+			stackitem.NewInterop(Iterator{ID: "42"}),
+			stackitem.NewInterop(Iterator{
+				Values:    []stackitem.Item{stackitem.NewBigInteger(big.NewInt(1))},
+				Truncated: true,
+			}),
+		},
+		Session: "deadbeef",
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	expected := `{
+		"state":"HALT",
+		"gasconsumed":"1000",
+		"script":"` + base64.StdEncoding.EncodeToString(result.Script) + `",
+		"stack":[
+			{"type":"Interop","interface":"IIterator","id":"42"},
+			{"type":"Interop","interface":"IIterator","iterator":[{"type":"Integer","value":"1"}],"truncated":true}
+		],
+		"session":"deadbeef"
+}`
+	require.JSONEq(t, expected, string(data))
+
+	actual := new(Invoke)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, "deadbeef", actual.Session)
+	require.Equal(t, 2, len(actual.Stack))
+	require.Equal(t, Iterator{ID: "42", Values: []stackitem.Item{}}, actual.Stack[0].Value())
+	require.Equal(t, result.Stack[1].Value(), actual.Stack[1].Value())
+}
+
+func TestInvoke_MarshalJSONTrace(t *testing.T) {
+	result := &Invoke{
+		State:       "FAULT",
+		GasConsumed: 60,
+		Script:      []byte{byte(opcode.PUSH1), byte(opcode.THROW)},
+		Stack:       []stackitem.Item{},
+		Trace: &Trace{
+			Steps: []TraceStep{
+				{
+					IP:          0,
+					OpCode:      opcode.PUSH1,
+					Contract:    util.Uint160{1, 2, 3},
+					Depth:       1,
+					GasConsumed: 30,
+					Pushed:      []json.RawMessage{json.RawMessage(`{"type":"Integer","value":"1"}`)},
+				},
+				{
+					IP:          1,
+					OpCode:      opcode.THROW,
+					Contract:    util.Uint160{1, 2, 3},
+					Depth:       1,
+					GasConsumed: 30,
+					Popped:      1,
+					Pushed:      []json.RawMessage{},
+				},
+			},
+			Truncated: true,
+		},
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	expected := `{
+		"state":"FAULT",
+		"gasconsumed":"60",
+		"script":"` + base64.StdEncoding.EncodeToString(result.Script) + `",
+		"stack":[],
+		"trace":{
+			"steps":[
+				{"ip":0,"opcode":"PUSH1","contract":"0x` + util.Uint160{1, 2, 3}.StringLE() + `","depth":1,"gasconsumed":"30","popped":0,"pushed":[{"type":"Integer","value":"1"}]},
+				{"ip":1,"opcode":"THROW","contract":"0x` + util.Uint160{1, 2, 3}.StringLE() + `","depth":1,"gasconsumed":"30","popped":1,"pushed":[]}
+			],
+			"truncated":true
+		}
+}`
+	require.JSONEq(t, expected, string(data))
+
+	actual := new(Invoke)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, result, actual)
+}
+
+func TestInvoke_MarshalJSONStorageChanges(t *testing.T) {
+	result := &Invoke{
+		State:       "HALT",
+		GasConsumed: 60,
+		Script:      []byte{byte(opcode.PUSH1)},
+		Stack:       []stackitem.Item{},
+		StorageChanges: []state.StorageChange{
+			{ID: -1, Key: []byte{1}, NewValue: []byte{2}},
+			{ID: 1, Key: []byte{3}, OldValue: []byte{4}},
+		},
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	expected := `{
+		"state":"HALT",
+		"gasconsumed":"60",
+		"script":"` + base64.StdEncoding.EncodeToString(result.Script) + `",
+		"stack":[],
+		"storagechanges":[
+			{"id":-1,"key":"AQ==","oldvalue":null,"newvalue":"Ag=="},
+			{"id":1,"key":"Aw==","oldvalue":"BA==","newvalue":null}
+		]
+}`
+	require.JSONEq(t, expected, string(data))
+
+	actual := new(Invoke)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, result, actual)
+}
+
+func TestInvoke_MarshalJSONCoverage(t *testing.T) {
+	result := &Invoke{
+		State:       "HALT",
+		GasConsumed: 60,
+		Script:      []byte{byte(opcode.PUSH1)},
+		Stack:       []stackitem.Item{},
+		Coverage: []ContractCoverage{{
+			Contract: util.Uint160{1, 2, 3},
+			Hits:     map[int]int{0: 1, 5: 3},
+		}},
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	expected := `{
+		"state":"HALT",
+		"gasconsumed":"60",
+		"script":"` + base64.StdEncoding.EncodeToString(result.Script) + `",
+		"stack":[],
+		"coverage":[
+			{"contract":"0x` + result.Coverage[0].Contract.StringLE() + `","hits":{"0":1,"5":3}}
+		]
+}`
+	require.JSONEq(t, expected, string(data))
+
+	actual := new(Invoke)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, result, actual)
+}
@@ -0,0 +1,76 @@
+package result
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// NEP11Balances is a result for the getnep11balances RPC call.
+type NEP11Balances struct {
+	Balances []NEP11AssetBalance `json:"balance"`
+	Address  string              `json:"address"`
+}
+
+// NEP11AssetBalance represents balances of tokens for the single NEP11
+// contract.
+type NEP11AssetBalance struct {
+	Asset  util.Uint160        `json:"assethash"`
+	Tokens []NEP11TokenBalance `json:"tokens"`
+}
+
+// NEP11TokenBalance represents balance of the single NEP11 token.
+type NEP11TokenBalance struct {
+	ID          string `json:"tokenid"`
+	Amount      string `json:"amount"`
+	LastUpdated uint32 `json:"lastupdatedblock"`
+}
+
+// NEP11Transfers is a result for the getnep11transfers RPC.
+type NEP11Transfers struct {
+	Sent     []NEP11Transfer `json:"sent"`
+	Received []NEP11Transfer `json:"received"`
+	Address  string          `json:"address"`
+}
+
+// NEP11Transfer represents single NEP11 transfer event.
+type NEP11Transfer struct {
+	NEP17Transfer
+	ID string `json:"tokenid"`
+}
+
+// KnownNEP11Properties contains a list of well-known NEP-11 token property
+// names, values of these properties are UTF-8 strings.
+var KnownNEP11Properties = map[string]bool{
+	"description": true,
+	"image":       true,
+	"name":        true,
+	"tokenURI":    true,
+}
+
+// NewNEP11Properties converts the map returned from `properties` NEP-11
+// method to JSON-friendly form, well-known properties are converted to
+// strings and all the others to byte slices. Elements with keys or values
+// that can't be converted to byte strings are skipped.
+func NewNEP11Properties(props []stackitem.MapElement) map[string]interface{} {
+	m := make(map[string]interface{}, len(props))
+	for _, kv := range props {
+		key, err := kv.Key.TryBytes()
+		if err != nil {
+			continue
+		}
+		var val interface{}
+		if kv.Value.Type() != stackitem.AnyT {
+			v, err := kv.Value.TryBytes()
+			if err != nil {
+				continue
+			}
+			if KnownNEP11Properties[string(key)] {
+				val = string(v)
+			} else {
+				val = v
+			}
+		}
+		m[string(key)] = val
+	}
+	return m
+}
@@ -0,0 +1,22 @@
+package result
+
+type (
+	// SyncProgress is the state of node's block synchronization returned by
+	// `getsyncprogress` RPC call.
+	SyncProgress struct {
+		Height  uint32             `json:"height"`
+		Target  uint32             `json:"target"`
+		Rate    float64            `json:"rate"`
+		Pending int                `json:"pending"`
+		Peers   []PeerSyncProgress `json:"peers"`
+	}
+
+	// PeerSyncProgress is the contribution of a peer to block synchronization.
+	PeerSyncProgress struct {
+		Address  string `json:"address"`
+		Height   uint32 `json:"height"`
+		Blocks   uint64 `json:"blocks"`
+		Pending  int    `json:"pending"`
+		Timeouts int    `json:"timeouts"`
+	}
+)
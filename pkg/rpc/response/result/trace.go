@@ -0,0 +1,90 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// Trace is an execution trace of some invocation. It contains at most the
+// configured number of last executed instructions (limited by their size as
+// well), Truncated flag is set if some instructions were dropped because of
+// these limits.
+type Trace struct {
+	Steps     []TraceStep `json:"steps"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// TraceStep describes a single executed instruction. Evaluation stack changes
+// are given for the stack the VM uses after the instruction execution
+// relative to the last step that ended up with the same stack: Popped is the
+// number of items removed from it and Pushed are items added (from bottom to
+// top). Pushed items are serialized to JSON with types (see
+// stackitem.ToJSONWithTypes) at the moment of execution, so they're not
+// affected by subsequent instructions. Items that are too big or contain
+// recursive references are replaced with TruncatedItem.
+type TraceStep struct {
+	IP          int
+	OpCode      opcode.Opcode
+	Contract    util.Uint160
+	Depth       int
+	GasConsumed int64
+	Popped      int
+	Pushed      []json.RawMessage
+}
+
+// TruncatedItem is a replacement for stack items that are not included into
+// execution trace, it only contains the item type.
+type TruncatedItem struct {
+	Type      string `json:"type"`
+	Truncated bool   `json:"truncated"`
+}
+
+type traceStepAux struct {
+	IP          int               `json:"ip"`
+	OpCode      string            `json:"opcode"`
+	Contract    util.Uint160      `json:"contract"`
+	Depth       int               `json:"depth"`
+	GasConsumed int64             `json:"gasconsumed,string"`
+	Popped      int               `json:"popped"`
+	Pushed      []json.RawMessage `json:"pushed"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s TraceStep) MarshalJSON() ([]byte, error) {
+	pushed := s.Pushed
+	if pushed == nil {
+		pushed = []json.RawMessage{}
+	}
+	return json.Marshal(&traceStepAux{
+		IP:          s.IP,
+		OpCode:      s.OpCode.String(),
+		Contract:    s.Contract,
+		Depth:       s.Depth,
+		GasConsumed: s.GasConsumed,
+		Popped:      s.Popped,
+		Pushed:      pushed,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *TraceStep) UnmarshalJSON(data []byte) error {
+	aux := new(traceStepAux)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	op, err := opcode.FromString(aux.OpCode)
+	if err != nil {
+		return fmt.Errorf("invalid opcode: %w", err)
+	}
+	s.IP = aux.IP
+	s.OpCode = op
+	s.Contract = aux.Contract
+	s.Depth = aux.Depth
+	s.GasConsumed = aux.GasConsumed
+	s.Popped = aux.Popped
+	s.Pushed = aux.Pushed
+	return nil
+}
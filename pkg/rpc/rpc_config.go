@@ -13,8 +13,26 @@ type (
 		// MaxGasInvoke is a maximum amount of gas which
 		// can be spent during RPC call.
 		MaxGasInvoke fixedn.Fixed8 `yaml:"MaxGasInvoke"`
-		Port         uint16        `yaml:"Port"`
-		TLSConfig    TLSConfig     `yaml:"TLSConfig"`
+		// MaxIteratorResultItems is a maximum number of iterator values
+		// returned in a single invocation result or traverseiterator call.
+		MaxIteratorResultItems int `yaml:"MaxIteratorResultItems"`
+		// MaxTraceSize is a maximum size (in bytes) of serialized stack
+		// items returned in a single execution trace.
+		MaxTraceSize int `yaml:"MaxTraceSize"`
+		// MaxTraceSteps is a maximum number of executed instructions
+		// returned in a single execution trace.
+		MaxTraceSteps int `yaml:"MaxTraceSteps"`
+		// PeerBansEnabled enables banpeer and unbanpeer calls changing
+		// the set of banned P2P peers.
+		PeerBansEnabled       bool      `yaml:"PeerBansEnabled"`
+		Port                  uint16    `yaml:"Port"`
+		SessionEnabled        bool      `yaml:"SessionEnabled"`
+		SessionExpirationTime int       `yaml:"SessionExpirationTime"`
+		SessionPoolSize       int       `yaml:"SessionPoolSize"`
+		TLSConfig             TLSConfig `yaml:"TLSConfig"`
+		// TraceEnabled enables execution tracing for invocations and
+		// tracetransaction call.
+		TraceEnabled bool `yaml:"TraceEnabled"`
 	}
 
 	// TLSConfig describes SSL/TLS configuration.
@@ -26,3 +44,13 @@ type (
 		KeyFile  string `yaml:"KeyFile"`
 	}
 )
+
+// Default session-related and tracing settings used if they're not specified in the
+// configuration.
+const (
+	DefaultMaxIteratorResultItems = 100
+	DefaultMaxTraceSize           = 1024 * 1024
+	DefaultMaxTraceSteps          = 1000
+	DefaultSessionExpirationTime  = 60 // seconds
+	DefaultSessionPoolSize        = 20
+)
@@ -17,6 +17,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
@@ -61,6 +62,7 @@ func TestClient_NEP17(t *testing.T) {
 		require.Equal(t, "Rubl", tok.Name)
 		require.Equal(t, "RUB", tok.Symbol)
 		require.EqualValues(t, 2, tok.Decimals)
+		require.Equal(t, manifest.NEP17StandardName, tok.Standard)
 	})
 	t.Run("BalanceOf", func(t *testing.T) {
 		acc := testchain.PrivateKeyByID(0).GetScriptHash()
@@ -711,7 +713,7 @@ func TestCreateNEP17TransferTx(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, acc.SignTx(testchain.Network(), tx))
 	require.NoError(t, chain.VerifyTx(tx))
-	v := chain.GetTestVM(trigger.Application, tx, nil)
+	v, _ := chain.GetTestVM(trigger.Application, tx, nil)
 	v.LoadScriptWithFlags(tx.Script, callflag.All)
 	require.NoError(t, v.Run())
 }
@@ -810,6 +812,22 @@ func TestClient_NEP11(t *testing.T) {
 		require.NoError(t, err)
 		require.EqualValues(t, acc, b)
 	})
+	t.Run("TokensOf", func(t *testing.T) {
+		ids, err := c.NEP11TokensOf(h, acc)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("neo.com")}, ids)
+	})
+	t.Run("TokenInfo", func(t *testing.T) {
+		tok, err := c.NEP11TokenInfo(h)
+		require.NoError(t, err)
+		require.Equal(t, &wallet.Token{
+			Name:     nativenames.NameService,
+			Hash:     h,
+			Decimals: 0,
+			Symbol:   "NNS",
+			Standard: manifest.NEP11StandardName,
+		}, tok)
+	})
 	t.Run("Properties", func(t *testing.T) {
 		p, err := c.NEP11Properties(h, "neo.com")
 		require.NoError(t, err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/elliptic"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -20,9 +22,12 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/dao"
 	"github.com/nspcc-dev/neo-go/pkg/core/fee"
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
@@ -39,7 +44,11 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"go.uber.org/zap"
 )
 
@@ -57,6 +66,9 @@ type (
 		https            *http.Server
 		shutdown         chan struct{}
 
+		sessionsLock sync.Mutex
+		sessions     map[string]*session
+
 		subsLock         sync.RWMutex
 		subscribers      map[*subscriber]bool
 		subsGroup        sync.WaitGroup
@@ -94,8 +106,10 @@ const (
 )
 
 var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *response.Error){
+	"banpeer":                (*Server).banPeer,
 	"calculatenetworkfee":    (*Server).calculateNetworkFee,
 	"getapplicationlog":      (*Server).getApplicationLog,
+	"getbannedpeers":         (*Server).getBannedPeers,
 	"getbestblockhash":       (*Server).getBestBlockHash,
 	"getblock":               (*Server).getBlock,
 	"getblockcount":          (*Server).getBlockCount,
@@ -107,6 +121,9 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"getconnectioncount":     (*Server).getConnectionCount,
 	"getcontractstate":       (*Server).getContractState,
 	"getnativecontracts":     (*Server).getNativeContracts,
+	"getnep11balances":       (*Server).getNEP11Balances,
+	"getnep11properties":     (*Server).getNEP11Properties,
+	"getnep11transfers":      (*Server).getNEP11Transfers,
 	"getnep17balances":       (*Server).getNEP17Balances,
 	"getnep17transfers":      (*Server).getNEP17Transfers,
 	"getpeers":               (*Server).getPeers,
@@ -116,17 +133,25 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"getstateheight":         (*Server).getStateHeight,
 	"getstateroot":           (*Server).getStateRoot,
 	"getstorage":             (*Server).getStorage,
+	"getstoragehistoric":     (*Server).getStorageHistoric,
+	"getsyncprogress":        (*Server).getSyncProgress,
 	"gettransactionheight":   (*Server).getTransactionHeight,
 	"getunclaimedgas":        (*Server).getUnclaimedGas,
 	"getnextblockvalidators": (*Server).getNextBlockValidators,
 	"getversion":             (*Server).getVersion,
 	"invokefunction":         (*Server).invokeFunction,
+	"invokefunctionhistoric": (*Server).invokeFunctionHistoric,
 	"invokescript":           (*Server).invokescript,
+	"invokescripthistoric":   (*Server).invokescriptHistoric,
 	"invokecontractverify":   (*Server).invokeContractVerify,
 	"sendrawtransaction":     (*Server).sendrawtransaction,
 	"submitblock":            (*Server).submitBlock,
 	"submitnotaryrequest":    (*Server).submitNotaryRequest,
 	"submitoracleresponse":   (*Server).submitOracleResponse,
+	"terminatesession":       (*Server).terminateSession,
+	"tracetransaction":       (*Server).traceTransaction,
+	"traverseiterator":       (*Server).traverseIterator,
+	"unbanpeer":              (*Server).unbanPeer,
 	"validateaddress":        (*Server).validateAddress,
 	"verifyproof":            (*Server).verifyProof,
 }
@@ -140,6 +165,10 @@ var invalidBlockHeightError = func(index int, height int) *response.Error {
 	return response.NewRPCError(fmt.Sprintf("Param at index %d should be greater than or equal to 0 and less then or equal to current block height, got: %d", index, height), "", nil)
 }
 
+// errPeerBansDisabled is returned by peer bans management calls when they're
+// not enabled in the configuration.
+var errPeerBansDisabled = response.NewRPCError("Peer bans management is disabled", "", nil)
+
 // upgrader is a no-op websocket.Upgrader that reuses HTTP server buffers and
 // doesn't set any Error function.
 var upgrader = websocket.Upgrader{}
@@ -161,6 +190,21 @@ func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.S
 	if orc != nil {
 		orc.SetBroadcaster(broadcaster.New(orc.MainCfg, log))
 	}
+	if conf.MaxIteratorResultItems <= 0 {
+		conf.MaxIteratorResultItems = rpc.DefaultMaxIteratorResultItems
+	}
+	if conf.SessionExpirationTime <= 0 {
+		conf.SessionExpirationTime = rpc.DefaultSessionExpirationTime
+	}
+	if conf.SessionPoolSize <= 0 {
+		conf.SessionPoolSize = rpc.DefaultSessionPoolSize
+	}
+	if conf.MaxTraceSteps <= 0 {
+		conf.MaxTraceSteps = rpc.DefaultMaxTraceSteps
+	}
+	if conf.MaxTraceSize <= 0 {
+		conf.MaxTraceSize = rpc.DefaultMaxTraceSize
+	}
 	return Server{
 		Server:           httpServer,
 		chain:            chain,
@@ -173,6 +217,8 @@ func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.S
 		https:            tlsServer,
 		shutdown:         make(chan struct{}),
 
+		sessions: make(map[string]*session),
+
 		subscribers: make(map[*subscriber]bool),
 		// These are NOT buffered to preserve original order of events.
 		blockCh:        make(chan *block.Block),
@@ -245,6 +291,8 @@ func (s *Server) Shutdown() error {
 	// Wait for handleSubEvents to finish.
 	<-s.executionCh
 
+	s.dropSessions()
+
 	if err == nil {
 		return httpsErr
 	}
@@ -527,6 +575,80 @@ func (s *Server) getPeers(_ request.Params) (interface{}, *response.Error) {
 	return peers, nil
 }
 
+func (s *Server) getBannedPeers(_ request.Params) (interface{}, *response.Error) {
+	banned := s.coreServer.BannedPeers()
+	res := make([]result.BannedPeer, len(banned))
+	for i := range banned {
+		res[i] = result.BannedPeer{
+			Address: banned[i].Address,
+			Reason:  banned[i].Reason,
+			Until:   banned[i].Until,
+		}
+	}
+	return res, nil
+}
+
+// banPeer bans the given host for the specified number of seconds (or for the
+// default node ban duration if it's omitted).
+func (s *Server) banPeer(reqParams request.Params) (interface{}, *response.Error) {
+	if !s.config.PeerBansEnabled {
+		return nil, errPeerBansDisabled
+	}
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.NewInvalidParamsError("invalid address", err)
+	}
+	var d time.Duration
+	if p := reqParams.Value(1); p != nil {
+		secs, err := p.GetInt()
+		if err != nil || secs < 0 {
+			return nil, response.NewInvalidParamsError("invalid ban duration", err)
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	reason := "banned via RPC"
+	if p := reqParams.Value(2); p != nil {
+		reason, err = p.GetString()
+		if err != nil {
+			return nil, response.NewInvalidParamsError("invalid reason", err)
+		}
+	}
+	s.coreServer.BanPeer(addr, d, reason)
+	return true, nil
+}
+
+func (s *Server) unbanPeer(reqParams request.Params) (interface{}, *response.Error) {
+	if !s.config.PeerBansEnabled {
+		return nil, errPeerBansDisabled
+	}
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.NewInvalidParamsError("invalid address", err)
+	}
+	return s.coreServer.UnbanPeer(addr), nil
+}
+
+func (s *Server) getSyncProgress(_ request.Params) (interface{}, *response.Error) {
+	sp := s.coreServer.SyncProgress()
+	res := result.SyncProgress{
+		Height:  sp.Height,
+		Target:  sp.Target,
+		Rate:    sp.Rate,
+		Pending: sp.Pending,
+		Peers:   make([]result.PeerSyncProgress, len(sp.Peers)),
+	}
+	for i, p := range sp.Peers {
+		res.Peers[i] = result.PeerSyncProgress{
+			Address:  p.Address,
+			Height:   p.Height,
+			Blocks:   p.Blocks,
+			Pending:  p.Pending,
+			Timeouts: p.Timeouts,
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) getRawMempool(reqParams request.Params) (interface{}, *response.Error) {
 	verbose := reqParams.Value(0).GetBoolean()
 	mp := s.chain.GetMemPool()
@@ -584,7 +706,7 @@ func (s *Server) calculateNetworkFee(reqParams request.Params) (interface{}, *re
 		}
 		if verificationScript == nil { // then it still might be a contract-based verification
 			verificationErr := fmt.Sprintf("contract verification for signer #%d failed", i)
-			res, respErr := s.runScriptInVM(trigger.Verification, tx.Scripts[i].InvocationScript, signer.Account, tx)
+			res, respErr := s.runScriptInVM(trigger.Verification, tx.Scripts[i].InvocationScript, signer.Account, tx, nil, nil)
 			if respErr != nil && errors.Is(respErr.Cause, core.ErrUnknownVerificationContract) {
 				// it's neither a contract-based verification script nor a standard witness attached to
 				// the tx, so the user did not provide enough data to calculate fee for that witness =>
@@ -652,7 +774,94 @@ func (s *Server) getApplicationLog(reqParams request.Params) (interface{}, *resp
 	if err != nil {
 		return nil, response.NewRPCError("Unknown transaction or block", "", err)
 	}
-	return result.NewApplicationLog(hash, appExecResults, trig), nil
+	appLog := result.NewApplicationLog(hash, appExecResults, trig)
+	if appLog.IsTransaction && s.chain.GetConfig().SaveStorageChanges {
+		changes, err := s.chain.GetStorageChanges(hash)
+		if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, response.NewInternalServerError("can't get storage changes", err)
+		}
+		appLog.StorageChanges = changes
+	}
+	return appLog, nil
+}
+
+func (s *Server) getNEP11Balances(ps request.Params) (interface{}, *response.Error) {
+	u, err := ps.Value(0).GetUint160FromAddressOrHex()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+
+	as := s.chain.GetNEP11Balances(u)
+	bs := &result.NEP11Balances{
+		Address:  address.Uint160ToString(u),
+		Balances: []result.NEP11AssetBalance{},
+	}
+	if as != nil {
+		cache := make(map[int32]util.Uint160)
+		for id, tr := range as.Trackers {
+			h, err := s.getHash(id, cache)
+			if err != nil {
+				continue
+			}
+			ab := result.NEP11AssetBalance{
+				Asset:  h,
+				Tokens: make([]result.NEP11TokenBalance, 0, len(tr.Tokens)),
+			}
+			for tokenID, bal := range tr.Tokens {
+				ab.Tokens = append(ab.Tokens, result.NEP11TokenBalance{
+					ID:          hex.EncodeToString([]byte(tokenID)),
+					Amount:      bal.Balance.String(),
+					LastUpdated: bal.LastUpdatedBlock,
+				})
+			}
+			sort.Slice(ab.Tokens, func(i, j int) bool {
+				return ab.Tokens[i].ID < ab.Tokens[j].ID
+			})
+			bs.Balances = append(bs.Balances, ab)
+		}
+		sort.Slice(bs.Balances, func(i, j int) bool {
+			return bs.Balances[i].Asset.Less(bs.Balances[j].Asset)
+		})
+	}
+	return bs, nil
+}
+
+// getNEP11Properties implements the `getnep11properties` RPC call, it invokes
+// `properties` method of the contract and converts the resulting map to JSON
+// object.
+func (s *Server) getNEP11Properties(ps request.Params) (interface{}, *response.Error) {
+	asset, err := ps.Value(0).GetUint160FromAddressOrHex()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	tokenID, err := ps.Value(1).GetBytesHex()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, asset, "properties", callflag.ReadOnly, tokenID)
+	if w.Err != nil {
+		return nil, response.NewInternalServerError("can't create invocation script", w.Err)
+	}
+	tx := &transaction.Transaction{
+		Script:  w.Bytes(),
+		Signers: []transaction.Signer{{Account: util.Uint160{}, Scopes: transaction.None}},
+	}
+	res, respErr := s.runScriptInVM(trigger.Application, tx.Script, util.Uint160{}, tx, nil, nil)
+	if respErr != nil {
+		return nil, respErr
+	}
+	if res.State != "HALT" {
+		return nil, response.NewRPCError("failed to get properties", res.FaultException, nil)
+	}
+	if len(res.Stack) != 1 {
+		return nil, response.NewRPCError("invalid properties result", "", nil)
+	}
+	props, ok := res.Stack[0].Value().([]stackitem.MapElement)
+	if !ok {
+		return nil, response.NewRPCError("properties is not a map", "", nil)
+	}
+	return result.NewNEP11Properties(props), nil
 }
 
 func (s *Server) getNEP17Balances(ps request.Params) (interface{}, *response.Error) {
@@ -733,7 +942,17 @@ func getTimestampsAndLimit(ps request.Params, index int) (uint64, uint64, int, i
 	return start, end, limit, page, nil
 }
 
+func (s *Server) getNEP11Transfers(ps request.Params) (interface{}, *response.Error) {
+	return s.getTokenTransfers(ps, true)
+}
+
 func (s *Server) getNEP17Transfers(ps request.Params) (interface{}, *response.Error) {
+	return s.getTokenTransfers(ps, false)
+}
+
+// getTokenTransfers implements getnep11transfers and getnep17transfers calls,
+// they only differ in the transfer log used and token ID presence.
+func (s *Server) getTokenTransfers(ps request.Params, isNEP11 bool) (interface{}, *response.Error) {
 	u, err := ps.Value(0).GetUint160FromAddressOrHex()
 	if err != nil {
 		return nil, response.ErrInvalidParams
@@ -749,9 +968,14 @@ func (s *Server) getNEP17Transfers(ps request.Params) (interface{}, *response.Er
 		Received: []result.NEP17Transfer{},
 		Sent:     []result.NEP17Transfer{},
 	}
+	bs11 := &result.NEP11Transfers{
+		Address:  bs.Address,
+		Received: []result.NEP11Transfer{},
+		Sent:     []result.NEP11Transfer{},
+	}
 	cache := make(map[int32]util.Uint160)
 	var resCount, frameCount int
-	err = s.chain.ForEachNEP17Transfer(u, func(tr *state.NEP17Transfer) (bool, error) {
+	handleTransfer := func(tr *state.NEP17Transfer, tokenID []byte) (bool, error) {
 		// Iterating from newest to oldest, not yet reached required
 		// time frame, continue looping.
 		if tr.Timestamp > end {
@@ -779,17 +1003,26 @@ func (s *Server) getNEP17Transfers(ps request.Params) (interface{}, *response.Er
 			Index:     tr.Block,
 			TxHash:    tr.Tx,
 		}
-		if tr.Amount.Sign() > 0 { // token was received
+		received := tr.Amount.Sign() > 0
+		if received { // token was received
 			transfer.Amount = tr.Amount.String()
 			if !tr.From.Equals(util.Uint160{}) {
 				transfer.Address = address.Uint160ToString(tr.From)
 			}
-			bs.Received = append(bs.Received, transfer)
 		} else {
 			transfer.Amount = new(big.Int).Neg(&tr.Amount).String()
 			if !tr.To.Equals(util.Uint160{}) {
 				transfer.Address = address.Uint160ToString(tr.To)
 			}
+		}
+		switch {
+		case isNEP11 && received:
+			bs11.Received = append(bs11.Received, result.NEP11Transfer{NEP17Transfer: transfer, ID: hex.EncodeToString(tokenID)})
+		case isNEP11:
+			bs11.Sent = append(bs11.Sent, result.NEP11Transfer{NEP17Transfer: transfer, ID: hex.EncodeToString(tokenID)})
+		case received:
+			bs.Received = append(bs.Received, transfer)
+		default:
 			bs.Sent = append(bs.Sent, transfer)
 		}
 
@@ -799,6 +1032,18 @@ func (s *Server) getNEP17Transfers(ps request.Params) (interface{}, *response.Er
 			return false, nil
 		}
 		return true, nil
+	}
+	if isNEP11 {
+		err = s.chain.ForEachNEP11Transfer(u, func(tr *state.NEP11Transfer) (bool, error) {
+			return handleTransfer(&tr.NEP17Transfer, tr.ID)
+		})
+		if err != nil {
+			return nil, response.NewInternalServerError("invalid NEP11 transfer log", err)
+		}
+		return bs11, nil
+	}
+	err = s.chain.ForEachNEP17Transfer(u, func(tr *state.NEP17Transfer) (bool, error) {
+		return handleTransfer(tr, nil)
 	})
 	if err != nil {
 		return nil, response.NewInternalServerError("invalid NEP17 transfer log", err)
@@ -819,7 +1064,7 @@ func (s *Server) getHash(contractID int32, cache map[int32]util.Uint160) (util.U
 	return h, nil
 }
 
-func (s *Server) contractIDFromParam(param *request.Param) (int32, *response.Error) {
+func (s *Server) contractIDFromParam(param *request.Param, hs *historicState) (int32, *response.Error) {
 	var result int32
 	if param == nil {
 		return 0, response.ErrInvalidParams
@@ -831,7 +1076,15 @@ func (s *Server) contractIDFromParam(param *request.Param) (int32, *response.Err
 		if err != nil {
 			return 0, response.ErrInvalidParams
 		}
-		cs := s.chain.GetContractState(scriptHash)
+		var cs *state.Contract
+		if hs != nil {
+			cs, err = s.getHistoricContractState(hs.root, scriptHash)
+			if err != nil {
+				return 0, response.NewInternalServerError("failed to get contract state", err)
+			}
+		} else {
+			cs = s.chain.GetContractState(scriptHash)
+		}
 		if cs == nil {
 			return 0, response.ErrUnknown
 		}
@@ -993,7 +1246,7 @@ func (s *Server) getStateRoot(ps request.Params) (interface{}, *response.Error)
 }
 
 func (s *Server) getStorage(ps request.Params) (interface{}, *response.Error) {
-	id, rErr := s.contractIDFromParam(ps.Value(0))
+	id, key, rErr := s.getStorageParams(ps, nil)
 	if rErr == response.ErrUnknown {
 		return nil, nil
 	}
@@ -1001,11 +1254,6 @@ func (s *Server) getStorage(ps request.Params) (interface{}, *response.Error) {
 		return nil, rErr
 	}
 
-	key, err := ps.Value(1).GetBytesBase64()
-	if err != nil {
-		return nil, response.ErrInvalidParams
-	}
-
 	item := s.chain.GetStorageItem(id, key)
 	if item == nil {
 		return "", nil
@@ -1014,6 +1262,112 @@ func (s *Server) getStorage(ps request.Params) (interface{}, *response.Error) {
 	return []byte(item), nil
 }
 
+// getStorageHistoric implements the `getstoragehistoric` RPC call.
+func (s *Server) getStorageHistoric(ps request.Params) (interface{}, *response.Error) {
+	hs, rErr := s.getHistoricState(ps.Value(0))
+	if rErr != nil {
+		return nil, rErr
+	}
+	id, key, rErr := s.getStorageParams(ps[1:], hs)
+	if rErr == response.ErrUnknown {
+		return nil, nil
+	}
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	item, err := s.chain.GetStateModule().GetState(hs.root, makeStorageKey(id, key))
+	if err != nil {
+		if errors.Is(err, mpt.ErrNotFound) {
+			return "", nil
+		}
+		return nil, response.NewInternalServerError("failed to get storage item", err)
+	}
+	return item, nil
+}
+
+// getStorageParams parses contract ID (or hash) and key parameters of
+// storage-related calls, contract hash is resolved using the given historic
+// state if it's not nil and using the current state otherwise.
+func (s *Server) getStorageParams(ps request.Params, hs *historicState) (int32, []byte, *response.Error) {
+	if len(ps) < 2 {
+		return 0, nil, response.ErrInvalidParams
+	}
+	id, rErr := s.contractIDFromParam(ps.Value(0), hs)
+	if rErr != nil {
+		return 0, nil, rErr
+	}
+	key, err := ps.Value(1).GetBytesBase64()
+	if err != nil {
+		return 0, nil, response.ErrInvalidParams
+	}
+	return id, key, nil
+}
+
+// getHistoricContractState returns the state of the contract with the given
+// hash from the MPT with the given root, it's nil if there is no such contract.
+func (s *Server) getHistoricContractState(root util.Uint256, h util.Uint160) (*state.Contract, error) {
+	id, key := native.ContractStateKey(h)
+	data, err := s.chain.GetStateModule().GetState(root, makeStorageKey(id, key))
+	if err != nil {
+		if errors.Is(err, mpt.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cs := new(state.Contract)
+	r := io.NewBinReaderFromBuf(data)
+	cs.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return cs, nil
+}
+
+// historicState describes chain state used for historic calls.
+type historicState struct {
+	// root is the state root hash of MPT to get storage items from.
+	root util.Uint256
+	// index is the index of a block to be used as execution context.
+	index uint32
+}
+
+// getHistoricState returns historic state for the given parameter which can
+// be either a block index, a block hash or a state root hash. In the latter
+// case next block after the current one is used as execution context.
+func (s *Server) getHistoricState(param *request.Param) (*historicState, *response.Error) {
+	if s.chain.GetConfig().KeepOnlyLatestState {
+		return nil, response.NewInvalidRequestError("historic calls are not supported", errKeepOnlyLatestState)
+	}
+	if param == nil {
+		return nil, response.ErrInvalidParams
+	}
+	var height uint32
+	if index, err := param.GetInt(); err == nil {
+		if index < 0 || index > int(s.chain.BlockHeight()) {
+			return nil, invalidBlockHeightError(0, index)
+		}
+		height = uint32(index)
+	} else if h, err := param.GetUint256(); err == nil {
+		hdr, err := s.chain.GetHeader(h)
+		if err != nil {
+			// Not a known block, so it's treated as a state root.
+			if _, err := s.chain.GetStateModule().GetMPTNode(h); err != nil {
+				return nil, response.NewRPCError("Unknown block or state root.", "", err)
+			}
+			return &historicState{root: h, index: s.chain.BlockHeight() + 1}, nil
+		}
+		height = hdr.Index
+	} else {
+		return nil, response.ErrInvalidParams
+	}
+	sr, err := s.chain.GetStateModule().GetStateRoot(height)
+	if err != nil {
+		return nil, response.NewRPCError("Unknown state root.", "", err)
+	}
+	return &historicState{root: sr.Root, index: height + 1}, nil
+}
+
 func (s *Server) getrawtransaction(reqParams request.Params) (interface{}, *response.Error) {
 	txHash, err := reqParams.Value(0).GetUint256()
 	if err != nil {
@@ -1186,6 +1540,44 @@ func (s *Server) getCommittee(_ request.Params) (interface{}, *response.Error) {
 
 // invokeFunction implements the `invokeFunction` RPC call.
 func (s *Server) invokeFunction(reqParams request.Params) (interface{}, *response.Error) {
+	tx, respErr := s.getInvokeFunctionParams(reqParams)
+	if respErr != nil {
+		return nil, respErr
+	}
+	opts, respErr := s.getInvokeOptions(reqParams.Value(4))
+	if respErr != nil {
+		return nil, respErr
+	}
+	return s.runScriptInVM(trigger.Application, tx.Script, util.Uint160{}, tx, nil, opts)
+}
+
+// invokeFunctionHistoric implements the `invokefunctionhistoric` RPC call.
+func (s *Server) invokeFunctionHistoric(reqParams request.Params) (interface{}, *response.Error) {
+	hs, respErr := s.getHistoricState(reqParams.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	tx, respErr := s.getInvokeFunctionParams(reqParams[1:])
+	if respErr != nil {
+		return nil, respErr
+	}
+	opts, respErr := s.getInvokeOptions(reqParams.Value(5))
+	if respErr != nil {
+		return nil, respErr
+	}
+	return s.runScriptInVM(trigger.Application, tx.Script, util.Uint160{}, tx, hs, opts)
+}
+
+// getInvokeFunctionParams creates test transaction from `invokefunction`
+// parameters.
+func (s *Server) getInvokeFunctionParams(reqParams request.Params) (*transaction.Transaction, *response.Error) {
+	if len(reqParams) < 2 {
+		return nil, response.ErrInvalidParams
+	}
+	// Invocation options are handled separately.
+	if len(reqParams) > 4 {
+		reqParams = reqParams[:4]
+	}
 	scriptHash, responseErr := s.contractScriptHashFromParam(reqParams.Value(0))
 	if responseErr != nil {
 		return nil, responseErr
@@ -1208,11 +1600,42 @@ func (s *Server) invokeFunction(reqParams request.Params) (interface{}, *respons
 		return nil, response.NewInternalServerError("can't create invocation script", err)
 	}
 	tx.Script = script
-	return s.runScriptInVM(trigger.Application, script, util.Uint160{}, tx)
+	return tx, nil
 }
 
 // invokescript implements the `invokescript` RPC call.
 func (s *Server) invokescript(reqParams request.Params) (interface{}, *response.Error) {
+	tx, respErr := s.getInvokeScriptParams(reqParams)
+	if respErr != nil {
+		return nil, respErr
+	}
+	opts, respErr := s.getInvokeOptions(reqParams.Value(2))
+	if respErr != nil {
+		return nil, respErr
+	}
+	return s.runScriptInVM(trigger.Application, tx.Script, util.Uint160{}, tx, nil, opts)
+}
+
+// invokescriptHistoric implements the `invokescripthistoric` RPC call.
+func (s *Server) invokescriptHistoric(reqParams request.Params) (interface{}, *response.Error) {
+	hs, respErr := s.getHistoricState(reqParams.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	tx, respErr := s.getInvokeScriptParams(reqParams[1:])
+	if respErr != nil {
+		return nil, respErr
+	}
+	opts, respErr := s.getInvokeOptions(reqParams.Value(3))
+	if respErr != nil {
+		return nil, respErr
+	}
+	return s.runScriptInVM(trigger.Application, tx.Script, util.Uint160{}, tx, hs, opts)
+}
+
+// getInvokeScriptParams creates test transaction from `invokescript`
+// parameters.
+func (s *Server) getInvokeScriptParams(reqParams request.Params) (*transaction.Transaction, *response.Error) {
 	if len(reqParams) < 1 {
 		return nil, response.ErrInvalidParams
 	}
@@ -1234,7 +1657,7 @@ func (s *Server) invokescript(reqParams request.Params) (interface{}, *response.
 		tx.Signers = []transaction.Signer{{Account: util.Uint160{}, Scopes: transaction.None}}
 	}
 	tx.Script = script
-	return s.runScriptInVM(trigger.Application, script, util.Uint160{}, tx)
+	return tx, nil
 }
 
 // invokeContractVerify implements the `invokecontractverify` RPC call.
@@ -1272,37 +1695,130 @@ func (s *Server) invokeContractVerify(reqParams request.Params) (interface{}, *r
 		tx.Scripts = []transaction.Witness{{InvocationScript: invocationScript, VerificationScript: []byte{}}}
 	}
 
-	return s.runScriptInVM(trigger.Verification, invocationScript, scriptHash, tx)
+	return s.runScriptInVM(trigger.Verification, invocationScript, scriptHash, tx, nil, nil)
+}
+
+// getTraceConfig returns execution trace settings from the given optional
+// parameter, nil is returned if it's not specified (so no trace is needed).
+func (s *Server) getTraceConfig(param *request.Param) (*request.TraceConfig, *response.Error) {
+	if param == nil {
+		return nil, nil
+	}
+	tc, err := param.GetTraceConfig()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, err)
+	}
+	if respErr := s.limitTraceConfig(&tc); respErr != nil {
+		return nil, respErr
+	}
+	return &tc, nil
+}
+
+// getInvokeOptions returns additional invocation result settings from the
+// given optional parameter, nil is returned if it's not specified.
+func (s *Server) getInvokeOptions(param *request.Param) (*request.InvokeOptions, *response.Error) {
+	if param == nil {
+		return nil, nil
+	}
+	opts, err := param.GetInvokeOptions()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, err)
+	}
+	if opts.Trace != nil {
+		if respErr := s.limitTraceConfig(opts.Trace); respErr != nil {
+			return nil, respErr
+		}
+	}
+	return &opts, nil
+}
+
+// limitTraceConfig applies server limits to the given trace settings, an
+// error is returned if tracing is disabled.
+func (s *Server) limitTraceConfig(tc *request.TraceConfig) *response.Error {
+	if !s.config.TraceEnabled {
+		return errTracingDisabled
+	}
+	if tc.MaxSteps <= 0 || tc.MaxSteps > s.config.MaxTraceSteps {
+		tc.MaxSteps = s.config.MaxTraceSteps
+	}
+	return nil
+}
+
+var errTracingDisabled = response.NewRPCError("Tracing is disabled", "", nil)
+
+// traceTransaction implements the `tracetransaction` RPC call.
+func (s *Server) traceTransaction(reqParams request.Params) (interface{}, *response.Error) {
+	if s.chain.GetConfig().KeepOnlyLatestState {
+		return nil, response.NewInvalidRequestError("'tracetransaction' is not supported", errKeepOnlyLatestState)
+	}
+	if !s.config.TraceEnabled {
+		return nil, errTracingDisabled
+	}
+	h, err := reqParams.Value(0).GetUint256()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	tc, respErr := s.getTraceConfig(reqParams.Value(1))
+	if respErr != nil {
+		return nil, respErr
+	}
+	if tc == nil {
+		tc = &request.TraceConfig{MaxSteps: s.config.MaxTraceSteps}
+	}
+	if _, _, err := s.chain.GetTransaction(h); err != nil {
+		return nil, response.NewRPCError("Unknown transaction", err.Error(), err)
+	}
+	v, tx, err := s.chain.GetReplayVM(h)
+	if err != nil {
+		return nil, response.NewInternalServerError("can't create replay VM", err)
+	}
+	return s.executeVM(v, tx.Script, tc, false)
 }
 
 // runScriptInVM runs given script in a new test VM and returns the invocation
 // result. The script is either a simple script in case of `application` trigger
 // witness invocation script in case of `verification` trigger (it pushes `verify`
 // arguments on stack before verification). In case of contract verification
-// contractScriptHash should be specified.
-func (s *Server) runScriptInVM(t trigger.Type, script []byte, contractScriptHash util.Uint160, tx *transaction.Transaction) (*result.Invoke, *response.Error) {
+// contractScriptHash should be specified. If historic state is given, the
+// script is executed against it, otherwise the latest state is used. Execution
+// trace and storage changes are collected if requested by invocation options.
+func (s *Server) runScriptInVM(t trigger.Type, script []byte, contractScriptHash util.Uint160, tx *transaction.Transaction, hs *historicState, opts *request.InvokeOptions) (*result.Invoke, *response.Error) {
 	// When transferring funds, script execution does no auto GAS claim,
 	// because it depends on persisting tx height.
 	// This is why we provide block here.
 	b := block.New(s.stateRootEnabled)
 	b.Index = s.chain.BlockHeight() + 1
-	hdr, err := s.chain.GetHeader(s.chain.GetHeaderHash(int(s.chain.BlockHeight())))
+	if hs != nil {
+		b.Index = hs.index
+	}
+	hdr, err := s.chain.GetHeader(s.chain.GetHeaderHash(int(b.Index - 1)))
 	if err != nil {
 		return nil, response.NewInternalServerError("can't get last block", err)
 	}
 	b.Timestamp = hdr.Timestamp + uint64(s.chain.GetConfig().SecondsPerBlock*int(time.Second/time.Millisecond))
 
-	vm := s.chain.GetTestVM(t, tx, b)
-	vm.GasLimit = int64(s.config.MaxGasInvoke)
+	var (
+		v *vm.VM
+		d dao.DAO
+	)
+	if hs != nil {
+		v, d, err = s.chain.GetTestHistoricVM(t, tx, b, hs.root)
+		if err != nil {
+			return nil, response.NewInternalServerError("can't create historic VM", err)
+		}
+	} else {
+		v, d = s.chain.GetTestVM(t, tx, b)
+	}
+	v.GasLimit = int64(s.config.MaxGasInvoke)
 	if t == trigger.Verification {
 		// We need this special case because witnesses verification is not the simple System.Contract.Call,
 		// and we need to define exactly the amount of gas consumed for a contract witness verification.
 		gasPolicy := s.chain.GetPolicer().GetMaxVerificationGAS()
-		if vm.GasLimit > gasPolicy {
-			vm.GasLimit = gasPolicy
+		if v.GasLimit > gasPolicy {
+			v.GasLimit = gasPolicy
 		}
 
-		err := s.chain.InitVerificationVM(vm, func(h util.Uint160) (*state.Contract, error) {
+		err := s.chain.InitVerificationVM(v, func(h util.Uint160) (*state.Contract, error) {
 			res := s.chain.GetContractState(h)
 			if res == nil {
 				return nil, fmt.Errorf("unknown contract: %s", h.StringBE())
@@ -1313,20 +1829,65 @@ func (s *Server) runScriptInVM(t trigger.Type, script []byte, contractScriptHash
 			return nil, response.NewInternalServerError("can't prepare verification VM", err)
 		}
 	} else {
-		vm.LoadScriptWithFlags(script, callflag.All)
+		v.LoadScriptWithFlags(script, callflag.All)
+	}
+	var tc *request.TraceConfig
+	if opts != nil {
+		tc = opts.Trace
+	}
+	res, respErr := s.executeVM(v, script, tc, opts != nil && opts.Coverage)
+	if respErr == nil && opts != nil && opts.StorageChanges {
+		res.StorageChanges = d.GetStorageChanges()
 	}
-	err = vm.Run()
+	return res, respErr
+}
+
+// executeVM runs the given VM (with script loaded) collecting execution trace
+// if trace configuration is given and executed instructions counters if
+// coverage is requested and returns the invocation result.
+func (s *Server) executeVM(v *vm.VM, script []byte, tc *request.TraceConfig, cover bool) (*result.Invoke, *response.Error) {
+	var (
+		tr    *tracer
+		cc    *coverage.Collector
+		hooks []vm.OnExecHook
+	)
+	if tc != nil {
+		tr = newTracer(v, tc.MaxSteps, tc.MaxDepth, s.config.MaxTraceSize)
+		hooks = append(hooks, tr.onExec)
+	}
+	if cover {
+		cc = coverage.NewCollector()
+		hooks = append(hooks, cc.OnExec)
+	}
+	if len(hooks) != 0 {
+		v.SetOnExecHook(func(ctx *vm.Context, op opcode.Opcode, depth int) {
+			for _, f := range hooks {
+				f(ctx, op, depth)
+			}
+		})
+	}
+	err := v.Run()
+	v.SetOnExecHook(nil)
 	var faultException string
 	if err != nil {
 		faultException = err.Error()
 	}
 	result := &result.Invoke{
-		State:          vm.State().String(),
-		GasConsumed:    vm.GasConsumed(),
+		State:          v.State().String(),
+		GasConsumed:    v.GasConsumed(),
 		Script:         script,
-		Stack:          vm.Estack().ToArray(),
+		Stack:          v.Estack().ToArray(),
 		FaultException: faultException,
 	}
+	if tr != nil {
+		result.Trace = tr.trace()
+	}
+	if cc != nil {
+		result.Coverage = coverageResult(cc)
+	}
+	if respErr := s.processIterators(result); respErr != nil {
+		return nil, respErr
+	}
 	return result, nil
 }
 
@@ -1448,7 +2009,7 @@ func (s *Server) subscribe(reqParams request.Params, sub *subscriber) (interface
 		return nil, response.ErrInvalidParams
 	}
 	event, err := response.GetEventIDFromString(streamName)
-	if err != nil || event == response.MissedEventID {
+	if err != nil || event == response.MissedEventID || event == response.ReconnectedEventID {
 		return nil, response.ErrInvalidParams
 	}
 	// Optional filter.
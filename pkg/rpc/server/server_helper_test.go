@@ -34,6 +34,7 @@ func getUnitTestChain(t *testing.T, enableOracle bool, enableNotary bool) (*core
 	cfg, err := config.Load(configPath, net)
 	require.NoError(t, err, "could not load config")
 
+	cfg.ProtocolConfiguration.SaveStorageChanges = true
 	memoryStore := storage.NewMemoryStore()
 	logger := zaptest.NewLogger(t)
 	if enableNotary {
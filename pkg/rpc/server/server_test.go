@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -22,8 +23,11 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/fee"
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/io"
@@ -31,11 +35,13 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	rpc2 "github.com/nspcc-dev/neo-go/pkg/services/oracle/broadcaster"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,6 +69,8 @@ const testContractHash = "c6ca2347bb84b99807221365c900ec069a265e7c"
 const deploymentTxHash = "26692315f71f4790263160c0f570828be77c6927493ae6657a6e5a6a09229eb9"
 const genesisBlockHash = "5b60644c6c6f58faca72c70689d7ed1f40c2e795772bd0de5a88e983ad55080c"
 
+var nnsHashLE = state.CreateContractHash(util.Uint160{}, 0, nativenames.NameService).StringLE()
+
 const verifyContractHash = "5bb4bac40e961e334ba7bd36d2496010f67e246e"
 const verifyContractAVM = "VwMAQS1RCDAhcAwUVVQtU+0PVUb61E1umZEoZwIvzl7bMHFoE87bKGnbKJdA"
 const verifyWithArgsContractHash = "59b08e81dcf94f6ddbef5c2d84a4c1a098b9a984"
@@ -84,6 +92,14 @@ var rpcTestCases = map[string][]rpcTestCase{
 				assert.Equal(t, expectedTxHash, res.Container)
 				assert.Equal(t, trigger.Application, res.Executions[0].Trigger)
 				assert.Equal(t, vm.HaltState, res.Executions[0].VMState)
+				// Contract state is created by the deployment.
+				var created bool
+				for _, ch := range res.StorageChanges {
+					if ch.ID == e.chain.GetContractState(e.chain.ManagementContractHash()).ID && ch.OldValue == nil {
+						created = true
+					}
+				}
+				require.True(t, created)
 			},
 		},
 		{
@@ -215,6 +231,81 @@ var rpcTestCases = map[string][]rpcTestCase{
 		},
 	},
 
+	"getnep11balances": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `"]`,
+			result: func(e *executor) interface{} { return &result.NEP11Balances{} },
+			check:  checkNep11Balances,
+		},
+	},
+	"getnep11properties": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "no token",
+			params: `["` + nnsHashLE + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown token",
+			params: `["` + nnsHashLE + `", "` + hex.EncodeToString([]byte("unknown.com")) + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive",
+			params: `["` + nnsHashLE + `", "` + hex.EncodeToString([]byte("neo.com")) + `"]`,
+			result: func(e *executor) interface{} { return &map[string]interface{}{} },
+			check: func(t *testing.T, e *executor, props interface{}) {
+				res, ok := props.(*map[string]interface{})
+				require.True(t, ok)
+				require.Equal(t, "neo.com", (*res)["name"])
+				require.Contains(t, *res, "expiration")
+			},
+		},
+	},
+	"getnep11transfers": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `", 0]`,
+			result: func(e *executor) interface{} { return &result.NEP11Transfers{} },
+			check:  checkNep11Transfers,
+		},
+		{
+			name:   "out of time frame",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `", 0, 1]`,
+			result: func(e *executor) interface{} { return &result.NEP11Transfers{} },
+			check: func(t *testing.T, e *executor, acc interface{}) {
+				res, ok := acc.(*result.NEP11Transfers)
+				require.True(t, ok)
+				require.Equal(t, 0, len(res.Received))
+				require.Equal(t, 0, len(res.Sent))
+			},
+		},
+	},
 	"getnep17balances": {
 		{
 			name:   "no params",
@@ -573,6 +664,48 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"banpeer": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "empty address",
+			params: `[""]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid duration",
+			params: `["1.2.3.4", "abc"]`,
+			fail:   true,
+		},
+		{
+			name:   "negative duration",
+			params: `["1.2.3.4", -1]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid reason",
+			params: `["1.2.3.4", 10, 42]`,
+			fail:   true,
+		},
+	},
+	"unbanpeer": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "not banned",
+			params: `["4.3.2.1"]`,
+			result: func(*executor) interface{} {
+				v := false
+				return &v
+			},
+		},
+	},
 	"getpeers": {
 		{
 			params: "[]",
@@ -585,6 +718,18 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"getsyncprogress": {
+		{
+			params: "[]",
+			result: func(e *executor) interface{} {
+				return &result.SyncProgress{
+					Height: e.chain.BlockHeight(),
+					Target: e.chain.BlockHeight(),
+					Peers:  []result.PeerSyncProgress{},
+				}
+			},
+		},
+	},
 	"getrawtransaction": {
 		{
 			name:   "no params",
@@ -725,6 +870,69 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["qwerty", "test", []]`,
 			fail:   true,
 		},
+		{
+			name:   "positive, trace with depth limit",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", [], [], {"trace": {"maxdepth": 1}}]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.NotNil(t, res.Trace)
+				require.NotEqual(t, 0, len(res.Trace.Steps))
+				for _, step := range res.Trace.Steps {
+					require.Equal(t, 1, step.Depth)
+					require.Equal(t, hash.Hash160(res.Script), step.Contract)
+				}
+			},
+		},
+		{
+			name:   "positive, storage changes",
+			params: fmt.Sprintf(`["%s", "putValue", [{"type": "ByteArray", "value": "dGVzdGtleQ=="}, {"type": "ByteArray", "value": "bmV3dmFsdWU="}], [], {"storagechanges": true}]`, testContractHash),
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.Equal(t, "HALT", res.State)
+				require.Nil(t, res.Trace)
+				h, err := util.Uint160DecodeStringLE(testContractHash)
+				require.NoError(t, err)
+				require.Equal(t, []state.StorageChange{{
+					ID:       e.chain.GetContractState(h).ID,
+					Key:      []byte("testkey"),
+					OldValue: []byte("testvalue"),
+					NewValue: []byte("newvalue"),
+				}}, res.StorageChanges)
+				// Changes are not applied.
+				require.Equal(t, state.StorageItem("testvalue"), e.chain.GetStorageItem(e.chain.GetContractState(h).ID, []byte("testkey")))
+			},
+		},
+		{
+			name:   "positive, coverage and trace",
+			params: fmt.Sprintf(`["%s", "putValue", [{"type": "ByteArray", "value": "dGVzdGtleQ=="}, {"type": "ByteArray", "value": "bmV3dmFsdWU="}], [], {"coverage": true, "trace": {}}]`, testContractHash),
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.Equal(t, "HALT", res.State)
+				require.NotNil(t, res.Trace)
+				h, err := util.Uint160DecodeStringLE(testContractHash)
+				require.NoError(t, err)
+				hits := make(map[util.Uint160]map[int]int)
+				for _, step := range res.Trace.Steps {
+					if hits[step.Contract] == nil {
+						hits[step.Contract] = make(map[int]int)
+					}
+					hits[step.Contract][step.IP]++
+				}
+				require.Equal(t, len(hits), len(res.Coverage))
+				var found bool
+				for _, c := range res.Coverage {
+					require.Equal(t, hits[c.Contract], c.Hits)
+					found = found || c.Contract.Equals(h)
+				}
+				require.True(t, found)
+			},
+		},
 		{
 			name:   "bad params",
 			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", [{"type": "Integer", "value": "qwerty"}]]`,
@@ -808,6 +1016,93 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["qwerty"]`,
 			fail:   true,
 		},
+		{
+			name:   "positive, trace",
+			params: `["ERKeQA==", [], {"trace": {"maxsteps": 3}}]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				assert.Equal(t, "HALT", res.State)
+				require.NotNil(t, res.Trace)
+				require.True(t, res.Trace.Truncated)
+				require.Equal(t, 3, len(res.Trace.Steps))
+				h := hash.Hash160([]byte{byte(opcode.PUSH1), byte(opcode.PUSH2), byte(opcode.ADD), byte(opcode.RET)})
+				var gas int64
+				for i, op := range []opcode.Opcode{opcode.PUSH2, opcode.ADD, opcode.RET} {
+					step := res.Trace.Steps[i]
+					require.Equal(t, i+1, step.IP)
+					require.Equal(t, op, step.OpCode)
+					require.Equal(t, h, step.Contract)
+					require.Equal(t, 1, step.Depth)
+					gas += step.GasConsumed
+				}
+				require.Equal(t, []json.RawMessage{json.RawMessage(`{"type":"Integer","value":"2"}`)}, res.Trace.Steps[0].Pushed)
+				require.Equal(t, 2, res.Trace.Steps[1].Popped)
+				require.Equal(t, []json.RawMessage{json.RawMessage(`{"type":"Integer","value":"3"}`)}, res.Trace.Steps[1].Pushed)
+				require.Equal(t, 0, res.Trace.Steps[2].Popped)
+				require.Equal(t, 0, len(res.Trace.Steps[2].Pushed))
+				require.True(t, gas < res.GasConsumed)
+			},
+		},
+		{
+			name:   "bad trace config",
+			params: `["ERKeQA==", [], 42]`,
+			fail:   true,
+		},
+	},
+	"tracetransaction": {
+		{
+			name:   "positive",
+			params: `["` + deploymentTxHash + `"]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				h, err := util.Uint256DecodeStringLE(deploymentTxHash)
+				require.NoError(t, err)
+				aers, err := e.chain.GetAppExecResults(h, trigger.Application)
+				require.NoError(t, err)
+				require.Equal(t, aers[0].VMState.String(), res.State)
+				require.Equal(t, aers[0].GasConsumed, res.GasConsumed)
+				require.NotNil(t, res.Trace)
+				var deeper bool
+				for _, step := range res.Trace.Steps {
+					deeper = deeper || step.Depth > 1
+				}
+				require.True(t, deeper)
+			},
+		},
+		{
+			name:   "positive, limited",
+			params: `["` + deploymentTxHash + `", {"maxsteps": 5, "maxdepth": 1}]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.NotNil(t, res.Trace)
+				require.Equal(t, 5, len(res.Trace.Steps))
+				require.Equal(t, opcode.RET, res.Trace.Steps[4].OpCode)
+				for _, step := range res.Trace.Steps {
+					require.Equal(t, 1, step.Depth)
+				}
+			},
+		},
+		{
+			name:   "unknown transaction",
+			params: `["` + util.Uint256{1, 2, 3}.StringLE() + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahash"]`,
+			fail:   true,
+		},
+		{
+			name:   "bad trace config",
+			params: `["` + deploymentTxHash + `", "bad"]`,
+			fail:   true,
+		},
 	},
 	"invokecontractverify": {
 		{
@@ -1285,6 +1580,43 @@ func testRPCProtocol(t *testing.T, doRPCCall func(string, string, *testing.T) []
 		require.Equal(t, vm.HaltState, res.Executions[1].VMState)
 	})
 
+	t.Run("ban", func(t *testing.T) {
+		call := func(t *testing.T, method string, params string, res interface{}) {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": %s}`, method, params)
+			require.NoError(t, json.Unmarshal(checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), false), res))
+		}
+		var (
+			ok     bool
+			banned []result.BannedPeer
+		)
+		call(t, "banpeer", `["1.2.3.4:20333", 3600, "spam"]`, &ok)
+		require.True(t, ok)
+		call(t, "getbannedpeers", `[]`, &banned)
+		require.Equal(t, 1, len(banned))
+		require.Equal(t, "1.2.3.4", banned[0].Address)
+		require.Equal(t, "spam", banned[0].Reason)
+		require.True(t, banned[0].Until.After(time.Now().Add(59*time.Minute)))
+
+		call(t, "unbanpeer", `["1.2.3.4"]`, &ok)
+		require.True(t, ok)
+		call(t, "getbannedpeers", `[]`, &banned)
+		require.Equal(t, 0, len(banned))
+
+		t.Run("disabled", func(t *testing.T) {
+			rpcSrv.config.PeerBansEnabled = false
+			defer func() { rpcSrv.config.PeerBansEnabled = true }()
+			for _, params := range []string{
+				`"banpeer", "params": ["1.2.3.4"]`,
+				`"unbanpeer", "params": ["1.2.3.4"]`,
+			} {
+				body := doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": `+params+`}`, httpSrv.URL, t)
+				checkErrGetResult(t, body, true)
+			}
+			call(t, "getbannedpeers", `[]`, &banned)
+			require.Equal(t, 0, len(banned))
+		})
+	})
+
 	t.Run("submit", func(t *testing.T) {
 		rpc := `{"jsonrpc": "2.0", "id": 1, "method": "submitblock", "params": ["%s"]}`
 		t.Run("invalid signature", func(t *testing.T) {
@@ -1354,6 +1686,176 @@ func testRPCProtocol(t *testing.T, doRPCCall func(string, string, *testing.T) []
 		require.NoError(t, json.Unmarshal(rawRes, vp))
 		require.Equal(t, []byte("testvalue"), vp.Value)
 	})
+	t.Run("historic calls", func(t *testing.T) {
+		neoHash, err := chain.GetNativeContractScriptHash(nativenames.Neo)
+		require.NoError(t, err)
+		acc := testchain.PrivateKeyByID(0).GetScriptHash()
+		stateAt := func(t *testing.T, height uint32) string {
+			r, err := chain.GetStateModule().GetStateRoot(height)
+			require.NoError(t, err)
+			return `"` + r.Root.StringLE() + `"`
+		}
+		checkBalance := func(t *testing.T, method string, point string, expected int64) {
+			var params string
+			switch method {
+			case "invokefunctionhistoric":
+				params = fmt.Sprintf(`[%s, "%s", "balanceOf", [{"type": "Hash160", "value": "%s"}]]`,
+					point, neoHash.StringLE(), acc.StringLE())
+			case "invokescripthistoric":
+				w := io.NewBufBinWriter()
+				emit.AppCall(w.BinWriter, neoHash, "balanceOf", callflag.ReadStates, acc)
+				require.NoError(t, w.Err)
+				params = fmt.Sprintf(`[%s, "%s"]`, point, base64.StdEncoding.EncodeToString(w.Bytes()))
+			}
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": %s}`, method, params)
+			body := doRPCCall(rpc, httpSrv.URL, t)
+			res := new(result.Invoke)
+			require.NoError(t, json.Unmarshal(checkErrGetResult(t, body, false), res))
+			require.Equal(t, "HALT", res.State, res.FaultException)
+			require.Equal(t, 1, len(res.Stack))
+			require.Equal(t, big.NewInt(expected), res.Stack[0].Value())
+		}
+		for _, method := range []string{"invokefunctionhistoric", "invokescripthistoric"} {
+			t.Run(method, func(t *testing.T) {
+				t.Run("by index", func(t *testing.T) {
+					checkBalance(t, method, "0", 0)
+					checkBalance(t, method, "1", 99999000)
+				})
+				t.Run("by block hash", func(t *testing.T) {
+					checkBalance(t, method, `"`+chain.GetHeaderHash(0).StringLE()+`"`, 0)
+					checkBalance(t, method, `"`+chain.GetHeaderHash(1).StringLE()+`"`, 99999000)
+				})
+				t.Run("by state root", func(t *testing.T) {
+					checkBalance(t, method, stateAt(t, 0), 0)
+					checkBalance(t, method, stateAt(t, 1), 99999000)
+				})
+				t.Run("bad height", func(t *testing.T) {
+					rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%d, "%s", "balanceOf", []]}`,
+						method, chain.BlockHeight()+1, neoHash.StringLE())
+					checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), true)
+				})
+				t.Run("unknown state root", func(t *testing.T) {
+					rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s", "%s", "balanceOf", []]}`,
+						method, util.Uint256{1, 2, 3}.StringLE(), neoHash.StringLE())
+					checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), true)
+				})
+			})
+		}
+		t.Run("getstoragehistoric", func(t *testing.T) {
+			getValue := func(t *testing.T, point string) string {
+				rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstoragehistoric", "params": [%s, "%s", "%s"]}`,
+					point, testContractHash, base64.StdEncoding.EncodeToString([]byte("testkey")))
+				var res string
+				require.NoError(t, json.Unmarshal(checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), false), &res))
+				return res
+			}
+			// Value is stored in the third block.
+			require.Equal(t, "", getValue(t, "2"))
+			require.Equal(t, base64.StdEncoding.EncodeToString([]byte("testvalue")), getValue(t, "3"))
+			require.Equal(t, "", getValue(t, stateAt(t, 2)))
+			require.Equal(t, base64.StdEncoding.EncodeToString([]byte("testvalue")), getValue(t, stateAt(t, 3)))
+
+			// Contract is resolved at the given state, it's not deployed at
+			// the genesis block.
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstoragehistoric", "params": [0, "%s", "%s"]}`,
+				testContractHash, base64.StdEncoding.EncodeToString([]byte("testkey")))
+			require.Empty(t, checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), false))
+
+			rpc = fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstoragehistoric", "params": [3, "%s"]}`, testContractHash)
+			checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), true)
+
+			rpc = fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstoragehistoric", "params": ["%s", "%s", "%s"]}`,
+				util.Uint256{1, 2, 3}.StringLE(), testContractHash, base64.StdEncoding.EncodeToString([]byte("testkey")))
+			checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), true)
+		})
+	})
+	t.Run("tracing disabled", func(t *testing.T) {
+		rpcSrv.config.TraceEnabled = false
+		defer func() { rpcSrv.config.TraceEnabled = true }()
+		for _, params := range []string{
+			`"invokescript", "params": ["ERKeQA==", [], {"trace": {}}]`,
+			`"tracetransaction", "params": ["` + deploymentTxHash + `"]`,
+		} {
+			body := doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": `+params+`}`, httpSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+		body := doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "invokescript", "params": ["ERKeQA==", []]}`, httpSrv.URL, t)
+		checkErrGetResult(t, body, false)
+	})
+	t.Run("iterator sessions", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Array(w.BinWriter, int64(1), int64(2), int64(3))
+		emit.Syscall(w.BinWriter, interopnames.SystemIteratorCreate)
+		require.NoError(t, w.Err)
+		script := base64.StdEncoding.EncodeToString(w.Bytes())
+		invoke := func(t *testing.T) *result.Invoke {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "invokescript", "params": ["%s"]}`, script)
+			res := new(result.Invoke)
+			require.NoError(t, json.Unmarshal(checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), false), res))
+			require.Equal(t, "HALT", res.State, res.FaultException)
+			require.Equal(t, 1, len(res.Stack))
+			return res
+		}
+		traverse := func(t *testing.T, session, iterator string, count int, fail bool) []stackitem.Item {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "traverseiterator", "params": ["%s", "%s", %d]}`, session, iterator, count)
+			raw := checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), fail)
+			if fail {
+				return nil
+			}
+			var arr []json.RawMessage
+			require.NoError(t, json.Unmarshal(raw, &arr))
+			items := make([]stackitem.Item, len(arr))
+			for i := range arr {
+				item, err := stackitem.FromJSONWithTypes(arr[i])
+				require.NoError(t, err)
+				items[i] = item
+			}
+			return items
+		}
+		terminate := func(t *testing.T, session string) bool {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "terminatesession", "params": ["%s"]}`, session)
+			var ok bool
+			require.NoError(t, json.Unmarshal(checkErrGetResult(t, doRPCCall(rpc, httpSrv.URL, t), false), &ok))
+			return ok
+		}
+
+		res := invoke(t)
+		require.NotEmpty(t, res.Session)
+		iter, ok := res.Stack[0].Value().(result.Iterator)
+		require.True(t, ok)
+		require.NotEmpty(t, iter.ID)
+
+		require.Equal(t, []stackitem.Item{stackitem.Make(1), stackitem.Make(2)}, traverse(t, res.Session, iter.ID, 2, false))
+		require.Equal(t, []stackitem.Item{stackitem.Make(3)}, traverse(t, res.Session, iter.ID, 2, false))
+		require.Equal(t, []stackitem.Item{}, traverse(t, res.Session, iter.ID, 2, false))
+		traverse(t, res.Session, iter.ID, 0, true)
+		traverse(t, res.Session, iter.ID, rpcSrv.config.MaxIteratorResultItems+1, true)
+		traverse(t, res.Session, "unknown", 1, true)
+		traverse(t, "unknown", iter.ID, 1, true)
+
+		require.True(t, terminate(t, res.Session))
+		require.False(t, terminate(t, res.Session))
+		traverse(t, res.Session, iter.ID, 1, true)
+
+		t.Run("disabled", func(t *testing.T) {
+			maxItems := rpcSrv.config.MaxIteratorResultItems
+			rpcSrv.config.SessionEnabled = false
+			rpcSrv.config.MaxIteratorResultItems = 2
+			defer func() {
+				rpcSrv.config.SessionEnabled = true
+				rpcSrv.config.MaxIteratorResultItems = maxItems
+			}()
+			res := invoke(t)
+			require.Empty(t, res.Session)
+			iter, ok := res.Stack[0].Value().(result.Iterator)
+			require.True(t, ok)
+			require.Equal(t, result.Iterator{
+				Values:    []stackitem.Item{stackitem.Make(1), stackitem.Make(2)},
+				Truncated: true,
+			}, iter)
+			traverse(t, "any", "any", 1, true)
+		})
+	})
 	t.Run("getstateroot", func(t *testing.T) {
 		testRoot := func(t *testing.T, p string) {
 			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstateroot", "params": [%s]}`, p)
@@ -1619,6 +2121,46 @@ func doRPCCallOverHTTP(rpcCall string, url string, t *testing.T) []byte {
 	return bytes.TrimSpace(body)
 }
 
+func checkNep11Balances(t *testing.T, e *executor, acc interface{}) {
+	res, ok := acc.(*result.NEP11Balances)
+	require.True(t, ok)
+	nnsHash, err := e.chain.GetNativeContractScriptHash(nativenames.NameService)
+	require.NoError(t, err)
+	expected := []result.NEP11AssetBalance{{
+		Asset: nnsHash,
+		Tokens: []result.NEP11TokenBalance{{
+			ID:          hex.EncodeToString([]byte("neo.com")),
+			Amount:      "1",
+			LastUpdated: 13,
+		}},
+	}}
+	require.Equal(t, testchain.PrivateKeyByID(0).Address(), res.Address)
+	require.Equal(t, expected, res.Balances)
+}
+
+func checkNep11Transfers(t *testing.T, e *executor, acc interface{}) {
+	res, ok := acc.(*result.NEP11Transfers)
+	require.True(t, ok)
+	nnsHash, err := e.chain.GetNativeContractScriptHash(nativenames.NameService)
+	require.NoError(t, err)
+	blockRegisterDomain, err := e.chain.GetBlock(e.chain.GetHeaderHash(13)) // register `neo.com` domain via NNS
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blockRegisterDomain.Transactions))
+
+	require.Equal(t, testchain.PrivateKeyByID(0).Address(), res.Address)
+	require.Equal(t, 0, len(res.Sent))
+	require.Equal(t, []result.NEP11Transfer{{
+		NEP17Transfer: result.NEP17Transfer{
+			Timestamp: blockRegisterDomain.Timestamp,
+			Asset:     nnsHash,
+			Amount:    "1",
+			Index:     13,
+			TxHash:    blockRegisterDomain.Transactions[0].Hash(),
+		},
+		ID: hex.EncodeToString([]byte("neo.com")),
+	}}, res.Received)
+}
+
 func checkNep17Balances(t *testing.T, e *executor, acc interface{}) {
 	res, ok := acc.(*result.NEP17Balances)
 	require.True(t, ok)
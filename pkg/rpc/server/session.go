@@ -0,0 +1,199 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+type (
+	// iterator is a VM iterator interface implemented by all iterators that
+	// can be returned from the contract (both VM and storage ones).
+	iterator interface {
+		Next() bool
+		Value() stackitem.Item
+	}
+
+	// session is a set of iterators returned by a single invocation. It's
+	// removed from the server after SessionExpirationTime of inactivity.
+	session struct {
+		iteratorsLock sync.Mutex
+		iterators     map[string]iterator
+		timer         *time.Timer
+	}
+)
+
+// newSessionID returns random UUID-formatted (version 4) identifier.
+func newSessionID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// processIterators replaces iterators found in the invocation result stack
+// with result.Iterator values. If sessions are enabled the iterators are stored
+// in a new session, otherwise their values (up to MaxIteratorResultItems) are
+// returned right away.
+func (s *Server) processIterators(res *result.Invoke) *response.Error {
+	var sess *session
+	for i, item := range res.Stack {
+		iop, ok := item.(*stackitem.Interop)
+		if !ok {
+			continue
+		}
+		iter, ok := iop.Value().(iterator)
+		if !ok {
+			continue
+		}
+		if !s.config.SessionEnabled {
+			values, err := traverse(iter, s.config.MaxIteratorResultItems)
+			if err != nil {
+				return response.NewInternalServerError("can't traverse iterator", err)
+			}
+			res.Stack[i] = stackitem.NewInterop(result.Iterator{
+				Values: values,
+				// The iterator is not reused after this, so it's fine
+				// to move it one more time to check for more values.
+				Truncated: len(values) == s.config.MaxIteratorResultItems && iter.Next(),
+			})
+			continue
+		}
+		if sess == nil {
+			sess = &session{iterators: make(map[string]iterator)}
+		}
+		id := newSessionID()
+		sess.iterators[id] = iter
+		res.Stack[i] = stackitem.NewInterop(result.Iterator{ID: id})
+	}
+	if sess == nil {
+		return nil
+	}
+
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	if len(s.sessions) >= s.config.SessionPoolSize {
+		return response.NewInternalServerError("max session capacity reached", nil)
+	}
+	res.Session = newSessionID()
+	id := res.Session
+	sess.timer = time.AfterFunc(time.Duration(s.config.SessionExpirationTime)*time.Second, func() {
+		s.sessionsLock.Lock()
+		delete(s.sessions, id)
+		s.sessionsLock.Unlock()
+	})
+	s.sessions[id] = sess
+	return nil
+}
+
+// traverse returns at most max next values of the iterator.
+func traverse(iter iterator, max int) (values []stackitem.Item, err error) {
+	// Storage iterator can panic on deserialization.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("iterator panicked: %v", r)
+		}
+	}()
+	values = []stackitem.Item{}
+	for len(values) < max && iter.Next() {
+		values = append(values, iter.Value())
+	}
+	return values, nil
+}
+
+// getSession returns the session with the given ID (taken from the parameter
+// with index 0) and resets its expiration timer.
+func (s *Server) getSession(reqParams request.Params) (*session, *response.Error) {
+	if !s.config.SessionEnabled {
+		return nil, response.NewRPCError("Sessions are disabled", "", nil)
+	}
+	id, err := reqParams.Value(0).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, response.NewRPCError("Unknown session", id, nil)
+	}
+	sess.timer.Reset(time.Duration(s.config.SessionExpirationTime) * time.Second)
+	return sess, nil
+}
+
+// traverseIterator returns next values of the iterator from the session.
+func (s *Server) traverseIterator(reqParams request.Params) (interface{}, *response.Error) {
+	sess, respErr := s.getSession(reqParams)
+	if respErr != nil {
+		return nil, respErr
+	}
+	iterID, err := reqParams.Value(1).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	count, err := reqParams.Value(2).GetInt()
+	if err != nil || count <= 0 {
+		return nil, response.ErrInvalidParams
+	}
+	if count > s.config.MaxIteratorResultItems {
+		return nil, response.NewInvalidParamsError(fmt.Sprintf("count is out of range: %d", count), nil)
+	}
+
+	sess.iteratorsLock.Lock()
+	defer sess.iteratorsLock.Unlock()
+	iter, ok := sess.iterators[iterID]
+	if !ok {
+		return nil, response.NewRPCError("Unknown iterator", iterID, nil)
+	}
+	values, err := traverse(iter, count)
+	if err != nil {
+		return nil, response.NewInternalServerError("can't traverse iterator", err)
+	}
+	res := make([]json.RawMessage, 0, len(values))
+	for _, v := range values {
+		data, err := stackitem.ToJSONWithTypes(v)
+		if err != nil {
+			return nil, response.NewInternalServerError("can't marshal iterator value", err)
+		}
+		res = append(res, data)
+	}
+	return res, nil
+}
+
+// terminateSession removes the session with all of its iterators.
+func (s *Server) terminateSession(reqParams request.Params) (interface{}, *response.Error) {
+	if !s.config.SessionEnabled {
+		return nil, response.NewRPCError("Sessions are disabled", "", nil)
+	}
+	id, err := reqParams.Value(0).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	sess, ok := s.sessions[id]
+	if ok {
+		sess.timer.Stop()
+		delete(s.sessions, id)
+	}
+	return ok, nil
+}
+
+// dropSessions stops all session timers and removes sessions, it's used on
+// server shutdown.
+func (s *Server) dropSessions() {
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	for id, sess := range s.sessions {
+		sess.timer.Stop()
+		delete(s.sessions, id)
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// maxTraceItemSize is the maximum size of a single stack item serialized to
+// JSON included into execution trace, bigger items are truncated.
+const maxTraceItemSize = 4096
+
+// maxTraceIntegerLen is the maximum length of decimal integer representation
+// (integers are limited to 256 bits).
+const maxTraceIntegerLen = 80
+
+// traceStepOverhead is the approximate size of serialized trace step without
+// stack items.
+const traceStepOverhead = 160
+
+// tracer collects VM execution trace via OnExecHook. It keeps the last steps
+// with invocation depth not exceeding maxDepth (if it's positive), at most
+// maxSteps of them with at most maxSize bytes of serialized data.
+type tracer struct {
+	v        *vm.VM
+	maxSteps int
+	maxDepth int
+	maxSize  int
+	// gas is the amount of GAS consumed before the current instruction.
+	gas int64
+	// steps is a ring buffer of count steps starting at start, size is
+	// their approximate serialized size.
+	steps     []result.TraceStep
+	start     int
+	count     int
+	size      int
+	truncated bool
+	// stacks contain evaluation stack contents after the last traced step
+	// that used them.
+	stacks map[*vm.Stack][]stackitem.Item
+}
+
+// newTracer creates a tracer for the given VM, its onExec method is to be
+// used as VM execution hook.
+func newTracer(v *vm.VM, maxSteps, maxDepth, maxSize int) *tracer {
+	return &tracer{
+		v:        v,
+		maxSteps: maxSteps,
+		maxDepth: maxDepth,
+		maxSize:  maxSize,
+		gas:      v.GasConsumed(),
+		steps:    make([]result.TraceStep, maxSteps),
+		stacks:   make(map[*vm.Stack][]stackitem.Item),
+	}
+}
+
+func (t *tracer) onExec(ctx *vm.Context, op opcode.Opcode, depth int) {
+	gas := t.v.GasConsumed() - t.gas
+	t.gas = t.v.GasConsumed()
+	if t.maxDepth > 0 && depth > t.maxDepth {
+		return
+	}
+	// Stack contents are updated in place, the common part of the previous
+	// and the current contents is the one not changed by the instructions.
+	estack := t.v.Estack()
+	items := t.stacks[estack]
+	prevLen := len(items)
+	var common, i int
+	estack.IterBack(func(e *vm.Element) {
+		item := e.Item()
+		if i < prevLen {
+			if common == i && items[i] == item {
+				common++
+			}
+			items[i] = item
+		} else {
+			items = append(items, item)
+		}
+		i++
+	})
+	items = items[:i]
+	t.stacks[estack] = items
+
+	size := traceStepOverhead
+	pushed := make([]json.RawMessage, 0, len(items)-common)
+	for _, item := range items[common:] {
+		data := serializeTraceItem(item, t.maxSize-size)
+		size += len(data)
+		pushed = append(pushed, data)
+	}
+	t.add(result.TraceStep{
+		IP:          ctx.IP(),
+		OpCode:      op,
+		Contract:    ctx.ScriptHash(),
+		Depth:       depth,
+		GasConsumed: gas,
+		Popped:      prevLen - common,
+		Pushed:      pushed,
+	}, size)
+}
+
+// add appends the given step to the trace dropping the oldest steps if limits
+// are exceeded.
+func (t *tracer) add(step result.TraceStep, size int) {
+	for t.count > 0 && (t.count == t.maxSteps || t.size+size > t.maxSize) {
+		t.size -= traceStepSize(t.steps[t.start])
+		t.steps[t.start] = result.TraceStep{}
+		t.start = (t.start + 1) % t.maxSteps
+		t.count--
+		t.truncated = true
+	}
+	t.steps[(t.start+t.count)%t.maxSteps] = step
+	t.count++
+	t.size += size
+}
+
+// trace returns collected trace.
+func (t *tracer) trace() *result.Trace {
+	steps := make([]result.TraceStep, 0, t.count)
+	for i := 0; i < t.count; i++ {
+		steps = append(steps, t.steps[(t.start+i)%t.maxSteps])
+	}
+	return &result.Trace{
+		Steps:     steps,
+		Truncated: t.truncated,
+	}
+}
+
+// traceStepSize returns an approximate size of the serialized step.
+func traceStepSize(step result.TraceStep) int {
+	size := traceStepOverhead
+	for _, data := range step.Pushed {
+		size += len(data)
+	}
+	return size
+}
+
+// serializeTraceItem serializes item to JSON with types if it's not bigger
+// than both maxTraceItemSize and limit, otherwise TruncatedItem is returned.
+func serializeTraceItem(item stackitem.Item, limit int) json.RawMessage {
+	if limit > maxTraceItemSize {
+		limit = maxTraceItemSize
+	}
+	if jsonSizeExceeds(item, limit, make(map[stackitem.Item]bool)) < 0 {
+		return truncatedTraceItem(item)
+	}
+	data, err := stackitem.ToJSONWithTypes(item)
+	if err != nil || len(data) > limit {
+		return truncatedTraceItem(item)
+	}
+	return data
+}
+
+func truncatedTraceItem(item stackitem.Item) json.RawMessage {
+	data, _ := json.Marshal(result.TruncatedItem{
+		Type:      item.Type().String(),
+		Truncated: true,
+	})
+	return data
+}
+
+// jsonSizeExceeds returns the remaining limit after serializing item to JSON
+// with types, -1 is returned if limit is exceeded (counting stops at this
+// point) or item has recursive references. The size is estimated from above.
+func jsonSizeExceeds(item stackitem.Item, limit int, seen map[stackitem.Item]bool) int {
+	const itemOverhead = 32 // {"type":"...","value":...}.
+	limit -= itemOverhead
+	if limit < 0 {
+		return -1
+	}
+	switch it := item.(type) {
+	case *stackitem.Array, *stackitem.Struct:
+		if seen[item] {
+			return -1
+		}
+		seen[item] = true
+		for _, elem := range it.Value().([]stackitem.Item) {
+			if limit = jsonSizeExceeds(elem, limit-1, seen); limit < 0 {
+				return -1
+			}
+		}
+	case *stackitem.Map:
+		if seen[item] {
+			return -1
+		}
+		seen[item] = true
+		for _, elem := range it.Value().([]stackitem.MapElement) {
+			if limit = jsonSizeExceeds(elem.Key, limit-len(`{"key":,"value":},`), seen); limit < 0 {
+				return -1
+			}
+			if limit = jsonSizeExceeds(elem.Value, limit, seen); limit < 0 {
+				return -1
+			}
+		}
+	case *stackitem.ByteArray, *stackitem.Buffer:
+		limit -= base64.StdEncoding.EncodedLen(len(it.Value().([]byte)))
+	case *stackitem.BigInteger:
+		limit -= maxTraceIntegerLen
+	}
+	if limit < 0 {
+		return -1
+	}
+	return limit
+}
+
+// coverageResult converts executed instructions counters collected by cc to
+// the invocation result format.
+func coverageResult(cc *coverage.Collector) []result.ContractCoverage {
+	hits := cc.Hits()
+	res := make([]result.ContractCoverage, 0, len(hits))
+	for h, m := range hits {
+		res = append(res, result.ContractCoverage{
+			Contract: h,
+			Hits:     m,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Contract.Less(res[j].Contract)
+	})
+	return res
+}
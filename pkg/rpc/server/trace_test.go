@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func runTracer(t *testing.T, script []byte, maxSteps, maxSize int) *tracer {
+	v := vm.New()
+	v.LoadScript(script)
+	tr := newTracer(v, maxSteps, 0, maxSize)
+	v.SetOnExecHook(tr.onExec)
+	require.NoError(t, v.Run())
+	return tr
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("big item", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Bytes(w.BinWriter, make([]byte, maxTraceItemSize))
+		emit.Opcodes(w.BinWriter, opcode.NEWARRAY0)
+		require.NoError(t, w.Err)
+
+		res := runTracer(t, w.Bytes(), 100, 1024*1024).trace()
+		require.False(t, res.Truncated)
+		require.Equal(t, []json.RawMessage{json.RawMessage(`{"type":"ByteString","truncated":true}`)}, res.Steps[0].Pushed)
+		require.Equal(t, []json.RawMessage{json.RawMessage(`{"type":"Array","value":[]}`)}, res.Steps[1].Pushed)
+	})
+	t.Run("recursive item", func(t *testing.T) {
+		script := []byte{byte(opcode.NEWARRAY0), byte(opcode.DUP), byte(opcode.DUP), byte(opcode.APPEND), byte(opcode.DUP)}
+		res := runTracer(t, script, 100, 1024*1024).trace()
+		require.Equal(t, 2, res.Steps[3].Popped)
+		require.Equal(t, 0, len(res.Steps[3].Pushed))
+		require.Equal(t, []json.RawMessage{json.RawMessage(`{"type":"Array","truncated":true}`)}, res.Steps[4].Pushed)
+	})
+	t.Run("steps limit", func(t *testing.T) {
+		script := []byte{byte(opcode.PUSH1), byte(opcode.PUSH2), byte(opcode.PUSH3), byte(opcode.RET)}
+		res := runTracer(t, script, 2, 1024*1024).trace()
+		require.True(t, res.Truncated)
+		require.Equal(t, 2, len(res.Steps))
+		require.Equal(t, opcode.PUSH3, res.Steps[0].OpCode)
+		require.Equal(t, opcode.RET, res.Steps[1].OpCode)
+	})
+	t.Run("size limit", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		for i := 0; i < 10; i++ {
+			emit.Bytes(w.BinWriter, make([]byte, 300))
+		}
+		require.NoError(t, w.Err)
+
+		maxSize := 3 * (traceStepOverhead + 500)
+		tr := runTracer(t, w.Bytes(), 100, maxSize)
+		require.True(t, tr.size <= maxSize)
+		res := tr.trace()
+		require.True(t, res.Truncated)
+		require.True(t, len(res.Steps) < 5)
+		for _, step := range res.Steps {
+			for _, item := range step.Pushed {
+				require.NotContains(t, string(item), "truncated")
+			}
+		}
+	})
+}
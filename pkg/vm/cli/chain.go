@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/dao"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// executionContext contains the VM used by the shell along with the chain
+// scripts are executed against. Shell context values are copied for every
+// command, so it's stored by pointer to allow replacing the VM.
+type executionContext struct {
+	vm *vm.VM
+	// chain is nil for the bare VM without interop layer.
+	chain blockchainer.Blockchainer
+	// dao contains changes made by the script loaded into the VM.
+	dao dao.DAO
+	// signers and trigger are used for the next script loaded.
+	signers []transaction.Signer
+	trigger trigger.Type
+	// coverage collects instructions executed by all VMs used by the shell.
+	coverage *coverage.Collector
+}
+
+func newExecutionContext(chain blockchainer.Blockchainer) *executionContext {
+	e := &executionContext{
+		vm:       vm.New(),
+		chain:    chain,
+		trigger:  trigger.Application,
+		coverage: coverage.NewCollector(),
+	}
+	e.vm.SetOnExecHook(e.coverage.OnExec)
+	return e
+}
+
+// load loads the script from the given NEF into the VM. For chain-backed
+// context a new VM with interop context is created for every script (method
+// tokens are only usable there), cs specifies the deployed contract the script
+// belongs to (if any).
+func (e *executionContext) load(nf *nef.File, cs *state.Contract) error {
+	if e.chain == nil {
+		e.vm.Load(nf.Script)
+		return nil
+	}
+	b, err := debugger.NextBlock(e.chain)
+	if err != nil {
+		return err
+	}
+	tx := &transaction.Transaction{
+		Script:  []byte{byte(opcode.RET)},
+		Signers: e.signers,
+	}
+	v, d := e.chain.GetTestVM(e.trigger, tx, b)
+	if cs != nil {
+		v.LoadScriptWithHash(cs.NEF.Script, cs.Hash, callflag.All)
+		// Method to run is not known yet, so return values can't be checked.
+		v.Context().RetCount = -1
+		v.Context().NEF = &cs.NEF
+	} else {
+		v.LoadScriptWithFlags(nf.Script, callflag.All)
+		v.Context().NEF = nf
+	}
+	v.SetOnExecHook(e.coverage.OnExec)
+	e.vm, e.dao = v, d
+	return nil
+}
+
+// persist saves storage changes made by the script, so that they're visible
+// to scripts loaded later. Changes are flushed into the chain's in-memory
+// cache only, the chain is never run, so they never reach its database.
+func (e *executionContext) persist() error {
+	if e.dao == nil {
+		return nil
+	}
+	_, err := e.dao.Persist()
+	e.dao = nil
+	return err
+}
+
+// getContract returns deployed contract specified by its hash, address or
+// native contract name.
+func (e *executionContext) getContract(s string) (*state.Contract, error) {
+	h, err := e.chain.GetNativeContractScriptHash(s)
+	if err != nil {
+		h, err = parseUint160(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+		}
+	}
+	cs := e.chain.GetContractState(h)
+	if cs == nil {
+		return nil, fmt.Errorf("%w: contract %s is not deployed", ErrInvalidParameter, h.StringLE())
+	}
+	return cs, nil
+}
+
+// parseSigner parses signer in a "<hash or address>[:<scope>]" format, the
+// default scope is CalledByEntry.
+func parseSigner(s string) (transaction.Signer, error) {
+	res := transaction.Signer{
+		Scopes: transaction.CalledByEntry,
+	}
+	data := strings.SplitN(s, ":", 2)
+	var err error
+	res.Account, err = parseUint160(data[0])
+	if err != nil {
+		return res, err
+	}
+	if len(data) > 1 {
+		res.Scopes, err = transaction.ScopesFromString(data[1])
+		if err != nil {
+			return transaction.Signer{}, err
+		}
+	}
+	return res, nil
+}
+
+// parseUint160 parses either an address or LE hash with an optional 0x prefix.
+func parseUint160(s string) (util.Uint160, error) {
+	if u, err := address.StringToUint160(s); err == nil {
+		return u, nil
+	}
+	u, err := util.Uint160DecodeStringLE(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return u, fmt.Errorf("neither an address nor a hash: %s", s)
+	}
+	return u, nil
+}
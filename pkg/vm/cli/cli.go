@@ -10,17 +10,25 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/abiosoft/readline"
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"gopkg.in/abiosoft/ishell.v2"
 )
@@ -28,6 +36,7 @@ import (
 const (
 	vmKey       = "vm"
 	manifestKey = "manifest"
+	debugKey    = "debugInfo"
 	boolType    = "bool"
 	boolFalse   = "false"
 	boolTrue    = "true"
@@ -52,11 +61,40 @@ var commands = []*ishell.Cmd{
 	{
 		Name: "break",
 		Help: "Place a breakpoint",
-		LongHelp: `Usage: break <ip>
-<ip> is mandatory parameter, example:
-> break 12`,
+		LongHelp: `Usage: break <ip>|<file>:<line>
+<ip> is an instruction offset, <file>:<line> is a source code location
+        (requires a script loaded with 'loadgo'), <file> can be either a full
+        path or its trailing part. Examples:
+> break 12
+> break contract.go:10`,
 		Func: handleBreak,
 	},
+	{
+		Name: "list",
+		Help: "Show source code around the current statement",
+		LongHelp: `Usage: list
+Requires a script loaded with 'loadgo', example:
+> list`,
+		Func: handleList,
+	},
+	{
+		Name:     "locals",
+		Help:     "Show local variables of the current method",
+		LongHelp: "Show local variables of the current method (with names if debug information is available)",
+		Func:     handleSlots,
+	},
+	{
+		Name:     "args",
+		Help:     "Show arguments of the current method",
+		LongHelp: "Show arguments of the current method (with names if debug information is available)",
+		Func:     handleSlots,
+	},
+	{
+		Name:     "globals",
+		Help:     "Show static variables of the current script",
+		LongHelp: "Show static variables of the current script (with names if debug information is available)",
+		Func:     handleSlots,
+	},
 	{
 		Name:     "estack",
 		Help:     "Show evaluation stack contents",
@@ -107,6 +145,37 @@ both parameters are mandatory, example:
 > loadgo /path/to/file.go`,
 		Func: handleLoadGo,
 	},
+	{
+		Name: "loaddeployed",
+		Help: "Load a deployed contract into the VM (requires chain)",
+		LongHelp: `Usage: loaddeployed <contract>
+<contract> is mandatory parameter, it can be a contract hash (LE, with or
+        without '0x' prefix), its address or native contract name, example:
+> loaddeployed NeoToken
+Contract's manifest is loaded too, so its methods can be invoked with 'run'.`,
+		Func: handleLoadDeployed,
+	},
+	{
+		Name: "signers",
+		Help: "Show or set signers for the scripts loaded (requires chain)",
+		LongHelp: `Usage: signers [<signer>...]
+<signer> is a signer to be used for the next scripts loaded, it's specified as
+        <account>[:<scope>] where <account> is an address or LE script hash
+        and <scope> is a comma-separated list of witness scopes (CalledByEntry
+        by default). Current signers are printed if no signers are given. Example:
+> signers NVTiAjNgagDkTr5HTzDmQP9kPwPHN5BgVq:Global`,
+		Func: handleSigners,
+	},
+	{
+		Name: "trigger",
+		Help: "Show or set trigger for the scripts loaded (requires chain)",
+		LongHelp: `Usage: trigger [<trigger>]
+<trigger> is a trigger type to be used for the next scripts loaded
+        (Application by default), current trigger is printed if it's not
+        given. Example:
+> trigger Verification`,
+		Func: handleTrigger,
+	},
 	{
 		Name: "parse",
 		Help: "Parse provided argument and convert it into other possible formats",
@@ -157,7 +226,8 @@ Example:
 		Name: "stepinto",
 		Help: "Stepinto instruction to take in the debugger",
 		LongHelp: `Usage: stepInto
-example:
+Steps by source lines if the script was loaded with 'loadgo' and by
+instructions otherwise, example:
 > stepinto`,
 		Func: handleStepInto,
 	},
@@ -173,7 +243,8 @@ example:
 		Name: "stepover",
 		Help: "Stepover instruction to take in the debugger",
 		LongHelp: `Usage: stepOver
-example:
+Steps by source lines if the script was loaded with 'loadgo' and by
+instructions otherwise, example:
 > stepover`,
 		Func: handleStepOver,
 	},
@@ -183,17 +254,29 @@ example:
 		LongHelp: "Dump opcodes of the current loaded program",
 		Func:     handleOps,
 	},
+	{
+		Name: "coverage",
+		Help: "Show code coverage of the scripts loaded with 'loadgo'",
+		LongHelp: `Usage: coverage [<file>]
+<file> is an optional file to save coverage data to, HTML report is written
+if it has .html extension and Go coverage profile otherwise. Coverage is
+accumulated over all runs since the VM CLI start, example:
+> coverage cover.out`,
+		Func: handleCoverage,
+	},
 }
 
 // Various errors.
 var (
 	ErrMissingParameter = errors.New("missing argument")
 	ErrInvalidParameter = errors.New("can't parse argument")
+	ErrNoDebugInfo      = errors.New("no debug information for the current script")
+	ErrNoChain          = errors.New("VM is not backed by a chain")
 )
 
 // VMCLI object for interacting with the VM.
 type VMCLI struct {
-	vm    *vm.VM
+	exec  *executionContext
 	shell *ishell.Shell
 	// printLogo specifies if logo is printed.
 	printLogo bool
@@ -208,29 +291,56 @@ func New() *VMCLI {
 
 // NewWithConfig returns new VMCLI instance using provided config.
 func NewWithConfig(printLogo bool, onExit func(int), c *readline.Config) *VMCLI {
+	return NewWithChain(nil, printLogo, onExit, c)
+}
+
+// NewWithChain returns new VMCLI instance using provided config that executes
+// scripts against the given chain (with interop layer available to them). Any
+// storage changes made by scripts are kept in memory only and never saved to
+// the chain. Bare VM is used if chain is nil.
+func NewWithChain(chain blockchainer.Blockchainer, printLogo bool, onExit func(int), c *readline.Config) *VMCLI {
 	vmcli := VMCLI{
-		vm:        vm.New(),
+		exec:      newExecutionContext(chain),
 		shell:     ishell.NewWithConfig(c),
 		printLogo: printLogo,
 	}
-	vmcli.shell.Set(vmKey, vmcli.vm)
+	vmcli.shell.Set(vmKey, vmcli.exec)
 	vmcli.shell.Set(manifestKey, new(manifest.Manifest))
+	vmcli.shell.Set(debugKey, new(debugInfo))
 	vmcli.shell.Set(exitFunc, onExit)
 	for _, c := range commands {
 		vmcli.shell.AddCmd(c)
 	}
-	changePrompt(vmcli.shell, vmcli.vm)
+	changePrompt(vmcli.shell, vmcli.exec.vm)
 	return &vmcli
 }
 
+func getExecutionContext(c *ishell.Context) *executionContext {
+	return c.Get(vmKey).(*executionContext)
+}
+
 func getVMFromContext(c *ishell.Context) *vm.VM {
-	return c.Get(vmKey).(*vm.VM)
+	return getExecutionContext(c).vm
 }
 
 func getManifestFromContext(c *ishell.Context) *manifest.Manifest {
 	return c.Get(manifestKey).(*manifest.Manifest)
 }
 
+func getDebugInfoFromContext(c *ishell.Context) *debugInfo {
+	return c.Get(debugKey).(*debugInfo)
+}
+
+// setDebugInfoInContext replaces debug information stored in the context,
+// nil di removes it.
+func setDebugInfoInContext(c *ishell.Context, di *debugInfo) {
+	old := getDebugInfoFromContext(c)
+	if di == nil {
+		di = new(debugInfo)
+	}
+	*old = *di
+}
+
 func setManifestInContext(c *ishell.Context, m *manifest.Manifest) {
 	old := getManifestFromContext(c)
 	*old = *m
@@ -273,6 +383,10 @@ func handleBreak(c *ishell.Context) {
 		c.Err(fmt.Errorf("%w: <ip>", ErrMissingParameter))
 		return
 	}
+	if i := strings.LastIndexByte(c.Args[0], ':'); i >= 0 {
+		handleBreakSource(c, v, c.Args[0][:i], c.Args[0][i+1:])
+		return
+	}
 	n, err := strconv.Atoi(c.Args[0])
 	if err != nil {
 		c.Err(fmt.Errorf("%w: %v", ErrInvalidParameter, err))
@@ -283,18 +397,150 @@ func handleBreak(c *ishell.Context) {
 	c.Printf("breakpoint added at instruction %d\n", n)
 }
 
+func handleBreakSource(c *ishell.Context, v *vm.VM, file string, lineStr string) {
+	di := getDebugInfoFromContext(c)
+	if !di.Covers(v.Context()) {
+		c.Err(ErrNoDebugInfo)
+		return
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		c.Err(fmt.Errorf("%w: %v", ErrInvalidParameter, err))
+		return
+	}
+	offsets, err := di.lineOffsets(file, line)
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	for _, n := range offsets {
+		v.AddBreakPoint(n)
+		c.Printf("breakpoint added at instruction %d (%s:%d)\n", n, file, line)
+	}
+}
+
+func handleList(c *ishell.Context) {
+	if !checkVMIsReady(c) {
+		return
+	}
+	v := getVMFromContext(c)
+	di := getDebugInfoFromContext(c)
+	ctx := v.Context()
+	if !di.Covers(ctx) {
+		c.Err(ErrNoDebugInfo)
+		return
+	}
+	sp := di.SeqPoint(ctx.NextIP())
+	if sp == nil {
+		c.Err(fmt.Errorf("no source code for instruction %d", ctx.NextIP()))
+		return
+	}
+	from := sp.StartLine - listContextLines
+	if from < 1 {
+		from = 1
+	}
+	lines, err := di.sourceLines(sp.Document, from, sp.EndLine+listContextLines)
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	c.Println(di.Documents[sp.Document])
+	for i, l := range lines {
+		n := from + i
+		marker := "  "
+		if sp.StartLine <= n && n <= sp.EndLine {
+			marker = "=>"
+		}
+		c.Printf("%s%4d\t%s\n", marker, n, l)
+	}
+}
+
+func handleSlots(c *ishell.Context) {
+	if !checkVMIsReady(c) {
+		return
+	}
+	v := getVMFromContext(c)
+	di := getDebugInfoFromContext(c)
+	ctx := v.Context()
+	var (
+		items []stackitem.Item
+		vars  map[int]debugger.SlotVariable
+	)
+	hasDI := di.Covers(ctx)
+	switch c.Cmd.Name {
+	case "locals":
+		items = ctx.Locals()
+		if hasDI {
+			vars = di.Locals(ctx.NextIP())
+		}
+	case "args":
+		items = ctx.Arguments()
+		if hasDI {
+			vars = di.Arguments(ctx.NextIP(), len(items))
+		}
+	case "globals":
+		items = ctx.Statics()
+		if hasDI {
+			vars = di.Statics()
+		}
+	}
+	buf := bytes.NewBuffer(nil)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	for i := range items {
+		name, typ := "?", "?"
+		if sv, ok := vars[i]; ok {
+			name, typ = sv.Name, sv.Type
+		}
+		data, err := stackitem.ToJSONWithTypes(items[i])
+		if err != nil {
+			data = []byte("error: " + err.Error())
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i, name, typ, data)
+	}
+	if err := w.Flush(); err != nil {
+		c.Err(err)
+		return
+	}
+	c.Print(buf.String())
+}
+
+// printSourceLine prints the location and the text of the current statement
+// if debug information is available for it.
+func printSourceLine(c *ishell.Context, v *vm.VM) {
+	di := getDebugInfoFromContext(c)
+	ctx := v.Context()
+	if !di.Covers(ctx) {
+		return
+	}
+	sp := di.SeqPoint(ctx.NextIP())
+	if sp == nil {
+		return
+	}
+	var text string
+	lines, err := di.sourceLines(sp.Document, sp.StartLine, sp.StartLine)
+	if err == nil && len(lines) == 1 {
+		text = strings.TrimSpace(lines[0])
+	}
+	c.Printf("%s\t%s\n", di.location(sp), text)
+}
+
 func handleXStack(c *ishell.Context) {
 	v := getVMFromContext(c)
 	c.Println(v.Stack(c.Cmd.Name))
 }
 
 func handleLoadNEF(c *ishell.Context) {
-	v := getVMFromContext(c)
 	if len(c.Args) < 2 {
 		c.Err(fmt.Errorf("%w: <file> <manifest>", ErrMissingParameter))
 		return
 	}
-	if err := v.LoadFile(c.Args[0]); err != nil {
+	b, err := ioutil.ReadFile(c.Args[0])
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	nefFile, err := nef.FileFromBytes(b)
+	if err != nil {
 		c.Err(err)
 		return
 	}
@@ -303,13 +549,15 @@ func handleLoadNEF(c *ishell.Context) {
 		c.Err(err)
 		return
 	}
-	c.Printf("READY: loaded %d instructions\n", v.Context().LenInstr())
+	if err := loadScript(c, &nefFile, nil); err != nil {
+		c.Err(err)
+		return
+	}
 	setManifestInContext(c, m)
-	changePrompt(c, v)
+	setDebugInfoInContext(c, nil)
 }
 
 func handleLoadBase64(c *ishell.Context) {
-	v := getVMFromContext(c)
 	if len(c.Args) < 1 {
 		c.Err(fmt.Errorf("%w: <string>", ErrMissingParameter))
 		return
@@ -319,13 +567,14 @@ func handleLoadBase64(c *ishell.Context) {
 		c.Err(fmt.Errorf("%w: %v", ErrInvalidParameter, err))
 		return
 	}
-	v.Load(b)
-	c.Printf("READY: loaded %d instructions\n", v.Context().LenInstr())
-	changePrompt(c, v)
+	if err := loadScript(c, &nef.File{Script: b}, nil); err != nil {
+		c.Err(err)
+		return
+	}
+	setDebugInfoInContext(c, nil)
 }
 
 func handleLoadHex(c *ishell.Context) {
-	v := getVMFromContext(c)
 	if len(c.Args) < 1 {
 		c.Err(fmt.Errorf("%w: <string>", ErrMissingParameter))
 		return
@@ -335,13 +584,14 @@ func handleLoadHex(c *ishell.Context) {
 		c.Err(fmt.Errorf("%w: %v", ErrInvalidParameter, err))
 		return
 	}
-	v.Load(b)
-	c.Printf("READY: loaded %d instructions\n", v.Context().LenInstr())
-	changePrompt(c, v)
+	if err := loadScript(c, &nef.File{Script: b}, nil); err != nil {
+		c.Err(err)
+		return
+	}
+	setDebugInfoInContext(c, nil)
 }
 
 func handleLoadGo(c *ishell.Context) {
-	v := getVMFromContext(c)
 	if len(c.Args) < 1 {
 		c.Err(fmt.Errorf("%w: <file>", ErrMissingParameter))
 		return
@@ -358,11 +608,88 @@ func handleLoadGo(c *ishell.Context) {
 		c.Err(fmt.Errorf("can't create manifest: %w", err))
 		return
 	}
+	if err := loadScript(c, b, nil); err != nil {
+		c.Err(err)
+		return
+	}
 	setManifestInContext(c, m)
+	setDebugInfoInContext(c, newDebugInfo(b.Script, di))
+	getExecutionContext(c).coverage.AddContract(hash.Hash160(b.Script), di)
+}
 
-	v.Load(b)
-	c.Printf("READY: loaded %d instructions\n", v.Context().LenInstr())
-	changePrompt(c, v)
+func handleLoadDeployed(c *ishell.Context) {
+	e := getExecutionContext(c)
+	if e.chain == nil {
+		c.Err(ErrNoChain)
+		return
+	}
+	if len(c.Args) < 1 {
+		c.Err(fmt.Errorf("%w: <contract>", ErrMissingParameter))
+		return
+	}
+	cs, err := e.getContract(c.Args[0])
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	if err := loadScript(c, &cs.NEF, cs); err != nil {
+		c.Err(err)
+		return
+	}
+	setManifestInContext(c, &cs.Manifest)
+	setDebugInfoInContext(c, nil)
+}
+
+// loadScript loads the script (belonging to the deployed contract cs if it's
+// not nil) into the VM.
+func loadScript(c *ishell.Context, nf *nef.File, cs *state.Contract) error {
+	e := getExecutionContext(c)
+	if err := e.load(nf, cs); err != nil {
+		return err
+	}
+	c.Printf("READY: loaded %d instructions\n", e.vm.Context().LenInstr())
+	changePrompt(c, e.vm)
+	return nil
+}
+
+func handleSigners(c *ishell.Context) {
+	e := getExecutionContext(c)
+	if e.chain == nil {
+		c.Err(ErrNoChain)
+		return
+	}
+	if len(c.Args) != 0 {
+		signers := make([]transaction.Signer, len(c.Args))
+		for i := range c.Args {
+			var err error
+			signers[i], err = parseSigner(c.Args[i])
+			if err != nil {
+				c.Err(fmt.Errorf("%w: signer #%d: %v", ErrInvalidParameter, i, err))
+				return
+			}
+		}
+		e.signers = signers
+	}
+	for _, s := range e.signers {
+		c.Printf("%s (%s)\n", address.Uint160ToString(s.Account), s.Scopes)
+	}
+}
+
+func handleTrigger(c *ishell.Context) {
+	e := getExecutionContext(c)
+	if e.chain == nil {
+		c.Err(ErrNoChain)
+		return
+	}
+	if len(c.Args) != 0 {
+		t, err := trigger.FromString(c.Args[0])
+		if err != nil {
+			c.Err(fmt.Errorf("%w: %v", ErrInvalidParameter, err))
+			return
+		}
+		e.trigger = t
+	}
+	c.Println(e.trigger)
 }
 
 func getManifestFromFile(name string) (*manifest.Manifest, error) {
@@ -429,6 +756,9 @@ func runVMWithHandling(c *ishell.Context, v *vm.VM) {
 		message = "" // the error will be printed on return
 	case v.HasHalted():
 		message = v.Stack("estack")
+		if err := getExecutionContext(c).persist(); err != nil {
+			c.Err(fmt.Errorf("can't save storage changes: %w", err))
+		}
 	case v.AtBreakpoint():
 		ctx := v.Context()
 		if ctx.NextIP() < ctx.LenInstr() {
@@ -441,6 +771,9 @@ func runVMWithHandling(c *ishell.Context, v *vm.VM) {
 	if message != "" {
 		c.Println(message)
 	}
+	if v.AtBreakpoint() {
+		printSourceLine(c, v)
+	}
 }
 
 func handleCont(c *ishell.Context) {
@@ -491,19 +824,30 @@ func handleStepType(c *ishell.Context, stepType string) {
 		return
 	}
 	v := getVMFromContext(c)
+	di := getDebugInfoFromContext(c)
+	byLine := di.Covers(v.Context())
 	var err error
 	switch stepType {
 	case "into":
-		err = v.StepInto()
+		if byLine {
+			err = di.stepLine(v, false)
+		} else {
+			err = v.StepInto()
+		}
 	case "out":
 		err = v.StepOut()
 	case "over":
-		err = v.StepOver()
+		if byLine {
+			err = di.stepLine(v, true)
+		} else {
+			err = v.StepOver()
+		}
 	}
 	if err != nil {
 		c.Err(err)
 	} else {
 		handleIP(c)
+		printSourceLine(c, v)
 	}
 	changePrompt(c, v)
 }
@@ -535,6 +879,30 @@ func (c *VMCLI) Run() error {
 	return nil
 }
 
+func handleCoverage(c *ishell.Context) {
+	p := getExecutionContext(c).coverage.Profile()
+	c.Printf("coverage: %.1f%% of statements\n", p.Coverage())
+	if len(c.Args) == 0 {
+		return
+	}
+	f, err := os.Create(c.Args[0])
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	defer f.Close()
+	if filepath.Ext(c.Args[0]) == ".html" {
+		err = p.WriteHTML(f)
+	} else {
+		err = p.Write(f)
+	}
+	if err != nil {
+		c.Err(fmt.Errorf("can't write coverage data: %w", err))
+		return
+	}
+	c.Printf("coverage data saved to %s\n", c.Args[0])
+}
+
 func handleParse(c *ishell.Context) {
 	res, err := Parse(c.Args)
 	if err != nil {
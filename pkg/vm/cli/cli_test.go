@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -16,17 +17,26 @@ import (
 	"time"
 
 	"github.com/abiosoft/readline"
+	"github.com/nspcc-dev/neo-go/internal/testchain"
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
 	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/io"
-	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/coverage"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
+	"go.uber.org/zap/zaptest"
 )
 
 type readCloser struct {
@@ -189,9 +199,7 @@ func TestLoad(t *testing.T) {
 	t.Run("loadnef", func(t *testing.T) {
 		config.Version = "0.92.0-test"
 
-		script, di, err := compiler.CompileWithDebugInfo("test", strings.NewReader(src))
-		require.NoError(t, err)
-		nefFile, err := nef.NewFile(script)
+		nefFile, di, err := compiler.CompileWithDebugInfo("test", strings.NewReader(src))
 		require.NoError(t, err)
 		filename := path.Join(tmpDir, "vmtestcontract.nef")
 		rawNef, err := nefFile.Bytes()
@@ -434,6 +442,293 @@ func TestStepIntoOverOut(t *testing.T) {
 	e.checkStack(t, 5)
 }
 
+func TestSourceDebugging(t *testing.T) {
+	src := `package kek
+
+var g = 7
+
+func Main(a, b int) int {
+	c := a + g
+	d := sum(c, b)
+	return d
+}
+
+func sum(x, y int) int {
+	s := x + y
+	return s
+}`
+	tmpDir := path.Join(os.TempDir(), "vmclisourcetest")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+	filename := path.Join(tmpDir, "kek.go")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(src), os.ModePerm))
+
+	e := newTestVMCLI(t)
+	e.runProg(t,
+		"loadhex "+hex.EncodeToString([]byte{byte(opcode.PUSH1)}),
+		"break kek.go:7",
+		"list",
+		"loadgo "+filename,
+		"break kek.go:abc",
+		"break other.go:7",
+		"break kek.go:2",
+		"break kek.go:7",
+		"run main 2 3",
+		"locals", "args", "globals",
+		"stepinto",
+		"args",
+		"stepover",
+		"stepover",
+		"list",
+		"cont")
+
+	e.checkNextLine(t, "READY: loaded 1 instructions")
+	e.checkError(t, ErrNoDebugInfo)
+	e.checkError(t, ErrNoDebugInfo)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkError(t, ErrInvalidParameter)
+	e.checkError(t, ErrInvalidParameter)
+	e.checkError(t, ErrInvalidParameter)
+	e.checkNextLine(t, "breakpoint added at instruction \\d+ \\(kek.go:7\\)")
+
+	e.checkNextLine(t, "at breakpoint \\d+")
+	e.checkNextLine(t, "kek.go:7\\s+d := sum\\(c, b\\)")
+	e.checkNextLine(t, `^0\s+c\s+Integer\s+{"type":"Integer","value":"9"}`)
+	e.checkNextLine(t, `^1\s+d\s+Integer\s+{"type":"Any"}`)
+	e.checkNextLine(t, `^2\s+\?\s+\?\s+{"type":"Any"}`)
+	e.checkNextLine(t, `^0\s+a\s+Integer\s+{"type":"Integer","value":"2"}`)
+	e.checkNextLine(t, `^1\s+b\s+Integer\s+{"type":"Integer","value":"3"}`)
+	e.checkNextLine(t, `^0\s+g\s+Any\s+{"type":"Integer","value":"7"}`)
+
+	e.checkNextLine(t, "instruction pointer at \\d+")
+	e.checkNextLine(t, "kek.go:12\\s+s := x \\+ y")
+	e.checkNextLine(t, `^0\s+x\s+Integer\s+{"type":"Integer","value":"9"}`)
+	e.checkNextLine(t, `^1\s+y\s+Integer\s+{"type":"Integer","value":"3"}`)
+	e.checkNextLine(t, "instruction pointer at \\d+")
+	e.checkNextLine(t, "kek.go:13\\s+return s")
+	e.checkNextLine(t, "instruction pointer at \\d+")
+	e.checkNextLine(t, "kek.go:8\\s+return d")
+
+	e.checkNextLine(t, "^"+filename)
+	for i := 3; i <= 13; i++ {
+		marker := "  "
+		if i == 8 {
+			marker = "=>"
+		}
+		e.checkNextLine(t, fmt.Sprintf("^%s\\s+%d\\t", marker, i))
+	}
+	e.checkStack(t, 12)
+}
+
+func TestCoverage(t *testing.T) {
+	src := `package kek
+
+func Main(a int) int {
+	if a > 0 {
+		return a
+	}
+	return -a
+}`
+	tmpDir := path.Join(os.TempDir(), "vmclicoveragetest")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+	filename := path.Join(tmpDir, "kek.go")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(src), os.ModePerm))
+	profile := path.Join(tmpDir, "cover.out")
+	report := path.Join(tmpDir, "cover.html")
+
+	e := newTestVMCLI(t)
+	e.runProg(t,
+		"coverage",
+		"loadgo "+filename,
+		"run main 5",
+		"coverage",
+		"loadgo "+filename,
+		"run main -5",
+		"coverage "+profile,
+		"coverage "+report)
+
+	e.checkNextLine(t, `^coverage: 0\.0% of statements`)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, 5)
+	e.checkNextLine(t, `^coverage: \d+\.\d% of statements`)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, 5)
+	e.checkNextLine(t, `^coverage: 100\.0% of statements`)
+	e.checkNextLine(t, "coverage data saved to "+profile)
+	e.checkNextLine(t, `^coverage: 100\.0% of statements`)
+	e.checkNextLine(t, "coverage data saved to "+report)
+
+	f, err := os.Open(profile)
+	require.NoError(t, err)
+	defer f.Close()
+	p, err := coverage.ReadProfile(f)
+	require.NoError(t, err)
+	require.Equal(t, []string{filename}, p.Files())
+	for _, b := range p.Blocks() {
+		require.True(t, b.Count > 0)
+	}
+
+	html, err := ioutil.ReadFile(report)
+	require.NoError(t, err)
+	require.Contains(t, string(html), `<span class="cov">`)
+}
+
+func newTestVMCLIWithChain(t *testing.T) (*executor, *core.Blockchain, storage.Store) {
+	cfg, err := config.Load("../../../config", netmode.UnitTestNet)
+	require.NoError(t, err)
+	store := storage.NewMemoryStore()
+	chain, err := core.NewBlockchain(store, cfg.ProtocolConfiguration, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	e := &executor{
+		in:  &readCloser{Buffer: *bytes.NewBuffer(nil)},
+		out: bytes.NewBuffer(nil),
+		ch:  make(chan struct{}),
+	}
+	e.cli = NewWithChain(chain, false,
+		func(int) { e.exit.Store(true) },
+		&readline.Config{
+			Prompt: "",
+			Stdin:  e.in,
+			Stdout: e.out,
+		})
+	return e, chain, store
+}
+
+func TestNoChain(t *testing.T) {
+	e := newTestVMCLI(t)
+	e.runProg(t,
+		"loaddeployed NeoToken",
+		"signers "+util.Uint160{1, 2, 3}.StringLE(),
+		"trigger Verification")
+
+	e.checkError(t, ErrNoChain)
+	e.checkError(t, ErrNoChain)
+	e.checkError(t, ErrNoChain)
+}
+
+func TestChain(t *testing.T) {
+	e, chain, store := newTestVMCLIWithChain(t)
+	neoHash, err := chain.GetNativeContractScriptHash(nativenames.Neo)
+	require.NoError(t, err)
+	multisig := testchain.MultisigScriptHash()
+	acc := util.Uint160{1, 2, 3}
+
+	w := io.NewBufBinWriter()
+	emit.Syscall(w.BinWriter, interopnames.SystemRuntimeGetTrigger)
+	emit.Bytes(w.BinWriter, multisig.BytesBE())
+	emit.Syscall(w.BinWriter, interopnames.SystemRuntimeCheckWitness)
+	runtimeScript := hex.EncodeToString(w.Bytes())
+
+	w.Reset()
+	emit.AppCall(w.BinWriter, neoHash, "transfer", callflag.All, multisig, acc, int64(10), nil)
+	require.NoError(t, w.Err)
+	transferScript := hex.EncodeToString(w.Bytes())
+
+	w.Reset()
+	emit.AppCall(w.BinWriter, neoHash, "balanceOf", callflag.ReadStates, acc)
+	require.NoError(t, w.Err)
+	balanceScript := hex.EncodeToString(w.Bytes())
+
+	e.runProg(t,
+		"loaddeployed",
+		"loaddeployed 0x"+acc.StringLE(),
+		"loaddeployed NeoToken",
+		"run symbol",
+		"loaddeployed "+neoHash.StringLE(),
+		"run decimals",
+		"loadhex "+runtimeScript,
+		"run",
+		"signers "+address.Uint160ToString(multisig)+":Global",
+		"signers",
+		"signers "+multisig.StringLE()+":Unknown",
+		"trigger Verification",
+		"trigger Unknown",
+		"loadhex "+runtimeScript,
+		"run",
+		"trigger Application",
+		"loadhex "+transferScript,
+		"run",
+		"loadhex "+balanceScript,
+		"run")
+
+	e.checkError(t, ErrMissingParameter)
+	e.checkError(t, ErrInvalidParameter)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, "NEO")
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, 0)
+
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, int(trigger.Application), false)
+
+	e.checkNextLine(t, "^"+address.Uint160ToString(multisig)+" \\(Global\\)")
+	e.checkNextLine(t, "^"+address.Uint160ToString(multisig)+" \\(Global\\)")
+	e.checkError(t, ErrInvalidParameter)
+	e.checkNextLine(t, "^Verification")
+	e.checkError(t, ErrInvalidParameter)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, int(trigger.Verification), true)
+
+	e.checkNextLine(t, "^Application")
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, true)
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkStack(t, 10)
+
+	// Changes are never saved to the database.
+	neo := chain.GetContractState(neoHash)
+	key := append([]byte{byte(storage.STStorage), 0, 0, 0, 0, 20}, acc.BytesBE()...)
+	binary.LittleEndian.PutUint32(key[1:], uint32(neo.ID))
+	_, err = store.Get(key)
+	require.Equal(t, storage.ErrKeyNotFound, err)
+}
+
+func TestStepOverContractCall(t *testing.T) {
+	src := `package kek
+
+import "github.com/nspcc-dev/neo-go/pkg/interop/native/neo"
+
+func Main() int {
+	s := neo.Symbol()
+	return len(s)
+}`
+	// Source is compiled within the module to resolve interop imports.
+	tmpDir, err := ioutil.TempDir(".", "stepovertest")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+	filename := path.Join(tmpDir, "kek.go")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(src), os.ModePerm))
+
+	e, _, _ := newTestVMCLIWithChain(t)
+	e.runProg(t,
+		"loadgo "+filename,
+		"break kek.go:6",
+		"run main",
+		"stepover",
+		"stepover",
+		"cont")
+
+	e.checkNextLine(t, "READY: loaded \\d+ instructions")
+	e.checkNextLine(t, "breakpoint added at instruction \\d+ \\(kek.go:6\\)")
+	e.checkNextLine(t, "at breakpoint \\d+")
+	e.checkNextLine(t, "kek.go:6\\s+s := neo.Symbol\\(\\)")
+	// Native contract call is stepped over, the rest of the inlined
+	// function is executed after it.
+	e.checkNextLine(t, "instruction pointer at \\d+ \\(STLOC0\\)")
+	e.checkNextLine(t, "neo.go:\\d+\\s+return neogointernal.CallWithToken")
+	e.checkNextLine(t, "instruction pointer at \\d+")
+	e.checkNextLine(t, "kek.go:7\\s+return len\\(s\\)")
+	e.checkStack(t, 3)
+}
+
 // `Parse` output is written via `tabwriter` so if any problems
 // are encountered in this test, try to replace ' ' with '\\s+'.
 func TestParse(t *testing.T) {
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
+)
+
+// listContextLines is the number of source lines shown by `list` command
+// before and after the current statement.
+const listContextLines = 5
+
+// debugInfo is a compiler debug information bound to the script it describes.
+type debugInfo struct {
+	debugger.Program
+}
+
+func newDebugInfo(script []byte, di *compiler.DebugInfo) *debugInfo {
+	return &debugInfo{debugger.Program{
+		DebugInfo: di,
+		Hash:      hash.Hash160(script),
+	}}
+}
+
+// document returns an index of the document matching the given file name.
+// Either the full path or its trailing part can be used.
+func (d *debugInfo) document(file string) (int, bool) {
+	file = filepath.Clean(file)
+	for i, doc := range d.Documents {
+		doc = filepath.Clean(doc)
+		if doc == file || strings.HasSuffix(doc, string(filepath.Separator)+file) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lineOffsets returns the offsets of the code corresponding to the given
+// source line, one per method (the line can be a part of several methods
+// because of inlining).
+func (d *debugInfo) lineOffsets(file string, line int) ([]int, error) {
+	doc, ok := d.document(file)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown document %s", ErrInvalidParameter, file)
+	}
+	res := d.LineOffsets(doc, line)
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%w: no code at %s:%d", ErrInvalidParameter, file, line)
+	}
+	return res, nil
+}
+
+// location returns a "file:line" string for the given sequence point.
+func (d *debugInfo) location(sp *compiler.DebugSeqPoint) string {
+	return fmt.Sprintf("%s:%d", d.Documents[sp.Document], sp.StartLine)
+}
+
+// sourceLines returns the source lines of the document in [from, to] range
+// (1-based, inclusive), the range is truncated to the file size.
+func (d *debugInfo) sourceLines(doc int, from, to int) ([]string, error) {
+	f, err := os.Open(d.Documents[doc])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []string
+	sc := bufio.NewScanner(f)
+	for n := 1; n <= to && sc.Scan(); n++ {
+		if n >= from {
+			res = append(res, sc.Text())
+		}
+	}
+	return res, sc.Err()
+}
+
+// stepLine executes the script until the beginning of the next source line
+// is reached. If over is true, lines of the called methods are not taken into
+// account. Execution also stops at breakpoints and in the code not covered by
+// the debug information (unless it's called from the code being stepped over).
+func (d *debugInfo) stepLine(v *vm.VM, over bool) error {
+	depth := v.Istack().Len()
+	next := d.NextLine(v, over)
+	for {
+		if err := v.StepInto(); err != nil {
+			return err
+		}
+		if v.HasStopped() {
+			return nil
+		}
+		ctx := v.Context()
+		if ctx.AtBreakPoint() {
+			return nil
+		}
+		currDepth := v.Istack().Len()
+		if next(ctx, currDepth) || !(over && currDepth > depth) && !d.Covers(ctx) {
+			return nil
+		}
+	}
+}
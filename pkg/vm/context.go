@@ -218,6 +218,31 @@ func (c *Context) Program() []byte {
 	return c.prog
 }
 
+// BreakPoints returns the list of breakpoints set for the context.
+func (c *Context) BreakPoints() []int {
+	res := make([]int, len(c.breakPoints))
+	copy(res, c.breakPoints)
+	return res
+}
+
+// Statics returns the contents of static slot of the context, nil if it
+// isn't initialized.
+func (c *Context) Statics() []stackitem.Item {
+	return c.static.items()
+}
+
+// Locals returns the contents of local variables slot of the context, nil if
+// it isn't initialized.
+func (c *Context) Locals() []stackitem.Item {
+	return c.local.items()
+}
+
+// Arguments returns the contents of arguments slot of the context, nil if it
+// isn't initialized.
+func (c *Context) Arguments() []stackitem.Item {
+	return c.arguments.items()
+}
+
 // ScriptHash returns a hash of the script in the current context.
 func (c *Context) ScriptHash() util.Uint160 {
 	if c.scriptHash.Equals(util.Uint160{}) {
@@ -262,7 +287,9 @@ func (c *Context) Equals(s stackitem.Item) bool {
 	return c == s
 }
 
-func (c *Context) atBreakPoint() bool {
+// AtBreakPoint returns whether the next instruction to be executed has a
+// breakpoint set.
+func (c *Context) AtBreakPoint() bool {
 	for _, n := range c.breakPoints {
 		if n == c.nextip {
 			return true
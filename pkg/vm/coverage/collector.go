@@ -0,0 +1,96 @@
+package coverage
+
+import (
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// Collector counts instructions executed by VM for every contract (it's to be
+// used as vm.OnExecHook) and converts this data into coverage profile using
+// compiler debug information of the contracts. It's safe for concurrent use,
+// so the same Collector can be attached to several VMs.
+type Collector struct {
+	lock sync.Mutex
+	// hits maps contract script hash to the instruction offset execution
+	// counters.
+	hits      map[util.Uint160]map[int]int
+	debugInfo map[util.Uint160]*compiler.DebugInfo
+}
+
+// NewCollector returns new empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		hits:      make(map[util.Uint160]map[int]int),
+		debugInfo: make(map[util.Uint160]*compiler.DebugInfo),
+	}
+}
+
+// AddContract registers debug information for the contract with the given
+// script hash, only registered contracts are included into coverage profile.
+// Script hash is the one VM context uses, that is the contract hash for
+// deployed contracts and script hash for scripts loaded directly.
+func (c *Collector) AddContract(h util.Uint160, di *compiler.DebugInfo) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.debugInfo[h] = di
+}
+
+// OnExec implements vm.OnExecHook, it counts executed instruction.
+func (c *Collector) OnExec(ctx *vm.Context, _ opcode.Opcode, _ int) {
+	h := ctx.ScriptHash()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	m := c.hits[h]
+	if m == nil {
+		m = make(map[int]int)
+		c.hits[h] = m
+	}
+	m[ctx.IP()]++
+}
+
+// AddHits adds instruction execution counters for the contract with the given
+// script hash, it can be used to process coverage data collected elsewhere
+// (like the one returned by RPC invocation calls).
+func (c *Collector) AddHits(h util.Uint160, hits map[int]int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	m := c.hits[h]
+	if m == nil {
+		m = make(map[int]int, len(hits))
+		c.hits[h] = m
+	}
+	for ip, n := range hits {
+		m[ip] += n
+	}
+}
+
+// Hits returns a copy of instruction execution counters collected for every
+// contract.
+func (c *Collector) Hits() map[util.Uint160]map[int]int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	res := make(map[util.Uint160]map[int]int, len(c.hits))
+	for h, m := range c.hits {
+		cp := make(map[int]int, len(m))
+		for ip, n := range m {
+			cp[ip] = n
+		}
+		res[h] = cp
+	}
+	return res
+}
+
+// Profile returns coverage profile for all registered contracts.
+func (c *Collector) Profile() *Profile {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	p := NewProfile()
+	for h, di := range c.debugInfo {
+		p.AddContract(di, c.hits[h])
+	}
+	return p
+}
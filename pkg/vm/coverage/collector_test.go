@@ -0,0 +1,54 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	src := `package foo
+	func Main(a int) int {
+		if a > 0 {
+			return a
+		}
+		return -a
+	}`
+	b, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(src))
+	require.NoError(t, err)
+	h := hash.Hash160(b.Script)
+
+	c := NewCollector()
+	c.AddContract(h, di)
+	require.Equal(t, float64(0), c.Profile().Coverage())
+
+	run := func(t *testing.T, arg int) {
+		v := vm.New()
+		v.SetOnExecHook(c.OnExec)
+		v.Load(b.Script)
+		v.Estack().PushVal(arg)
+		require.NoError(t, v.Run())
+	}
+	run(t, 1)
+	partial := c.Profile().Coverage()
+	require.True(t, partial > 0 && partial < 100)
+	run(t, -1)
+	require.Equal(t, float64(100), c.Profile().Coverage())
+
+	hits := c.Hits()
+	require.Equal(t, 1, len(hits))
+	require.Equal(t, 2, hits[h][0])
+
+	t.Run("AddHits", func(t *testing.T) {
+		other := NewCollector()
+		other.AddContract(h, di)
+		other.AddHits(h, hits[h])
+		other.AddHits(util.Uint160{1, 2, 3}, map[int]int{0: 1})
+		require.Equal(t, c.Profile().Blocks(), other.Profile().Blocks())
+	})
+}
@@ -0,0 +1,150 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// Source code byte states used for HTML report.
+const (
+	stateNone = iota
+	stateUncovered
+	stateCovered
+)
+
+type htmlFile struct {
+	Name     string
+	Coverage string
+	Body     template.HTML
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+<title>Contract coverage</title>
+<style>
+body { background: black; color: rgb(80, 80, 80); font-family: Menlo, monospace; }
+h2 { color: rgb(200, 200, 200); font-size: 14px; }
+pre { font-size: 14px; }
+.cov { color: rgb(44, 212, 149); }
+.uncov { color: rgb(192, 0, 0); }
+</style>
+</head>
+<body>
+<p>Total coverage: {{.Total}}</p>
+{{range .Files}}<h2>{{.Name}} ({{.Coverage}})</h2>
+<pre>{{.Body}}</pre>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes HTML report with contract source files (which are read
+// from the paths the profile contains) and covered/uncovered statements
+// highlighted.
+func (p *Profile) WriteHTML(w io.Writer) error {
+	blocks := p.Blocks()
+	files := p.Files()
+	data := struct {
+		Total string
+		Files []htmlFile
+	}{
+		Total: formatPercent(p.Coverage()),
+	}
+	for _, name := range files {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("can't read source file: %w", err)
+		}
+		var fileBlocks []Block
+		for _, b := range blocks {
+			if b.File == name {
+				fileBlocks = append(fileBlocks, b)
+			}
+		}
+		data.Files = append(data.Files, htmlFile{
+			Name:     name,
+			Coverage: formatPercent(p.Coverage(name)),
+			Body:     renderSource(src, fileBlocks),
+		})
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.1f%%", f)
+}
+
+// renderSource returns HTML-escaped source code with blocks highlighted.
+// Blocks can be nested (like function call inside assignment statement),
+// the innermost one defines the state of the source code byte.
+func renderSource(src []byte, blocks []Block) template.HTML {
+	lineStarts := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	offset := func(line, col int) int {
+		if line < 1 {
+			return 0
+		}
+		if line > len(lineStarts) {
+			return len(src)
+		}
+		off := lineStarts[line-1] + col - 1
+		if off > len(src) {
+			off = len(src)
+		}
+		return off
+	}
+
+	type span struct {
+		start, end int
+		covered    bool
+	}
+	spans := make([]span, 0, len(blocks))
+	for _, b := range blocks {
+		s := span{start: offset(b.StartLine, b.StartCol), end: offset(b.EndLine, b.EndCol), covered: b.Count > 0}
+		if s.start < s.end {
+			spans = append(spans, s)
+		}
+	}
+	// Paint larger spans first, so that inner ones override them.
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].end-spans[i].start > spans[j].end-spans[j].start
+	})
+	states := make([]byte, len(src))
+	for _, s := range spans {
+		st := byte(stateUncovered)
+		if s.covered {
+			st = stateCovered
+		}
+		for i := s.start; i < s.end; i++ {
+			states[i] = st
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(src); {
+		j := i
+		for j < len(src) && states[j] == states[i] {
+			j++
+		}
+		text := template.HTMLEscapeString(string(src[i:j]))
+		switch states[i] {
+		case stateCovered:
+			fmt.Fprintf(buf, `<span class="cov">%s</span>`, text)
+		case stateUncovered:
+			fmt.Fprintf(buf, `<span class="uncov">%s</span>`, text)
+		default:
+			buf.WriteString(text)
+		}
+		i = j
+	}
+	return template.HTML(buf.String())
+}
@@ -0,0 +1,235 @@
+/*
+Package coverage implements code coverage collection for contracts executed
+by VM. Executed instructions are mapped to the sequence points of compiler
+debug information, so coverage is reported for the contract source code
+(every sequence point is a statement there). Coverage profile uses the same
+format that `go test -coverprofile` does, so it can be processed by standard
+Go tools, HTML report can also be generated from it.
+*/
+package coverage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+)
+
+// Mode is the coverage profile mode, execution counters are stored for blocks.
+const Mode = "count"
+
+// Block is a source code block corresponding to a single sequence point along
+// with the number of statements it contains and the number of times it was
+// executed. Lines and columns are 1-based, end position points right after
+// the block.
+type Block struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+type blockKey struct {
+	file      string
+	startLine int
+	startCol  int
+	endLine   int
+	endCol    int
+}
+
+// Profile is a coverage profile for some set of source files.
+type Profile struct {
+	blocks map[blockKey]*Block
+}
+
+// lineRe matches a single profile line like
+// "path/file.go:10.2,12.16 1 5".
+var lineRe = regexp.MustCompile(`^(.+):([0-9]+)\.([0-9]+),([0-9]+)\.([0-9]+) ([0-9]+) ([0-9]+)$`)
+
+// NewProfile returns new empty profile.
+func NewProfile() *Profile {
+	return &Profile{blocks: make(map[blockKey]*Block)}
+}
+
+// ReadProfile reads profile in the Go coverage profile format.
+func ReadProfile(r io.Reader) (*Profile, error) {
+	p := NewProfile()
+	s := bufio.NewScanner(r)
+	var lineNum int
+	for s.Scan() {
+		lineNum++
+		line := s.Text()
+		if lineNum == 1 {
+			if !strings.HasPrefix(line, "mode: ") {
+				return nil, errors.New("missing profile mode")
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		m := lineRe.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: invalid block format", lineNum)
+		}
+		b := Block{File: m[1]}
+		for i, dst := range []*int{&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count} {
+			v, err := strconv.Atoi(m[i+2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			*dst = v
+		}
+		p.Add(b)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if lineNum == 0 {
+		return nil, errors.New("empty profile")
+	}
+	return p, nil
+}
+
+// Add adds block to the profile, if there is the same block already its
+// counter is increased.
+func (p *Profile) Add(b Block) {
+	k := blockKey{
+		file:      b.File,
+		startLine: b.StartLine,
+		startCol:  b.StartCol,
+		endLine:   b.EndLine,
+		endCol:    b.EndCol,
+	}
+	old, ok := p.blocks[k]
+	if !ok {
+		p.blocks[k] = &b
+		return
+	}
+	old.Count += b.Count
+	if old.NumStmt < b.NumStmt {
+		old.NumStmt = b.NumStmt
+	}
+}
+
+// AddContract adds blocks for all sequence points of the contract described by
+// the given debug information. hits maps instruction offsets to the number of
+// times they were executed, sequence point is counted as executed when its
+// first instruction is.
+func (p *Profile) AddContract(di *compiler.DebugInfo, hits map[int]int) {
+	for _, m := range di.Methods {
+		for _, sp := range m.SeqPoints {
+			if sp.Document < 0 || sp.Document >= len(di.Documents) {
+				continue
+			}
+			p.Add(Block{
+				File:      di.Documents[sp.Document],
+				StartLine: sp.StartLine,
+				StartCol:  sp.StartCol,
+				EndLine:   sp.EndLine,
+				EndCol:    sp.EndCol,
+				NumStmt:   1,
+				Count:     hits[sp.Opcode],
+			})
+		}
+	}
+}
+
+// Merge adds all blocks of other profile to p.
+func (p *Profile) Merge(other *Profile) {
+	for _, b := range other.blocks {
+		p.Add(*b)
+	}
+}
+
+// Blocks returns all profile blocks sorted by file and position.
+func (p *Profile) Blocks() []Block {
+	res := make([]Block, 0, len(p.blocks))
+	for _, b := range p.blocks {
+		res = append(res, *b)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		bi, bj := res[i], res[j]
+		if bi.File != bj.File {
+			return bi.File < bj.File
+		}
+		if bi.StartLine != bj.StartLine {
+			return bi.StartLine < bj.StartLine
+		}
+		if bi.StartCol != bj.StartCol {
+			return bi.StartCol < bj.StartCol
+		}
+		if bi.EndLine != bj.EndLine {
+			return bi.EndLine < bj.EndLine
+		}
+		return bi.EndCol < bj.EndCol
+	})
+	return res
+}
+
+// Files returns sorted list of files profile has blocks for.
+func (p *Profile) Files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for k := range p.blocks {
+		if !seen[k.file] {
+			seen[k.file] = true
+			files = append(files, k.file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Coverage returns the percentage of executed statements in the given files
+// (or in all of them if none are given). It's 0 for empty profile.
+func (p *Profile) Coverage(files ...string) float64 {
+	var total, covered int
+	for k, b := range p.blocks {
+		if len(files) != 0 && !contains(files, k.file) {
+			continue
+		}
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+func contains(ss []string, s string) bool {
+	for i := range ss {
+		if ss[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes profile in the Go coverage profile format.
+func (p *Profile) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "mode: %s\n", Mode); err != nil {
+		return err
+	}
+	for _, b := range p.Blocks() {
+		_, err := fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n", b.File,
+			b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,87 @@
+package coverage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileReadWrite(t *testing.T) {
+	p := NewProfile()
+	p.Add(Block{File: "b.go", StartLine: 3, StartCol: 2, EndLine: 3, EndCol: 10, NumStmt: 1, Count: 0})
+	p.Add(Block{File: "a.go", StartLine: 5, StartCol: 2, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 2})
+	p.Add(Block{File: "a.go", StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 8, NumStmt: 1, Count: 1})
+	p.Add(Block{File: "a.go", StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 8, NumStmt: 1, Count: 3})
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, p.Write(buf))
+	require.Equal(t, `mode: count
+a.go:1.1,1.8 1 4
+a.go:5.2,6.3 1 2
+b.go:3.2,3.10 1 0
+`, buf.String())
+
+	actual, err := ReadProfile(buf)
+	require.NoError(t, err)
+	require.Equal(t, p.Blocks(), actual.Blocks())
+	require.Equal(t, []string{"a.go", "b.go"}, actual.Files())
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"a.go:1.1,1.8 1 4\n",
+			"mode: count\na.go:1.1,1.8 1\n",
+			"mode: count\na.go:1.1,1.8 1 99999999999999999999999\n",
+		} {
+			_, err := ReadProfile(strings.NewReader(s))
+			require.Error(t, err, s)
+		}
+	})
+}
+
+func TestProfileCoverage(t *testing.T) {
+	p := NewProfile()
+	require.Equal(t, float64(0), p.Coverage())
+
+	p.Add(Block{File: "a.go", StartLine: 1, EndLine: 1, NumStmt: 1, Count: 1})
+	p.Add(Block{File: "a.go", StartLine: 2, EndLine: 2, NumStmt: 1})
+	p.Add(Block{File: "b.go", StartLine: 1, EndLine: 1, NumStmt: 2})
+	require.Equal(t, float64(25), p.Coverage())
+	require.Equal(t, float64(50), p.Coverage("a.go"))
+	require.Equal(t, float64(0), p.Coverage("b.go"))
+
+	other := NewProfile()
+	other.Add(Block{File: "b.go", StartLine: 1, EndLine: 1, NumStmt: 2, Count: 5})
+	p.Merge(other)
+	require.Equal(t, float64(75), p.Coverage())
+	require.Equal(t, float64(100), p.Coverage("b.go"))
+}
+
+func TestProfileWriteHTML(t *testing.T) {
+	src := "package a\n\nfunc F() int {\n\tif true {\n\t\treturn 1\n\t}\n\treturn 2 < 3\n}\n"
+	tmpDir := path.Join(os.TempDir(), "coveragehtmltest")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+	filename := path.Join(tmpDir, "a.go")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(src), os.ModePerm))
+
+	p := NewProfile()
+	p.Add(Block{File: filename, StartLine: 5, StartCol: 3, EndLine: 5, EndCol: 11, NumStmt: 1, Count: 1})
+	p.Add(Block{File: filename, StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 14, NumStmt: 1})
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, p.WriteHTML(buf))
+	require.Contains(t, buf.String(), "Total coverage: 50.0%")
+	require.Contains(t, buf.String(), `<span class="cov">return 1</span>`)
+	require.Contains(t, buf.String(), `<span class="uncov">return 2 &lt; 3</span>`)
+
+	p.Add(Block{File: path.Join(tmpDir, "missing.go"), StartLine: 1, EndLine: 1, NumStmt: 1})
+	require.Error(t, p.WriteHTML(new(bytes.Buffer)))
+}
@@ -0,0 +1,212 @@
+package dap
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// LaunchArguments describe the contract invocation to debug. They're passed
+// in the launch request, the ones not specified there are taken from the
+// server defaults.
+type LaunchArguments struct {
+	// Program is a path to the contract NEF file.
+	Program string `json:"program,omitempty"`
+	// Manifest is a path to the contract manifest, by default it's the
+	// Program path with '.nef' extension replaced by '.manifest.json'.
+	Manifest string `json:"manifest,omitempty"`
+	// DebugInfo is a path to the contract debug information, by default it's
+	// the Program path with '.nef' extension replaced by '.debug.json'.
+	DebugInfo string `json:"debugInfo,omitempty"`
+	// Sender is the account deploying the contract and signing the
+	// invocation (with CalledByEntry scope), zero account is used by default.
+	// The contract hash depends on it.
+	Sender *util.Uint160 `json:"sender,omitempty"`
+	// Method is the contract method to invoke with Args.
+	Method string                    `json:"method,omitempty"`
+	Args   []smartcontract.Parameter `json:"args,omitempty"`
+	// Script is a hex-encoded invocation script, it's used instead of Method
+	// and Args if specified.
+	Script string `json:"script,omitempty"`
+	// StopOnEntry specifies whether to stop at the first contract statement.
+	StopOnEntry bool `json:"stopOnEntry,omitempty"`
+}
+
+// program is a contract being debugged along with its debug information.
+type program struct {
+	debugger.Program
+	sender   util.Uint160
+	nef      *nef.File
+	manifest *manifest.Manifest
+	// documents contain absolute paths of the source files.
+	documents []string
+}
+
+// withDefaults returns the arguments with the unspecified values taken
+// from the defaults. Invocation is taken from the defaults only if neither
+// method nor script is specified.
+func (a LaunchArguments) withDefaults(d LaunchArguments) LaunchArguments {
+	if a.Program == "" {
+		a.Program = d.Program
+	}
+	if a.Manifest == "" {
+		a.Manifest = d.Manifest
+	}
+	if a.DebugInfo == "" {
+		a.DebugInfo = d.DebugInfo
+	}
+	if a.Sender == nil {
+		a.Sender = d.Sender
+	}
+	if a.Method == "" && a.Script == "" {
+		a.Method, a.Args, a.Script = d.Method, d.Args, d.Script
+	}
+	a.StopOnEntry = a.StopOnEntry || d.StopOnEntry
+	return a
+}
+
+// loadProgram reads the contract files specified in the launch arguments.
+func loadProgram(args LaunchArguments) (*program, error) {
+	if args.Program == "" {
+		return nil, errors.New("no program specified")
+	}
+	base := strings.TrimSuffix(args.Program, ".nef")
+	manifestPath := args.Manifest
+	if manifestPath == "" {
+		manifestPath = base + ".manifest.json"
+	}
+	debugPath := args.DebugInfo
+	if debugPath == "" {
+		debugPath = base + ".debug.json"
+	}
+
+	data, err := ioutil.ReadFile(args.Program)
+	if err != nil {
+		return nil, fmt.Errorf("can't read NEF file: %w", err)
+	}
+	nf, err := nef.FileFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode NEF file: %w", err)
+	}
+	data, err = ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't read manifest: %w", err)
+	}
+	m := new(manifest.Manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("can't decode manifest: %w", err)
+	}
+	data, err = ioutil.ReadFile(debugPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't read debug info: %w", err)
+	}
+	di := new(compiler.DebugInfo)
+	if err := json.Unmarshal(data, di); err != nil {
+		return nil, fmt.Errorf("can't decode debug info: %w", err)
+	}
+
+	p := &program{
+		nef:       &nf,
+		manifest:  m,
+		documents: make([]string, len(di.Documents)),
+	}
+	if args.Sender != nil {
+		p.sender = *args.Sender
+	}
+	p.DebugInfo = di
+	p.Hash = state.CreateContractHash(p.sender, nf.Checksum, m.Name)
+	for i, doc := range di.Documents {
+		p.documents[i], err = filepath.Abs(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// invocationScript returns the script to be executed for the given launch
+// arguments.
+func (p *program) invocationScript(args LaunchArguments) ([]byte, error) {
+	if args.Script != "" {
+		script, err := hex.DecodeString(args.Script)
+		if err != nil {
+			return nil, fmt.Errorf("bad script: %w", err)
+		}
+		return script, nil
+	}
+	if args.Method == "" {
+		return nil, errors.New("neither method nor script specified")
+	}
+	params := make([]interface{}, len(args.Args))
+	for i := range args.Args {
+		var err error
+		params[i], err = smartcontract.ExpandParameterToEmitable(args.Args[i])
+		if err != nil {
+			return nil, fmt.Errorf("bad argument #%d: %w", i, err)
+		}
+	}
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, p.Hash, args.Method, callflag.All, params...)
+	if w.Err != nil {
+		return nil, fmt.Errorf("can't create invocation script: %w", w.Err)
+	}
+	return w.Bytes(), nil
+}
+
+// document returns an index of the document with the given path.
+func (p *program) document(path string) (int, bool) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return 0, false
+	}
+	for i := range p.documents {
+		if p.documents[i] == path {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// source returns the source of the given document.
+func (p *program) source(doc int) *source {
+	return &source{
+		Name: filepath.Base(p.documents[doc]),
+		Path: p.documents[doc],
+	}
+}
+
+// deployScript returns the script deploying the contract via the management
+// contract with the given hash.
+func (p *program) deployScript(management util.Uint160) ([]byte, error) {
+	nefData, err := p.nef.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("can't encode NEF file: %w", err)
+	}
+	manifestData, err := json.Marshal(p.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode manifest: %w", err)
+	}
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, management, "deploy", callflag.All, nefData, manifestData)
+	emit.Opcodes(w.BinWriter, opcode.DROP)
+	if w.Err != nil {
+		return nil, fmt.Errorf("can't create deployment script: %w", w.Err)
+	}
+	return w.Bytes(), nil
+}
@@ -0,0 +1,236 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Message types of the Debug Adapter Protocol.
+const (
+	typeRequest  = "request"
+	typeResponse = "response"
+	typeEvent    = "event"
+)
+
+// Stop reasons used in the stopped event.
+const (
+	reasonEntry      = "entry"
+	reasonStep       = "step"
+	reasonBreakpoint = "breakpoint"
+	reasonException  = "exception"
+)
+
+// threadID is the ID of the only thread VM has.
+const threadID = 1
+
+// contentLengthHeader is the only header DAP messages have.
+const contentLengthHeader = "Content-Length"
+
+// maxMessageSize is the maximum allowed message body size.
+const maxMessageSize = 16 * 1024 * 1024
+
+type (
+	// message contains fields common for all protocol messages.
+	message struct {
+		Seq  int    `json:"seq"`
+		Type string `json:"type"`
+	}
+
+	// request is a client request, arguments are decoded by the request
+	// handler.
+	request struct {
+		message
+		Command   string          `json:"command"`
+		Arguments json.RawMessage `json:"arguments,omitempty"`
+	}
+
+	// response is a reply to the client request.
+	response struct {
+		message
+		RequestSeq int         `json:"request_seq"`
+		Success    bool        `json:"success"`
+		Command    string      `json:"command"`
+		Message    string      `json:"message,omitempty"`
+		Body       interface{} `json:"body,omitempty"`
+	}
+
+	// event is a notification sent to the client.
+	event struct {
+		message
+		Event string      `json:"event"`
+		Body  interface{} `json:"body,omitempty"`
+	}
+)
+
+type (
+	// capabilities are the features supported by the adapter.
+	capabilities struct {
+		SupportsConfigurationDoneRequest bool `json:"supportsConfigurationDoneRequest"`
+		SupportsTerminateRequest         bool `json:"supportsTerminateRequest"`
+	}
+
+	source struct {
+		Name string `json:"name,omitempty"`
+		Path string `json:"path,omitempty"`
+	}
+
+	sourceBreakpoint struct {
+		Line int `json:"line"`
+	}
+
+	setBreakpointsArguments struct {
+		Source      source             `json:"source"`
+		Breakpoints []sourceBreakpoint `json:"breakpoints"`
+	}
+
+	breakpoint struct {
+		Verified bool    `json:"verified"`
+		Message  string  `json:"message,omitempty"`
+		Source   *source `json:"source,omitempty"`
+		Line     int     `json:"line,omitempty"`
+	}
+
+	breakpointsBody struct {
+		Breakpoints []breakpoint `json:"breakpoints"`
+	}
+
+	thread struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	threadsBody struct {
+		Threads []thread `json:"threads"`
+	}
+
+	stackFrame struct {
+		ID     int     `json:"id"`
+		Name   string  `json:"name"`
+		Source *source `json:"source,omitempty"`
+		Line   int     `json:"line"`
+		Column int     `json:"column"`
+	}
+
+	stackTraceBody struct {
+		StackFrames []stackFrame `json:"stackFrames"`
+		TotalFrames int          `json:"totalFrames"`
+	}
+
+	frameArguments struct {
+		FrameID int `json:"frameId"`
+	}
+
+	scope struct {
+		Name               string `json:"name"`
+		VariablesReference int    `json:"variablesReference"`
+		Expensive          bool   `json:"expensive"`
+	}
+
+	scopesBody struct {
+		Scopes []scope `json:"scopes"`
+	}
+
+	variablesArguments struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+
+	variable struct {
+		Name               string `json:"name"`
+		Value              string `json:"value"`
+		Type               string `json:"type,omitempty"`
+		VariablesReference int    `json:"variablesReference"`
+	}
+
+	variablesBody struct {
+		Variables []variable `json:"variables"`
+	}
+
+	continueBody struct {
+		AllThreadsContinued bool `json:"allThreadsContinued"`
+	}
+
+	stoppedBody struct {
+		Reason            string `json:"reason"`
+		Description       string `json:"description,omitempty"`
+		Text              string `json:"text,omitempty"`
+		ThreadID          int    `json:"threadId"`
+		AllThreadsStopped bool   `json:"allThreadsStopped"`
+	}
+
+	outputBody struct {
+		Category string `json:"category"`
+		Output   string `json:"output"`
+	}
+
+	exitedBody struct {
+		ExitCode int `json:"exitCode"`
+	}
+)
+
+// readMessage reads a single DAP request from r.
+func readMessage(r *bufio.Reader) (*request, error) {
+	data, err := readContent(r)
+	if err != nil {
+		return nil, err
+	}
+	req := new(request)
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, fmt.Errorf("bad message: %w", err)
+	}
+	if req.Type != typeRequest {
+		return nil, fmt.Errorf("unexpected message type: %s", req.Type)
+	}
+	return req, nil
+}
+
+// readContent reads a single DAP message content from r.
+func readContent(r *bufio.Reader) ([]byte, error) {
+	var length = -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed header: %q", line)
+		}
+		if strings.TrimSpace(kv[0]) != contentLengthHeader {
+			continue
+		}
+		length, err = strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("bad content length: %w", err)
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("no content length")
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message is too big: %d bytes", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeMessage writes a single DAP message to w.
+func writeMessage(w io.Writer, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s: %d\r\n\r\n%s", contentLengthHeader, len(data), data)
+	return err
+}
@@ -0,0 +1,352 @@
+/*
+Package dap implements a Debug Adapter Protocol server for smart contracts.
+
+The server debugs a single contract invocation described by the launch request
+arguments (see LaunchArguments), contract source files are taken from the
+compiler debug information. The contract is deployed and invoked in a test VM
+of the chain given to the server, so it can interact with native and other
+deployed contracts the same way it does on the network. Nothing is persisted,
+every launch starts from the current chain state.
+*/
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Server is a Debug Adapter Protocol server working over a single
+// connection.
+type Server struct {
+	r        *bufio.Reader
+	w        io.Writer
+	chain    blockchainer.Blockchainer
+	defaults LaunchArguments
+	seq      int
+
+	session     *session
+	stopOnEntry bool
+	// started and finished specify the invocation state.
+	started  bool
+	finished bool
+}
+
+// errNotLaunched is returned for requests requiring a program to be launched.
+var errNotLaunched = errors.New("no program launched")
+
+// NewServer creates a server communicating via the given connection and
+// executing contracts against the given chain (it's never modified), defaults
+// are used for the launch arguments not specified by the client.
+func NewServer(conn io.ReadWriter, chain blockchainer.Blockchainer, defaults LaunchArguments) *Server {
+	return &Server{
+		r:        bufio.NewReader(conn),
+		w:        conn,
+		chain:    chain,
+		defaults: defaults,
+	}
+}
+
+// Serve handles client requests until it disconnects or the connection is
+// closed.
+func (s *Server) Serve() error {
+	for {
+		req, err := readMessage(s.r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		done, err := s.handle(req)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// handle handles a single request, it returns true if the client has
+// disconnected.
+func (s *Server) handle(req *request) (bool, error) {
+	var (
+		body interface{}
+		err  error
+		// after is executed after the response is sent.
+		after func() error
+	)
+	switch req.Command {
+	case "initialize":
+		body = capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsTerminateRequest:         true,
+		}
+	case "launch":
+		err = s.launch(req.Arguments)
+		after = func() error { return s.sendEvent("initialized", nil) }
+	case "setBreakpoints":
+		body, err = s.setBreakpoints(req.Arguments)
+	case "setExceptionBreakpoints":
+		body = breakpointsBody{Breakpoints: []breakpoint{}}
+	case "configurationDone":
+		if s.session == nil {
+			err = errNotLaunched
+			break
+		}
+		after = s.start
+	case "threads":
+		body = threadsBody{Threads: []thread{{ID: threadID, Name: "main"}}}
+	case "stackTrace":
+		if s.session == nil {
+			err = errNotLaunched
+			break
+		}
+		frames := s.session.stackTrace()
+		body = stackTraceBody{StackFrames: frames, TotalFrames: len(frames)}
+	case "scopes":
+		body, err = s.scopes(req.Arguments)
+	case "variables":
+		body, err = s.variables(req.Arguments)
+	case "continue", "next", "stepIn", "stepOut":
+		if err = s.checkRunning(); err != nil {
+			break
+		}
+		if req.Command == "continue" {
+			body = continueBody{AllThreadsContinued: true}
+		}
+		after = s.executor(req.Command)
+	case "terminate":
+		if s.session == nil {
+			err = errNotLaunched
+			break
+		}
+		after = s.terminate
+	case "disconnect":
+		return true, s.respond(req, nil, nil)
+	default:
+		err = fmt.Errorf("unsupported command: %s", req.Command)
+	}
+	if err := s.respond(req, body, err); err != nil {
+		return false, err
+	}
+	if after != nil && err == nil {
+		return false, after()
+	}
+	return false, nil
+}
+
+func (s *Server) launch(data json.RawMessage) error {
+	if s.session != nil {
+		return errors.New("program is already launched")
+	}
+	var args LaunchArguments
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, &args); err != nil {
+			return err
+		}
+	}
+	args = args.withDefaults(s.defaults)
+	p, err := loadProgram(args)
+	if err != nil {
+		return err
+	}
+	script, err := p.invocationScript(args)
+	if err != nil {
+		return err
+	}
+	s.session, err = newSession(s.chain, p, script, s.output)
+	if err != nil {
+		return err
+	}
+	s.stopOnEntry = args.StopOnEntry
+	return nil
+}
+
+func (s *Server) setBreakpoints(data json.RawMessage) (interface{}, error) {
+	if s.session == nil {
+		return nil, errNotLaunched
+	}
+	var args setBreakpointsArguments
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	lines := make([]int, len(args.Breakpoints))
+	for i := range args.Breakpoints {
+		lines[i] = args.Breakpoints[i].Line
+	}
+	return breakpointsBody{Breakpoints: s.session.setBreakpoints(args.Source.Path, lines)}, nil
+}
+
+func (s *Server) scopes(data json.RawMessage) (interface{}, error) {
+	if s.session == nil {
+		return nil, errNotLaunched
+	}
+	var args frameArguments
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	scopes, err := s.session.scopes(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+	return scopesBody{Scopes: scopes}, nil
+}
+
+func (s *Server) variables(data json.RawMessage) (interface{}, error) {
+	if s.session == nil {
+		return nil, errNotLaunched
+	}
+	var args variablesArguments
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	vars, err := s.session.variables(args.VariablesReference)
+	if err != nil {
+		return nil, err
+	}
+	return variablesBody{Variables: vars}, nil
+}
+
+func (s *Server) checkRunning() error {
+	switch {
+	case s.session == nil:
+		return errNotLaunched
+	case !s.started:
+		return errors.New("configuration is not done")
+	case s.finished:
+		return errors.New("program has finished")
+	}
+	return nil
+}
+
+// start starts the invocation after the client is configured.
+func (s *Server) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	if !s.stopOnEntry {
+		return s.stopped(s.session.cont())
+	}
+	reason := s.session.stepLine(false)
+	if reason == reasonStep {
+		reason = reasonEntry
+	}
+	return s.stopped(reason)
+}
+
+// executor returns the function executing the given command.
+func (s *Server) executor(cmd string) func() error {
+	return func() error {
+		if s.session.v.HasFailed() {
+			// Failed VM can't be resumed.
+			return s.finish()
+		}
+		var reason string
+		switch cmd {
+		case "continue":
+			reason = s.session.cont()
+		case "next":
+			reason = s.session.stepLine(true)
+		case "stepIn":
+			reason = s.session.stepLine(false)
+		case "stepOut":
+			reason = s.session.stepOut()
+		}
+		return s.stopped(reason)
+	}
+}
+
+// stopped notifies the client about the execution stop with the given reason.
+func (s *Server) stopped(reason string) error {
+	if reason == "" {
+		return s.finish()
+	}
+	body := stoppedBody{
+		Reason:            reason,
+		ThreadID:          threadID,
+		AllThreadsStopped: true,
+	}
+	if reason == reasonException {
+		body.Description = "VM has failed"
+		body.Text = s.session.err.Error()
+	}
+	return s.sendEvent("stopped", body)
+}
+
+// finish reports the invocation result and terminates the session.
+func (s *Server) finish() error {
+	if s.finished {
+		return nil
+	}
+	s.finished = true
+	v := s.session.v
+	exitCode := 0
+	if v.HasFailed() {
+		exitCode = 1
+		s.output(categoryConsole, fmt.Sprintf("FAULT: %s\n", s.session.err))
+	} else {
+		s.output(categoryConsole, fmt.Sprintf("HALT: %s\n", resultStack(v.Estack().ToArray())))
+	}
+	if err := s.sendEvent("exited", exitedBody{ExitCode: exitCode}); err != nil {
+		return err
+	}
+	return s.sendEvent("terminated", nil)
+}
+
+func (s *Server) terminate() error {
+	if s.finished {
+		return nil
+	}
+	s.finished = true
+	return s.sendEvent("terminated", nil)
+}
+
+// output sends an output event ignoring errors, they will be returned for
+// the next message sent.
+func (s *Server) output(category, text string) {
+	_ = s.sendEvent("output", outputBody{Category: category, Output: text})
+}
+
+func (s *Server) respond(req *request, body interface{}, err error) error {
+	s.seq++
+	resp := response{
+		message:    message{Seq: s.seq, Type: typeResponse},
+		RequestSeq: req.Seq,
+		Success:    err == nil,
+		Command:    req.Command,
+		Body:       body,
+	}
+	if err != nil {
+		resp.Message = err.Error()
+		resp.Body = nil
+	}
+	return writeMessage(s.w, resp)
+}
+
+func (s *Server) sendEvent(name string, body interface{}) error {
+	s.seq++
+	return writeMessage(s.w, event{
+		message: message{Seq: s.seq, Type: typeEvent},
+		Event:   name,
+		Body:    body,
+	})
+}
+
+// resultStack returns JSON representation of the items left on the stack.
+func resultStack(items []stackitem.Item) string {
+	res := make([]json.RawMessage, len(items))
+	for i := range items {
+		data, err := stackitem.ToJSONWithTypes(items[i])
+		if err != nil {
+			data, _ = json.Marshal(err.Error())
+		}
+		res[i] = data
+	}
+	data, _ := json.Marshal(res)
+	return string(data)
+}
@@ -0,0 +1,320 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testSource = `package foo
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/neo"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+)
+
+var counter = 1
+
+func Main(a int) int {
+	b := a + counter
+	b = double(b)
+	runtime.Log("done")
+	return b
+}
+
+func Fail() {
+	panic("boom")
+}
+
+func double(x int) int {
+	return x * 2
+}
+
+func Native() string {
+	return neo.Symbol()
+}`
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	seq  int
+	done chan error
+}
+
+type testMessage struct {
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Event   string          `json:"event"`
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// newTestClient compiles the test contract and starts the server for it.
+func newTestClient(t *testing.T) (*testClient, string) {
+	nf, di, err := compiler.CompileWithDebugInfo("foo.go", strings.NewReader(testSource))
+	require.NoError(t, err)
+	m, err := di.ConvertToManifest(&compiler.Options{Name: "foo"})
+	require.NoError(t, err)
+
+	tmpDir := path.Join(os.TempDir(), "dapservertest")
+	require.NoError(t, os.Mkdir(tmpDir, os.ModePerm))
+	t.Cleanup(func() {
+		os.RemoveAll(tmpDir)
+	})
+	data, err := nf.Bytes()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "foo.nef"), data, os.ModePerm))
+	data, err = json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "foo.manifest.json"), data, os.ModePerm))
+	data, err = json.Marshal(di)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "foo.debug.json"), data, os.ModePerm))
+
+	cfg, err := config.Load("../../../config", netmode.UnitTestNet)
+	require.NoError(t, err)
+	chain, err := core.NewBlockchain(storage.NewMemoryStore(), cfg.ProtocolConfiguration, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	serverConn, clientConn := net.Pipe()
+	c := &testClient{
+		t:    t,
+		conn: clientConn,
+		r:    bufio.NewReader(clientConn),
+		done: make(chan error, 1),
+	}
+	s := NewServer(serverConn, chain, LaunchArguments{Program: path.Join(tmpDir, "foo.nef")})
+	go func() {
+		c.done <- s.Serve()
+		serverConn.Close()
+	}()
+	t.Cleanup(func() {
+		clientConn.Close()
+	})
+	src, err := filepath.Abs("foo.go")
+	require.NoError(t, err)
+	return c, src
+}
+
+func (c *testClient) send(cmd string, args interface{}) {
+	c.seq++
+	req := map[string]interface{}{
+		"seq":       c.seq,
+		"type":      typeRequest,
+		"command":   cmd,
+		"arguments": args,
+	}
+	require.NoError(c.t, writeMessage(c.conn, req))
+}
+
+func (c *testClient) next() testMessage {
+	data, err := readContent(c.r)
+	require.NoError(c.t, err)
+	var msg testMessage
+	require.NoError(c.t, json.Unmarshal(data, &msg))
+	return msg
+}
+
+func (c *testClient) checkResponse(cmd string, body interface{}) {
+	msg := c.next()
+	require.Equal(c.t, typeResponse, msg.Type)
+	require.Equal(c.t, cmd, msg.Command)
+	require.True(c.t, msg.Success, msg.Message)
+	if body != nil {
+		require.NoError(c.t, json.Unmarshal(msg.Body, body))
+	}
+}
+
+func (c *testClient) checkError(cmd string, text string) {
+	msg := c.next()
+	require.Equal(c.t, typeResponse, msg.Type)
+	require.Equal(c.t, cmd, msg.Command)
+	require.False(c.t, msg.Success)
+	require.Contains(c.t, msg.Message, text)
+}
+
+func (c *testClient) checkEvent(name string, body interface{}) {
+	msg := c.next()
+	require.Equal(c.t, typeEvent, msg.Type)
+	require.Equal(c.t, name, msg.Event)
+	if body != nil {
+		require.NoError(c.t, json.Unmarshal(msg.Body, body))
+	}
+}
+
+func (c *testClient) checkStopped(reason string) {
+	var body stoppedBody
+	c.checkEvent("stopped", &body)
+	require.Equal(c.t, reason, body.Reason)
+}
+
+func (c *testClient) checkFrame(name string, line int, frames int) {
+	c.send("stackTrace", map[string]int{"threadId": threadID})
+	var body stackTraceBody
+	c.checkResponse("stackTrace", &body)
+	require.Equal(c.t, frames, len(body.StackFrames))
+	require.Equal(c.t, name, body.StackFrames[0].Name)
+	require.Equal(c.t, line, body.StackFrames[0].Line)
+}
+
+func (c *testClient) variables(ref int) map[string]string {
+	c.send("variables", variablesArguments{VariablesReference: ref})
+	var body variablesBody
+	c.checkResponse("variables", &body)
+	res := make(map[string]string, len(body.Variables))
+	for _, v := range body.Variables {
+		res[v.Name] = v.Value
+	}
+	return res
+}
+
+func (c *testClient) launch(args LaunchArguments) {
+	c.send("initialize", map[string]string{"adapterID": "neo-go"})
+	c.checkResponse("initialize", nil)
+	c.send("launch", args)
+	c.checkResponse("launch", nil)
+	c.checkEvent("initialized", nil)
+}
+
+func TestServer(t *testing.T) {
+	c, src := newTestClient(t)
+
+	c.send("stackTrace", map[string]int{"threadId": threadID})
+	c.checkError("stackTrace", errNotLaunched.Error())
+	c.launch(LaunchArguments{
+		Method: "main",
+		Args:   []smartcontract.Parameter{{Type: smartcontract.IntegerType, Value: int64(3)}},
+	})
+
+	c.send("setBreakpoints", setBreakpointsArguments{
+		Source:      source{Path: src},
+		Breakpoints: []sourceBreakpoint{{Line: 12}, {Line: 2}},
+	})
+	var bps breakpointsBody
+	c.checkResponse("setBreakpoints", &bps)
+	require.Equal(t, 2, len(bps.Breakpoints))
+	require.True(t, bps.Breakpoints[0].Verified)
+	require.False(t, bps.Breakpoints[1].Verified)
+
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.checkError("continue", "configuration is not done")
+	c.send("configurationDone", nil)
+	c.checkResponse("configurationDone", nil)
+	c.checkStopped(reasonBreakpoint)
+	c.checkFrame("Main", 12, 2)
+
+	c.send("scopes", frameArguments{FrameID: 1})
+	var scopes scopesBody
+	c.checkResponse("scopes", &scopes)
+	require.Equal(t, 4, len(scopes.Scopes))
+	require.Equal(t, "3", c.variables(scopes.Scopes[0].VariablesReference)["a"])
+	require.Equal(t, "4", c.variables(scopes.Scopes[1].VariablesReference)["b"])
+	require.Equal(t, "1", c.variables(scopes.Scopes[2].VariablesReference)["counter"])
+
+	c.send("stepIn", map[string]int{"threadId": threadID})
+	c.checkResponse("stepIn", nil)
+	c.checkStopped(reasonStep)
+	c.checkFrame("double", 22, 3)
+
+	c.send("stepOut", map[string]int{"threadId": threadID})
+	c.checkResponse("stepOut", nil)
+	c.checkStopped(reasonStep)
+	c.checkFrame("Main", 13, 2)
+
+	c.send("next", map[string]int{"threadId": threadID})
+	c.checkResponse("next", nil)
+	var out outputBody
+	c.checkEvent("output", &out)
+	require.Equal(t, "log: done\n", out.Output)
+	c.checkStopped(reasonStep)
+	c.checkFrame("Main", 14, 2)
+
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.checkResponse("continue", nil)
+	c.checkEvent("output", &out)
+	require.Equal(t, `HALT: [{"type":"Integer","value":"8"}]`+"\n", out.Output)
+	var exited exitedBody
+	c.checkEvent("exited", &exited)
+	require.Equal(t, 0, exited.ExitCode)
+	c.checkEvent("terminated", nil)
+
+	c.send("next", map[string]int{"threadId": threadID})
+	c.checkError("next", "program has finished")
+	c.send("disconnect", nil)
+	c.checkResponse("disconnect", nil)
+	require.NoError(t, <-c.done)
+}
+
+func TestServerException(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.launch(LaunchArguments{Method: "fail", StopOnEntry: true})
+	c.send("configurationDone", nil)
+	c.checkResponse("configurationDone", nil)
+	// Global variables are initialized first.
+	c.checkStopped(reasonEntry)
+	c.checkFrame("_initialize", 8, 3)
+
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.checkResponse("continue", nil)
+	var stopped stoppedBody
+	c.checkEvent("stopped", &stopped)
+	require.Equal(t, reasonException, stopped.Reason)
+	require.Contains(t, stopped.Text, "boom")
+
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.checkResponse("continue", nil)
+	var out outputBody
+	c.checkEvent("output", &out)
+	require.True(t, strings.HasPrefix(out.Output, "FAULT: "))
+	var exited exitedBody
+	c.checkEvent("exited", &exited)
+	require.Equal(t, 1, exited.ExitCode)
+	c.checkEvent("terminated", nil)
+}
+
+func TestServerNativeCall(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.launch(LaunchArguments{Method: "native"})
+	c.send("configurationDone", nil)
+	c.checkResponse("configurationDone", nil)
+	var out outputBody
+	c.checkEvent("output", &out)
+	require.Equal(t, `HALT: [{"type":"ByteString","value":"TkVP"}]`+"\n", out.Output)
+	var exited exitedBody
+	c.checkEvent("exited", &exited)
+	require.Equal(t, 0, exited.ExitCode)
+	c.checkEvent("terminated", nil)
+}
+
+func TestServerLaunchErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.send("launch", LaunchArguments{Program: "unknown.nef"})
+	c.checkError("launch", "can't read NEF file")
+	c.send("launch", LaunchArguments{Method: "main", Script: "0102"})
+	c.checkResponse("launch", nil)
+	c.checkEvent("initialized", nil)
+	c.send("launch", LaunchArguments{Method: "main"})
+	c.checkError("launch", "already launched")
+	c.send("unknown", nil)
+	c.checkError("unknown", "unsupported command")
+	c.send("disconnect", nil)
+	c.checkResponse("disconnect", nil)
+	require.NoError(t, <-c.done)
+}
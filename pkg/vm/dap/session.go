@@ -0,0 +1,275 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Output categories.
+const (
+	categoryConsole = "console"
+	categoryStdout  = "stdout"
+)
+
+var (
+	runtimeLogID    = interopnames.ToID([]byte(interopnames.SystemRuntimeLog))
+	runtimeNotifyID = interopnames.ToID([]byte(interopnames.SystemRuntimeNotify))
+)
+
+// session is a single contract invocation being debugged. The contract is
+// deployed and the script is executed in a test VM of the chain, changes made
+// by them are never persisted.
+type session struct {
+	*program
+	v *vm.VM
+	// syscall is the chain's syscall handler.
+	syscall func(v *vm.VM, id uint32) error
+	// breakpoints contain breakpoints offsets by document, offsets maps all
+	// of them to the corresponding document.
+	breakpoints map[int][]int
+	offsets     map[int]int
+	// err is the error VM has failed with.
+	err error
+	// output reports logs and notifications emitted by the contract.
+	output func(category, text string)
+	// refs contain functions returning children of the variables given to
+	// the client, they're only valid while execution is stopped.
+	refs []func() []variable
+}
+
+// newSession creates a VM for the given invocation script and deploys the
+// contract in it before the script is executed.
+func newSession(chain blockchainer.Blockchainer, p *program, script []byte, output func(category, text string)) (*session, error) {
+	b, err := debugger.NextBlock(chain)
+	if err != nil {
+		return nil, err
+	}
+	deploy, err := p.deployScript(chain.ManagementContractHash())
+	if err != nil {
+		return nil, err
+	}
+	tx := &transaction.Transaction{
+		Script: script,
+		Signers: []transaction.Signer{{
+			Account: p.sender,
+			Scopes:  transaction.CalledByEntry,
+		}},
+	}
+	v, _ := chain.GetTestVM(trigger.Application, tx, b)
+	v.LoadScriptWithFlags(script, callflag.All)
+	// Deployment is executed on top of the invocation script, so that it
+	// doesn't become the entry script.
+	v.LoadScriptWithFlags(deploy, callflag.All)
+	for v.Istack().Len() > 1 {
+		if err := v.StepInto(); err != nil {
+			return nil, fmt.Errorf("can't deploy the contract: %w", err)
+		}
+	}
+	s := &session{
+		program:     p,
+		v:           v,
+		syscall:     v.SyscallHandler,
+		breakpoints: make(map[int][]int),
+		offsets:     make(map[int]int),
+		output:      output,
+	}
+	v.SyscallHandler = s.handleSyscall
+	return s, nil
+}
+
+// setBreakpoints replaces breakpoints of the given document. It returns the
+// breakpoints verification results.
+func (s *session) setBreakpoints(path string, lines []int) []breakpoint {
+	res := make([]breakpoint, len(lines))
+	doc, ok := s.document(path)
+	if !ok {
+		for i := range lines {
+			res[i] = breakpoint{Line: lines[i], Message: "unknown source"}
+		}
+		return res
+	}
+	for _, offset := range s.breakpoints[doc] {
+		delete(s.offsets, offset)
+	}
+	var offsets []int
+	for i := range lines {
+		res[i] = breakpoint{Line: lines[i], Source: s.source(doc)}
+		lineOffsets := s.LineOffsets(doc, lines[i])
+		if len(lineOffsets) == 0 {
+			res[i].Message = "no code at this line"
+			continue
+		}
+		res[i].Verified = true
+		for _, offset := range lineOffsets {
+			s.offsets[offset] = doc
+		}
+		offsets = append(offsets, lineOffsets...)
+	}
+	s.breakpoints[doc] = offsets
+	return res
+}
+
+// atBreakpoint checks whether the next instruction has a breakpoint set.
+func (s *session) atBreakpoint(ctx *vm.Context) bool {
+	if !s.Covers(ctx) {
+		return false
+	}
+	_, ok := s.offsets[ctx.NextIP()]
+	return ok
+}
+
+// run executes the script until stop returns true, breakpoint is reached or
+// execution ends. It returns the reason of the stop, empty string means
+// that the execution has finished.
+func (s *session) run(stop func(ctx *vm.Context, depth int) bool, reason string) string {
+	s.refs = nil
+	for {
+		err := s.v.StepInto()
+		if s.v.HasFailed() {
+			s.err = err
+			if s.err == nil {
+				s.err = errors.New("VM has failed")
+			}
+			return reasonException
+		}
+		if s.v.HasStopped() {
+			return ""
+		}
+		ctx := s.v.Context()
+		if s.atBreakpoint(ctx) {
+			return reasonBreakpoint
+		}
+		if stop != nil && stop(ctx, s.v.Istack().Len()) {
+			return reason
+		}
+	}
+}
+
+// cont continues execution until the next breakpoint.
+func (s *session) cont() string {
+	return s.run(nil, "")
+}
+
+// stepLine executes the script until the beginning of the next contract
+// source line is reached. If over is true, lines of the called methods are not
+// taken into account.
+func (s *session) stepLine(over bool) string {
+	return s.run(s.NextLine(s.v, over), reasonStep)
+}
+
+// stepOut executes the script until the next contract source line of the
+// calling method is reached.
+func (s *session) stepOut() string {
+	depth := s.v.Istack().Len()
+	return s.run(func(ctx *vm.Context, currDepth int) bool {
+		return currDepth < depth && s.Covers(ctx) && s.SeqPointStart(ctx.NextIP()) != nil
+	}, reasonStep)
+}
+
+// frame returns the context of the given stack frame along with the
+// current instruction of it.
+func (s *session) frame(id int) (*vm.Context, int, error) {
+	istack := s.v.Istack()
+	if id < 1 || id > istack.Len() {
+		return nil, 0, fmt.Errorf("unknown frame %d", id)
+	}
+	ctx := istack.Peek(id - 1).Item().(*vm.Context)
+	if id == 1 {
+		return ctx, ctx.NextIP(), nil
+	}
+	// Calling contexts are stopped at the call instruction.
+	return ctx, ctx.IP(), nil
+}
+
+// stackTrace returns the stack frames with the current one being the first.
+func (s *session) stackTrace() []stackFrame {
+	n := s.v.Istack().Len()
+	frames := make([]stackFrame, 0, n)
+	for id := 1; id <= n; id++ {
+		ctx, ip, _ := s.frame(id)
+		frame := stackFrame{
+			ID:   id,
+			Name: fmt.Sprintf("%s:%d", ctx.ScriptHash().StringLE(), ip),
+		}
+		if s.Covers(ctx) {
+			if m := s.Method(ip); m != nil {
+				frame.Name = m.ID
+			}
+			if sp := s.SeqPoint(ip); sp != nil {
+				frame.Source = s.source(sp.Document)
+				frame.Line, frame.Column = sp.StartLine, sp.StartCol
+			}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// scopes returns the variable scopes of the given stack frame.
+func (s *session) scopes(frameID int) ([]scope, error) {
+	ctx, ip, err := s.frame(frameID)
+	if err != nil {
+		return nil, err
+	}
+	var args, locals, statics map[int]debugger.SlotVariable
+	if s.Covers(ctx) {
+		args, locals, statics = s.Arguments(ip, len(ctx.Arguments())), s.Locals(ip), s.Statics()
+	}
+	estack := ctx.Estack().ToArray()
+	return []scope{
+		{Name: "Arguments", VariablesReference: s.slotReference(ctx.Arguments(), args)},
+		{Name: "Locals", VariablesReference: s.slotReference(ctx.Locals(), locals)},
+		{Name: "Static", VariablesReference: s.slotReference(ctx.Statics(), statics)},
+		{Name: "Evaluation Stack", VariablesReference: s.addReference(func() []variable {
+			res := make([]variable, len(estack))
+			for i := range estack {
+				res[i] = s.itemVariable(fmt.Sprintf("[%d]", i), estack[len(estack)-1-i])
+			}
+			return res
+		})},
+	}, nil
+}
+
+// variables returns the variables with the given reference.
+func (s *session) variables(ref int) ([]variable, error) {
+	if ref < 1 || ref > len(s.refs) {
+		return nil, fmt.Errorf("unknown variables reference %d", ref)
+	}
+	return s.refs[ref-1](), nil
+}
+
+// handleSyscall passes syscalls to the chain reporting logs and notifications
+// emitted by the contract.
+func (s *session) handleSyscall(v *vm.VM, id uint32) error {
+	var text string
+	switch id {
+	case runtimeLogID:
+		if v.Estack().Len() > 0 {
+			text = fmt.Sprintf("log: %s\n", v.Estack().Peek(0).String())
+		}
+	case runtimeNotifyID:
+		if v.Estack().Len() > 1 {
+			name := v.Estack().Peek(0).String()
+			data, err := stackitem.ToJSONWithTypes(v.Estack().Peek(1).Item())
+			if err == nil {
+				text = fmt.Sprintf("notification %s: %s\n", name, data)
+			}
+		}
+	}
+	if err := s.syscall(v, id); err != nil {
+		return err
+	}
+	if text != "" {
+		s.output(categoryStdout, text)
+	}
+	return nil
+}
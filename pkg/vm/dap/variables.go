@@ -0,0 +1,103 @@
+package dap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/debugger"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// addReference registers the function returning children of some variable
+// and returns the reference to be given to the client.
+func (s *session) addReference(f func() []variable) int {
+	s.refs = append(s.refs, f)
+	return len(s.refs)
+}
+
+// slotReference returns the reference to the slot variables, names and types
+// of them are taken from the debug information if it's available.
+func (s *session) slotReference(items []stackitem.Item, vars map[int]debugger.SlotVariable) int {
+	return s.addReference(func() []variable {
+		res := make([]variable, len(items))
+		for i := range items {
+			name := fmt.Sprintf("[%d]", i)
+			if sv, ok := vars[i]; ok {
+				name = sv.Name
+			}
+			res[i] = s.itemVariable(name, items[i])
+		}
+		return res
+	})
+}
+
+// itemVariable converts the stack item into a variable, compound items get
+// references to their elements.
+func (s *session) itemVariable(name string, item stackitem.Item) variable {
+	res := variable{
+		Name:  name,
+		Value: itemValue(item),
+	}
+	if item == nil {
+		return res
+	}
+	res.Type = item.Type().String()
+	switch t := item.(type) {
+	case *stackitem.Array, *stackitem.Struct:
+		elems := t.Value().([]stackitem.Item)
+		res.VariablesReference = s.addReference(func() []variable {
+			vars := make([]variable, len(elems))
+			for i := range elems {
+				vars[i] = s.itemVariable(fmt.Sprintf("[%d]", i), elems[i])
+			}
+			return vars
+		})
+	case *stackitem.Map:
+		elems := t.Value().([]stackitem.MapElement)
+		res.VariablesReference = s.addReference(func() []variable {
+			vars := make([]variable, len(elems))
+			for i := range elems {
+				vars[i] = s.itemVariable(itemValue(elems[i].Key), elems[i].Value)
+			}
+			return vars
+		})
+	}
+	return res
+}
+
+// itemValue returns a short human-readable representation of the stack item.
+func itemValue(item stackitem.Item) string {
+	switch t := item.(type) {
+	case nil, stackitem.Null:
+		return "null"
+	case *stackitem.Bool:
+		return strconv.FormatBool(t.Value().(bool))
+	case *stackitem.BigInteger:
+		return t.Value().(fmt.Stringer).String()
+	case *stackitem.ByteArray, *stackitem.Buffer:
+		b := t.Value().([]byte)
+		if utf8.Valid(b) && isPrintable(string(b)) {
+			return strconv.Quote(string(b))
+		}
+		return "0x" + hex.EncodeToString(b)
+	case *stackitem.Array, *stackitem.Struct:
+		return fmt.Sprintf("%s[%d]", t.Type(), len(t.Value().([]stackitem.Item)))
+	case *stackitem.Map:
+		return fmt.Sprintf("%s[%d]", t.Type(), len(t.Value().([]stackitem.MapElement)))
+	case *stackitem.Pointer:
+		return fmt.Sprintf("%s(%d)", t.Type(), t.Position())
+	default:
+		return t.Type().String()
+	}
+}
+
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !strconv.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
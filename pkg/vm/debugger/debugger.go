@@ -0,0 +1,193 @@
+/*
+Package debugger contains helpers shared by the VM CLI and the debug adapter.
+They map the code being executed to the contract sources using the compiler
+debug information and prepare blocks for the scripts executed against the
+chain.
+*/
+package debugger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+)
+
+// Program is a compiler debug information bound to the script it describes.
+type Program struct {
+	*compiler.DebugInfo
+	// Hash is the hash the script is executed with.
+	Hash util.Uint160
+}
+
+// SlotVariable is a variable name and type bound to the slot index.
+type SlotVariable struct {
+	Name string
+	Type string
+}
+
+// Covers checks whether the debug information describes the script executed
+// in the given context.
+func (p *Program) Covers(ctx *vm.Context) bool {
+	return p != nil && p.DebugInfo != nil && ctx != nil && ctx.ScriptHash().Equals(p.Hash)
+}
+
+// Method returns the method containing the given instruction.
+func (p *Program) Method(ip int) *compiler.MethodDebugInfo {
+	for i := range p.Methods {
+		if int(p.Methods[i].Range.Start) <= ip && ip <= int(p.Methods[i].Range.End) {
+			return &p.Methods[i]
+		}
+	}
+	return nil
+}
+
+// SeqPoint returns the sequence point of the statement the given instruction
+// belongs to.
+func (p *Program) SeqPoint(ip int) *compiler.DebugSeqPoint {
+	m := p.Method(ip)
+	if m == nil {
+		return nil
+	}
+	var res *compiler.DebugSeqPoint
+	for i := range m.SeqPoints {
+		if m.SeqPoints[i].Opcode <= ip && (res == nil || res.Opcode < m.SeqPoints[i].Opcode) {
+			res = &m.SeqPoints[i]
+		}
+	}
+	return res
+}
+
+// SeqPointStart returns the sequence point starting at the given instruction,
+// nil if there is no such point.
+func (p *Program) SeqPointStart(ip int) *compiler.DebugSeqPoint {
+	sp := p.SeqPoint(ip)
+	if sp == nil || sp.Opcode != ip {
+		return nil
+	}
+	return sp
+}
+
+// LineOffsets returns the offsets of the code corresponding to the given
+// source line of the document, one per method (the line can be a part of
+// several methods because of inlining).
+func (p *Program) LineOffsets(doc int, line int) []int {
+	var res []int
+	for i := range p.Methods {
+		offset := -1
+		for _, sp := range p.Methods[i].SeqPoints {
+			if sp.Document == doc && sp.StartLine == line && (offset < 0 || sp.Opcode < offset) {
+				offset = sp.Opcode
+			}
+		}
+		if offset >= 0 {
+			res = append(res, offset)
+		}
+	}
+	return res
+}
+
+// Locals returns the local variables of the method containing the given
+// instruction.
+func (p *Program) Locals(ip int) map[int]SlotVariable {
+	m := p.Method(ip)
+	if m == nil {
+		return nil
+	}
+	return ParseSlotVariables(m.Variables)
+}
+
+// Arguments returns the parameters of the method containing the given
+// instruction, n is the number of the method arguments slot items. Receivers
+// aren't stored in the debug information files, so methods having n greater
+// than the number of parameters are considered to have a receiver passed as
+// the first argument.
+func (p *Program) Arguments(ip int, n int) map[int]SlotVariable {
+	m := p.Method(ip)
+	if m == nil {
+		return nil
+	}
+	var offset int
+	if n > len(m.Parameters) {
+		offset = 1
+	}
+	res := make(map[int]SlotVariable, len(m.Parameters))
+	for i, param := range m.Parameters {
+		res[i+offset] = SlotVariable{Name: param.Name, Type: param.Type}
+	}
+	return res
+}
+
+// Statics returns the global variables of the contract.
+func (p *Program) Statics() map[int]SlotVariable {
+	return ParseSlotVariables(p.StaticVariables)
+}
+
+// NextLine returns a function checking whether the execution has reached the
+// beginning of the source line following the one VM is currently at. It's to
+// be called after every instruction executed with the current context and
+// invocation stack depth. If over is true, lines of the called methods are not
+// taken into account. The code not covered by the program is never considered
+// to be the next line.
+func (p *Program) NextLine(v *vm.VM, over bool) func(ctx *vm.Context, depth int) bool {
+	var (
+		ctx     = v.Context()
+		depth   = v.Istack().Len()
+		startIP = -1
+		start   *compiler.DebugSeqPoint
+	)
+	if p.Covers(ctx) {
+		startIP = ctx.NextIP()
+		start = p.SeqPoint(startIP)
+	}
+	return func(ctx *vm.Context, currDepth int) bool {
+		if over && currDepth > depth || !p.Covers(ctx) {
+			return false
+		}
+		ip := ctx.NextIP()
+		sp := p.SeqPointStart(ip)
+		if sp == nil {
+			return false
+		}
+		return start == nil || currDepth != depth || ip <= startIP ||
+			sp.Document != start.Document || sp.StartLine != start.StartLine
+	}
+}
+
+// ParseSlotVariables parses variables given in a "{name},{type},{slot}"
+// format, malformed entries are skipped.
+func ParseSlotVariables(vars []string) map[int]SlotVariable {
+	res := make(map[int]SlotVariable, len(vars))
+	for _, v := range vars {
+		ss := strings.Split(v, ",")
+		if len(ss) != 3 {
+			continue
+		}
+		index, err := strconv.Atoi(ss[2])
+		if err != nil {
+			continue
+		}
+		res[index] = SlotVariable{Name: ss[0], Type: ss[1]}
+	}
+	return res
+}
+
+// NextBlock returns a block following the current chain's one for scripts to
+// be executed in.
+func NextBlock(chain blockchainer.Blockchainer) (*block.Block, error) {
+	cfg := chain.GetConfig()
+	b := block.New(cfg.StateRootInHeader)
+	b.Index = chain.BlockHeight() + 1
+	hdr, err := chain.GetHeader(chain.GetHeaderHash(int(b.Index - 1)))
+	if err != nil {
+		return nil, fmt.Errorf("can't get the last block: %w", err)
+	}
+	b.Timestamp = hdr.Timestamp + uint64(cfg.SecondsPerBlock*int(time.Second/time.Millisecond))
+	return b, nil
+}
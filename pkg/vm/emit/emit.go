@@ -82,6 +82,8 @@ func Array(w *io.BinWriter, es ...interface{}) {
 			Array(w, e...)
 		case int64:
 			Int(w, e)
+		case int:
+			Int(w, int64(e))
 		case *big.Int:
 			bigInt(w, e)
 		case string:
@@ -173,6 +173,14 @@ func TestEmitArray(t *testing.T) {
 		assert.EqualValues(t, opcode.PUSH0, res[35])
 	})
 
+	t.Run("int", func(t *testing.T) {
+		buf := io.NewBufBinWriter()
+		Array(buf.BinWriter, 3, int64(3))
+		require.NoError(t, buf.Err)
+		assert.EqualValues(t, []byte{byte(opcode.PUSH3), byte(opcode.PUSH3),
+			byte(opcode.PUSH2), byte(opcode.PACK)}, buf.Bytes())
+	})
+
 	t.Run("empty", func(t *testing.T) {
 		buf := io.NewBufBinWriter()
 		Array(buf.BinWriter)
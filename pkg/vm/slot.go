@@ -66,3 +66,16 @@ func (s *Slot) Size() int {
 	}
 	return len(s.storage)
 }
+
+// items returns a copy of slot contents with missing values replaced by Null.
+// It's safe to call it for nil or uninitialized slot.
+func (s *Slot) items() []stackitem.Item {
+	if s == nil || s.storage == nil {
+		return nil
+	}
+	res := make([]stackitem.Item, len(s.storage))
+	for i := range s.storage {
+		res[i] = s.Get(i)
+	}
+	return res
+}
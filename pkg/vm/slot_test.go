@@ -23,3 +23,15 @@ func TestSlot_Get(t *testing.T) {
 	s.Set(1, stackitem.NewBigInteger(big.NewInt(42)))
 	require.Equal(t, stackitem.NewBigInteger(big.NewInt(42)), s.Get(1))
 }
+
+func TestSlot_Items(t *testing.T) {
+	var s *Slot
+	require.Nil(t, s.items())
+
+	s = newSlot(newRefCounter())
+	require.Nil(t, s.items())
+
+	s.init(2)
+	s.Set(1, stackitem.NewBool(true))
+	require.Equal(t, []stackitem.Item{stackitem.Null{}, stackitem.NewBool(true)}, s.items())
+}
@@ -60,6 +60,12 @@ const (
 // SyscallHandler is a type for syscall handler.
 type SyscallHandler = func(*VM, uint32) error
 
+// OnExecHook is a type for the function called after every executed
+// instruction. ctx is the context instruction belongs to (it can be unloaded
+// already), op is the instruction opcode and depth is the invocation stack
+// size at the moment of instruction execution.
+type OnExecHook = func(ctx *Context, op opcode.Opcode, depth int)
+
 // VM represents the virtual machine.
 type VM struct {
 	state State
@@ -83,6 +89,9 @@ type VM struct {
 	// LoadToken handles CALLT opcode.
 	LoadToken func(id int32) error
 
+	// onExecHook is called after every executed instruction.
+	onExecHook OnExecHook
+
 	trigger trigger.Type
 
 	// Invocations is a script invocation counter.
@@ -123,6 +132,13 @@ func (v *VM) SetPriceGetter(f func(opcode.Opcode, []byte) int64) {
 	v.getPrice = f
 }
 
+// SetOnExecHook registers the given OnExecHook in v, it's called after every
+// instruction execution (including failed ones) and can be used to trace
+// program execution. nil removes the hook.
+func (v *VM) SetOnExecHook(f OnExecHook) {
+	v.onExecHook = f
+}
+
 // GasConsumed returns the amount of GAS consumed during execution.
 func (v *VM) GasConsumed() int64 {
 	return v.gasConsumed
@@ -394,7 +410,7 @@ func (v *VM) Run() error {
 		}
 		// check for breakpoint before executing the next instruction
 		ctx := v.Context()
-		if ctx != nil && ctx.atBreakPoint() {
+		if ctx != nil && ctx.AtBreakPoint() {
 			v.state = BreakState
 		}
 	}
@@ -437,7 +453,7 @@ func (v *VM) StepInto() error {
 	}
 
 	cctx := v.Context()
-	if cctx != nil && cctx.atBreakPoint() {
+	if cctx != nil && cctx.AtBreakPoint() {
 		v.state = BreakState
 	}
 	return nil
@@ -515,6 +531,10 @@ func GetInteropID(parameter []byte) uint32 {
 
 // execute performs an instruction cycle in the VM. Acting on the instruction (opcode).
 func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err error) {
+	// Deferred before the recovery below to be called after it.
+	if v.onExecHook != nil {
+		defer v.onExecHook(ctx, op, v.istack.Len())
+	}
 	// Instead of polluting the whole VM logic with error handling, we will recover
 	// each panic at a central point, putting the VM in a fault state and setting error.
 	defer func() {
@@ -94,6 +94,48 @@ func TestVM_SetPriceGetter(t *testing.T) {
 	})
 }
 
+func TestVM_SetOnExecHook(t *testing.T) {
+	type step struct {
+		ip    int
+		op    opcode.Opcode
+		depth int
+	}
+	var steps []step
+	v := newTestVM()
+	v.SetOnExecHook(func(ctx *Context, op opcode.Opcode, depth int) {
+		steps = append(steps, step{ctx.IP(), op, depth})
+	})
+
+	t.Run("good", func(t *testing.T) {
+		steps = nil
+		v.Load([]byte{byte(opcode.PUSH1), byte(opcode.CALL), 3, byte(opcode.RET), byte(opcode.PUSH2), byte(opcode.RET)})
+		runVM(t, v)
+		require.Equal(t, []step{
+			{0, opcode.PUSH1, 1},
+			{1, opcode.CALL, 1},
+			{4, opcode.PUSH2, 2},
+			{5, opcode.RET, 2},
+			{3, opcode.RET, 1},
+		}, steps)
+	})
+	t.Run("fault", func(t *testing.T) {
+		steps = nil
+		v.Load([]byte{byte(opcode.PUSH1), byte(opcode.THROW)})
+		checkVMFailed(t, v)
+		require.Equal(t, []step{
+			{0, opcode.PUSH1, 1},
+			{1, opcode.THROW, 1},
+		}, steps)
+	})
+	t.Run("removed", func(t *testing.T) {
+		steps = nil
+		v.SetOnExecHook(nil)
+		v.Load([]byte{byte(opcode.PUSH1), byte(opcode.RET)})
+		runVM(t, v)
+		require.Nil(t, steps)
+	})
+}
+
 func TestAddGas(t *testing.T) {
 	v := newTestVM()
 	v.GasLimit = 10
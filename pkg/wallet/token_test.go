@@ -13,10 +13,11 @@ func TestToken_MarshalJSON(t *testing.T) {
 	h, err := util.Uint160DecodeStringLE("f8d448b227991cf07cb96a6f9c0322437f1599b9")
 	require.NoError(t, err)
 
-	tok := NewToken(h, "NEP17 Standard", "NEP17", 8)
+	tok := NewToken(h, "NEP17 Standard", "NEP17", 8, "NEP-17")
 	require.Equal(t, "NEP17 Standard", tok.Name)
 	require.Equal(t, "NEP17", tok.Symbol)
 	require.EqualValues(t, 8, tok.Decimals)
+	require.Equal(t, "NEP-17", tok.Standard)
 	require.Equal(t, h, tok.Hash)
 	require.Equal(t, "NcqKahsZ93ZyYS5bep8G2TY1zRB7tfUPdK", tok.Address())
 
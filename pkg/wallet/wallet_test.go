@@ -146,7 +146,7 @@ func removeWallet(t *testing.T, walletPath string) {
 
 func TestWallet_AddToken(t *testing.T) {
 	w := checkWalletConstructor(t)
-	tok := NewToken(util.Uint160{1, 2, 3}, "Rubl", "RUB", 2)
+	tok := NewToken(util.Uint160{1, 2, 3}, "Rubl", "RUB", 2, "NEP-17")
 	require.Equal(t, 0, len(w.Extra.Tokens))
 	w.AddToken(tok)
 	require.Equal(t, 1, len(w.Extra.Tokens))